@@ -5,22 +5,27 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"time"
 
 	"github.com/ONSdigital/dp-api-clients-go/v2/dataset"
+	"github.com/ONSdigital/dp-api-clients-go/v2/hierarchy"
 	"github.com/ONSdigital/dp-filter-api/config"
 	"github.com/ONSdigital/dp-filter-api/filters"
 	"github.com/ONSdigital/dp-filter-api/middleware"
 	"github.com/ONSdigital/dp-filter-api/models"
-	"github.com/ONSdigital/dp-net/v2/responder"
+	"github.com/ONSdigital/dp-filter-api/observations"
 	"github.com/gorilla/mux"
 )
 
 //go:generate moq -out mock/datasetapi.go -pkg mock . DatasetAPI
 //go:generate moq -out mock/filterflexapi.go -pkg mock . FilterFlexAPI
+//go:generate moq -out mock/hierarchyapi.go -pkg mock . HierarchyAPI
+//go:generate moq -out mock/observationsapi.go -pkg mock . ObservationsAPI
 
 // DatasetAPI - An interface used to access the DatasetAPI
 type DatasetAPI interface {
 	Get(ctx context.Context, userToken, svcToken, collectionID, datasetID string) (dataset.DatasetDetails, error)
+	GetEdition(ctx context.Context, userAuthToken, serviceAuthToken, collectionID, datasetID, edition string) (m dataset.Edition, err error)
 	GetVersion(ctx context.Context, userAuthToken, serviceAuthToken, downloadServiceAuthToken, collectionID, datasetID, edition, version string) (m dataset.Version, err error)
 	GetVersionDimensions(ctx context.Context, userAuthToken, serviceAuthToken, collectionID, id, edition, version string) (m dataset.VersionDimensions, err error)
 	GetOptionsBatchProcess(ctx context.Context, userAuthToken, serviceAuthToken, collectionID, id, edition, version, dimension string, optionIDs *[]string, processBatch dataset.OptionsBatchProcessor, batchSize, maxWorkers int) (err error)
@@ -30,6 +35,16 @@ type FilterFlexAPI interface {
 	ForwardRequest(*http.Request) (*http.Response, error)
 }
 
+// HierarchyAPI - An interface used to access the hierarchy of a dataset instance dimension
+type HierarchyAPI interface {
+	GetChild(ctx context.Context, instanceID, name, code string) (hierarchy.Model, error)
+}
+
+// ObservationsAPI - An interface used to query the observations of a dataset version
+type ObservationsAPI interface {
+	GetObservations(ctx context.Context, userAuthToken, serviceAuthToken, collectionID, datasetID, edition, version string, query url.Values) (observations.Observations, error)
+}
+
 // OutputQueue - An interface used to queue filter outputs
 type OutputQueue interface {
 	Queue(output *models.Filter) error
@@ -45,6 +60,10 @@ type FilterAPI struct {
 	outputQueue          OutputQueue
 	datasetAPI           DatasetAPI
 	FilterFlexAPI        FilterFlexAPI
+	hierarchyAPI         HierarchyAPI
+	observationsAPI      ObservationsAPI
+	labels               *labelCache
+	latestVersions       *latestVersionCache
 	downloadServiceURL   *url.URL
 	downloadServiceToken string
 	serviceAuthToken     string
@@ -52,8 +71,35 @@ type FilterAPI struct {
 	maxLimit             int
 	defaultOffset        int
 	maxDatasetOptions    int
+	maxXLSXRows          int
+	maxCells             int64
+	maxDatasetCells      map[string]int64
+	publishedCacheMaxAge time.Duration
+	idempotencyKeyTTL    time.Duration
+	idempotencyKeyLease  time.Duration
+	maxEmbeddedOptions   int
 	BatchMaxWorkers      int
 	enableURLRewriting   bool
+	enableVersionCheck   bool
+
+	enablePublishEventConsumer bool
+}
+
+// Option provides an optional client to the API, which is only required by some features
+type Option func(api *FilterAPI)
+
+// WithHierarchyAPI provides the client used to expand option selectors against the hierarchy of a dimension
+func WithHierarchyAPI(hierarchyAPI HierarchyAPI) Option {
+	return func(api *FilterAPI) {
+		api.hierarchyAPI = hierarchyAPI
+	}
+}
+
+// WithObservationsAPI provides the client used to preview the observations of a filter blueprint
+func WithObservationsAPI(observationsAPI ObservationsAPI) Option {
+	return func(api *FilterAPI) {
+		api.observationsAPI = observationsAPI
+	}
 }
 
 // Setup manages all the routes configured to API
@@ -67,7 +113,8 @@ func Setup(
 	hostURL *url.URL,
 	datasetAPIURL *url.URL,
 	downloadServiceURL *url.URL,
-	enableURLRewriting bool) *FilterAPI {
+	enableURLRewriting bool,
+	opts ...Option) *FilterAPI {
 	api := &FilterAPI{
 		host:                 hostURL,
 		DatasetAPIURL:        datasetAPIURL,
@@ -76,6 +123,8 @@ func Setup(
 		dataStore:            dataStore,
 		outputQueue:          outputQueue,
 		datasetAPI:           datasetAPI,
+		labels:               newLabelCache(cfg.LabelCacheTTL),
+		latestVersions:       newLatestVersionCache(cfg.LatestVersionCacheTTL),
 		downloadServiceURL:   downloadServiceURL,
 		downloadServiceToken: cfg.DownloadServiceSecretKey,
 		serviceAuthToken:     cfg.ServiceAuthToken,
@@ -83,13 +132,26 @@ func Setup(
 		maxLimit:             cfg.DefaultMaxLimit,
 		defaultOffset:        cfg.MongoConfig.Offset,
 		maxDatasetOptions:    cfg.MaxDatasetOptions,
+		maxXLSXRows:          cfg.MaxXLSXRows,
+		maxCells:             cfg.MaxCells,
+		maxDatasetCells:      cfg.MaxDatasetCells,
+		publishedCacheMaxAge: cfg.PublishedCacheMaxAge,
+		idempotencyKeyTTL:    cfg.IdempotencyKeyTTL,
+		idempotencyKeyLease:  cfg.IdempotencyKeyLease,
+		maxEmbeddedOptions:   cfg.MaxEmbeddedOptions,
 		BatchMaxWorkers:      cfg.BatchMaxWorkers,
 		enableURLRewriting:   enableURLRewriting,
+		enableVersionCheck:   cfg.EnableNewerVersionCheck,
+
+		enablePublishEventConsumer: cfg.EnablePublishEventConsumer,
+	}
+
+	for _, opt := range opts {
+		opt(api)
 	}
 
 	// middleware
 	assert := middleware.NewAssert(
-		responder.New(),
 		datasetAPI,
 		filterFlexAPI,
 		dataStore,
@@ -98,13 +160,20 @@ func Setup(
 	)
 
 	// routes
-	api.Router.Handle("/filters", assert.DatasetType(http.HandlerFunc(api.postFilterBlueprintHandler))).Methods("POST")
+	api.Router.Handle("/filters", assert.DatasetType(api.idempotent(http.HandlerFunc(api.postFilterBlueprintHandler)))).Methods("POST")
 	api.Router.Handle("/filters/{filter_blueprint_id}", assert.FilterType(http.HandlerFunc(api.getFilterBlueprintHandler))).Methods("GET")
-	api.Router.Handle("/filters/{filter_blueprint_id}", assert.FilterType(http.HandlerFunc(api.putFilterBlueprintHandler))).Methods("PUT")
+	api.Router.Handle("/filters/{filter_blueprint_id}", assert.FilterType(api.idempotent(http.HandlerFunc(api.putFilterBlueprintHandler)))).Methods("PUT")
+	api.Router.Handle("/filters/{filter_blueprint_id}", assert.FilterType(http.HandlerFunc(api.patchFilterBlueprintHandler))).Methods("PATCH")
+	api.Router.Handle("/filters/{filter_blueprint_id}/copy", assert.FilterType(http.HandlerFunc(api.postFilterBlueprintCopyHandler))).Methods("POST")
+	api.Router.Handle("/filters/{filter_blueprint_id}/rebase", assert.FilterType(http.HandlerFunc(api.postFilterBlueprintRebaseHandler))).Methods("POST")
+	api.Router.Handle("/filters/{filter_blueprint_id}/restore", assert.FilterType(http.HandlerFunc(api.postFilterBlueprintRestoreHandler))).Methods("POST")
 	api.Router.Handle("/filters/{filter_blueprint_id}/submit", assert.FilterType(http.HandlerFunc(api.postFilterBlueprintSubmitHandler))).Methods("POST")
+	api.Router.Handle("/filters/{filter_blueprint_id}/estimate", assert.FilterType(http.HandlerFunc(api.getFilterBlueprintEstimateHandler))).Methods("GET")
+	api.Router.Handle("/filters/{filter_blueprint_id}/preview", assert.FilterType(http.HandlerFunc(api.getFilterBlueprintPreviewHandler))).Methods("GET")
 
 	api.Router.Handle("/filters/{filter_blueprint_id}/dimensions", assert.FilterType(http.HandlerFunc(api.getFilterBlueprintDimensionsHandler))).Methods("GET")
 	api.Router.Handle("/filters/{filter_blueprint_id}/dimensions", assert.FilterType(http.HandlerFunc(api.filterFlexNullEndpointHandler))).Methods("POST")
+	api.Router.Handle("/filters/{filter_blueprint_id}/dimensions", assert.FilterType(http.HandlerFunc(api.replaceFilterBlueprintDimensionsHandler))).Methods("PUT")
 	api.Router.Handle("/filters/{filter_blueprint_id}/dimensions/{name}", assert.FilterType(http.HandlerFunc(api.putFilterBlueprintDimensionHandler))).Methods("PUT")
 	api.Router.Handle("/filters/{filter_blueprint_id}/dimensions/{name}", assert.FilterType(http.HandlerFunc(api.getFilterBlueprintDimensionHandler))).Methods("GET")
 	api.Router.Handle("/filters/{filter_blueprint_id}/dimensions/{name}", assert.FilterType(http.HandlerFunc(api.addFilterBlueprintDimensionHandler))).Methods("POST")
@@ -121,9 +190,11 @@ func Setup(
 	api.Router.Handle("/filter-outputs/{filter_output_id}", assert.FilterOutputType(http.HandlerFunc(api.updateFilterOutputHandler))).Methods("PUT")
 
 	if cfg.EnablePrivateEndpoints {
-		api.Router.Handle("/filter-outputs/{filter_output_id}/events", assert.FilterOutputType(http.HandlerFunc(api.addEventHandler))).Methods("POST")
+		api.Router.Handle("/filter-outputs/{filter_output_id}/events", assert.FilterOutputType(api.idempotent(http.HandlerFunc(api.addEventHandler)))).Methods("POST")
 	}
 
+	api.setupV2(assert)
+
 	return api
 }
 
@@ -1,12 +1,15 @@
 package api_test
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"io"
 	"net/http"
 
 	"github.com/ONSdigital/dp-filter-api/api"
 	"github.com/ONSdigital/dp-filter-api/config"
 	"github.com/ONSdigital/dp-filter-api/filters"
+	"github.com/ONSdigital/dp-filter-api/models"
 	dprequest "github.com/ONSdigital/dp-net/request"
 	. "github.com/smartystreets/goconvey/convey"
 )
@@ -21,14 +24,14 @@ const (
 )
 
 var (
-	filterNotFoundResponse         = filters.ErrFilterBlueprintNotFound.Error() + "\n"
-	dimensionNotFoundResponse      = filters.ErrDimensionNotFound.Error() + "\n"
-	filerBlueprintConflictResponse = filters.ErrFilterBlueprintConflict.Error() + "\n"
-	versionNotFoundResponse        = filters.ErrVersionNotFound.Error() + "\n"
-	optionNotFoundResponse         = filters.ErrDimensionOptionNotFound.Error() + "\n"
-	invalidQueryParameterResponse  = filters.ErrInvalidQueryParameter.Error() + "\n"
-	badRequestResponse             = api.BadRequest + "\n"
-	internalErrResponse            = api.InternalError + "\n"
+	filterNotFoundResponse         = filters.ErrFilterBlueprintNotFound.Error()
+	dimensionNotFoundResponse      = filters.ErrDimensionNotFound.Error()
+	filerBlueprintConflictResponse = filters.ErrFilterBlueprintConflict.Error()
+	versionNotFoundResponse        = filters.ErrVersionNotFound.Error()
+	optionNotFoundResponse         = filters.ErrDimensionOptionNotFound.Error()
+	invalidQueryParameterResponse  = filters.ErrInvalidQueryParameter.Error()
+	badRequestResponse             = api.BadRequest
+	internalErrResponse            = api.InternalError
 )
 
 // cfg obtains a new config for testing. Each test will have its own config instance by using this func.
@@ -42,6 +45,7 @@ func cfg() *config.Config {
 		EnablePrivateEndpoints:   enablePrivateEndpoints,
 		MaxDatasetOptions:        200,
 		DefaultMaxLimit:          1000,
+		MaxXLSXRows:              1048575,
 		MongoConfig: config.MongoConfig{
 			Limit:  20,
 			Offset: 0,
@@ -58,3 +62,45 @@ func createAuthenticatedRequest(method, url string, body io.Reader) *http.Reques
 	So(err, ShouldBeNil)
 	return r
 }
+
+// validationErrorsResponse returns the JSON errors member of a problem with the provided validation errors
+func validationErrorsResponse(validationErrors ...models.ValidationError) string {
+	b, err := json.Marshal(validationErrors)
+	So(err, ShouldBeNil)
+	return string(b)
+}
+
+// decodeProblem decodes the problem details of an error response body
+func decodeProblem(body string) *filters.Problem {
+	var problem filters.Problem
+	So(json.Unmarshal([]byte(body), &problem), ShouldBeNil)
+	return &problem
+}
+
+// problemDetail returns the detail of the problem in an error response body
+func problemDetail(body string) string {
+	return decodeProblem(body).Detail
+}
+
+// problemErrors returns the JSON errors member of the problem in an error response body
+func problemErrors(body string) string {
+	var problem struct {
+		Errors json.RawMessage `json:"errors"`
+	}
+	So(json.Unmarshal([]byte(body), &problem), ShouldBeNil)
+	return string(problem.Errors)
+}
+
+// pageLink returns the link to a page of a list at the provided path, from the JSON of a cursor and any other query parameters
+func pageLink(path, cursor, query string) *models.LinkObject {
+	href := host + path + "?cursor=" + encodeCursor(cursor)
+	if query != "" {
+		href += "&" + query
+	}
+	return &models.LinkObject{HRef: href}
+}
+
+// encodeCursor returns the value of the cursor query parameter for the JSON of a cursor
+func encodeCursor(cursor string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursor))
+}
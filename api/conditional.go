@@ -0,0 +1,94 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/ONSdigital/dp-filter-api/models"
+	dprequest "github.com/ONSdigital/dp-net/request"
+)
+
+const (
+	// cacheControlPublished is the Cache-Control of responses for published content, which can be stored by shared caches,
+	// but must be revalidated before it is reused, as filter blueprints and filter outputs can change at any time
+	cacheControlPublished = "no-cache"
+
+	// cacheControlUnpublished is the Cache-Control of responses for unpublished content, which must not be stored by shared caches,
+	// and must be revalidated by clients before it is reused
+	cacheControlUnpublished = "private, no-cache"
+
+	// representationSeparator separates the ETag of a filter blueprint from the suffix identifying one of its representations
+	representationSeparator = "~"
+)
+
+// isPublished returns true if the filter blueprint or filter output is for published data
+func isPublished(filter *models.Filter) bool {
+	return filter.Published != nil && *filter.Published == models.Published
+}
+
+// setCacheControl sets the Cache-Control header of a response, only allowing completed filter outputs of published data,
+// which no longer change, to be reused without revalidation for the configured time.
+// It also sets the Vary header, as the labels of dimensions and options depend on the Accept-Language header.
+func (api *FilterAPI) setCacheControl(w http.ResponseWriter, published, completed bool) {
+	w.Header().Set("Vary", acceptLanguageHeader)
+	switch {
+	case !published:
+		w.Header().Set("Cache-Control", cacheControlUnpublished)
+	case completed && api.publishedCacheMaxAge > 0:
+		w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(api.publishedCacheMaxAge.Seconds())))
+	default:
+		w.Header().Set("Cache-Control", cacheControlPublished)
+	}
+}
+
+// writeNotModified responds with a 304 Not Modified, without a body, if the If-None-Match header of a GET request matches the provided ETag.
+// It returns true if the response has been written, in which case the handler must not write anything else.
+func (api *FilterAPI) writeNotModified(w http.ResponseWriter, r *http.Request, eTag string, published, completed bool) bool {
+	if !ifNoneMatch(r, eTag) {
+		return false
+	}
+
+	setETag(w, eTag)
+	api.setCacheControl(w, published, completed)
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// representationETag returns the ETag of a representation of a filter blueprint resource, which also depends on the language it is
+// requested in, and on whether a newer version is available, although neither changes the filter blueprint itself.
+// The ETag of the filter blueprint is followed by a suffix identifying the representation, unless it is in the default language
+// without a newer version. The suffix is ignored when the ETag is provided in an If-Match header.
+func representationETag(eTag, language string, newerVersionAvailable *bool) string {
+	var suffix []string
+	if language != dprequest.DefaultLang {
+		suffix = append(suffix, language)
+	}
+	if newerVersionAvailable != nil && *newerVersionAvailable {
+		suffix = append(suffix, "newer")
+	}
+	if len(suffix) == 0 {
+		return eTag
+	}
+	return eTag + representationSeparator + strings.Join(suffix, representationSeparator)
+}
+
+// ifNoneMatch returns true if any of the entity tags of the If-None-Match header matches the provided ETag, or the header is a wildcard.
+// Entity tags are compared weakly, as defined for If-None-Match, and may be provided with or without quotes.
+func ifNoneMatch(r *http.Request, eTag string) bool {
+	header := r.Header.Get("If-None-Match")
+	if header == "" || eTag == "" {
+		return false
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return true
+		}
+		if strings.Trim(strings.TrimPrefix(tag, "W/"), `"`) == eTag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,235 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-api-clients-go/v2/dataset"
+	"github.com/ONSdigital/dp-filter-api/api"
+	apimock "github.com/ONSdigital/dp-filter-api/api/mock"
+	"github.com/ONSdigital/dp-filter-api/mock"
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestConditionalGetFilterBlueprint(t *testing.T) {
+	t.Parallel()
+
+	filterFlexAPIMock := &apimock.FilterFlexAPIMock{}
+
+	Convey("Given a published filter blueprint and a published cache max age of a minute, which only applies to completed filter outputs", t, func() {
+		config := cfg()
+		config.PublishedCacheMaxAge = time.Minute
+		filterAPI := api.Setup(config, mux.NewRouter(), mock.NewDataStore(), &mock.FilterJob{}, &mock.DatasetAPI{}, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+
+		for _, path := range []string{"/filters/12345678", "/filters/12345678/dimensions", "/filters/12345678/dimensions/age", "/filters/12345678/dimensions/age/options", "/filters/12345678/dimensions/age/options/33"} {
+			Convey("When a GET request to "+path+" is made without an If-None-Match header", func() {
+				r, err := http.NewRequest("GET", cfg().Host+path, http.NoBody)
+				So(err, ShouldBeNil)
+				w := httptest.NewRecorder()
+				filterAPI.Router.ServeHTTP(w, r)
+
+				Convey("Then the resource is returned with its ETag, and must be revalidated before it is reused", func() {
+					So(w.Code, ShouldEqual, http.StatusOK)
+					So(w.Header().Get("ETag"), ShouldEqual, testETag)
+					So(w.Header().Get("Cache-Control"), ShouldEqual, "no-cache")
+					So(w.Header().Get("Vary"), ShouldEqual, "Accept-Language")
+					So(w.Body.Len(), ShouldBeGreaterThan, 0)
+				})
+			})
+
+			Convey("When a GET request to "+path+" is made with an If-None-Match header matching its ETag", func() {
+				r, err := http.NewRequest("GET", cfg().Host+path, http.NoBody)
+				So(err, ShouldBeNil)
+				r.Header.Set("If-None-Match", `"other", "`+testETag+`"`)
+				w := httptest.NewRecorder()
+				filterAPI.Router.ServeHTTP(w, r)
+
+				Convey("Then 304 not modified is returned without a body", func() {
+					So(w.Code, ShouldEqual, http.StatusNotModified)
+					So(w.Header().Get("ETag"), ShouldEqual, testETag)
+					So(w.Header().Get("Cache-Control"), ShouldEqual, "no-cache")
+					So(w.Header().Get("Vary"), ShouldEqual, "Accept-Language")
+					So(w.Body.Len(), ShouldEqual, 0)
+				})
+			})
+
+			Convey("When a GET request to "+path+" is made in Welsh with an If-None-Match header matching the ETag of the English representation", func() {
+				r, err := http.NewRequest("GET", cfg().Host+path, http.NoBody)
+				So(err, ShouldBeNil)
+				r.Header.Set("Accept-Language", "cy")
+				r.Header.Set("If-None-Match", testETag)
+				w := httptest.NewRecorder()
+				filterAPI.Router.ServeHTTP(w, r)
+
+				Convey("Then the Welsh representation is not reported as not modified, as its ETag differs", func() {
+					So(w.Code, ShouldNotEqual, http.StatusNotModified)
+				})
+			})
+
+			Convey("When a GET request to "+path+" is made with an If-None-Match header for a previous ETag", func() {
+				r, err := http.NewRequest("GET", cfg().Host+path, http.NoBody)
+				So(err, ShouldBeNil)
+				r.Header.Set("If-None-Match", testETag1)
+				w := httptest.NewRecorder()
+				filterAPI.Router.ServeHTTP(w, r)
+
+				Convey("Then the resource is returned", func() {
+					So(w.Code, ShouldEqual, http.StatusOK)
+					So(w.Body.Len(), ShouldBeGreaterThan, 0)
+				})
+			})
+		}
+	})
+
+	Convey("Given an unpublished filter blueprint", t, func() {
+		config := cfg()
+		config.PublishedCacheMaxAge = time.Minute
+		filterAPI := api.Setup(config, mux.NewRouter(), mock.NewDataStore().Unpublished(), &mock.FilterJob{}, mock.NewDatasetAPI().Unpublished(), filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+
+		Convey("When an authenticated GET request is made with an If-None-Match header matching its ETag", func() {
+			r := createAuthenticatedRequest("GET", cfg().Host+"/filters/12345678", http.NoBody)
+			r.Header.Set("If-None-Match", `W/"`+testETag+`"`)
+			w := httptest.NewRecorder()
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then 304 not modified is returned, and the response must not be stored by shared caches", func() {
+				So(w.Code, ShouldEqual, http.StatusNotModified)
+				So(w.Header().Get("Cache-Control"), ShouldEqual, "private, no-cache")
+			})
+		})
+
+		Convey("When an unauthenticated GET request is made with an If-None-Match wildcard", func() {
+			r, err := http.NewRequest("GET", cfg().Host+"/filters/12345678", http.NoBody)
+			So(err, ShouldBeNil)
+			r.Header.Set("If-None-Match", "*")
+			w := httptest.NewRecorder()
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then not found is returned rather than not modified", func() {
+				So(w.Code, ShouldEqual, http.StatusNotFound)
+			})
+		})
+	})
+}
+
+func TestConditionalGetFilterBlueprintRepresentation(t *testing.T) {
+	t.Parallel()
+
+	filterFlexAPIMock := &apimock.FilterFlexAPIMock{}
+
+	Convey("Given a published filter blueprint for which a newer version has been published", t, func() {
+		config := cfg()
+		config.EnableNewerVersionCheck = true
+		config.LatestVersionCacheTTL = time.Minute
+		datasetAPIMock := &apimock.DatasetAPIMock{
+			GetEditionFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, collectionID string, datasetID string, edition string) (dataset.Edition, error) {
+				return dataset.Edition{Edition: edition, Links: dataset.Links{LatestVersion: dataset.Link{ID: "2"}}}, nil
+			},
+		}
+		ds := mock.NewDataStore().Mock
+		filterAPI := api.Setup(config, mux.NewRouter(), ds, &mock.FilterJob{}, datasetAPIMock, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+
+		for _, path := range []string{"/filters/12345678", "/v2/filters/12345678"} {
+			Convey("When a GET request to "+path+" is made with an If-None-Match header matching the ETag of the filter blueprint", func() {
+				r, err := http.NewRequest("GET", cfg().Host+path, http.NoBody)
+				So(err, ShouldBeNil)
+				r.Header.Set("If-None-Match", testETag)
+				w := httptest.NewRecorder()
+				filterAPI.Router.ServeHTTP(w, r)
+
+				Convey("Then the representation flagging the newer version is returned, with an ETag identifying it", func() {
+					So(w.Code, ShouldEqual, http.StatusOK)
+					So(w.Header().Get("ETag"), ShouldEqual, testETag+"~newer")
+
+					Convey("And a GET request with an If-None-Match header matching that ETag returns 304 not modified", func() {
+						r.Header.Set("If-None-Match", w.Header().Get("ETag"))
+						w2 := httptest.NewRecorder()
+						filterAPI.Router.ServeHTTP(w2, r)
+						So(w2.Code, ShouldEqual, http.StatusNotModified)
+					})
+				})
+			})
+		}
+
+		Convey("When the filter blueprint is updated with the ETag of a representation in the If-Match header", func() {
+			r := createAuthenticatedRequest("PUT", cfg().Host+"/filters/12345678", strings.NewReader(`{"dataset":{"version":1}}`))
+			r.Header.Set("If-Match", testETag+"~cy~newer")
+			w := httptest.NewRecorder()
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the suffix of the representation is ignored, and the ETag of the filter blueprint is matched", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(ds.UpdateFilterCalls(), ShouldHaveLength, 1)
+				So(ds.UpdateFilterCalls()[0].ETagSelector, ShouldEqual, testETag)
+			})
+		})
+	})
+}
+
+func TestConditionalGetFilterOutput(t *testing.T) {
+	t.Parallel()
+
+	filterFlexAPIMock := &apimock.FilterFlexAPIMock{}
+
+	Convey("Given a published filter output and a published cache max age of a minute", t, func() {
+		config := cfg()
+		config.PublishedCacheMaxAge = time.Minute
+		filterAPI := api.Setup(config, mux.NewRouter(), mock.NewDataStore(), &mock.FilterJob{}, &mock.DatasetAPI{}, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+
+		Convey("When a GET request is made without an If-None-Match header", func() {
+			r, err := http.NewRequest("GET", cfg().Host+"/filter-outputs/12345678", http.NoBody)
+			So(err, ShouldBeNil)
+			w := httptest.NewRecorder()
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the completed filter output is returned with a generated ETag, and can be cached publicly", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Header().Get("ETag"), ShouldNotBeEmpty)
+				So(w.Header().Get("Cache-Control"), ShouldEqual, "public, max-age=60")
+
+				Convey("And a GET request with an If-None-Match header matching the ETag returns 304 not modified without a body", func() {
+					r, err := http.NewRequest("GET", cfg().Host+"/filter-outputs/12345678", http.NoBody)
+					So(err, ShouldBeNil)
+					r.Header.Set("If-None-Match", w.Header().Get("ETag"))
+					w2 := httptest.NewRecorder()
+					filterAPI.Router.ServeHTTP(w2, r)
+
+					So(w2.Code, ShouldEqual, http.StatusNotModified)
+					So(w2.Header().Get("ETag"), ShouldEqual, w.Header().Get("ETag"))
+					So(w2.Body.Len(), ShouldEqual, 0)
+				})
+			})
+		})
+
+		Convey("When a GET request is made for a filter output that is not completed", func() {
+			filterAPI := api.Setup(config, mux.NewRouter(), mock.NewDataStore().MissingPublicLinks(), &mock.FilterJob{}, &mock.DatasetAPI{}, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+			r, err := http.NewRequest("GET", cfg().Host+"/filter-outputs/12345678", http.NoBody)
+			So(err, ShouldBeNil)
+			w := httptest.NewRecorder()
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the filter output, which can still change, must be revalidated before it is reused", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Header().Get("Cache-Control"), ShouldEqual, "no-cache")
+			})
+		})
+
+		Convey("When a GET request is made with the download service token", func() {
+			r, err := http.NewRequest("GET", cfg().Host+"/filter-outputs/12345678", http.NoBody)
+			So(err, ShouldBeNil)
+			r.Header.Set("X-Download-Service-Token", downloadServiceToken)
+			w := httptest.NewRecorder()
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the filter output with private links must not be stored by shared caches", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Header().Get("Cache-Control"), ShouldEqual, "private, no-cache")
+			})
+		})
+	})
+}
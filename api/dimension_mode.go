@@ -0,0 +1,88 @@
+package api
+
+import (
+	"context"
+	"fmt"
+
+	datasetAPI "github.com/ONSdigital/dp-api-clients-go/v2/dataset"
+	"github.com/ONSdigital/dp-filter-api/filters"
+	"github.com/ONSdigital/dp-filter-api/models"
+	"github.com/ONSdigital/dp-filter-api/utils"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// hasDatasetDimensionOption checks if the provided option is available for the dimension in the dataset version
+func (api *FilterAPI) hasDatasetDimensionOption(ctx context.Context, dataset *models.Dataset, dimensionName, option string) (bool, error) {
+	found := false
+	processBatch := func(batch datasetAPI.Options) (forceAbort bool, err error) {
+		for i := range batch.Items {
+			if batch.Items[i].Option == option {
+				found = true
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+
+	dimension := models.Dimension{Name: dimensionName, Options: []string{option}}
+	if err := api.getDimensionOptionsBatchProcess(ctx, dimension, dataset, processBatch); err != nil {
+		return false, err
+	}
+	return found, nil
+}
+
+// excludeFilterBlueprintDimensionOptions adds the provided options to the excluded options of a dimension in exclude mode,
+// only if the options are available for the dimension.
+func (api *FilterAPI) excludeFilterBlueprintDimensionOptions(ctx context.Context, filterBlueprint *models.Filter, dimensionName string, options []string) (newETag string, err error) {
+	// if all the provided options are already excluded, there is nothing to change
+	if len(options) == 0 {
+		log.Info(ctx, "options are already excluded from the dimension, nothing to exclude")
+		return filterBlueprint.ETag, nil
+	}
+
+	dimension := models.Dimension{Name: dimensionName, Options: options}
+	logData := log.Data{"filter_blueprint_id": filterBlueprint.FilterID}
+	if err := api.checkNewFilterDimensionOptions(ctx, dimension, filterBlueprint.Dataset, logData); err != nil {
+		return "", err
+	}
+
+	return api.dataStore.AddFilterDimensionOptions(ctx, filterBlueprint.FilterID, dimensionName, options, filterBlueprint.UniqueTimestamp, filterBlueprint.ETag, filterBlueprint)
+}
+
+// includeFilterOutputExcludedOptions resolves the dimensions of a filter output in exclude mode into the options that they include,
+// so that the options of a filter output, and the exporters that read them, always list the options to export.
+// Option ranges must have been expanded already, as they are part of the excluded options.
+func (api *FilterAPI) includeFilterOutputExcludedOptions(ctx context.Context, filterOutput *models.Filter) error {
+	dimensions := make([]models.Dimension, len(filterOutput.Dimensions))
+	copy(dimensions, filterOutput.Dimensions)
+
+	for i := range dimensions {
+		if !dimensions[i].IsExclude() {
+			continue
+		}
+
+		orderedOptions, err := api.getOrderedDimensionOptions(ctx, filterOutput.Dataset, dimensions[i].Name)
+		if err != nil {
+			return err
+		}
+
+		excluded := utils.CreateMap(dimensions[i].Options)
+		included := []string{}
+		for _, option := range orderedOptions {
+			if _, ok := excluded[option]; !ok {
+				included = append(included, option)
+			}
+		}
+
+		// a dimension without options selects all of them, so a dimension excluding all of them cannot be exported
+		if len(included) == 0 {
+			return filters.NewBadRequestErr(fmt.Sprintf("every option of dimension %s is excluded", dimensions[i].Name))
+		}
+
+		dimensions[i].Mode = ""
+		dimensions[i].Options = included
+	}
+
+	filterOutput.Dimensions = dimensions
+	return nil
+}
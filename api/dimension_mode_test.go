@@ -0,0 +1,305 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ONSdigital/dp-filter-api/api"
+	apimock "github.com/ONSdigital/dp-filter-api/api/mock"
+	"github.com/ONSdigital/dp-filter-api/mock"
+	"github.com/ONSdigital/dp-filter-api/models"
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// excludeModeDataStoreMock returns a datastore mock with a filter blueprint whose 'age' dimension
+// selects every option except '27'
+func excludeModeDataStoreMock() *apimock.DataStoreMock {
+	ds := mock.NewDataStore().Mock
+	ds.GetFilterFunc = func(ctx context.Context, filterID string, eTagSelector string) (*models.Filter, error) {
+		return &models.Filter{
+			FilterID:   filterID,
+			Dataset:    &models.Dataset{ID: "123", Edition: "2017", Version: 1},
+			InstanceID: "12345678",
+			Published:  &models.Published,
+			Dimensions: []models.Dimension{{Name: "age", Mode: models.DimensionModeExclude, Options: []string{"27"}}},
+			ETag:       testETag,
+		}, nil
+	}
+	return ds
+}
+
+func TestAddFilterBlueprintDimension_ExcludeMode(t *testing.T) {
+	t.Parallel()
+
+	filterFlexAPIMock := &apimock.FilterFlexAPIMock{}
+
+	Convey("Given a filter blueprint", t, func() {
+		ds := mock.NewDataStore().Mock
+		datasetAPIMock := mock.NewDatasetAPI().Mock
+		w := httptest.NewRecorder()
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), ds, &mock.FilterJob{}, datasetAPIMock, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+
+		Convey("When a dimension is added in exclude mode", func() {
+			reader := strings.NewReader(`{"mode":"exclude","options":["27"]}`)
+			r, err := http.NewRequest("POST", "http://localhost:22100/filters/12345678/dimensions/age", reader)
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the excluded options are validated and stored along with the mode", func() {
+				So(w.Code, ShouldEqual, http.StatusCreated)
+				So(*datasetAPIMock.GetOptionsBatchProcessCalls()[0].OptionIDs, ShouldResemble, []string{"27"})
+				So(ds.AddFilterDimensionCalls(), ShouldHaveLength, 1)
+				So(ds.AddFilterDimensionCalls()[0].Mode, ShouldEqual, models.DimensionModeExclude)
+				So(ds.AddFilterDimensionCalls()[0].Options, ShouldResemble, []string{"27"})
+			})
+		})
+
+		Convey("When a dimension is added in include mode", func() {
+			reader := strings.NewReader(`{"mode":"include","options":["27"]}`)
+			r, err := http.NewRequest("POST", "http://localhost:22100/filters/12345678/dimensions/age", reader)
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the default mode is not stored", func() {
+				So(w.Code, ShouldEqual, http.StatusCreated)
+				So(ds.AddFilterDimensionCalls()[0].Mode, ShouldEqual, "")
+			})
+		})
+
+		Convey("When a dimension is added with an unsupported mode", func() {
+			reader := strings.NewReader(`{"mode":"invert","options":["27"]}`)
+			r, err := http.NewRequest("POST", "http://localhost:22100/filters/12345678/dimensions/age", reader)
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the response is 400 bad request and nothing is stored", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(problemDetail(w.Body.String()), ShouldStartWith, `invalid dimension mode provided: "invert"`)
+				So(ds.AddFilterDimensionCalls(), ShouldHaveLength, 0)
+			})
+		})
+
+		Convey("When a dimension is added in exclude mode with an option that does not exist", func() {
+			reader := strings.NewReader(`{"mode":"exclude","options":["99"]}`)
+			r, err := http.NewRequest("POST", "http://localhost:22100/filters/12345678/dimensions/age", reader)
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the response is 400 bad request", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(problemErrors(w.Body.String()), ShouldResemble, validationErrorsResponse(models.NewOptionsNotFoundError("age", []string{"99"}, nil)))
+			})
+		})
+	})
+}
+
+func TestGetFilterBlueprintDimensionOptions_ExcludeMode(t *testing.T) {
+	t.Parallel()
+
+	filterFlexAPIMock := &apimock.FilterFlexAPIMock{}
+
+	Convey("Given a filter blueprint with a dimension in exclude mode", t, func() {
+		datasetAPIMock := mock.NewDatasetAPI().Mock
+		w := httptest.NewRecorder()
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), excludeModeDataStoreMock(), &mock.FilterJob{}, datasetAPIMock, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+
+		Convey("When the dimension options are requested", func() {
+			r, err := http.NewRequest("GET", "http://localhost:22100/filters/12345678/dimensions/age/options", http.NoBody)
+			So(err, ShouldBeNil)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the options are computed from the full dataset option list", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(datasetAPIMock.GetOptionsBatchProcessCalls(), ShouldHaveLength, 1)
+				So(datasetAPIMock.GetOptionsBatchProcessCalls()[0].OptionIDs, ShouldBeNil)
+			})
+
+			Convey("Then the response pages through every option except the excluded ones", func() {
+				var options models.PublicDimensionOptions
+				So(json.Unmarshal(w.Body.Bytes(), &options), ShouldBeNil)
+				So(options.TotalCount, ShouldEqual, 1)
+				So(options.Items, ShouldHaveLength, 1)
+				So(options.Items[0].Option, ShouldEqual, "33")
+			})
+		})
+
+		Convey("When an option that is not excluded is requested", func() {
+			r, err := http.NewRequest("GET", "http://localhost:22100/filters/12345678/dimensions/age/options/33", http.NoBody)
+			So(err, ShouldBeNil)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the option is found", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+			})
+		})
+
+		Convey("When an excluded option is requested", func() {
+			r, err := http.NewRequest("GET", "http://localhost:22100/filters/12345678/dimensions/age/options/27", http.NoBody)
+			So(err, ShouldBeNil)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the option is not found", func() {
+				So(w.Code, ShouldEqual, http.StatusNotFound)
+				So(problemDetail(w.Body.String()), ShouldEqual, optionNotFoundResponse)
+			})
+		})
+
+		Convey("When an option that is not available in the dataset is requested", func() {
+			r, err := http.NewRequest("GET", "http://localhost:22100/filters/12345678/dimensions/age/options/99", http.NoBody)
+			So(err, ShouldBeNil)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the option is not found", func() {
+				So(w.Code, ShouldEqual, http.StatusNotFound)
+			})
+		})
+	})
+}
+
+func TestUpdateFilterBlueprintDimensionOptions_ExcludeMode(t *testing.T) {
+	t.Parallel()
+
+	filterFlexAPIMock := &apimock.FilterFlexAPIMock{}
+
+	Convey("Given a filter blueprint with a dimension in exclude mode", t, func() {
+		ds := excludeModeDataStoreMock()
+		w := httptest.NewRecorder()
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), ds, &mock.FilterJob{}, mock.NewDatasetAPI().Mock, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+
+		Convey("When an 'add' patch operation selects an excluded option", func() {
+			reader := strings.NewReader(`[{"op":"add", "path": "/options/-", "value": ["27", "33"]}]`)
+			r, err := http.NewRequest("PATCH", "http://localhost:22100/filters/12345678/dimensions/age", reader)
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the option is removed from the excluded options", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(ds.AddFilterDimensionOptionsCalls(), ShouldHaveLength, 0)
+				So(ds.RemoveFilterDimensionOptionsCalls(), ShouldHaveLength, 1)
+				So(ds.RemoveFilterDimensionOptionsCalls()[0].Options, ShouldResemble, []string{"27"})
+			})
+		})
+
+		Convey("When a 'remove' patch operation deselects an option", func() {
+			reader := strings.NewReader(`[{"op":"remove", "path": "/options/-", "value": ["27", "33"]}]`)
+			r, err := http.NewRequest("PATCH", "http://localhost:22100/filters/12345678/dimensions/age", reader)
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the option is added to the excluded options", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(ds.RemoveFilterDimensionOptionsCalls(), ShouldHaveLength, 0)
+				So(ds.AddFilterDimensionOptionsCalls(), ShouldHaveLength, 1)
+				So(ds.AddFilterDimensionOptionsCalls()[0].Options, ShouldResemble, []string{"33"})
+			})
+		})
+
+		Convey("When a 'remove' patch operation deselects an option that does not exist", func() {
+			reader := strings.NewReader(`[{"op":"remove", "path": "/options/-", "value": ["99"]}]`)
+			r, err := http.NewRequest("PATCH", "http://localhost:22100/filters/12345678/dimensions/age", reader)
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the response is 400 bad request and nothing is excluded", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(ds.AddFilterDimensionOptionsCalls(), ShouldHaveLength, 0)
+			})
+		})
+
+		Convey("When a selected option is deleted", func() {
+			r, err := http.NewRequest("DELETE", "http://localhost:22100/filters/12345678/dimensions/age/options/33", http.NoBody)
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the option is added to the excluded options", func() {
+				So(w.Code, ShouldEqual, http.StatusNoContent)
+				So(ds.AddFilterDimensionOptionCalls(), ShouldHaveLength, 1)
+				So(ds.AddFilterDimensionOptionCalls()[0].Option, ShouldEqual, "33")
+				So(ds.RemoveFilterDimensionOptionCalls(), ShouldHaveLength, 0)
+			})
+		})
+
+		Convey("When an excluded option is deleted", func() {
+			r, err := http.NewRequest("DELETE", "http://localhost:22100/filters/12345678/dimensions/age/options/27", http.NoBody)
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the response is 404 not found", func() {
+				So(w.Code, ShouldEqual, http.StatusNotFound)
+				So(ds.AddFilterDimensionOptionCalls(), ShouldHaveLength, 0)
+			})
+		})
+	})
+}
+
+func TestSubmitFilterBlueprint_ExcludeMode(t *testing.T) {
+	t.Parallel()
+
+	filterFlexAPIMock := &apimock.FilterFlexAPIMock{}
+
+	Convey("Given a filter blueprint whose 'age' dimension excludes option '27'", t, func() {
+		ds := excludeModeDataStoreMock()
+		outputQueue := &mock.FilterJob{}
+		w := httptest.NewRecorder()
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), ds, outputQueue, mock.NewDatasetAPI().Mock, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+
+		Convey("When the filter blueprint is submitted", func() {
+			r, err := http.NewRequest("PUT", "http://localhost:22100/filters/12345678?submitted=true", strings.NewReader("{}"))
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the filter output lists the options that the dimension includes, so that exporters export the selection", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(ds.CreateFilterOutputCalls(), ShouldHaveLength, 1)
+				dimensions := ds.CreateFilterOutputCalls()[0].Filter.Dimensions
+				So(dimensions, ShouldHaveLength, 1)
+				So(dimensions[0].Mode, ShouldBeEmpty)
+				So(dimensions[0].Options, ShouldResemble, []string{"33"})
+			})
+		})
+	})
+
+	Convey("Given a filter blueprint whose 'age' dimension excludes every option", t, func() {
+		ds := excludeModeDataStoreMock()
+		ds.GetFilterFunc = func(ctx context.Context, filterID string, eTagSelector string) (*models.Filter, error) {
+			return &models.Filter{
+				FilterID:   filterID,
+				Dataset:    &models.Dataset{ID: "123", Edition: "2017", Version: 1},
+				InstanceID: "12345678",
+				Published:  &models.Published,
+				Dimensions: []models.Dimension{{Name: "age", Mode: models.DimensionModeExclude, Options: []string{"27", "33"}}},
+				ETag:       testETag,
+			}, nil
+		}
+		w := httptest.NewRecorder()
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), ds, &mock.FilterJob{}, mock.NewDatasetAPI().Mock, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+
+		Convey("When the filter blueprint is submitted", func() {
+			r, err := http.NewRequest("PUT", "http://localhost:22100/filters/12345678?submitted=true", strings.NewReader("{}"))
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the response is 400 bad request and no filter output is created", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(problemDetail(w.Body.String()), ShouldEqual, "every option of dimension age is excluded")
+				So(ds.CreateFilterOutputCalls(), ShouldHaveLength, 0)
+			})
+		})
+	})
+}
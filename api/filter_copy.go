@@ -0,0 +1,146 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/ONSdigital/dp-filter-api/filters"
+	"github.com/ONSdigital/dp-filter-api/models"
+	"github.com/ONSdigital/dp-filter-api/mongo"
+	dphttp "github.com/ONSdigital/dp-net/http"
+	dprequest "github.com/ONSdigital/dp-net/request"
+	"github.com/ONSdigital/log.go/v2/log"
+	"github.com/gorilla/mux"
+	uuid "github.com/satori/go.uuid"
+)
+
+func (api *FilterAPI) postFilterBlueprintCopyHandler(w http.ResponseWriter, r *http.Request) {
+	defer dphttp.DrainBody(r)
+
+	vars := mux.Vars(r)
+	filterID := vars["filter_blueprint_id"]
+	logData := log.Data{"filter_blueprint_id": filterID}
+	ctx := r.Context()
+	log.Info(ctx, "copying filter blueprint", logData)
+
+	copyFilter, err := models.CreateCopyFilter(r.Body)
+	if err != nil {
+		log.Error(ctx, "unable to unmarshal request body", err, logData)
+		writeError(w, r, http.StatusBadRequest, errBadRequestBody)
+		return
+	}
+
+	newFilter, err := api.copyFilterBlueprint(ctx, filterID, copyFilter)
+	if err != nil {
+		log.Error(ctx, "failed to copy filter blueprint", err, logData)
+		setErrorCode(w, r, err)
+		return
+	}
+	logData["new_filter_blueprint_id"] = newFilter.FilterID
+	log.Info(ctx, "copied filter blueprint", logData)
+
+	bytes, err := json.Marshal(newFilter)
+	if err != nil {
+		log.Error(ctx, "failed to marshal filter blueprint into bytes", err, logData)
+		setErrorCode(w, r, err)
+		return
+	}
+
+	setJSONContentType(w)
+	setETag(w, newFilter.ETag)
+	w.WriteHeader(http.StatusCreated)
+	_, err = w.Write(bytes)
+	if err != nil {
+		log.Error(ctx, "failed to write bytes for http response", err, logData)
+		setErrorCode(w, r, err)
+		return
+	}
+}
+
+// copyFilterBlueprint creates a new filter blueprint with the same dataset and dimension selections as an existing one.
+// The dataset version can optionally be overridden, in which case the dimensions are validated against the new version.
+func (api *FilterAPI) copyFilterBlueprint(ctx context.Context, filterID string, copyFilter *models.CopyFilter) (*models.Filter, error) {
+	logData := log.Data{"filter_blueprint_id": filterID, "copy_filter": copyFilter}
+
+	currentFilter, err := api.getFilterBlueprint(ctx, filterID, mongo.AnyETag)
+	if err != nil {
+		log.Error(ctx, "unable to get filter blueprint", err, logData)
+		return nil, err
+	}
+
+	// Create unique id
+	u, err := uuid.NewV4()
+	if err != nil {
+		log.Error(ctx, "failed to create a new UUID for filter blueprint", err, logData)
+		return nil, err
+	}
+
+	dataset := *currentFilter.Dataset
+	newFilter := &models.Filter{
+		FilterID:   u.String(),
+		Dataset:    &dataset,
+		InstanceID: currentFilter.InstanceID,
+		Published:  currentFilter.Published,
+		Type:       currentFilter.Type,
+		Links: models.LinkMap{
+			Dimensions: &models.LinkObject{
+				HRef: fmt.Sprintf("%s/filters/%s/dimensions", api.host, u.String()),
+			},
+			Self: &models.LinkObject{
+				HRef: fmt.Sprintf("%s/filters/%s", api.host, u.String()),
+			},
+			Version: currentFilter.Links.Version,
+		},
+	}
+
+	for _, dimension := range currentFilter.Dimensions {
+		dimension.URL = fmt.Sprintf("%s/filters/%s/dimensions/%s", api.host, newFilter.FilterID, dimension.Name)
+		dimension.Options = append([]string{}, dimension.Options...)
+		newFilter.Dimensions = append(newFilter.Dimensions, dimension)
+	}
+	logData["new_filter"] = newFilter
+
+	if copyFilter.Dataset != nil && copyFilter.Dataset.Version != 0 && copyFilter.Dataset.Version != currentFilter.Dataset.Version {
+		log.Info(ctx, "finding new version details for copied filter after version change", logData)
+		newFilter.Dataset.Version = copyFilter.Dataset.Version
+
+		version, err := api.getVersion(ctx, newFilter.Dataset)
+		if err != nil {
+			log.Error(ctx, "unable to retrieve version document", err, logData)
+			return nil, err
+		}
+
+		if version.State != publishedState && !dprequest.IsCallerPresent(ctx) {
+			log.Info(ctx, "unauthenticated request to filter unpublished version", log.Data{"dataset": *newFilter.Dataset, "state": version.State})
+			return nil, filters.ErrVersionNotFound
+		}
+
+		newFilter.Published = &models.Unpublished
+		if version.State == publishedState {
+			newFilter.Published = &models.Published
+		}
+
+		newFilter.InstanceID = version.ID
+		newFilter.Links.Version = &models.LinkObject{
+			HRef: version.Links.Self.URL,
+			ID:   strconv.Itoa(version.Version),
+		}
+
+		// Check existing dimensions work for new version
+		if err = api.checkFilterOptions(ctx, newFilter, version); err != nil {
+			log.Error(ctx, "failed to select valid filter options", err, logData)
+			return nil, newValidationBadRequestErr(err)
+		}
+	}
+
+	newFilter, err = api.dataStore.AddFilter(ctx, newFilter)
+	if err != nil {
+		log.Error(ctx, "failed to create copy of filter blueprint", err, logData)
+		return nil, err
+	}
+
+	return newFilter, nil
+}
@@ -0,0 +1,171 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ONSdigital/dp-filter-api/api"
+	apimock "github.com/ONSdigital/dp-filter-api/api/mock"
+	"github.com/ONSdigital/dp-filter-api/mock"
+	"github.com/ONSdigital/dp-filter-api/models"
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSuccessfulCopyFilterBlueprint(t *testing.T) {
+	t.Parallel()
+
+	filterFlexAPIMock := &apimock.FilterFlexAPIMock{}
+
+	Convey("Given an existing filter blueprint for a published dataset", t, func() {
+		w := httptest.NewRecorder()
+
+		mockDatastore := &apimock.DataStoreMock{
+			GetFilterFunc: func(ctx context.Context, filterID string, eTagSelector string) (*models.Filter, error) {
+				return &models.Filter{
+					FilterID:   filterID,
+					Dataset:    &models.Dataset{ID: "123", Edition: "2017", Version: 1},
+					InstanceID: "12345678",
+					Published:  &models.Published,
+					Dimensions: []models.Dimension{{URL: "http://localhost:80/filters/21312/dimensions/age", Name: "age", Options: []string{"27", "33"}}},
+					Links:      models.LinkMap{Version: &models.LinkObject{ID: "1", HRef: "http://localhost:22000/datasets/123/editions/2017/versions/1"}},
+					ETag:       testETag,
+				}, nil
+			},
+			AddFilterFunc: func(ctx context.Context, filter *models.Filter) (*models.Filter, error) {
+				filter.ETag = testETag1
+				return filter, nil
+			},
+		}
+
+		datasetAPIMock := &apimock.DatasetAPIMock{
+			GetVersionFunc:             mock.NewDatasetAPI().GetVersion,
+			GetVersionDimensionsFunc:   mock.NewDatasetAPI().GetVersionDimensions,
+			GetOptionsBatchProcessFunc: mock.NewDatasetAPI().GetOptionsBatchProcess,
+		}
+
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), mockDatastore, &mock.FilterJob{}, datasetAPIMock, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+
+		Convey("When a POST request is made to the copy endpoint without a body", func() {
+			r, err := http.NewRequest("POST", cfg().Host+"/filters/21312/copy", http.NoBody)
+			So(err, ShouldBeNil)
+
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the response is 201 created", func() {
+				So(w.Code, ShouldEqual, http.StatusCreated)
+			})
+
+			Convey("Then the ETag of the new filter blueprint is returned in a header", func() {
+				So(w.Result().Header.Get("ETag"), ShouldResemble, testETag1)
+			})
+
+			Convey("Then a new filter blueprint is stored with a new ID and the same dataset and dimensions", func() {
+				So(mockDatastore.AddFilterCalls(), ShouldHaveLength, 1)
+				newFilter := mockDatastore.AddFilterCalls()[0].Filter
+				So(newFilter.FilterID, ShouldNotBeEmpty)
+				So(newFilter.FilterID, ShouldNotEqual, "21312")
+				So(newFilter.Dataset, ShouldResemble, &models.Dataset{ID: "123", Edition: "2017", Version: 1})
+				So(newFilter.InstanceID, ShouldEqual, "12345678")
+				So(newFilter.Dimensions, ShouldResemble, []models.Dimension{
+					{URL: "http://localhost:80/filters/" + newFilter.FilterID + "/dimensions/age", Name: "age", Options: []string{"27", "33"}},
+				})
+				So(newFilter.Links.Self.HRef, ShouldEqual, "http://localhost:80/filters/"+newFilter.FilterID)
+			})
+
+			Convey("Then the dimension options are not validated against the dataset API", func() {
+				So(datasetAPIMock.GetVersionCalls(), ShouldHaveLength, 0)
+				So(datasetAPIMock.GetOptionsBatchProcessCalls(), ShouldHaveLength, 0)
+			})
+
+			Convey("Then the request body has been drained", func() {
+				bytesRead, err := r.Body.Read(make([]byte, 1))
+				So(bytesRead, ShouldEqual, 0)
+				So(err, ShouldEqual, io.EOF)
+			})
+		})
+
+		Convey("When a POST request is made to the copy endpoint overriding the dataset version", func() {
+			reader := strings.NewReader(`{"dataset":{"version":2}}`)
+			r, err := http.NewRequest("POST", cfg().Host+"/filters/21312/copy", reader)
+			So(err, ShouldBeNil)
+
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the response is 201 created, with the new version in the body", func() {
+				So(w.Code, ShouldEqual, http.StatusCreated)
+				var filter models.Filter
+				So(json.Unmarshal(w.Body.Bytes(), &filter), ShouldBeNil)
+				So(filter.Dataset.Version, ShouldEqual, 2)
+			})
+
+			Convey("Then the dimension options are validated against the new version", func() {
+				So(datasetAPIMock.GetVersionCalls(), ShouldHaveLength, 1)
+				So(datasetAPIMock.GetVersionCalls()[0].Version, ShouldEqual, "2")
+				So(datasetAPIMock.GetVersionDimensionsCalls(), ShouldHaveLength, 1)
+				So(datasetAPIMock.GetOptionsBatchProcessCalls(), ShouldHaveLength, 1)
+			})
+		})
+	})
+}
+
+func TestFailedToCopyFilterBlueprint(t *testing.T) {
+	t.Parallel()
+
+	filterFlexAPIMock := &apimock.FilterFlexAPIMock{}
+
+	Convey("When an invalid json message is sent, a bad request is returned", t, func() {
+		reader := strings.NewReader("{")
+		r, err := http.NewRequest("POST", cfg().Host+"/filters/21312/copy", reader)
+		So(err, ShouldBeNil)
+
+		w := httptest.NewRecorder()
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), mock.NewDataStore().Mock, &mock.FilterJob{}, &mock.DatasetAPI{}, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+		filterAPI.Router.ServeHTTP(w, r)
+		So(w.Code, ShouldEqual, http.StatusBadRequest)
+		So(problemDetail(w.Body.String()), ShouldEqual, badRequestResponse)
+	})
+
+	Convey("When the filter blueprint does not exist, a not found is returned", t, func() {
+		r, err := http.NewRequest("POST", cfg().Host+"/filters/21312/copy", http.NoBody)
+		So(err, ShouldBeNil)
+
+		w := httptest.NewRecorder()
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), mock.NewDataStore().NotFound().Mock, &mock.FilterJob{}, &mock.DatasetAPI{}, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+		filterAPI.Router.ServeHTTP(w, r)
+		So(w.Code, ShouldEqual, http.StatusNotFound)
+		So(problemDetail(w.Body.String()), ShouldEqual, filterNotFoundResponse)
+	})
+
+	Convey("When the overridden version does not exist, a not found is returned", t, func() {
+		reader := strings.NewReader(`{"dataset":{"version":2}}`)
+		r, err := http.NewRequest("POST", cfg().Host+"/filters/21312/copy", reader)
+		So(err, ShouldBeNil)
+
+		w := httptest.NewRecorder()
+		mockDatastore := mock.NewDataStore().Mock
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), mockDatastore, &mock.FilterJob{}, mock.NewDatasetAPI().VersionNotFound(), filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+		filterAPI.Router.ServeHTTP(w, r)
+		So(w.Code, ShouldEqual, http.StatusNotFound)
+		So(problemDetail(w.Body.String()), ShouldEqual, versionNotFoundResponse)
+		So(mockDatastore.AddFilterCalls(), ShouldHaveLength, 0)
+	})
+
+	Convey("When the existing dimensions are not valid for the overridden version, a bad request is returned", t, func() {
+		reader := strings.NewReader(`{"dataset":{"version":2}}`)
+		r, err := http.NewRequest("POST", cfg().Host+"/filters/21312/copy", reader)
+		So(err, ShouldBeNil)
+
+		w := httptest.NewRecorder()
+		mockDatastore := mock.NewDataStore().Mock
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), mockDatastore, &mock.FilterJob{}, &mock.DatasetAPI{}, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+		filterAPI.Router.ServeHTTP(w, r)
+		So(w.Code, ShouldEqual, http.StatusBadRequest)
+		So(mockDatastore.AddFilterCalls(), ShouldHaveLength, 0)
+	})
+}
@@ -4,7 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"net/http"
-	"sort"
+	"slices"
 
 	"github.com/ONSdigital/dp-filter-api/models"
 	"github.com/ONSdigital/dp-filter-api/mongo"
@@ -43,7 +43,7 @@ func (api *FilterAPI) getFilterBlueprintDimensionOptionsHandler(w http.ResponseW
 		offset, err = validatePositiveInt(offsetParameter)
 		if err != nil {
 			log.Error(ctx, "failed to obtain offset from request query parameters", err, logData)
-			setErrorCode(w, err)
+			setErrorCode(w, r, err)
 			return
 		}
 	}
@@ -53,7 +53,7 @@ func (api *FilterAPI) getFilterBlueprintDimensionOptionsHandler(w http.ResponseW
 		limit, err = validatePositiveInt(limitParameter)
 		if err != nil {
 			log.Error(ctx, "failed to obtain limit from request query parameters", err, logData)
-			setErrorCode(w, err)
+			setErrorCode(w, r, err)
 			return
 		}
 	}
@@ -62,23 +62,70 @@ func (api *FilterAPI) getFilterBlueprintDimensionOptionsHandler(w http.ResponseW
 		logData["max_limit"] = api.maxLimit
 		err = filters.ErrInvalidQueryParameter
 		log.Error(ctx, "limit is greater than the maximum allowed", err, logData)
-		setErrorCode(w, err)
+		setErrorCode(w, r, err)
+		return
+	}
+
+	includeLabels, err := getIncludeLabels(r)
+	if err != nil {
+		log.Error(ctx, "failed to obtain include from request query parameters", err, logData)
+		setErrorCode(w, r, err)
+		return
+	}
+
+	query, err := getOptionsQuery(r)
+	if err != nil {
+		log.Error(ctx, "failed to obtain sort from request query parameters", err, logData)
+		setErrorCode(w, r, err)
+		return
+	}
+	logData["q"] = query.search
+	logData["sort"] = query.sortBy
+
+	pageCursor, err := getCursor(r)
+	if err != nil {
+		log.Error(ctx, "failed to obtain cursor from request query parameters", err, logData)
+		setErrorCode(w, r, err)
 		return
 	}
 
 	filter, err := api.getFilterBlueprint(ctx, filterBlueprintID, mongo.AnyETag)
 	if err != nil {
 		log.Error(ctx, "failed to get dimension options for filter blueprint", err, logData)
-		setErrorCode(w, err)
+		setErrorCode(w, r, err)
+		return
+	}
+
+	if err := checkCursor(pageCursor, filter.ETag); err != nil {
+		logData["current_etag"] = filter.ETag
+		log.Error(ctx, "cursor was issued for a previous version of the filter blueprint", err, logData)
+		setErrorCode(w, r, err)
+		return
+	}
+
+	eTag := representationETag(filter.ETag, getAcceptLanguage(r), nil)
+	if findDimension(filter, dimensionName) != nil && api.writeNotModified(w, r, eTag, isPublished(filter), false) {
+		log.Info(ctx, "filter blueprint dimension options not modified", logData)
 		return
 	}
 
-	options, err := api.getFilterBlueprintDimensionOptions(ctx, filter, dimensionName, offset, limit)
+	options, optionsPage, err := api.getFilterBlueprintDimensionOptions(withLanguage(ctx, getAcceptLanguage(r)), filter, dimensionName, query, offset, limit, pageCursor)
 	if err != nil {
 		log.Error(ctx, "failed to get dimension options for filter blueprint", err, logData)
-		setErrorCode(w, err)
+		setErrorCode(w, r, err)
 		return
 	}
+	options.Links = optionsPage.links(api.host.String()+r.URL.Path, r.URL.Query(), filter.ETag)
+
+	if includeLabels {
+		language := getAcceptLanguage(r)
+		if err := api.labelPublicDimensionOptions(withLanguage(ctx, language), filter.Dataset, dimensionName, options.Items); err != nil {
+			log.Error(ctx, "failed to get dimension option labels for filter blueprint", err, logData)
+			setErrorCode(w, r, err)
+			return
+		}
+		setContentLanguage(w, language)
+	}
 
 	// The additions of `options` has been commented out below because sometimes it is resulting
 	// in a log line that is greater or equal to: 270836 bytes
@@ -101,17 +148,23 @@ func (api *FilterAPI) getFilterBlueprintDimensionOptionsHandler(w http.ResponseW
 			return nil
 		}
 
+		if err := rewritePageLinks(filterAPILinksBuilder, options.Links); err != nil {
+			log.Error(ctx, "failed to rewrite dimension options page links", err, logData)
+			setErrorCode(w, r, err)
+			return
+		}
+
 		for i := range options.Items {
 			if err := updateLink(options.Items[i].Links.Self); err != nil {
-				setErrorCode(w, err)
+				setErrorCode(w, r, err)
 				return
 			}
 			if err := updateLink(options.Items[i].Links.Filter); err != nil {
-				setErrorCode(w, err)
+				setErrorCode(w, r, err)
 				return
 			}
 			if err := updateLink(options.Items[i].Links.Dimension); err != nil {
-				setErrorCode(w, err)
+				setErrorCode(w, r, err)
 				return
 			}
 		}
@@ -120,16 +173,17 @@ func (api *FilterAPI) getFilterBlueprintDimensionOptionsHandler(w http.ResponseW
 	b, err := json.Marshal(options)
 	if err != nil {
 		log.Error(ctx, "failed to marshal filter blueprint dimension options into bytes", err, logData)
-		http.Error(w, InternalError, http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, errInternal)
 		return
 	}
 
 	setJSONContentType(w)
-	setETag(w, filter.ETag)
+	setETag(w, eTag)
+	api.setCacheControl(w, isPublished(filter), false)
 	_, err = w.Write(b)
 	if err != nil {
 		log.Error(ctx, "failed to write bytes for http response", err, logData)
-		setErrorCode(w, err)
+		setErrorCode(w, r, err)
 		return
 	}
 
@@ -150,19 +204,35 @@ func slice(full []string, offset, limit int) (sliced []string) {
 	return full[offset:end]
 }
 
-func (api *FilterAPI) getFilterBlueprintDimensionOptions(_ context.Context, filter *models.Filter, dimensionName string, offset, limit int) (options *models.PublicDimensionOptions, err error) {
+// getFilterBlueprintDimensionOptions returns the page of options of a dimension of a filter blueprint,
+// from the cursor if one is provided, or from the offset otherwise
+func (api *FilterAPI) getFilterBlueprintDimensionOptions(ctx context.Context, filter *models.Filter, dimensionName string, query optionsQuery, offset, limit int, c *cursor) (options *models.PublicDimensionOptions, optionsPage page, err error) {
 	for _, dimension := range filter.Dimensions {
 		if dimension.Name == dimensionName {
+			// in exclude mode, or with option ranges, the selected options are computed from the dataset options
+			selectedOptions, err := api.getSelectedOptions(ctx, filter.Dataset, dimension)
+			if err != nil {
+				return nil, page{}, err
+			}
+
+			selectedOptions, err = api.queryOptions(ctx, filter.Dataset, dimension.Name, selectedOptions, query)
+			if err != nil {
+				return nil, page{}, err
+			}
+
+			// cut according to the cursor, or limit and offset
+			optionsPage, err = paginate(selectedOptions, offset, limit, c)
+			if err != nil {
+				return nil, page{}, err
+			}
+
 			options = &models.PublicDimensionOptions{
 				Items:      []*models.PublicDimensionOption{},
-				TotalCount: len(dimension.Options),
-				Offset:     offset,
+				TotalCount: optionsPage.total,
+				Offset:     optionsPage.offset,
 				Limit:      limit,
 			}
-
-			// sort alphabetically and cut according to limit and offset
-			sort.Strings(dimension.Options)
-			dimension.Options = slice(dimension.Options, offset, limit)
+			selectedOptions = optionsPage.keys
 
 			dimLink := fmt.Sprintf("%s/filters/%s/dimensions/%s", api.host, filter.FilterID, dimension.Name)
 			filterObject := &models.LinkObject{
@@ -170,7 +240,7 @@ func (api *FilterAPI) getFilterBlueprintDimensionOptions(_ context.Context, filt
 				ID:   filter.FilterID,
 			}
 
-			for _, option := range dimension.Options {
+			for _, option := range selectedOptions {
 				dimensionOption := &models.PublicDimensionOption{
 					Links: &models.PublicDimensionOptionLinkMap{
 						Self:      &models.LinkObject{HRef: dimLink + "/options/" + option, ID: option},
@@ -182,11 +252,11 @@ func (api *FilterAPI) getFilterBlueprintDimensionOptions(_ context.Context, filt
 				options.Items = append(options.Items, dimensionOption)
 			}
 			options.Count = len(options.Items)
-			return options, nil
+			return options, optionsPage, nil
 		}
 	}
 
-	return nil, filters.ErrDimensionNotFound
+	return nil, page{}, filters.ErrDimensionNotFound
 }
 
 func (api *FilterAPI) getFilterBlueprintDimensionOptionHandler(w http.ResponseWriter, r *http.Request) {
@@ -203,20 +273,43 @@ func (api *FilterAPI) getFilterBlueprintDimensionOptionHandler(w http.ResponseWr
 	ctx := r.Context()
 	log.Info(ctx, "get filter blueprint dimension option", logData)
 
+	includeLabels, err := getIncludeLabels(r)
+	if err != nil {
+		log.Error(ctx, "failed to obtain include from request query parameters", err, logData)
+		setErrorCodeFromError(w, r, err)
+		return
+	}
+
 	filter, err := api.getFilterBlueprint(ctx, filterBlueprintID, mongo.AnyETag)
 	if err != nil {
 		log.Error(ctx, "unable to get dimension option for filter blueprint", err, logData)
-		setErrorCodeFromError(w, err)
+		setErrorCodeFromError(w, r, err)
 		return
 	}
 
 	dimensionOption, err := api.getFilterBlueprintDimensionOption(ctx, filter, dimensionName, option)
 	if err != nil {
 		log.Error(ctx, "unable to get dimension option for filter blueprint", err, logData)
-		setErrorCodeFromError(w, err)
+		setErrorCodeFromError(w, r, err)
 		return
 	}
 
+	eTag := representationETag(filter.ETag, getAcceptLanguage(r), nil)
+	if api.writeNotModified(w, r, eTag, isPublished(filter), false) {
+		log.Info(ctx, "filter blueprint dimension option not modified", logData)
+		return
+	}
+
+	if includeLabels {
+		language := getAcceptLanguage(r)
+		if err := api.labelPublicDimensionOptions(withLanguage(ctx, language), filter.Dataset, dimensionName, []*models.PublicDimensionOption{dimensionOption}); err != nil {
+			log.Error(ctx, "unable to get dimension option label for filter blueprint", err, logData)
+			setErrorCodeFromError(w, r, err)
+			return
+		}
+		setContentLanguage(w, language)
+	}
+
 	if api.enableURLRewriting {
 		filterAPILinksBuilder := links.FromHeadersOrDefault(&r.Header, api.host)
 
@@ -234,7 +327,7 @@ func (api *FilterAPI) getFilterBlueprintDimensionOptionHandler(w http.ResponseWr
 						"href": linkObj.ID,
 					}
 					log.Error(ctx, "failed to rewrite dimension option links", err, logData)
-					setErrorCode(w, err)
+					setErrorCode(w, r, err)
 					return
 				}
 				linkObj.HRef = newLink
@@ -245,65 +338,71 @@ func (api *FilterAPI) getFilterBlueprintDimensionOptionHandler(w http.ResponseWr
 	b, err := json.Marshal(dimensionOption)
 	if err != nil {
 		log.Error(ctx, "failed to marshal filter blueprint dimension option into bytes", err, logData)
-		http.Error(w, InternalError, http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, errInternal)
 		return
 	}
 
 	setJSONContentType(w)
-	setETag(w, filter.ETag)
+	setETag(w, eTag)
+	api.setCacheControl(w, isPublished(filter), false)
 	_, err = w.Write(b)
 	if err != nil {
 		log.Error(ctx, "failed to write bytes for http response", err, logData)
-		setErrorCode(w, err)
+		setErrorCode(w, r, err)
 		return
 	}
 
 	log.Info(ctx, "got dimension option for filter blueprint", logData)
 }
 
-func (api *FilterAPI) getFilterBlueprintDimensionOption(_ context.Context, filter *models.Filter, dimensionName, option string) (*models.PublicDimensionOption, error) {
-	optionFound := false
-	dimensionFound := false
-
-	var dimensionOption *models.PublicDimensionOption
-
-	for _, d := range filter.Dimensions {
-		if d.Name == dimensionName {
-			dimensionFound = true
-			for _, o := range d.Options {
-				if o == option {
-					optionFound = true
-
-					dimLink := fmt.Sprintf("%s/filters/%s/dimensions/%s", api.host, filter.FilterID, d.Name)
-					filterObject := &models.LinkObject{
-						HRef: fmt.Sprintf("%s/filters/%s", api.host, filter.FilterID),
-						ID:   filter.FilterID,
-					}
+func (api *FilterAPI) getFilterBlueprintDimensionOption(ctx context.Context, filter *models.Filter, dimensionName, option string) (*models.PublicDimensionOption, error) {
+	dimension := findDimension(filter, dimensionName)
+	if dimension == nil {
+		return nil, filters.ErrDimensionNotFound
+	}
 
-					dimensionOption = &models.PublicDimensionOption{
-						Links: &models.PublicDimensionOptionLinkMap{
-							Self:      &models.LinkObject{HRef: dimLink + "/options/" + option, ID: option},
-							Dimension: &models.LinkObject{HRef: dimLink, ID: d.Name},
-							Filter:    filterObject,
-						},
-						Option: option,
-					}
-					break
-				}
-			}
-			break
+	var optionFound bool
+	switch {
+	case dimension.HasRanges():
+		// the option may be listed by a range, so it is looked up in the selected options
+		selectedOptions, err := api.getSelectedOptions(ctx, filter.Dataset, *dimension)
+		if err != nil {
+			return nil, err
+		}
+		optionFound = slices.Contains(selectedOptions, option)
+	case dimension.IsExclude():
+		// in exclude mode, an option is selected if it is not excluded and it is available in the dataset
+		if slices.Contains(dimension.Options, option) {
+			return nil, filters.ErrDimensionOptionNotFound
 		}
-	}
 
-	if !dimensionFound {
-		return nil, filters.ErrDimensionNotFound
+		var err error
+		optionFound, err = api.hasDatasetDimensionOption(ctx, filter.Dataset, dimensionName, option)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		optionFound = slices.Contains(dimension.Options, option)
 	}
 
 	if !optionFound {
 		return nil, filters.ErrDimensionOptionNotFound
 	}
 
-	return dimensionOption, nil
+	dimLink := fmt.Sprintf("%s/filters/%s/dimensions/%s", api.host, filter.FilterID, dimension.Name)
+	filterObject := &models.LinkObject{
+		HRef: fmt.Sprintf("%s/filters/%s", api.host, filter.FilterID),
+		ID:   filter.FilterID,
+	}
+
+	return &models.PublicDimensionOption{
+		Links: &models.PublicDimensionOptionLinkMap{
+			Self:      &models.LinkObject{HRef: dimLink + "/options/" + option, ID: option},
+			Dimension: &models.LinkObject{HRef: dimLink, ID: dimension.Name},
+			Filter:    filterObject,
+		},
+		Option: option,
+	}, nil
 }
 
 func (api *FilterAPI) addFilterBlueprintDimensionOptionHandler(w http.ResponseWriter, r *http.Request) {
@@ -318,19 +417,37 @@ func (api *FilterAPI) addFilterBlueprintDimensionOptionHandler(w http.ResponseWr
 	}
 	ctx := r.Context()
 
+	dryRun, err := getDryRun(r)
+	if err != nil {
+		log.Error(ctx, "invalid dry_run query parameter", err, logData)
+		setErrorCodeFromErrorExpectDimension(w, r, err)
+		return
+	}
+
+	// a dry run only reports the dimension options that the option or pattern matches
+	if dryRun {
+		api.writeOptionPatternMatch(w, r, filterBlueprintID, dimensionName, option, logData)
+		return
+	}
+
 	// eTag value must be present in If-Match header
 	eTag, err := getIfMatchForce(r)
 	if err != nil {
 		log.Error(ctx, "missing header", err, log.Data{"error": err.Error()})
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if models.IsOptionPattern(option) {
+		api.addFilterBlueprintDimensionOptionPattern(w, r, filterBlueprintID, dimensionName, option, eTag, logData)
 		return
 	}
 
 	// add the dimension options, if valid
-	newETag, err := api.addFilterBlueprintDimensionOptions(ctx, filterBlueprintID, dimensionName, eTag, []string{option}, logData)
+	newETag, err := api.addFilterBlueprintDimensionOptions(ctx, filterBlueprintID, dimensionName, eTag, []string{option}, nil, nil, logData)
 	if err != nil {
 		log.Error(ctx, "error adding filter blueprint dimension option", err, logData)
-		setErrorCodeFromErrorExpectDimension(w, err)
+		setErrorCodeFromErrorExpectDimension(w, r, err)
 		return
 	}
 
@@ -338,7 +455,7 @@ func (api *FilterAPI) addFilterBlueprintDimensionOptionHandler(w http.ResponseWr
 	filterBlueprint, err := api.getFilterBlueprint(ctx, filterBlueprintID, newETag)
 	if err != nil {
 		log.Error(ctx, "error getting filter blueprint dimension option after the dimension option has been successfully added", err, logData)
-		setErrorCodeFromErrorExpectDimension(w, err)
+		setErrorCodeFromErrorExpectDimension(w, r, err)
 		return
 	}
 
@@ -346,14 +463,14 @@ func (api *FilterAPI) addFilterBlueprintDimensionOptionHandler(w http.ResponseWr
 	dimensionOption, err := api.getFilterBlueprintDimensionOption(ctx, filterBlueprint, dimensionName, option)
 	if err != nil {
 		log.Error(ctx, "unable to get dimension option for filter blueprint", err, logData)
-		setErrorCodeFromErrorExpectDimension(w, err)
+		setErrorCodeFromErrorExpectDimension(w, r, err)
 		return
 	}
 
 	b, err := json.Marshal(dimensionOption)
 	if err != nil {
 		log.Error(ctx, "failed to marshal filter blueprint dimension option into bytes", err, logData)
-		http.Error(w, InternalError, http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, errInternal)
 		return
 	}
 
@@ -363,21 +480,28 @@ func (api *FilterAPI) addFilterBlueprintDimensionOptionHandler(w http.ResponseWr
 	_, err = w.Write(b)
 	if err != nil {
 		log.Error(ctx, "failed to write bytes for http response", err, logData)
-		setErrorCode(w, err)
+		setErrorCode(w, r, err)
 		return
 	}
 
 	log.Info(ctx, "created new dimension option for filter blueprint", logData)
 }
 
-// addFilterBlueprintDimensionOptions adds the provided options to the filter dimension, only if the options are available for the dimension.
-func (api *FilterAPI) addFilterBlueprintDimensionOptions(ctx context.Context, filterBlueprintID, dimensionName, eTag string, options []string, _ log.Data) (newETag string, err error) {
+// addFilterBlueprintDimensionOptions adds the provided options, and the options selected by the provided selectors, to the filter dimension,
+// only if the options are available for the dimension. The selectors are expanded within the provided limit, which is shared by the patches of a request.
+func (api *FilterAPI) addFilterBlueprintDimensionOptions(ctx context.Context, filterBlueprintID, dimensionName, eTag string, options []string, selectors []models.OptionSelector, limit *optionSelectorLimit, _ log.Data) (newETag string, err error) {
 	// request filterBlueprint before updating it
 	filterBlueprint, err := api.getFilterBlueprint(ctx, filterBlueprintID, eTag)
 	if err != nil {
 		return "", err
 	}
 
+	// expand any option selectors and patterns into the option codes they select
+	options, err = api.expandOptions(ctx, filterBlueprint, dimensionName, options, selectors, limit)
+	if err != nil {
+		return "", err
+	}
+
 	// Check if dimension exists and any provided option already exists
 	hasDimension, _, missingOptions := findDimensionAndOptions(filterBlueprint, dimensionName, options)
 	if !hasDimension {
@@ -386,19 +510,21 @@ func (api *FilterAPI) addFilterBlueprintDimensionOptions(ctx context.Context, fi
 
 	// validate that the provided existing dimension is still valid and the options are acceptable for the dimension
 	if err := api.checkNewFilterDimension(ctx, dimensionName, options, filterBlueprint.Dataset); err != nil {
-		if err == filters.ErrVersionNotFound || err == filters.ErrDimensionsNotFound {
-			return "", err
-		}
+		return "", err
+	}
 
-		if incorrectDimensionOptions.MatchString(err.Error()) {
-			return "", filters.NewBadRequestErr(err.Error())
+	// in exclude mode, selecting options means removing them from the excluded options
+	if findDimension(filterBlueprint, dimensionName).IsExclude() {
+		excludedOptions := []string{}
+		for _, option := range options {
+			if _, missing := missingOptions[option]; !missing {
+				excludedOptions = append(excludedOptions, option)
+			}
 		}
-
-		if incorrectDimension.MatchString(err.Error()) {
-			return "", filters.NewBadRequestErr(err.Error())
+		if len(excludedOptions) == 0 {
+			return filterBlueprint.ETag, nil
 		}
-
-		return "", err
+		return api.dataStore.RemoveFilterDimensionOptions(ctx, filterBlueprintID, dimensionName, excludedOptions, filterBlueprint.UniqueTimestamp, filterBlueprint.ETag, filterBlueprint)
 	}
 
 	// All validations succeeded - add dimension options that do not already exist
@@ -419,18 +545,37 @@ func (api *FilterAPI) removeFilterBlueprintDimensionOptionHandler(w http.Respons
 	ctx := r.Context()
 	log.Info(ctx, "remove filter blueprint dimension option", logData)
 
+	dryRun, err := getDryRun(r)
+	if err != nil {
+		log.Error(ctx, "invalid dry_run query parameter", err, logData)
+		setErrorCodeFromError(w, r, err)
+		return
+	}
+
+	// a dry run only reports the dimension options that the option or pattern matches
+	if dryRun {
+		api.writeOptionPatternMatch(w, r, filterBlueprintID, dimensionName, option, logData)
+		return
+	}
+
 	// eTag value must be present in If-Match header
 	eTag, err := getIfMatchForce(r)
 	if err != nil {
 		log.Error(ctx, "missing header", err, log.Data{"error": err.Error()})
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, err)
 		return
 	}
 
-	newETag, err := api.removeFilterBlueprintDimensionOption(ctx, filterBlueprintID, dimensionName, option, eTag)
+	var newETag string
+	if models.IsOptionPattern(option) {
+		// removing a pattern does not fail for matched options that are not selected, like a patch remove operation
+		newETag, err = api.removeFilterBlueprintDimensionOptions(ctx, filterBlueprintID, dimensionName, eTag, []string{option}, nil, nil, logData)
+	} else {
+		newETag, err = api.removeFilterBlueprintDimensionOption(ctx, filterBlueprintID, dimensionName, option, eTag)
+	}
 	if err != nil {
 		log.Error(ctx, "error removing filter blueprint dimension option", err, logData)
-		setErrorCodeFromError(w, err)
+		setErrorCodeFromError(w, r, err)
 		return
 	}
 
@@ -456,6 +601,23 @@ func (api *FilterAPI) removeFilterBlueprintDimensionOption(ctx context.Context,
 		return "", filters.ErrDimensionNotFound
 	}
 
+	// in exclude mode, removing an option from the selection means adding it to the excluded options
+	if findDimension(filterBlueprint, dimensionName).IsExclude() {
+		if hasOptions {
+			return "", filters.ErrDimensionOptionNotFound
+		}
+
+		isDatasetOption, err := api.hasDatasetDimensionOption(ctx, filterBlueprint.Dataset, dimensionName, option)
+		if err != nil {
+			return "", err
+		}
+		if !isDatasetOption {
+			return "", filters.ErrDimensionOptionNotFound
+		}
+
+		return api.dataStore.AddFilterDimensionOption(ctx, filterBlueprint.FilterID, dimensionName, option, filterBlueprint.UniqueTimestamp, filterBlueprint.ETag, filterBlueprint)
+	}
+
 	if !hasOptions {
 		return "", filters.ErrDimensionOptionNotFound
 	}
@@ -463,10 +625,11 @@ func (api *FilterAPI) removeFilterBlueprintDimensionOption(ctx context.Context,
 	return api.dataStore.RemoveFilterDimensionOption(ctx, filterBlueprint.FilterID, dimensionName, option, filterBlueprint.UniqueTimestamp, filterBlueprint.ETag, filterBlueprint)
 }
 
-// removeFilterBlueprintDimensionOption removes all provided options.
-func (api *FilterAPI) removeFilterBlueprintDimensionOptions(ctx context.Context, filterBlueprintID, dimensionName, eTag string, options []string, _ log.Data) (newETag string, err error) {
+// removeFilterBlueprintDimensionOption removes all provided options, and the options selected by the provided selectors,
+// which are expanded within the provided limit.
+func (api *FilterAPI) removeFilterBlueprintDimensionOptions(ctx context.Context, filterBlueprintID, dimensionName, eTag string, options []string, selectors []models.OptionSelector, limit *optionSelectorLimit, _ log.Data) (newETag string, err error) {
 	// check if any option has been provided
-	if len(options) == 0 {
+	if len(options) == 0 && len(selectors) == 0 {
 		return eTag, nil
 	}
 
@@ -476,12 +639,29 @@ func (api *FilterAPI) removeFilterBlueprintDimensionOptions(ctx context.Context,
 		return "", err
 	}
 
+	// expand any option selectors and patterns into the option codes they select
+	options, err = api.expandOptions(ctx, filterBlueprint, dimensionName, options, selectors, limit)
+	if err != nil {
+		return "", err
+	}
+
 	// Check if provided dimension and options exists in filter blueprint
 	hasDimension, hasAllOptions, missingOptions := findDimensionAndOptions(filterBlueprint, dimensionName, options)
 	if !hasDimension {
 		return "", filters.ErrDimensionNotFound
 	}
 
+	// in exclude mode, removing options from the selection means adding them to the excluded options
+	if findDimension(filterBlueprint, dimensionName).IsExclude() {
+		optionsToExclude := []string{}
+		for _, option := range options {
+			if _, found := missingOptions[option]; found {
+				optionsToExclude = append(optionsToExclude, option)
+			}
+		}
+		return api.excludeFilterBlueprintDimensionOptions(ctx, filterBlueprint, dimensionName, optionsToExclude)
+	}
+
 	// find options that actually need to be removed according to the existing options before applying any change
 	optionsToRemove := []string{}
 	if hasAllOptions {
@@ -517,11 +697,18 @@ func (api *FilterAPI) patchFilterBlueprintDimensionHandler(w http.ResponseWriter
 	ctx := r.Context()
 	log.Info(ctx, "patch filter blueprint dimension", logData)
 
-	// eTag value must be present in If-Match header
-	eTag, err := getIfMatchForce(r)
+	dryRun, err := getDryRun(r)
 	if err != nil {
+		log.Error(ctx, "invalid dry_run query parameter", err, logData)
+		setErrorCodeFromError(w, r, err)
+		return
+	}
+
+	// eTag value must be present in If-Match header, unless the patch is only a dry run
+	eTag, err := getIfMatchForce(r)
+	if err != nil && !dryRun {
 		log.Error(ctx, "missing header", err, log.Data{"error": err.Error()})
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, err)
 		return
 	}
 
@@ -529,7 +716,7 @@ func (api *FilterAPI) patchFilterBlueprintDimensionHandler(w http.ResponseWriter
 	patches, err := models.CreatePatches(r.Body)
 	if err != nil {
 		log.Error(ctx, "error obtaining patch from request body", err, logData)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, err)
 		return
 	}
 	logData["patch_list"] = patches
@@ -540,50 +727,65 @@ func (api *FilterAPI) patchFilterBlueprintDimensionHandler(w http.ResponseWriter
 		if patch.Path != "/options/-" {
 			err = fmt.Errorf("provided path '%s' not supported. Supported paths: '/options/-'", patch.Path)
 			log.Error(ctx, "error validating patch operation path, no change has been applied", err, logData)
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			writeError(w, r, http.StatusBadRequest, err)
 			return
 		}
-		v2, err := getStringArrayFromInterface(patch.Value)
+		values, err := getOptionsFromInterface(patch.Value)
 		if err != nil {
-			err = fmt.Errorf("values provided are not strings")
+			err = fmt.Errorf("values provided are not strings, valid option selectors or valid option ranges")
 			log.Error(ctx, "error validating patch operation path, no change has been applied", err, logData)
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			writeError(w, r, http.StatusBadRequest, err)
 			return
 		}
 
-		totalValues += len(v2)
+		totalValues += len(values.Options) + len(values.Selectors) + len(values.Ranges)
 		if totalValues > api.maxRequestOptions {
 			logData["max_options"] = api.maxRequestOptions
 			err = fmt.Errorf("a maximum of %d overall option values can be provied in a set of patch operations, which has been exceeded", api.maxRequestOptions)
 			log.Error(ctx, "error validating patch operation values size, no change has been applied", err, logData)
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			writeError(w, r, http.StatusBadRequest, err)
 			return
 		}
 	}
 
+	// a dry run only reports the dimension options that the option patterns of the patches match
+	if dryRun {
+		api.writePatchOptionPatternMatches(w, r, filterBlueprintID, dimensionName, patches, logData)
+		return
+	}
+
 	// apply the patches to the filter blueprint dimension options
 	var newETag interface{}
 	newETag, err = api.dataStore.RunTransaction(ctx, true, func(txCtx context.Context) (interface{}, error) {
-		var (
-			allOptions, options []string
-			tag                 = eTag
-		)
+		tag := eTag
+		limit := api.newOptionSelectorLimit()
 
 		// apply patch operations sequentially, stop processing if one patch fails, and return a list of successful patches operations
 		for _, patch := range patches {
-			allOptions, err = getStringArrayFromInterface(patch.Value)
+			values, err := getOptionsFromInterface(patch.Value)
 			if err != nil {
 				return tag, err
 			}
-			options = RemoveDuplicateAndEmptyOptions(allOptions)
-
-			if patch.Op == dprequest.OpAdd.String() {
-				tag, err = api.addFilterBlueprintDimensionOptions(txCtx, filterBlueprintID, dimensionName, tag, options, logData)
+			options := RemoveDuplicateAndEmptyOptions(values.Options)
+
+			// a patch that only provides option ranges does not need to go through the option codes operation
+			if len(options) > 0 || len(values.Selectors) > 0 || len(values.Ranges) == 0 {
+				if patch.Op == dprequest.OpAdd.String() {
+					tag, err = api.addFilterBlueprintDimensionOptions(txCtx, filterBlueprintID, dimensionName, tag, options, values.Selectors, limit, logData)
+				} else {
+					tag, err = api.removeFilterBlueprintDimensionOptions(txCtx, filterBlueprintID, dimensionName, tag, options, values.Selectors, limit, logData)
+				}
 				if err != nil {
 					return tag, err
 				}
-			} else {
-				tag, err = api.removeFilterBlueprintDimensionOptions(txCtx, filterBlueprintID, dimensionName, tag, options, logData)
+			}
+
+			if len(values.Ranges) > 0 {
+				if patch.Op == dprequest.OpAdd.String() {
+					tag, err = api.addFilterBlueprintDimensionRanges(txCtx, filterBlueprintID, dimensionName, tag, values.Ranges)
+				} else {
+					tag, err = api.removeFilterBlueprintDimensionRanges(txCtx, filterBlueprintID, dimensionName, tag, values.Ranges)
+				}
 				if err != nil {
 					return tag, err
 				}
@@ -594,14 +796,14 @@ func (api *FilterAPI) patchFilterBlueprintDimensionHandler(w http.ResponseWriter
 	})
 	if err != nil {
 		log.Error(ctx, "error patching filter blueprint dimension options", err, logData)
-		setErrorCodeFromError(w, err)
+		setErrorCodeFromError(w, r, err)
 		return
 	}
 	t, ok := newETag.(string)
 	if !ok {
 		err = errors.New("returned ETag is not a string value")
 		log.Error(ctx, err.Error(), err, log.Data{"newETag": newETag})
-		setErrorCodeFromError(w, err)
+		setErrorCodeFromError(w, r, err)
 		return
 	}
 	setETag(w, t)
@@ -610,13 +812,23 @@ func (api *FilterAPI) patchFilterBlueprintDimensionHandler(w http.ResponseWriter
 	setJSONPatchContentType(w)
 	if err = WriteJSONBody(ctx, patches, w, logData); err != nil {
 		log.Error(ctx, "error writing JSON body after a successful filter blueprint patch", err, logData)
-		setErrorCodeFromError(w, err)
+		setErrorCodeFromError(w, r, err)
 		return
 	}
 
 	log.Info(ctx, "successfully patched filter dimension options on filter blueprint", logData)
 }
 
+// findDimension returns the dimension with the provided name in the filter blueprint, or nil if it is not found
+func findDimension(filterBlueprint *models.Filter, dimensionName string) *models.Dimension {
+	for i := range filterBlueprint.Dimensions {
+		if filterBlueprint.Dimensions[i].Name == dimensionName {
+			return &filterBlueprint.Dimensions[i]
+		}
+	}
+	return nil
+}
+
 // findDimensionAndOptions finds the provided dimensionName and options (in the dimension) in the filterBlueprint
 func findDimensionAndOptions(filterBlueprint *models.Filter, dimensionName string, options []string) (hasDimension, hasAllOptions bool, missingOptions map[string]struct{}) {
 	// unique option names that have not been found yet
@@ -640,30 +852,30 @@ func findDimensionAndOptions(filterBlueprint *models.Filter, dimensionName strin
 }
 
 // setErrorCodeFromError sets the HTTP Status Code according to the provided error.
-func setErrorCodeFromError(w http.ResponseWriter, err error) {
+func setErrorCodeFromError(w http.ResponseWriter, r *http.Request, err error) {
 	switch err {
 	case filters.ErrFilterBlueprintNotFound:
-		setErrorCode(w, err, statusBadRequest)
+		setErrorCode(w, r, err, statusBadRequest)
 	case filters.ErrDimensionsNotFound:
 		fallthrough
 	case filters.ErrVersionNotFound:
-		setErrorCode(w, err, statusUnprocessableEntity)
+		setErrorCode(w, r, err, statusUnprocessableEntity)
 	default:
-		setErrorCode(w, err)
+		setErrorCode(w, r, err)
 	}
 }
 
 // setErrorCodeFromError sets the HTTP Status Code according to the provided error, expecting the dimension (ErrDimensionNotFound will be mapped to statusBadRequest)
-func setErrorCodeFromErrorExpectDimension(w http.ResponseWriter, err error) {
+func setErrorCodeFromErrorExpectDimension(w http.ResponseWriter, r *http.Request, err error) {
 	switch err {
 	case filters.ErrFilterBlueprintNotFound, filters.ErrInvalidQueryParameter, filters.ErrDimensionNotFound:
-		setErrorCode(w, err, statusBadRequest)
+		setErrorCode(w, r, err, statusBadRequest)
 	case filters.ErrDimensionsNotFound:
 		fallthrough
 	case filters.ErrVersionNotFound:
-		setErrorCode(w, err, statusUnprocessableEntity)
+		setErrorCode(w, r, err, statusUnprocessableEntity)
 	default:
-		setErrorCode(w, err)
+		setErrorCode(w, r, err)
 	}
 }
 
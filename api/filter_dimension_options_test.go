@@ -108,7 +108,7 @@ func TestFailedToAddFilterBlueprintDimensionOption(t *testing.T) {
 
 		Convey("Then a 500 InternalServerError status is returned with the expected error response", func() {
 			So(w.Code, ShouldEqual, http.StatusInternalServerError)
-			So(w.Body.String(), ShouldResemble, internalErrResponse)
+			So(problemDetail(w.Body.String()), ShouldEqual, internalErrResponse)
 		})
 
 		Convey("Then the ETag header is empty", func() {
@@ -133,7 +133,7 @@ func TestFailedToAddFilterBlueprintDimensionOption(t *testing.T) {
 
 		Convey("Then a 400 BadRequest status is returned with the expected error response", func() {
 			So(w.Code, ShouldEqual, http.StatusBadRequest)
-			So(w.Body.String(), ShouldResemble, filterNotFoundResponse)
+			So(problemDetail(w.Body.String()), ShouldEqual, filterNotFoundResponse)
 		})
 
 		Convey("Then the ETag header is empty", func() {
@@ -158,7 +158,7 @@ func TestFailedToAddFilterBlueprintDimensionOption(t *testing.T) {
 
 		Convey("Then a 400 BadRequest status is returned with the expected error response", func() {
 			So(w.Code, ShouldEqual, http.StatusBadRequest)
-			So(w.Body.String(), ShouldResemble, filterNotFoundResponse)
+			So(problemDetail(w.Body.String()), ShouldEqual, filterNotFoundResponse)
 		})
 
 		Convey("Then the ETag header is empty", func() {
@@ -183,17 +183,18 @@ func TestFailedToAddFilterBlueprintDimensionOption(t *testing.T) {
 
 		Convey("Then a 400 BadRequest status is returned with the expected error response", func() {
 			So(w.Code, ShouldEqual, http.StatusBadRequest)
-			So(w.Body.String(), ShouldResemble, "incorrect dimension options chosen: [66]\n")
+			So(problemErrors(w.Body.String()), ShouldResemble, validationErrorsResponse(models.NewOptionsNotFoundError("age", []string{"66"}, []string{"27", "33"})))
 		})
 
 		Convey("Then the ETag header is empty", func() {
 			So(w.Result().Header.Get("ETag"), ShouldResemble, "")
 		})
 
-		Convey("And the dimension and options are efficiently validated with dataset API", func() {
+		Convey("And the dimension and options are efficiently validated with dataset API, only requesting every dimension option to suggest alternatives", func() {
 			So(datasetAPIMock.GetVersionDimensionsCalls(), ShouldHaveLength, 1)
-			So(datasetAPIMock.GetOptionsBatchProcessCalls(), ShouldHaveLength, 1)
+			So(datasetAPIMock.GetOptionsBatchProcessCalls(), ShouldHaveLength, 2)
 			So(*datasetAPIMock.GetOptionsBatchProcessCalls()[0].OptionIDs, ShouldResemble, []string{"66"})
+			So(datasetAPIMock.GetOptionsBatchProcessCalls()[1].OptionIDs, ShouldBeNil)
 		})
 	})
 
@@ -210,7 +211,7 @@ func TestFailedToAddFilterBlueprintDimensionOption(t *testing.T) {
 		Convey("Then a 400 BadRequest status is returned with the expected error response", func() {
 			So(w.Code, ShouldEqual, http.StatusBadRequest)
 			response := w.Body.String()
-			So(response, ShouldResemble, "dimension not found\n")
+			So(problemDetail(response), ShouldEqual, "dimension not found")
 		})
 
 		Convey("Then the ETag header is empty", func() {
@@ -320,7 +321,7 @@ func TestFailedToRemoveFilterBlueprintDimensionOption(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldResemble, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, internalErrResponse)
+		So(problemDetail(response), ShouldEqual, internalErrResponse)
 	})
 
 	Convey("When filter blueprint does not exist, a bad request is returned", t, func() {
@@ -335,7 +336,7 @@ func TestFailedToRemoveFilterBlueprintDimensionOption(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldResemble, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, filterNotFoundResponse)
+		So(problemDetail(response), ShouldEqual, filterNotFoundResponse)
 	})
 
 	Convey("When filter blueprint is unpublished and request is not authenticated, a bad request is returned", t, func() {
@@ -350,7 +351,7 @@ func TestFailedToRemoveFilterBlueprintDimensionOption(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldResemble, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, filterNotFoundResponse)
+		So(problemDetail(response), ShouldEqual, filterNotFoundResponse)
 	})
 
 	Convey("When dimension does not exist against filter blueprint, a not found is returned", t, func() {
@@ -365,7 +366,7 @@ func TestFailedToRemoveFilterBlueprintDimensionOption(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldResemble, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, dimensionNotFoundResponse)
+		So(problemDetail(response), ShouldEqual, dimensionNotFoundResponse)
 	})
 
 	Convey("When the filter document has been modified by an external source, a conflict request status is returned", t, func() {
@@ -540,6 +541,9 @@ func TestSuccessfulGetFilterBlueprintDimensionOptions(t *testing.T) {
 				Offset:     1,
 				Limit:      20,
 				TotalCount: 2,
+				Links: &models.PageLinks{
+					Prev: pageLink("/filters/12345678/dimensions/time/options", `{"key":"2015","etag":"testETag0","before":true}`, ""),
+				},
 			}
 			validateBody(w.Body.Bytes(), expected)
 		})
@@ -569,6 +573,9 @@ func TestSuccessfulGetFilterBlueprintDimensionOptions(t *testing.T) {
 				Offset:     0,
 				Limit:      1,
 				TotalCount: 2,
+				Links: &models.PageLinks{
+					Next: pageLink("/filters/12345678/dimensions/time/options", `{"key":"2014","etag":"testETag0"}`, "limit=1"),
+				},
 			}
 			validateBody(w.Body.Bytes(), expected)
 		})
@@ -646,7 +653,7 @@ func TestFailedToGetFilterBlueprintDimensionOptions(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldEqual, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, invalidQueryParameterResponse)
+		So(problemDetail(response), ShouldEqual, invalidQueryParameterResponse)
 	})
 
 	Convey("When negative values are provided for limit and offset query parameters, a bad request error is returned", t, func() {
@@ -660,7 +667,7 @@ func TestFailedToGetFilterBlueprintDimensionOptions(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldEqual, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, invalidQueryParameterResponse)
+		So(problemDetail(response), ShouldEqual, invalidQueryParameterResponse)
 	})
 
 	Convey("When a limit higher than the maximum allowed is provided, a bad request error is returned", t, func() {
@@ -674,7 +681,7 @@ func TestFailedToGetFilterBlueprintDimensionOptions(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldEqual, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, invalidQueryParameterResponse)
+		So(problemDetail(response), ShouldEqual, invalidQueryParameterResponse)
 	})
 
 	Convey("When an invalid offset value is provided, a bad request error is returned", t, func() {
@@ -688,7 +695,7 @@ func TestFailedToGetFilterBlueprintDimensionOptions(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldEqual, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, invalidQueryParameterResponse)
+		So(problemDetail(response), ShouldEqual, invalidQueryParameterResponse)
 	})
 
 	Convey("When no data store is available, an internal error is returned", t, func() {
@@ -702,7 +709,7 @@ func TestFailedToGetFilterBlueprintDimensionOptions(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldEqual, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, internalErrResponse)
+		So(problemDetail(response), ShouldEqual, internalErrResponse)
 	})
 
 	Convey("When filter blueprint does not exist, a not found is returned", t, func() {
@@ -716,7 +723,7 @@ func TestFailedToGetFilterBlueprintDimensionOptions(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldEqual, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, filterNotFoundResponse)
+		So(problemDetail(response), ShouldEqual, filterNotFoundResponse)
 	})
 
 	Convey("When filter blueprint is unpublished and the request is unauthenticated, a not found is returned", t, func() {
@@ -730,7 +737,7 @@ func TestFailedToGetFilterBlueprintDimensionOptions(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldEqual, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, filterNotFoundResponse)
+		So(problemDetail(response), ShouldEqual, filterNotFoundResponse)
 	})
 
 	Convey("When dimension does not exist against filter blueprint, a dimension not found is returned", t, func() {
@@ -744,7 +751,7 @@ func TestFailedToGetFilterBlueprintDimensionOptions(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldEqual, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, dimensionNotFoundResponse)
+		So(problemDetail(response), ShouldEqual, dimensionNotFoundResponse)
 	})
 }
 
@@ -823,7 +830,7 @@ func TestFailedToGetFilterBlueprintDimensionOption(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldEqual, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, internalErrResponse)
+		So(problemDetail(response), ShouldEqual, internalErrResponse)
 	})
 
 	Convey("When filter blueprint does not exist, a bad request is returned", t, func() {
@@ -837,7 +844,7 @@ func TestFailedToGetFilterBlueprintDimensionOption(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldEqual, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, filterNotFoundResponse)
+		So(problemDetail(response), ShouldEqual, filterNotFoundResponse)
 	})
 
 	Convey("When filter blueprint is unpublished and request is unauthenticated, a bad request is returned", t, func() {
@@ -851,7 +858,7 @@ func TestFailedToGetFilterBlueprintDimensionOption(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldEqual, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, filterNotFoundResponse)
+		So(problemDetail(response), ShouldEqual, filterNotFoundResponse)
 	})
 
 	Convey("When option does not exist against filter blueprint, an option not found is returned", t, func() {
@@ -865,7 +872,7 @@ func TestFailedToGetFilterBlueprintDimensionOption(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldEqual, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, optionNotFoundResponse)
+		So(problemDetail(response), ShouldEqual, optionNotFoundResponse)
 	})
 }
 
@@ -1173,7 +1180,7 @@ func TestFailedPatchBlueprintDimension(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldEqual, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, "failed to parse json body\n")
+		So(problemDetail(response), ShouldEqual, "failed to parse json body")
 	})
 
 	Convey("When a valid patch with an operation that is not supported is provided, a 400 BadRequest is returned", t, func() {
@@ -1189,7 +1196,7 @@ func TestFailedPatchBlueprintDimension(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldEqual, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, "op 'test' not supported. Supported op(s): [add remove]\n")
+		So(problemDetail(response), ShouldEqual, "op 'test' not supported. Supported op(s): [add remove]")
 	})
 
 	Convey("When a valid patch with an incorrect path is provided, a 400 BadRequest is returned", t, func() {
@@ -1205,7 +1212,7 @@ func TestFailedPatchBlueprintDimension(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldEqual, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, "provided path '/wrong/path' not supported. Supported paths: '/options/-'\n")
+		So(problemDetail(response), ShouldEqual, "provided path '/wrong/path' not supported. Supported paths: '/options/-'")
 	})
 
 	Convey("Whe a valid 'add' patch with an incorrect option for a dimension is provided, a 400 BadRequest is returned", t, func() {
@@ -1221,7 +1228,7 @@ func TestFailedPatchBlueprintDimension(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldEqual, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, "incorrect dimension options chosen: [wrong]\n")
+		So(problemErrors(response), ShouldResemble, validationErrorsResponse(models.NewOptionsNotFoundError("age", []string{"wrong"}, []string{"27", "33"})))
 	})
 
 	Convey("When a valid patch with an overall sum of values higher than the maximum allowed is provided, a 400 BadRequest is returned", t, func() {
@@ -1241,7 +1248,7 @@ func TestFailedPatchBlueprintDimension(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldEqual, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, "a maximum of 10 overall option values can be provied in a set of patch operations, which has been exceeded\n")
+		So(problemDetail(response), ShouldEqual, "a maximum of 10 overall option values can be provied in a set of patch operations, which has been exceeded")
 	})
 
 	Convey("When no data store is available, an internal error is returned", t, func() {
@@ -1257,7 +1264,7 @@ func TestFailedPatchBlueprintDimension(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldEqual, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, internalErrResponse)
+		So(problemDetail(response), ShouldEqual, internalErrResponse)
 	})
 
 	Convey("When filter blueprint does not exist, a bad request response is returned", t, func() {
@@ -1273,7 +1280,7 @@ func TestFailedPatchBlueprintDimension(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldEqual, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, filterNotFoundResponse)
+		So(problemDetail(response), ShouldEqual, filterNotFoundResponse)
 	})
 
 	Convey("When filter blueprint is unpublished and request is unauthenticated, a bad request is returned", t, func() {
@@ -1289,7 +1296,7 @@ func TestFailedPatchBlueprintDimension(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldEqual, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, filterNotFoundResponse)
+		So(problemDetail(response), ShouldEqual, filterNotFoundResponse)
 	})
 
 	Convey("When dimension does not exist against filter blueprint, a not found response is returned", t, func() {
@@ -1305,7 +1312,7 @@ func TestFailedPatchBlueprintDimension(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldEqual, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, dimensionNotFoundResponse)
+		So(problemDetail(response), ShouldEqual, dimensionNotFoundResponse)
 	})
 
 	Convey("When the value of the provided If-Match header doesn't match the existing value in the database, a conflict response is returned", t, func() {
@@ -39,7 +39,7 @@ func (api *FilterAPI) getFilterBlueprintDimensionsHandler(w http.ResponseWriter,
 		offset, err = validatePositiveInt(offsetParameter)
 		if err != nil {
 			log.Error(ctx, "failed to obtain offset from request query parameters", err, logData)
-			setErrorCode(w, err)
+			setErrorCode(w, r, err)
 			return
 		}
 	}
@@ -49,7 +49,7 @@ func (api *FilterAPI) getFilterBlueprintDimensionsHandler(w http.ResponseWriter,
 		limit, err = validatePositiveInt(limitParameter)
 		if err != nil {
 			log.Error(ctx, "failed to obtain limit from request query parameters", err, logData)
-			setErrorCode(w, err)
+			setErrorCode(w, r, err)
 			return
 		}
 	}
@@ -58,14 +58,35 @@ func (api *FilterAPI) getFilterBlueprintDimensionsHandler(w http.ResponseWriter,
 		logData["max_limit"] = api.maxLimit
 		err = filters.ErrInvalidQueryParameter
 		log.Error(ctx, "limit is greater than the maximum allowed", err, logData)
-		setErrorCode(w, err)
+		setErrorCode(w, r, err)
+		return
+	}
+
+	pageCursor, err := getCursor(r)
+	if err != nil {
+		log.Error(ctx, "failed to obtain cursor from request query parameters", err, logData)
+		setErrorCode(w, r, err)
+		return
+	}
+
+	includeLabels, err := getIncludeLabels(r)
+	if err != nil {
+		log.Error(ctx, "failed to obtain include from request query parameters", err, logData)
+		setErrorCode(w, r, err)
 		return
 	}
 
 	filter, err := api.getFilterBlueprint(ctx, filterBlueprintID, mongo.AnyETag)
 	if err != nil {
 		log.Error(ctx, "unable to get dimensions for filter blueprint", err, logData)
-		setErrorCode(w, err)
+		setErrorCode(w, r, err)
+		return
+	}
+
+	if err := checkCursor(pageCursor, filter.ETag); err != nil {
+		logData["current_etag"] = filter.ETag
+		log.Error(ctx, "cursor was issued for a previous version of the filter blueprint", err, logData)
+		setErrorCode(w, r, err)
 		return
 	}
 
@@ -75,7 +96,13 @@ func (api *FilterAPI) getFilterBlueprintDimensionsHandler(w http.ResponseWriter,
 
 	if len(filter.Dimensions) == 0 {
 		log.Error(ctx, "dimension not found", filters.ErrDimensionNotFound, logData)
-		setErrorCode(w, filters.ErrDimensionNotFound)
+		setErrorCode(w, r, filters.ErrDimensionNotFound)
+		return
+	}
+
+	eTag := representationETag(filter.ETag, getAcceptLanguage(r), nil)
+	if api.writeNotModified(w, r, eTag, isPublished(filter), false) {
+		log.Info(ctx, "filter blueprint dimensions not modified", logData)
 		return
 	}
 
@@ -86,11 +113,16 @@ func (api *FilterAPI) getFilterBlueprintDimensionsHandler(w http.ResponseWriter,
 	}
 
 	sort.Strings(dimensionNames)
-	slicedDimensionNames := slice(dimensionNames, offset, limit)
+	dimensionsPage, err := paginate(dimensionNames, offset, limit, pageCursor)
+	if err != nil {
+		log.Error(ctx, "cursor is not for a dimension of the filter blueprint", err, logData)
+		setErrorCode(w, r, err)
+		return
+	}
 
 	var filterDimensions []models.Dimension
 
-	for _, dimensionName := range slicedDimensionNames {
+	for _, dimensionName := range dimensionsPage.keys {
 		for _, dimension := range filter.Dimensions {
 			if dimension.Name == dimensionName {
 				filterDimensions = append(filterDimensions, dimension)
@@ -100,18 +132,34 @@ func (api *FilterAPI) getFilterBlueprintDimensionsHandler(w http.ResponseWriter,
 	}
 
 	items := CreatePublicDimensions(filterDimensions, api.host.String(), filter.FilterID)
+	if includeLabels {
+		language := getAcceptLanguage(r)
+		if err := api.labelPublicDimensions(withLanguage(ctx, language), filter.Dataset, items); err != nil {
+			log.Error(ctx, "unable to get dimension labels for filter blueprint", err, logData)
+			setErrorCode(w, r, err)
+			return
+		}
+		setContentLanguage(w, language)
+	}
 	publicDimensions := models.PublicDimensions{
 		Items:      items,
 		Count:      len(items),
 		TotalCount: len(filter.Dimensions),
-		Offset:     offset,
+		Offset:     dimensionsPage.offset,
 		Limit:      limit,
+		Links:      dimensionsPage.links(api.host.String()+r.URL.Path, r.URL.Query(), filter.ETag),
 	}
 	logData["items output:"] = items
 
 	if api.enableURLRewriting {
 		filterAPILinksBuilder := links.FromHeadersOrDefault(&r.Header, api.host)
 
+		if err := rewritePageLinks(filterAPILinksBuilder, publicDimensions.Links); err != nil {
+			log.Error(ctx, "failed to rewrite filter dimensions page links", err, logData)
+			setErrorCode(w, r, err)
+			return
+		}
+
 		for i := range publicDimensions.Items {
 			linkFields := map[string]*models.LinkObject{
 				"Self":    publicDimensions.Items[i].Links.Self,
@@ -126,7 +174,7 @@ func (api *FilterAPI) getFilterBlueprintDimensionsHandler(w http.ResponseWriter,
 						logData["link_type"] = linkType
 						logData["original_link"] = linkObj.HRef
 						log.Error(ctx, "failed to rewrite filter dimension links", err, logData)
-						setErrorCode(w, err)
+						setErrorCode(w, r, err)
 						return
 					}
 					linkObj.HRef = newLink
@@ -137,16 +185,17 @@ func (api *FilterAPI) getFilterBlueprintDimensionsHandler(w http.ResponseWriter,
 	b, err := json.Marshal(publicDimensions)
 	if err != nil {
 		log.Error(ctx, "failed to marshal filter blueprint dimensions into bytes", err, logData)
-		http.Error(w, InternalError, http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, errInternal)
 		return
 	}
 
 	setJSONContentType(w)
-	setETag(w, filter.ETag)
+	setETag(w, eTag)
+	api.setCacheControl(w, isPublished(filter), false)
 	_, err = w.Write(b)
 	if err != nil {
 		log.Error(ctx, "failed to write bytes for http response", err, logData)
-		setErrorCode(w, err)
+		setErrorCode(w, r, err)
 		return
 	}
 
@@ -164,25 +213,47 @@ func (api *FilterAPI) getFilterBlueprintDimensionHandler(w http.ResponseWriter,
 	ctx := r.Context()
 	log.Info(ctx, "getting filter blueprint dimension", logData)
 
+	includeLabels, err := getIncludeLabels(r)
+	if err != nil {
+		log.Error(ctx, "failed to obtain include from request query parameters", err, logData)
+		setErrorCode(w, r, err)
+		return
+	}
+
 	filter, err := api.getFilterBlueprint(ctx, filterBlueprintID, mongo.AnyETag)
 	if err != nil {
 		log.Error(ctx, "error getting filter blueprint", err, logData)
 		if err == filters.ErrFilterBlueprintNotFound {
-			setErrorCode(w, err, statusBadRequest)
+			setErrorCode(w, r, err, statusBadRequest)
 			return
 		}
-		setErrorCode(w, err)
+		setErrorCode(w, r, err)
 		return
 	}
 
 	dimension, err := api.dataStore.GetFilterDimension(ctx, filterBlueprintID, name, filter.ETag)
 	if err != nil {
 		log.Error(ctx, "error getting filter dimension", err, logData)
-		setErrorCode(w, err)
+		setErrorCode(w, r, err)
+		return
+	}
+
+	eTag := representationETag(filter.ETag, getAcceptLanguage(r), nil)
+	if api.writeNotModified(w, r, eTag, isPublished(filter), false) {
+		log.Info(ctx, "filter blueprint dimension not modified", logData)
 		return
 	}
 
 	publicDimension := CreatePublicDimension(*dimension, api.host.String(), filterBlueprintID)
+	if includeLabels {
+		language := getAcceptLanguage(r)
+		if err := api.labelPublicDimensions(withLanguage(ctx, language), filter.Dataset, []*models.PublicDimension{publicDimension}); err != nil {
+			log.Error(ctx, "error getting filter dimension label", err, logData)
+			setErrorCode(w, r, err)
+			return
+		}
+		setContentLanguage(w, language)
+	}
 
 	if api.enableURLRewriting {
 		filterAPILinksBuilder := links.FromHeadersOrDefault(&r.Header, api.host)
@@ -200,7 +271,7 @@ func (api *FilterAPI) getFilterBlueprintDimensionHandler(w http.ResponseWriter,
 					logData["link_type"] = linkType
 					logData["original_link"] = linkObj.HRef
 					log.Error(ctx, "failed to rewrite public dimension link", err, logData)
-					setErrorCode(w, err)
+					setErrorCode(w, r, err)
 					return
 				}
 				linkObj.HRef = newLink
@@ -211,16 +282,17 @@ func (api *FilterAPI) getFilterBlueprintDimensionHandler(w http.ResponseWriter,
 	b, err := json.Marshal(publicDimension)
 	if err != nil {
 		log.Error(ctx, "failed to marshal filter blueprint dimensions into bytes", err, logData)
-		http.Error(w, InternalError, http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, errInternal)
 		return
 	}
 
 	setJSONContentType(w)
-	setETag(w, filter.ETag)
+	setETag(w, eTag)
+	api.setCacheControl(w, isPublished(filter), false)
 	_, err = w.Write(b)
 	if err != nil {
 		log.Error(ctx, "failed to write bytes for http response", err, logData)
-		setErrorCode(w, err)
+		setErrorCode(w, r, err)
 		return
 	}
 
@@ -242,7 +314,7 @@ func (api *FilterAPI) removeFilterBlueprintDimensionHandler(w http.ResponseWrite
 	eTag, err := getIfMatchForce(r)
 	if err != nil {
 		log.Error(ctx, "missing header", err, log.Data{"error": err.Error()})
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, err)
 		return
 	}
 
@@ -250,10 +322,10 @@ func (api *FilterAPI) removeFilterBlueprintDimensionHandler(w http.ResponseWrite
 	if err != nil {
 		log.Error(r.Context(), "failed to remove dimension from filter blueprint", err, logData)
 		if err == filters.ErrFilterBlueprintNotFound {
-			setErrorCode(w, err, statusBadRequest)
+			setErrorCode(w, r, err, statusBadRequest)
 			return
 		}
-		setErrorCode(w, err)
+		setErrorCode(w, r, err)
 		return
 	}
 
@@ -301,41 +373,45 @@ func (api *FilterAPI) addFilterBlueprintDimensionHandler(w http.ResponseWriter,
 	eTag, err := getIfMatchForce(r)
 	if err != nil {
 		log.Error(ctx, "missing header", err, log.Data{"error": err.Error()})
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, err)
 		return
 	}
 
-	options, err := models.CreateDimensionOptions(r.Body)
+	dimensionOptions, err := models.CreateDimensionOptions(r.Body)
 	if err != nil {
 		log.Error(ctx, "unable to unmarshal request body", err, logData)
-		http.Error(w, BadRequest, http.StatusBadRequest)
+		if err == models.ErrorReadingBody || err == models.ErrorParsingBody {
+			writeError(w, r, http.StatusBadRequest, errBadRequestBody)
+			return
+		}
+		writeError(w, r, http.StatusBadRequest, err)
 		return
 	}
 
-	options = RemoveDuplicateAndEmptyOptions(options)
+	dimensionOptions.Options = RemoveDuplicateAndEmptyOptions(dimensionOptions.Options)
 
-	newETag, err := api.addFilterBlueprintDimension(ctx, filterBlueprintID, dimensionName, options, eTag)
+	newETag, err := api.addFilterBlueprintDimension(ctx, filterBlueprintID, dimensionName, dimensionOptions, eTag)
 	if err != nil {
 		log.Error(ctx, "error adding filter blueprint dimension", err, logData)
 		if err == filters.ErrVersionNotFound || err == filters.ErrDimensionsNotFound {
-			setErrorCode(w, err, statusUnprocessableEntity)
+			setErrorCode(w, r, err, statusUnprocessableEntity)
 			return
 		}
-		setErrorCode(w, err)
+		setErrorCode(w, r, err)
 		return
 	}
 
 	dimension, err := api.dataStore.GetFilterDimension(ctx, filterBlueprintID, dimensionName, newETag)
 	if err != nil {
 		log.Error(ctx, "error getting filter dimension", err, logData)
-		setErrorCode(w, err)
+		setErrorCode(w, r, err)
 		return
 	}
 	publicDimension := CreatePublicDimension(*dimension, api.host.String(), filterBlueprintID)
 	b, err := json.Marshal(publicDimension)
 	if err != nil {
 		log.Error(ctx, "failed to marshal filter blueprint dimensions into bytes", err, logData)
-		http.Error(w, InternalError, http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, errInternal)
 		return
 	}
 
@@ -345,7 +421,7 @@ func (api *FilterAPI) addFilterBlueprintDimensionHandler(w http.ResponseWriter,
 	_, err = w.Write(b)
 	if err != nil {
 		log.Error(ctx, "failed to write bytes for http response", err, logData)
-		setErrorCode(w, err)
+		setErrorCode(w, r, err)
 		return
 	}
 
@@ -367,25 +443,42 @@ func (api *FilterAPI) putFilterBlueprintDimensionHandler(w http.ResponseWriter,
 
 	err := errors.New("filter not of type flexible")
 	log.Error(ctx, "invalid filter type", err, logData)
-	http.Error(w, BadRequest, http.StatusBadRequest)
+	writeError(w, r, http.StatusBadRequest, errBadRequestBody)
 }
 
-func (api *FilterAPI) addFilterBlueprintDimension(ctx context.Context, filterBlueprintID, dimensionName string, options []string, eTag string) (newETag string, err error) {
+func (api *FilterAPI) addFilterBlueprintDimension(ctx context.Context, filterBlueprintID, dimensionName string, dimensionOptions *models.DimensionOptions, eTag string) (newETag string, err error) {
 	filterBlueprint, err := api.getFilterBlueprint(ctx, filterBlueprintID, eTag)
 	if err != nil {
 		return "", err
 	}
 
+	// include is the default mode, so it is not stored
+	mode := dimensionOptions.Mode
+	if mode == models.DimensionModeInclude {
+		mode = ""
+	}
+
+	// expand any option selectors and patterns into the option codes they select, which are then validated like any other option.
+	// In exclude mode, the selected options are the ones left out.
+	options, err := api.expandOptions(ctx, filterBlueprint, dimensionName, dimensionOptions.Options, dimensionOptions.Selectors, nil)
+	if err != nil {
+		return "", err
+	}
+
 	timestamp := filterBlueprint.UniqueTimestamp
 
 	if err = api.checkNewFilterDimension(ctx, dimensionName, options, filterBlueprint.Dataset); err != nil {
 		if err == filters.ErrVersionNotFound || err == filters.ErrDimensionsNotFound {
 			return "", err
 		}
-		return "", filters.NewBadRequestErr(err.Error())
+		return "", newValidationBadRequestErr(err)
+	}
+
+	if err = api.checkOptionRanges(ctx, filterBlueprint.Dataset, dimensionName, dimensionOptions.Ranges); err != nil {
+		return "", err
 	}
 
-	return api.dataStore.AddFilterDimension(ctx, filterBlueprintID, dimensionName, options, filterBlueprint.Dimensions, timestamp, eTag, filterBlueprint)
+	return api.dataStore.AddFilterDimension(ctx, filterBlueprintID, dimensionName, mode, options, dimensionOptions.Ranges, filterBlueprint.Dimensions, timestamp, eTag, filterBlueprint)
 }
 
 // checkNewFilterDimension validates that the dimension with the provided name is valid, by calling GetDimensions in Dataset API.
@@ -462,11 +555,20 @@ func (api *FilterAPI) checkNewFilterDimensionOptions(ctx context.Context, dimens
 
 	log.Info(ctx, "dimension options successfully retrieved from dataset API", logData)
 
-	// if there is any dimension that is not found, error
+	// if there is any option that is not found, error, suggesting the dimension options with a similar code
 	if len(optionsNotFound) > 0 {
 		incorrectDimensionOptions := utils.CreateArray(optionsNotFound)
+		sort.Strings(incorrectDimensionOptions)
 		logData["incorrect_dimension_options"] = incorrectDimensionOptions
-		err := fmt.Errorf("incorrect dimension options chosen: %v", incorrectDimensionOptions)
+
+		dimensionOptions, err := api.getOrderedDimensionOptions(ctx, dataset, dimension.Name)
+		if err != nil {
+			log.Error(ctx, "failed to retrieve the dimension options to suggest alternatives", err, logData)
+		}
+
+		err = models.ValidationErrors{Errors: []models.ValidationError{
+			models.NewOptionsNotFoundError(dimension.Name, incorrectDimensionOptions, dimensionOptions),
+		}}
 		log.Error(ctx, "incorrect dimension options chosen", err, logData)
 		return err
 	}
@@ -492,7 +594,9 @@ func CreatePublicDimension(dimension models.Dimension, host, filterID string) *m
 	dimensionURL := fmt.Sprintf("%s/dimensions/%s", filterURL, dimension.Name)
 
 	publicDim := &models.PublicDimension{
-		Name: dimension.Name,
+		Name:   dimension.Name,
+		Mode:   dimension.Mode,
+		Ranges: dimension.Ranges,
 		Links: &models.PublicDimensionLinkMap{
 			Self:    &models.LinkObject{HRef: dimensionURL, ID: dimension.Name},
 			Filter:  &models.LinkObject{HRef: filterURL, ID: filterID},
@@ -517,22 +621,3 @@ func RemoveDuplicateAndEmptyOptions(elements []string) []string {
 
 	return result
 }
-
-func getStringArrayFromInterface(elements interface{}) ([]string, error) {
-	result := []string{}
-
-	v2, ok := elements.([]interface{})
-	if !ok {
-		return result, errors.New("Missing list of items")
-	}
-
-	for _, v := range v2 {
-		v3, ok := v.(string)
-		if !ok {
-			return result, fmt.Errorf("non string item in list, got: %v", v)
-		}
-		result = append(result, v3)
-	}
-
-	return result, nil
-}
@@ -0,0 +1,213 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	datasetAPI "github.com/ONSdigital/dp-api-clients-go/v2/dataset"
+	"github.com/ONSdigital/dp-filter-api/filters"
+	"github.com/ONSdigital/dp-filter-api/models"
+	dphttp "github.com/ONSdigital/dp-net/http"
+	"github.com/ONSdigital/log.go/v2/log"
+	"github.com/gorilla/mux"
+)
+
+func (api *FilterAPI) replaceFilterBlueprintDimensionsHandler(w http.ResponseWriter, r *http.Request) {
+	defer dphttp.DrainBody(r)
+
+	vars := mux.Vars(r)
+	filterBlueprintID := vars["filter_blueprint_id"]
+	logData := log.Data{"filter_blueprint_id": filterBlueprintID}
+	ctx := r.Context()
+	log.Info(ctx, "replacing filter blueprint dimensions", logData)
+
+	// eTag value must be present in If-Match header
+	eTag, err := getIfMatchForce(r)
+	if err != nil {
+		log.Error(ctx, "missing header", err, log.Data{"error": err.Error()})
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	replaceDimensions, err := models.CreateReplaceDimensions(r.Body)
+	if err != nil {
+		log.Error(ctx, "unable to unmarshal request body", err, logData)
+		if err == models.ErrorReadingBody || err == models.ErrorParsingBody {
+			writeError(w, r, http.StatusBadRequest, errBadRequestBody)
+			return
+		}
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if err = replaceDimensions.Validate(); err != nil {
+		log.Error(ctx, "dimensions failed validation", err, logData)
+		setErrorCode(w, r, err)
+		return
+	}
+
+	if totalValues := replaceDimensions.TotalValues(); totalValues > api.maxRequestOptions {
+		logData["max_options"] = api.maxRequestOptions
+		err = fmt.Errorf("a maximum of %d overall option values can be provided for the dimensions of a filter blueprint, which has been exceeded", api.maxRequestOptions)
+		log.Error(ctx, "number of options is greater than the maximum allowed", err, logData)
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	for i := range replaceDimensions.Items {
+		replaceDimensions.Items[i].Options = RemoveDuplicateAndEmptyOptions(replaceDimensions.Items[i].Options)
+	}
+
+	filterBlueprint, err := api.replaceFilterBlueprintDimensions(ctx, filterBlueprintID, replaceDimensions.Items, eTag)
+	if err != nil {
+		log.Error(ctx, "error replacing filter blueprint dimensions, no change has been applied", err, logData)
+		if err == filters.ErrVersionNotFound || err == filters.ErrDimensionsNotFound {
+			setErrorCode(w, r, err, statusUnprocessableEntity)
+			return
+		}
+		setErrorCode(w, r, err)
+		return
+	}
+
+	items := CreatePublicDimensions(filterBlueprint.Dimensions, api.host.String(), filterBlueprint.FilterID)
+	publicDimensions := models.PublicDimensions{
+		Items:      items,
+		Count:      len(items),
+		TotalCount: len(items),
+		Limit:      len(items),
+	}
+
+	b, err := json.Marshal(publicDimensions)
+	if err != nil {
+		log.Error(ctx, "failed to marshal filter blueprint dimensions into bytes", err, logData)
+		writeError(w, r, http.StatusInternalServerError, errInternal)
+		return
+	}
+
+	setJSONContentType(w)
+	setETag(w, filterBlueprint.ETag)
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(b)
+	if err != nil {
+		log.Error(ctx, "failed to write bytes for http response", err, logData)
+		setErrorCode(w, r, err)
+		return
+	}
+
+	log.Info(ctx, "replaced dimensions of filter blueprint", logData)
+}
+
+// replaceFilterBlueprintDimensions replaces all the dimensions of a filter blueprint within a transaction.
+// The filter blueprint is only stored if every dimension is valid, otherwise the validation errors of all the dimensions are returned.
+func (api *FilterAPI) replaceFilterBlueprintDimensions(ctx context.Context, filterBlueprintID string, items []models.ReplaceDimension, eTag string) (*models.Filter, error) {
+	newFilter, err := api.dataStore.RunTransaction(ctx, true, func(txCtx context.Context) (interface{}, error) {
+		currentFilter, err := api.getFilterBlueprint(txCtx, filterBlueprintID, eTag)
+		if err != nil {
+			return nil, err
+		}
+
+		dimensions, err := api.checkReplaceDimensions(txCtx, currentFilter, items)
+		if err != nil {
+			return nil, err
+		}
+
+		filterBlueprint := copyFilterBlueprint(currentFilter)
+		filterBlueprint.Dimensions = dimensions
+		filterBlueprint.ETag, err = api.dataStore.ReplaceFilter(txCtx, filterBlueprint, currentFilter.UniqueTimestamp, eTag, currentFilter)
+		if err != nil {
+			return nil, err
+		}
+		return filterBlueprint, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	f, ok := newFilter.(*models.Filter)
+	if !ok {
+		return nil, errors.New("returned filter blueprint is not a filter")
+	}
+	return f, nil
+}
+
+// checkReplaceDimensions validates every dimension and its options against the dataset version of the filter blueprint.
+// The dimensions are validated in parallel, with at most BatchMaxWorkers of them at a time.
+// Dimensions that are not valid for the dataset version are reported together as ValidationErrors,
+// while any other error fails the whole request.
+func (api *FilterAPI) checkReplaceDimensions(ctx context.Context, filterBlueprint *models.Filter, items []models.ReplaceDimension) ([]models.Dimension, error) {
+	datasetDimensions, err := api.getDimensions(ctx, filterBlueprint.Dataset)
+	if err != nil {
+		return nil, err
+	}
+
+	workers := api.BatchMaxWorkers
+	if workers < 1 {
+		workers = 1
+	}
+
+	dimensions := make([]models.Dimension, len(items))
+	errs := make([]error, len(items))
+	limit := api.newOptionSelectorLimit()
+	semaphore := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+
+	for i := range items {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+			dimensions[i], errs[i] = api.newReplaceDimension(ctx, filterBlueprint, datasetDimensions, &items[i], limit)
+		}()
+	}
+	wg.Wait()
+
+	var validationErrors models.ValidationErrors
+	for i, err := range errs {
+		if err != nil && !addValidationErrors(&validationErrors, items[i].Name, err) {
+			return nil, err
+		}
+	}
+
+	if len(validationErrors.Errors) > 0 {
+		return nil, validationErrors
+	}
+	return dimensions, nil
+}
+
+// newReplaceDimension creates a filter dimension from the provided selection, validating it against the dataset version.
+// ValidationErrors or a BadRequestErr are returned if the dimension or any of its options, option selectors, option patterns or option ranges are not valid,
+// or if its option selectors exceed the provided limit, which is shared by all the dimensions.
+func (api *FilterAPI) newReplaceDimension(ctx context.Context, filterBlueprint *models.Filter, datasetDimensions *datasetAPI.VersionDimensions, item *models.ReplaceDimension, limit *optionSelectorLimit) (models.Dimension, error) {
+	logData := log.Data{"filter_blueprint_id": filterBlueprint.FilterID, "dimension_name": item.Name}
+
+	if err := models.ValidateFilterDimensions([]models.Dimension{{Name: item.Name}}, datasetDimensions); err != nil {
+		log.Error(ctx, "filter dimensions failed validation", err, logData)
+		return models.Dimension{}, err
+	}
+
+	dimension, err := api.newPatchDimension(ctx, filterBlueprint, item.Name, &item.DimensionOptions, limit)
+	if err != nil {
+		return models.Dimension{}, err
+	}
+
+	if err = api.checkNewFilterDimensionOptions(ctx, dimension, filterBlueprint.Dataset, logData); err != nil {
+		if err == filters.ErrDimensionOptionsNotFound {
+			return models.Dimension{}, filters.NewBadRequestErr(err.Error())
+		}
+		return models.Dimension{}, err
+	}
+
+	if err = api.checkOptionRanges(ctx, filterBlueprint.Dataset, item.Name, dimension.Ranges); err != nil {
+		if err == filters.ErrDimensionOptionsNotFound {
+			return models.Dimension{}, filters.NewBadRequestErr(err.Error())
+		}
+		return models.Dimension{}, err
+	}
+
+	return dimension, nil
+}
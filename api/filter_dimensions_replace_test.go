@@ -0,0 +1,156 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ONSdigital/dp-api-clients-go/v2/dataset"
+	"github.com/ONSdigital/dp-filter-api/api"
+	apimock "github.com/ONSdigital/dp-filter-api/api/mock"
+	"github.com/ONSdigital/dp-filter-api/mock"
+	"github.com/ONSdigital/dp-filter-api/models"
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestReplaceFilterBlueprintDimensions(t *testing.T) {
+	t.Parallel()
+
+	filterFlexAPIMock := &apimock.FilterFlexAPIMock{}
+
+	replaceDimensions := func(filterAPI *api.FilterAPI, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r, err := http.NewRequest("PUT", "http://localhost:22100/filters/12345678/dimensions", strings.NewReader(body))
+		So(err, ShouldBeNil)
+		r.Header.Set("If-Match", testETag)
+		filterAPI.Router.ServeHTTP(w, r)
+		return w
+	}
+
+	Convey("Given a filter blueprint for a dataset with age and sex dimensions", t, func() {
+		ds := ageFilterStore()
+		datasetAPIMock := mock.NewDatasetAPI().Mock
+		datasetAPIMock.GetVersionDimensionsFunc = func(ctx context.Context, userAuthToken string, serviceAuthToken string, collectionID string, id string, edition string, version string) (dataset.VersionDimensions, error) {
+			return dataset.VersionDimensions{Items: []dataset.VersionDimension{{Name: "age"}, {Name: "sex"}}}, nil
+		}
+		testCfg := cfg()
+		testCfg.BatchMaxWorkers = 2
+		filterAPI := api.Setup(testCfg, mux.NewRouter(), ds, &mock.FilterJob{}, datasetAPIMock, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+
+		Convey("When all the dimensions are replaced with valid dimensions", func() {
+			w := replaceDimensions(filterAPI, `{"items": [
+				{"name": "age", "options": ["27", "33", "27"]},
+				{"name": "sex", "mode": "exclude", "options": ["33"]}
+			]}`)
+
+			Convey("Then the dimensions of the filter blueprint are replaced within a single transaction", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Header().Get("ETag"), ShouldNotBeEmpty)
+				So(ds.RunTransactionCalls(), ShouldHaveLength, 1)
+				So(ds.ReplaceFilterCalls(), ShouldHaveLength, 1)
+				So(datasetAPIMock.GetVersionDimensionsCalls(), ShouldHaveLength, 1)
+				So(datasetAPIMock.GetOptionsBatchProcessCalls(), ShouldHaveLength, 2)
+
+				replaced := ds.ReplaceFilterCalls()[0].UpdatedFilter
+				So(replaced.Dimensions, ShouldHaveLength, 2)
+				So(replaced.Dimensions[0].Name, ShouldEqual, "age")
+				So(replaced.Dimensions[0].Options, ShouldResemble, []string{"27", "33"})
+				So(replaced.Dimensions[0].URL, ShouldEqual, hostURL.String()+"/filters/12345678/dimensions/age")
+				So(replaced.Dimensions[1].Name, ShouldEqual, "sex")
+				So(replaced.Dimensions[1].Mode, ShouldEqual, models.DimensionModeExclude)
+
+				Convey("And the new dimensions are returned", func() {
+					var publicDimensions models.PublicDimensions
+					So(json.Unmarshal(w.Body.Bytes(), &publicDimensions), ShouldBeNil)
+					So(publicDimensions.Count, ShouldEqual, 2)
+					So(publicDimensions.Items[0].Name, ShouldEqual, "age")
+					So(publicDimensions.Items[1].Name, ShouldEqual, "sex")
+				})
+			})
+		})
+
+		Convey("When several dimensions fail validation against the dataset version", func() {
+			w := replaceDimensions(filterAPI, `{"items": [
+				{"name": "age", "options": ["27", "99"]},
+				{"name": "sex", "options": ["33"]},
+				{"name": "agee", "options": ["27"]}
+			]}`)
+
+			Convey("Then the response is 400 bad request with the error of each invalid dimension, and no change is applied", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(ds.ReplaceFilterCalls(), ShouldHaveLength, 0)
+
+				var validationErrors models.ValidationErrors
+				So(json.Unmarshal(w.Body.Bytes(), &validationErrors), ShouldBeNil)
+				So(validationErrors.Errors, ShouldResemble, []models.ValidationError{
+					models.NewOptionsNotFoundError("age", []string{"99"}, nil),
+					models.NewDimensionNotFoundError("agee", []string{"age", "sex"}),
+				})
+				So(validationErrors.Errors[1].Suggestions, ShouldResemble, []string{"age"})
+			})
+		})
+
+		Convey("When the request has a duplicate dimension and a dimension without a name", func() {
+			w := replaceDimensions(filterAPI, `{"items": [{"name": "age"}, {"name": "age"}, {"options": ["27"]}]}`)
+
+			Convey("Then the response is 400 bad request with the error of each invalid dimension, without calling the dataset API", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				var validationErrors models.ValidationErrors
+				So(json.Unmarshal(w.Body.Bytes(), &validationErrors), ShouldBeNil)
+				So(validationErrors.Errors, ShouldResemble, []models.ValidationError{
+					{Dimension: "age", Reason: models.ValidationReasonInvalidSelection, Message: "Bad request - duplicate dimension found: age"},
+					models.NewInvalidSelectionError("", models.ErrorMissingDimensionName),
+				})
+				So(ds.RunTransactionCalls(), ShouldHaveLength, 0)
+				So(datasetAPIMock.GetVersionDimensionsCalls(), ShouldHaveLength, 0)
+			})
+		})
+
+		Convey("When the request is made without an If-Match header", func() {
+			w := httptest.NewRecorder()
+			r, err := http.NewRequest("PUT", "http://localhost:22100/filters/12345678/dimensions", strings.NewReader(`{"items": []}`))
+			So(err, ShouldBeNil)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the response is 400 bad request", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(ds.RunTransactionCalls(), ShouldHaveLength, 0)
+			})
+		})
+	})
+
+	Convey("Given a filter blueprint whose dataset API dimensions cannot be retrieved", t, func() {
+		ds := mock.NewDataStore().Mock
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), ds, &mock.FilterJob{}, mock.NewDatasetAPI().InternalServiceError().Mock, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+
+		Convey("When the dimensions are replaced", func() {
+			w := replaceDimensions(filterAPI, `{"items": [{"name": "age", "options": ["27"]}]}`)
+
+			Convey("Then the response is 500 internal server error and no change is applied", func() {
+				So(w.Code, ShouldEqual, http.StatusInternalServerError)
+				So(ds.ReplaceFilterCalls(), ShouldHaveLength, 0)
+			})
+		})
+	})
+
+	Convey("Given a maximum of 2 option values per request", t, func() {
+		ds := mock.NewDataStore().Mock
+		limitedCfg := cfg()
+		limitedCfg.MaxRequestOptions = 2
+		filterAPI := api.Setup(limitedCfg, mux.NewRouter(), ds, &mock.FilterJob{}, mock.NewDatasetAPI().Mock, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+
+		Convey("When the dimensions provide more option values than the maximum", func() {
+			w := replaceDimensions(filterAPI, `{"items": [{"name": "age", "options": ["27", "33"]}, {"name": "sex", "options": ["male"]}]}`)
+
+			Convey("Then the response is 400 bad request", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(problemDetail(w.Body.String()), ShouldEqual, "a maximum of 2 overall option values can be provided for the dimensions of a filter blueprint, which has been exceeded")
+				So(ds.RunTransactionCalls(), ShouldHaveLength, 0)
+			})
+		})
+	})
+}
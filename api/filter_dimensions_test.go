@@ -9,8 +9,6 @@ import (
 	"strings"
 	"testing"
 
-	"go.mongodb.org/mongo-driver/bson/primitive"
-
 	"github.com/ONSdigital/dp-filter-api/api"
 	apimock "github.com/ONSdigital/dp-filter-api/api/mock"
 	"github.com/ONSdigital/dp-filter-api/mock"
@@ -185,6 +183,9 @@ func TestSuccessfulGetFilterBlueprintDimensions(t *testing.T) {
 				Offset:     1,
 				Limit:      3,
 				TotalCount: 3,
+				Links: &models.PageLinks{
+					Prev: pageLink("/filters/12345678/dimensions", `{"key":"age","etag":"testETag0","before":true}`, "limit=3"),
+				},
 			}
 
 			w := httptest.NewRecorder()
@@ -235,7 +236,7 @@ func TestFailedToGetFilterBlueprintDimensions(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldResemble, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, internalErrResponse)
+		So(problemDetail(response), ShouldEqual, internalErrResponse)
 	})
 
 	Convey("When negative values are provided for limit and offset query parameters, a bad request error is returned", t, func() {
@@ -249,7 +250,7 @@ func TestFailedToGetFilterBlueprintDimensions(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldResemble, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, invalidQueryParameterResponse)
+		So(problemDetail(response), ShouldEqual, invalidQueryParameterResponse)
 	})
 
 	Convey("When a limit higher than the maximum allowed is provided, a bad request error is returned", t, func() {
@@ -263,7 +264,7 @@ func TestFailedToGetFilterBlueprintDimensions(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldResemble, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, invalidQueryParameterResponse)
+		So(problemDetail(response), ShouldEqual, invalidQueryParameterResponse)
 	})
 
 	Convey("When filter blueprint does not exist, a not found is returned", t, func() {
@@ -277,7 +278,7 @@ func TestFailedToGetFilterBlueprintDimensions(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldResemble, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, filterNotFoundResponse)
+		So(problemDetail(response), ShouldEqual, filterNotFoundResponse)
 	})
 }
 
@@ -380,7 +381,7 @@ func TestFailedToAddFilterBlueprintDimension(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldResemble, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, internalErrResponse)
+		So(problemDetail(response), ShouldEqual, internalErrResponse)
 
 		Convey("Then the request body has been drained", func() {
 			bytesRead, err := r.Body.Read(make([]byte, 1))
@@ -402,7 +403,7 @@ func TestFailedToAddFilterBlueprintDimension(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldResemble, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, badRequestResponse)
+		So(problemDetail(response), ShouldEqual, badRequestResponse)
 
 		Convey("Then the request body has been drained", func() {
 			bytesRead, err := r.Body.Read(make([]byte, 1))
@@ -424,7 +425,7 @@ func TestFailedToAddFilterBlueprintDimension(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldResemble, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, filterNotFoundResponse)
+		So(problemDetail(response), ShouldEqual, filterNotFoundResponse)
 
 		Convey("Then the request body has been drained", func() {
 			bytesRead, err := r.Body.Read(make([]byte, 1))
@@ -446,7 +447,7 @@ func TestFailedToAddFilterBlueprintDimension(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldResemble, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, filterNotFoundResponse)
+		So(problemDetail(response), ShouldEqual, filterNotFoundResponse)
 
 		Convey("Then the request body has been drained", func() {
 			bytesRead, err := r.Body.Read(make([]byte, 1))
@@ -468,7 +469,7 @@ func TestFailedToAddFilterBlueprintDimension(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldResemble, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, "incorrect dimensions chosen: [wealth]\n")
+		So(problemErrors(response), ShouldResemble, validationErrorsResponse(models.NewDimensionNotFoundError("wealth", []string{"age"})))
 
 		Convey("Then the request body has been drained", func() {
 			bytesRead, err := r.Body.Read(make([]byte, 1))
@@ -490,7 +491,7 @@ func TestFailedToAddFilterBlueprintDimension(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldResemble, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, "incorrect dimension options chosen: [22]\n")
+		So(problemErrors(response), ShouldResemble, validationErrorsResponse(models.NewOptionsNotFoundError("age", []string{"22"}, []string{"27", "33"})))
 
 		Convey("Then the request body has been drained", func() {
 			bytesRead, err := r.Body.Read(make([]byte, 1))
@@ -512,7 +513,7 @@ func TestFailedToAddFilterBlueprintDimension(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldResemble, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, filters.ErrFilterBlueprintConflict.Error()+"\n")
+		So(problemDetail(response), ShouldEqual, filters.ErrFilterBlueprintConflict.Error())
 
 		Convey("Then the request body has been drained", func() {
 			bytesRead, err := r.Body.Read(make([]byte, 1))
@@ -538,7 +539,7 @@ func TestFailedToAddFilterBlueprintDimension(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldResemble, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, filters.ErrFilterBlueprintConflict.Error()+"\n")
+		So(problemDetail(response), ShouldEqual, filters.ErrFilterBlueprintConflict.Error())
 
 		Convey("Then the request body has been drained", func() {
 			bytesRead, err := r.Body.Read(make([]byte, 1))
@@ -559,7 +560,7 @@ func TestFailedToAddFilterBlueprintDimension(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldResemble, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, "required If-Match header not provided\n")
+		So(problemDetail(response), ShouldEqual, "required If-Match header not provided")
 
 		Convey("Then the request body has been drained", func() {
 			bytesRead, err := r.Body.Read(make([]byte, 1))
@@ -633,7 +634,7 @@ func TestFailedToGetFilterBlueprintDimension(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldResemble, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, internalErrResponse)
+		So(problemDetail(response), ShouldEqual, internalErrResponse)
 	})
 
 	Convey("When filter blueprint does not exist, a bad request is returned", t, func() {
@@ -647,7 +648,7 @@ func TestFailedToGetFilterBlueprintDimension(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldResemble, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, filterNotFoundResponse)
+		So(problemDetail(response), ShouldEqual, filterNotFoundResponse)
 	})
 
 	Convey("When filter blueprint is unpublished and request is unauthenticated, a bad request is returned", t, func() {
@@ -661,7 +662,7 @@ func TestFailedToGetFilterBlueprintDimension(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldResemble, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, filterNotFoundResponse)
+		So(problemDetail(response), ShouldEqual, filterNotFoundResponse)
 	})
 
 	Convey("When dimension does not exist against filter blueprint, a not found is returned", t, func() {
@@ -675,25 +676,21 @@ func TestFailedToGetFilterBlueprintDimension(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldResemble, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, dimensionNotFoundResponse)
+		So(problemDetail(response), ShouldEqual, dimensionNotFoundResponse)
 	})
 
-	Convey("When an unpublished filter with a version that is published is updated by an external source after it is obtained from the datastore and before its state is updated, a conflict is returned", t, func() {
+	Convey("When an unpublished filter with a version that is published is requested, only the published flag of the filter blueprints for its instance is updated", t, func() {
 		r, err := http.NewRequest("GET", "http://localhost:22100/filters/12345678/dimensions/1_age", http.NoBody)
 		So(err, ShouldBeNil)
 
 		w := httptest.NewRecorder()
 		ds := mock.NewDataStore().Unpublished().Mock
-		ds.UpdateFilterFunc = func(ctx context.Context, updatedFilter *models.Filter, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error) {
-			return "", filters.ErrFilterBlueprintConflict
-		}
 		filterAPI := api.Setup(cfg(), mux.NewRouter(), ds, &mock.FilterJob{}, &mock.DatasetAPI{}, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
 		filterAPI.Router.ServeHTTP(w, r)
-		So(w.Code, ShouldEqual, http.StatusConflict)
-		So(w.Result().Header.Get("ETag"), ShouldResemble, "")
-
-		response := w.Body.String()
-		So(response, ShouldResemble, filerBlueprintConflictResponse)
+		So(w.Code, ShouldEqual, http.StatusOK)
+		So(ds.PublishFilterBlueprintsCalls(), ShouldHaveLength, 1)
+		So(ds.PublishFilterBlueprintsCalls()[0].InstanceID, ShouldEqual, "12345678")
+		So(ds.UpdateFilterCalls(), ShouldHaveLength, 0)
 	})
 }
 
@@ -740,7 +737,7 @@ func TestFailedToRemoveFilterBlueprintDimension(t *testing.T) {
 		So(w.Code, ShouldEqual, http.StatusInternalServerError)
 
 		response := w.Body.String()
-		So(response, ShouldResemble, internalErrResponse)
+		So(problemDetail(response), ShouldEqual, internalErrResponse)
 	})
 
 	Convey("When filter blueprint does not exist, a bad request is returned", t, func() {
@@ -754,7 +751,7 @@ func TestFailedToRemoveFilterBlueprintDimension(t *testing.T) {
 		So(w.Code, ShouldEqual, http.StatusBadRequest)
 
 		response := w.Body.String()
-		So(response, ShouldResemble, filterNotFoundResponse)
+		So(problemDetail(response), ShouldEqual, filterNotFoundResponse)
 	})
 
 	Convey("When filter blueprint is unpublished, and request is not authenticated, a bad request is returned", t, func() {
@@ -768,7 +765,7 @@ func TestFailedToRemoveFilterBlueprintDimension(t *testing.T) {
 		So(w.Code, ShouldEqual, http.StatusBadRequest)
 
 		response := w.Body.String()
-		So(response, ShouldResemble, filterNotFoundResponse)
+		So(problemDetail(response), ShouldEqual, filterNotFoundResponse)
 	})
 
 	Convey("When dimension does not exist against filter blueprint, the response is 404 Status Not Found", t, func() {
@@ -0,0 +1,212 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ONSdigital/dp-filter-api/filters"
+	"github.com/ONSdigital/dp-filter-api/models"
+	"github.com/ONSdigital/dp-net/v2/links"
+)
+
+const (
+	embedDimensions = "dimensions"
+	embedOptions    = "options"
+)
+
+// filterBlueprintFields are the top-level fields of a filter blueprint that can be selected with the fields query parameter
+var filterBlueprintFields = map[string]bool{
+	"id":                      true,
+	"dataset":                 true,
+	"instance_id":             true,
+	"dimensions":              true,
+	"downloads":               true,
+	"events":                  true,
+	"filter_id":               true,
+	"state":                   true,
+	"published":               true,
+	"links":                   true,
+	"type":                    true,
+	"language":                true,
+	"newer_version_available": true,
+}
+
+// filterBlueprintView holds the top-level fields of a filter blueprint to respond with, if not all of them,
+// and the sub-resources to embed in the response, with the maximum number of items of each embedded list
+type filterBlueprintView struct {
+	fields          []string
+	embedDimensions bool
+	embedOptions    bool
+	limit           int
+}
+
+// getFilterBlueprintView returns the view of a filter blueprint requested with the fields, embed and limit query parameters.
+// Embedding options implies embedding the dimensions they belong to.
+func (api *FilterAPI) getFilterBlueprintView(r *http.Request) (filterBlueprintView, error) {
+	query := r.URL.Query()
+	view := filterBlueprintView{limit: api.defaultLimit}
+
+	for _, field := range splitQueryList(query.Get("fields")) {
+		if !filterBlueprintFields[field] {
+			return filterBlueprintView{}, filters.NewBadRequestErr(fmt.Sprintf("unknown field in fields query parameter: %s", field))
+		}
+		view.fields = append(view.fields, field)
+	}
+
+	for _, value := range splitQueryList(query.Get("embed")) {
+		switch value {
+		case embedDimensions:
+			view.embedDimensions = true
+		case embedOptions:
+			view.embedDimensions = true
+			view.embedOptions = true
+		default:
+			return filterBlueprintView{}, filters.NewBadRequestErr(fmt.Sprintf("unknown sub-resource in embed query parameter: %s", value))
+		}
+	}
+
+	if limitParameter := query.Get("limit"); limitParameter != "" {
+		limit, err := validatePositiveInt(limitParameter)
+		if err != nil {
+			return filterBlueprintView{}, err
+		}
+		if limit > api.maxLimit {
+			return filterBlueprintView{}, filters.ErrInvalidQueryParameter
+		}
+		view.limit = limit
+	}
+	return view, nil
+}
+
+// splitQueryList returns the values of a comma separated query parameter, ignoring empty ones
+func splitQueryList(parameter string) []string {
+	var values []string
+	for _, value := range strings.Split(parameter, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// getEmbeddedDimensions returns the first page of the dimensions of a filter blueprint, each with the first page of its options if requested.
+// The number of options of each dimension is also capped by the configured maximum, and the links of each list lead to its following pages.
+func (api *FilterAPI) getEmbeddedDimensions(ctx context.Context, filter *models.Filter, view filterBlueprintView) (*models.PublicDimensions, error) {
+	dimensionNames := make([]string, len(filter.Dimensions))
+	for i, dimension := range filter.Dimensions {
+		dimensionNames[i] = dimension.Name
+	}
+	sort.Strings(dimensionNames)
+
+	dimensionsPage, err := paginate(dimensionNames, 0, view.limit, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var filterDimensions []models.Dimension
+	for _, dimensionName := range dimensionsPage.keys {
+		filterDimensions = append(filterDimensions, *findDimension(filter, dimensionName))
+	}
+
+	items := CreatePublicDimensions(filterDimensions, api.host.String(), filter.FilterID)
+	dimensions := &models.PublicDimensions{
+		Items:      items,
+		Count:      len(items),
+		Offset:     dimensionsPage.offset,
+		Limit:      view.limit,
+		TotalCount: dimensionsPage.total,
+		Links:      dimensionsPage.links(fmt.Sprintf("%s/filters/%s/dimensions", api.host, filter.FilterID), url.Values{"limit": {strconv.Itoa(view.limit)}}, filter.ETag),
+	}
+
+	if !view.embedOptions {
+		return dimensions, nil
+	}
+
+	optionsLimit := min(view.limit, api.maxEmbeddedOptions)
+	for _, item := range items {
+		options, optionsPage, err := api.getFilterBlueprintDimensionOptions(ctx, filter, item.Name, optionsQuery{sortBy: sortOptionsByCode}, 0, optionsLimit, nil)
+		if err != nil {
+			return nil, err
+		}
+		options.Links = optionsPage.links(item.Links.Options.HRef, url.Values{"limit": {strconv.Itoa(optionsLimit)}}, filter.ETag)
+		item.Options = options
+	}
+	return dimensions, nil
+}
+
+// rewriteEmbeddedDimensionLinks rewrites the links of embedded dimensions and options, and the links to their following pages
+func rewriteEmbeddedDimensionLinks(builder *links.Builder, dimensions *models.PublicDimensions) error {
+	if dimensions == nil {
+		return nil
+	}
+
+	linkObjects := []*models.LinkObject{}
+	if dimensions.Links != nil {
+		linkObjects = append(linkObjects, dimensions.Links.Prev, dimensions.Links.Next)
+	}
+
+	for _, dimension := range dimensions.Items {
+		linkObjects = append(linkObjects, dimension.Links.Self, dimension.Links.Filter, dimension.Links.Options)
+		if dimension.Options == nil {
+			continue
+		}
+		if dimension.Options.Links != nil {
+			linkObjects = append(linkObjects, dimension.Options.Links.Prev, dimension.Options.Links.Next)
+		}
+		for _, option := range dimension.Options.Items {
+			linkObjects = append(linkObjects, option.Links.Self, option.Links.Filter, option.Links.Dimension)
+		}
+	}
+
+	// the options of a dimension share their filter link, which must only be rewritten once
+	rewritten := make(map[*models.LinkObject]bool)
+	for _, link := range linkObjects {
+		if link == nil || link.HRef == "" || rewritten[link] {
+			continue
+		}
+		newLink, err := builder.BuildLink(link.HRef)
+		if err != nil {
+			return err
+		}
+		link.HRef = newLink
+		rewritten[link] = true
+	}
+	return nil
+}
+
+// marshalFilterBlueprint marshals a filter blueprint with any embedded dimensions, keeping only the selected fields if any are selected.
+// Embedded dimensions are always kept, as they have been requested explicitly.
+func marshalFilterBlueprint(filter *models.Filter, dimensions *models.PublicDimensions, view filterBlueprintView) ([]byte, error) {
+	b, err := json.Marshal(filter)
+	if err != nil || (len(view.fields) == 0 && dimensions == nil) {
+		return b, err
+	}
+
+	var response map[string]json.RawMessage
+	if err := json.Unmarshal(b, &response); err != nil {
+		return nil, err
+	}
+
+	if len(view.fields) > 0 {
+		selected := make(map[string]json.RawMessage, len(view.fields)+1)
+		for _, field := range view.fields {
+			if value, ok := response[field]; ok {
+				selected[field] = value
+			}
+		}
+		response = selected
+	}
+
+	if dimensions != nil {
+		if response[embedDimensions], err = json.Marshal(dimensions); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(response)
+}
@@ -0,0 +1,193 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ONSdigital/dp-filter-api/api"
+	apimock "github.com/ONSdigital/dp-filter-api/api/mock"
+	"github.com/ONSdigital/dp-filter-api/mock"
+	"github.com/ONSdigital/dp-filter-api/models"
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGetFilterBlueprintWithFieldsAndEmbed(t *testing.T) {
+	t.Parallel()
+
+	filterFlexAPIMock := &apimock.FilterFlexAPIMock{}
+
+	Convey("Given a filter blueprint with the dimensions age, time and 1_age", t, func() {
+		config := cfg()
+		config.MaxEmbeddedOptions = 100
+		filterAPI := api.Setup(config, mux.NewRouter(), &mock.DataStore{}, &mock.FilterJob{}, &mock.DatasetAPI{}, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+
+		get := func(query string) (*httptest.ResponseRecorder, map[string]json.RawMessage) {
+			r, err := http.NewRequest("GET", "http://localhost:22100/filters/12345678"+query, http.NoBody)
+			So(err, ShouldBeNil)
+			w := httptest.NewRecorder()
+			filterAPI.Router.ServeHTTP(w, r)
+
+			var response map[string]json.RawMessage
+			if w.Code == http.StatusOK {
+				So(json.Unmarshal(w.Body.Bytes(), &response), ShouldBeNil)
+			}
+			return w, response
+		}
+
+		embedded := func(response map[string]json.RawMessage) models.PublicDimensions {
+			var dimensions models.PublicDimensions
+			So(json.Unmarshal(response["dimensions"], &dimensions), ShouldBeNil)
+			return dimensions
+		}
+
+		Convey("When the filter blueprint is requested without fields or embed", func() {
+			w, response := get("")
+
+			Convey("Then every field is returned, apart from its dimensions", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(response, ShouldContainKey, "dataset")
+				So(response, ShouldContainKey, "instance_id")
+				So(response, ShouldContainKey, "links")
+				So(response, ShouldNotContainKey, "dimensions")
+			})
+		})
+
+		Convey("When only some fields of the filter blueprint are requested", func() {
+			w, response := get("?fields=dataset,%20instance_id")
+
+			Convey("Then only those fields are returned, with the ETag of the filter blueprint", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Header().Get("ETag"), ShouldEqual, testETag)
+				So(response, ShouldHaveLength, 2)
+				So(string(response["instance_id"]), ShouldEqual, `"12345678"`)
+				So(response, ShouldContainKey, "dataset")
+			})
+		})
+
+		Convey("When the filter blueprint is requested with its dimensions embedded", func() {
+			w, response := get("?embed=dimensions")
+
+			Convey("Then its dimensions are returned sorted by name, without their options", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(response, ShouldContainKey, "instance_id")
+
+				dimensions := embedded(response)
+				So(dimensions.TotalCount, ShouldEqual, 3)
+				So(dimensions.Count, ShouldEqual, 3)
+				So(dimensions.Links, ShouldBeNil)
+				So(dimensions.Items, ShouldHaveLength, 3)
+				So(dimensions.Items[0].Name, ShouldEqual, "1_age")
+				So(dimensions.Items[1].Name, ShouldEqual, "age")
+				So(dimensions.Items[2].Name, ShouldEqual, "time")
+				for _, dimension := range dimensions.Items {
+					So(dimension.Options, ShouldBeNil)
+				}
+			})
+		})
+
+		Convey("When the filter blueprint is requested with selected fields and its dimensions embedded", func() {
+			w, response := get("?fields=dataset&embed=dimensions")
+
+			Convey("Then the selected fields and the embedded dimensions are returned", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(response, ShouldHaveLength, 2)
+				So(response, ShouldContainKey, "dataset")
+				So(embedded(response).Items, ShouldHaveLength, 3)
+			})
+		})
+
+		Convey("When the filter blueprint is requested with its dimensions and options embedded, limited to one item per list", func() {
+			w, response := get("?embed=dimensions,options&limit=1")
+
+			Convey("Then the first dimension is returned with its first option, with links to the following pages", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+
+				dimensions := embedded(response)
+				So(dimensions.TotalCount, ShouldEqual, 3)
+				So(dimensions.Limit, ShouldEqual, 1)
+				So(dimensions.Items, ShouldHaveLength, 1)
+				So(dimensions.Items[0].Name, ShouldEqual, "1_age")
+				So(dimensions.Links.Next, ShouldResemble, pageLink("/filters//dimensions", `{"key":"1_age","etag":"testETag0"}`, "limit=1"))
+
+				options := dimensions.Items[0].Options
+				So(options, ShouldNotBeNil)
+				So(options.TotalCount, ShouldEqual, 2)
+				So(options.Items, ShouldHaveLength, 1)
+				So(options.Items[0].Option, ShouldEqual, "2014")
+				So(options.Links.Next.HRef, ShouldStartWith, host+"/filters//dimensions/1_age/options?cursor=")
+			})
+		})
+
+		Convey("When the filter blueprint is requested with only options embedded", func() {
+			w, response := get("?embed=options")
+
+			Convey("Then its dimensions are embedded with all their options", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+
+				dimensions := embedded(response)
+				So(dimensions.Items, ShouldHaveLength, 3)
+				So(dimensions.Items[1].Options.Items, ShouldHaveLength, 1)
+				So(dimensions.Items[1].Options.Items[0].Option, ShouldEqual, "33")
+				So(dimensions.Items[2].Options.Items, ShouldHaveLength, 2)
+				So(dimensions.Items[2].Options.Links, ShouldBeNil)
+			})
+		})
+
+		Convey("When an unknown field is requested", func() {
+			w, _ := get("?fields=dataset,colour")
+
+			Convey("Then 400 bad request is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(problemDetail(w.Body.String()), ShouldEqual, "unknown field in fields query parameter: colour")
+			})
+		})
+
+		Convey("When an unknown sub-resource is requested to be embedded", func() {
+			w, _ := get("?embed=events")
+
+			Convey("Then 400 bad request is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(problemDetail(w.Body.String()), ShouldEqual, "unknown sub-resource in embed query parameter: events")
+			})
+		})
+
+		Convey("When the limit of embedded lists is greater than the maximum allowed", func() {
+			w, _ := get("?embed=dimensions&limit=1001")
+
+			Convey("Then 400 bad request is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+			})
+		})
+	})
+
+	Convey("Given the number of options embedded for each dimension is capped to one", t, func() {
+		config := cfg()
+		config.MaxEmbeddedOptions = 1
+		filterAPI := api.Setup(config, mux.NewRouter(), &mock.DataStore{}, &mock.FilterJob{}, &mock.DatasetAPI{}, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+
+		Convey("When the filter blueprint is requested with its dimensions and options embedded", func() {
+			r, err := http.NewRequest("GET", "http://localhost:22100/filters/12345678?embed=options", http.NoBody)
+			So(err, ShouldBeNil)
+			w := httptest.NewRecorder()
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then every dimension is embedded with its first option only", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+
+				var response struct {
+					Dimensions models.PublicDimensions `json:"dimensions"`
+				}
+				So(json.Unmarshal(w.Body.Bytes(), &response), ShouldBeNil)
+				So(response.Dimensions.Items, ShouldHaveLength, 3)
+				time := response.Dimensions.Items[2].Options
+				So(time.Limit, ShouldEqual, 1)
+				So(time.TotalCount, ShouldEqual, 2)
+				So(time.Items, ShouldHaveLength, 1)
+				So(time.Links.Next, ShouldResemble, pageLink("/filters//dimensions/time/options", `{"key":"2014","etag":"testETag0"}`, "limit=1"))
+			})
+		})
+	})
+}
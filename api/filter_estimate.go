@@ -0,0 +1,205 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	datasetAPI "github.com/ONSdigital/dp-api-clients-go/v2/dataset"
+	"github.com/ONSdigital/dp-filter-api/filters"
+	"github.com/ONSdigital/dp-filter-api/models"
+	"github.com/ONSdigital/dp-filter-api/mongo"
+	"github.com/ONSdigital/log.go/v2/log"
+	"github.com/gorilla/mux"
+)
+
+func (api *FilterAPI) getFilterBlueprintEstimateHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	filterID := vars["filter_blueprint_id"]
+	logData := log.Data{"filter_blueprint_id": filterID}
+	ctx := r.Context()
+	log.Info(ctx, "estimating filter blueprint output", logData)
+
+	filterBlueprint, err := api.getFilterBlueprint(ctx, filterID, mongo.AnyETag)
+	if err != nil {
+		log.Error(ctx, "unable to get filter blueprint", err, logData)
+		setErrorCode(w, r, err)
+		return
+	}
+
+	estimate, err := api.estimateFilterOutput(ctx, filterBlueprint)
+	if err != nil {
+		log.Error(ctx, "unable to estimate filter blueprint output", err, logData)
+		setErrorCode(w, r, err)
+		return
+	}
+	logData["rows"] = estimate.Rows
+	logData["xlsx_skipped"] = estimate.XLSXSkipped
+
+	bytes, err := json.Marshal(estimate)
+	if err != nil {
+		log.Error(ctx, "failed to marshal filter blueprint estimate into bytes", err, logData)
+		setErrorCode(w, r, err)
+		return
+	}
+
+	setJSONContentType(w)
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(bytes)
+	if err != nil {
+		log.Error(ctx, "failed to write bytes for http response", err, logData)
+		setErrorCode(w, r, err)
+		return
+	}
+
+	log.Info(ctx, "estimated filter blueprint output", logData)
+}
+
+// estimateFilterOutput estimates the output of a filter blueprint from the number of options selected for each dimension.
+// Every option is selected for the dataset dimensions that are not in the filter, or that have no options selected.
+func (api *FilterAPI) estimateFilterOutput(ctx context.Context, filterBlueprint *models.Filter) (*models.FilterEstimate, error) {
+	datasetDimensions, err := api.getDimensions(ctx, filterBlueprint.Dataset)
+	if err != nil {
+		return nil, err
+	}
+
+	names := []string{}
+	listed := map[string]bool{}
+	for _, d := range datasetDimensions.Items {
+		names = append(names, d.Name)
+		listed[d.Name] = true
+	}
+
+	filterDimensions := make(map[string]models.Dimension, len(filterBlueprint.Dimensions))
+	for _, d := range filterBlueprint.Dimensions {
+		if !listed[d.Name] {
+			names = append(names, d.Name)
+			listed[d.Name] = true
+		}
+		filterDimensions[d.Name] = d
+	}
+
+	estimates := make([]models.DimensionEstimate, 0, len(names))
+	for _, name := range names {
+		estimate, err := api.estimateDimension(ctx, filterBlueprint.Dataset, name, filterDimensions)
+		if err != nil {
+			return nil, err
+		}
+		estimates = append(estimates, estimate)
+	}
+
+	return models.NewFilterEstimate(estimates, api.maxXLSXRows), nil
+}
+
+// estimateDimension returns the number of options of a dimension that would be in the filter output
+func (api *FilterAPI) estimateDimension(ctx context.Context, dataset *models.Dataset, name string, filterDimensions map[string]models.Dimension) (models.DimensionEstimate, error) {
+	dimension, ok := filterDimensions[name]
+	if ok && (len(dimension.Options) > 0 || dimension.HasRanges()) {
+		if !dimension.IsExclude() && !dimension.HasRanges() {
+			return models.DimensionEstimate{Name: name, OptionCount: len(dimension.Options)}, nil
+		}
+
+		// in exclude mode, or with option ranges, the selected options are computed from the dataset options
+		selectedOptions, err := api.getSelectedOptions(ctx, dataset, dimension)
+		if err != nil {
+			return models.DimensionEstimate{}, err
+		}
+		return models.DimensionEstimate{Name: name, OptionCount: len(selectedOptions)}, nil
+	}
+
+	totalCount, err := api.getDimensionOptionsCount(ctx, dataset, name)
+	if err != nil {
+		return models.DimensionEstimate{}, err
+	}
+	return models.DimensionEstimate{Name: name, OptionCount: totalCount, AllOptions: true}, nil
+}
+
+// getDimensionOptionsCount returns the total number of options of a dataset dimension, requesting a single option
+func (api *FilterAPI) getDimensionOptionsCount(ctx context.Context, dataset *models.Dataset, dimensionName string) (int, error) {
+	totalCount := 0
+	processBatch := func(batch datasetAPI.Options) (abort bool, err error) {
+		totalCount = batch.TotalCount
+		return true, nil
+	}
+
+	err := api.datasetAPI.GetOptionsBatchProcess(ctx,
+		getUserAuthToken(ctx),
+		api.serviceAuthToken,
+		getCollectionID(ctx),
+		dataset.ID,
+		dataset.Edition,
+		strconv.Itoa(dataset.Version),
+		dimensionName,
+		nil,
+		processBatch,
+		1,
+		1)
+	if err != nil {
+		if apiErr, ok := err.(*datasetAPI.ErrInvalidDatasetAPIResponse); ok {
+			if apiErr.Code() == http.StatusNotFound {
+				return 0, filters.ErrDimensionOptionsNotFound
+			}
+		}
+		return 0, err
+	}
+
+	return totalCount, nil
+}
+
+// getMaxCells returns the maximum number of cells of a submitted filter for the provided dataset,
+// which is the one configured for the dataset if any, or the global one. Zero means no maximum.
+func (api *FilterAPI) getMaxCells(datasetID string) int64 {
+	if maxCells, ok := api.maxDatasetCells[datasetID]; ok {
+		return maxCells
+	}
+	return api.maxCells
+}
+
+// checkMaxCells validates that the estimated cells of a filter blueprint do not exceed the maximum allowed for submission,
+// returning an error that lists the dimensions that can be narrowed otherwise
+func (api *FilterAPI) checkMaxCells(ctx context.Context, filterBlueprint *models.Filter) error {
+	maxCells := api.getMaxCells(filterBlueprint.Dataset.ID)
+	if maxCells <= 0 {
+		return nil
+	}
+
+	estimate, err := api.estimateFilterOutput(ctx, filterBlueprint)
+	if err != nil {
+		return err
+	}
+
+	if estimate.Rows <= maxCells {
+		return nil
+	}
+	return filters.NewUnprocessableEntityErr(maxCellsExceededMessage(estimate, maxCells))
+}
+
+// maxCellsExceededMessage explains that a filter has too many cells, listing the dimensions with more than one option,
+// starting with the ones with most options, as the ones to narrow
+func maxCellsExceededMessage(estimate *models.FilterEstimate, maxCells int64) string {
+	dimensions := []models.DimensionEstimate{}
+	for _, d := range estimate.Dimensions {
+		if d.OptionCount > 1 {
+			dimensions = append(dimensions, d)
+		}
+	}
+	sort.SliceStable(dimensions, func(i, j int) bool {
+		return dimensions[i].OptionCount > dimensions[j].OptionCount
+	})
+
+	narrow := make([]string, len(dimensions))
+	for i, d := range dimensions {
+		if d.AllOptions {
+			narrow[i] = fmt.Sprintf("%s (all %d options)", d.Name, d.OptionCount)
+			continue
+		}
+		narrow[i] = fmt.Sprintf("%s (%d options)", d.Name, d.OptionCount)
+	}
+
+	return fmt.Sprintf("the filter would produce an estimated %d cells, which exceeds the maximum of %d. Narrow the options selected for the dimensions: %s",
+		estimate.Rows, maxCells, strings.Join(narrow, ", "))
+}
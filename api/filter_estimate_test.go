@@ -0,0 +1,167 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ONSdigital/dp-api-clients-go/v2/dataset"
+	"github.com/ONSdigital/dp-filter-api/api"
+	apimock "github.com/ONSdigital/dp-filter-api/api/mock"
+	"github.com/ONSdigital/dp-filter-api/mock"
+	"github.com/ONSdigital/dp-filter-api/models"
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// estimateDatasetAPIMock returns a dataset API mock with a sex dimension that is not in the mocked filter blueprint
+func estimateDatasetAPIMock() *apimock.DatasetAPIMock {
+	return &apimock.DatasetAPIMock{
+		GetVersionDimensionsFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, collectionID string, id string, edition string, version string) (dataset.VersionDimensions, error) {
+			return dataset.VersionDimensions{Items: []dataset.VersionDimension{{Name: "age"}, {Name: "sex"}, {Name: "time"}}}, nil
+		},
+		GetOptionsBatchProcessFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, collectionID string, id string, edition string, version string, dimension string, optionIDs *[]string, processBatch dataset.OptionsBatchProcessor, batchSize int, maxWorkers int) error {
+			_, err := processBatch(dataset.Options{Items: []dataset.Option{{Option: "female"}}, Count: 1, TotalCount: 2})
+			return err
+		},
+	}
+}
+
+func TestGetFilterBlueprintEstimate(t *testing.T) {
+	t.Parallel()
+
+	filterFlexAPIMock := &apimock.FilterFlexAPIMock{}
+
+	Convey("Given a filter blueprint for a dataset with a dimension that is not in the filter", t, func() {
+		datasetAPIMock := estimateDatasetAPIMock()
+		w := httptest.NewRecorder()
+
+		Convey("When the filter output is estimated", func() {
+			filterAPI := api.Setup(cfg(), mux.NewRouter(), mock.NewDataStore().Mock, &mock.FilterJob{}, datasetAPIMock, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+			r, err := http.NewRequest("GET", "http://localhost:22100/filters/12345678/estimate", http.NoBody)
+			So(err, ShouldBeNil)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the selected option counts are multiplied, using every option for the dimension not in the filter", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+
+				var estimate models.FilterEstimate
+				So(json.Unmarshal(w.Body.Bytes(), &estimate), ShouldBeNil)
+				So(estimate.Dimensions, ShouldResemble, []models.DimensionEstimate{
+					{Name: "age", OptionCount: 1},
+					{Name: "sex", OptionCount: 2, AllOptions: true},
+					{Name: "time", OptionCount: 2},
+					{Name: "1_age", OptionCount: 2},
+				})
+				So(estimate.Rows, ShouldEqual, 8)
+				So(estimate.CSVSize, ShouldEqual, 8*(12+40*4))
+				So(estimate.XLSXSkipped, ShouldBeFalse)
+
+				So(datasetAPIMock.GetOptionsBatchProcessCalls(), ShouldHaveLength, 1)
+				So(datasetAPIMock.GetOptionsBatchProcessCalls()[0].Dimension, ShouldEqual, "sex")
+				So(datasetAPIMock.GetOptionsBatchProcessCalls()[0].BatchSize, ShouldEqual, 1)
+			})
+		})
+
+		Convey("When the filter output is estimated with an XLSX row limit lower than the rows", func() {
+			limitedCfg := cfg()
+			limitedCfg.MaxXLSXRows = 5
+			filterAPI := api.Setup(limitedCfg, mux.NewRouter(), mock.NewDataStore().Mock, &mock.FilterJob{}, datasetAPIMock, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+			r, err := http.NewRequest("GET", "http://localhost:22100/filters/12345678/estimate", http.NoBody)
+			So(err, ShouldBeNil)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the XLSX download is expected to be skipped", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+
+				var estimate models.FilterEstimate
+				So(json.Unmarshal(w.Body.Bytes(), &estimate), ShouldBeNil)
+				So(estimate.XLSXSkipped, ShouldBeTrue)
+				So(estimate.Warnings, ShouldResemble, []string{"the estimated 8 rows exceed the XLSX limit of 5 rows, so the XLSX download will be skipped"})
+			})
+		})
+	})
+
+	Convey("Given a filter blueprint that does not exist", t, func() {
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), mock.NewDataStore().NotFound().Mock, &mock.FilterJob{}, estimateDatasetAPIMock(), filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+		w := httptest.NewRecorder()
+
+		Convey("When the filter output is estimated", func() {
+			r, err := http.NewRequest("GET", "http://localhost:22100/filters/12345678/estimate", http.NoBody)
+			So(err, ShouldBeNil)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the response is 404 not found", func() {
+				So(w.Code, ShouldEqual, http.StatusNotFound)
+			})
+		})
+	})
+}
+
+func TestSubmitFilterBlueprint_MaxCells(t *testing.T) {
+	t.Parallel()
+
+	filterFlexAPIMock := &apimock.FilterFlexAPIMock{}
+
+	Convey("Given a maximum number of cells lower than the estimated cells of a filter blueprint", t, func() {
+		ds := mock.NewDataStore().Mock
+		limitedCfg := cfg()
+		limitedCfg.MaxCells = 4
+		filterAPI := api.Setup(limitedCfg, mux.NewRouter(), ds, &mock.FilterJob{}, estimateDatasetAPIMock(), filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+		w := httptest.NewRecorder()
+
+		Convey("When the filter blueprint is submitted", func() {
+			r, err := http.NewRequest("PUT", cfg().Host+"/filters/21312?submitted=true", strings.NewReader("{}"))
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the response is 422 unprocessable entity, explaining which dimensions to narrow", func() {
+				So(w.Code, ShouldEqual, http.StatusUnprocessableEntity)
+				So(problemDetail(w.Body.String()), ShouldEqual, "the filter would produce an estimated 8 cells, which exceeds the maximum of 4. "+
+					"Narrow the options selected for the dimensions: sex (all 2 options), time (2 options), 1_age (2 options)")
+			})
+
+			Convey("Then the filter blueprint is neither updated nor submitted", func() {
+				So(ds.UpdateFilterCalls(), ShouldHaveLength, 0)
+				So(ds.CreateFilterOutputCalls(), ShouldHaveLength, 0)
+			})
+		})
+
+		Convey("When the filter blueprint is updated without being submitted", func() {
+			r, err := http.NewRequest("PUT", cfg().Host+"/filters/21312", strings.NewReader(`{"events":[]}`))
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the maximum number of cells is not enforced", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(ds.UpdateFilterCalls(), ShouldHaveLength, 1)
+			})
+		})
+	})
+
+	Convey("Given a maximum number of cells for the dataset of the filter blueprint that overrides the global one", t, func() {
+		ds := mock.NewDataStore().Mock
+		limitedCfg := cfg()
+		limitedCfg.MaxCells = 4
+		limitedCfg.MaxDatasetCells = map[string]int64{"123": 8}
+		filterAPI := api.Setup(limitedCfg, mux.NewRouter(), ds, &mock.FilterJob{}, estimateDatasetAPIMock(), filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+		w := httptest.NewRecorder()
+
+		Convey("When the filter blueprint is submitted", func() {
+			r, err := http.NewRequest("PUT", cfg().Host+"/filters/21312?submitted=true", strings.NewReader("{}"))
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the filter blueprint is submitted", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(ds.CreateFilterOutputCalls(), ShouldHaveLength, 1)
+			})
+		})
+	})
+}
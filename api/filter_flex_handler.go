@@ -27,5 +27,5 @@ func (api *FilterAPI) filterFlexNullEndpointHandler(w http.ResponseWriter, r *ht
 	ctx := r.Context()
 
 	log.Error(ctx, "bad route", errors.New("attempted filter flex route with no CMD journey"), logData)
-	http.Error(w, BadRequest, http.StatusBadRequest)
+	writeError(w, r, http.StatusBadRequest, errBadRequestBody)
 }
@@ -15,6 +15,7 @@ import (
 	"github.com/ONSdigital/dp-net/v2/links"
 	"github.com/ONSdigital/log.go/v2/log"
 	"github.com/gorilla/mux"
+	"github.com/pkg/errors"
 )
 
 //nolint:gocyclo // high cyclomactic complexity not in scope for maintenance
@@ -29,7 +30,7 @@ func (api *FilterAPI) getFilterOutputHandler(w http.ResponseWriter, r *http.Requ
 	filterOutput, err := api.getOutput(ctx, filterOutputID, hideS3Links)
 	if err != nil {
 		log.Error(ctx, "unable to get filter output", err, logData)
-		setErrorCode(w, err)
+		setErrorCode(w, r, err)
 		return
 	}
 	logData["filter_output"] = filterOutput
@@ -43,7 +44,7 @@ func (api *FilterAPI) getFilterOutputHandler(w http.ResponseWriter, r *http.Requ
 			if err != nil {
 				log.Error(ctx, "failed to rewrite filter output self link", err, logData,
 					log.Data{"link_type": "Self", "original_link": filterOutput.Links.Self.HRef})
-				setErrorCode(w, err)
+				setErrorCode(w, r, err)
 				return
 			}
 			filterOutput.Links.Self.HRef = newLink
@@ -54,7 +55,7 @@ func (api *FilterAPI) getFilterOutputHandler(w http.ResponseWriter, r *http.Requ
 			if err != nil {
 				log.Error(ctx, "failed to rewrite filter output filterBlueprint link", err, logData,
 					log.Data{"link_type": "FilterBlueprint", "original_link": filterOutput.Links.FilterBlueprint.HRef})
-				setErrorCode(w, err)
+				setErrorCode(w, r, err)
 				return
 			}
 			filterOutput.Links.FilterBlueprint.HRef = newLink
@@ -65,49 +66,52 @@ func (api *FilterAPI) getFilterOutputHandler(w http.ResponseWriter, r *http.Requ
 			if err != nil {
 				log.Error(ctx, "failed to rewrite filter output version link", err, logData,
 					log.Data{"link_type": "Version", "original_link": filterOutput.Links.Version.HRef})
-				setErrorCode(w, err)
+				setErrorCode(w, r, err)
 				return
 			}
 			filterOutput.Links.Version.HRef = newLink
 		}
 
-		if filterOutput.Downloads != nil {
-			if filterOutput.Downloads.CSV != nil && filterOutput.Downloads.CSV.HRef != "" {
-				newDownloadLink, err := links.BuildDownloadLink(filterOutput.Downloads.CSV.HRef, api.downloadServiceURL)
-				if err != nil {
-					log.Error(ctx, "failed to rewrite CSV download link", err, logData,
-						log.Data{"link_type": "CSV", "original_link": filterOutput.Downloads.CSV.HRef})
-					setErrorCode(w, err)
-					return
-				}
-				filterOutput.Downloads.CSV.HRef = newDownloadLink
-			}
-			if filterOutput.Downloads.XLS != nil && filterOutput.Downloads.XLS.HRef != "" {
-				newDownloadLink, err := links.BuildDownloadLink(filterOutput.Downloads.XLS.HRef, api.downloadServiceURL)
-				if err != nil {
-					log.Error(ctx, "failed to rewrite XLS download link", err, logData,
-						log.Data{"link_type": "XLS", "original_link": filterOutput.Downloads.XLS.HRef})
-					setErrorCode(w, err)
-					return
-				}
-				filterOutput.Downloads.XLS.HRef = newDownloadLink
-			}
+		if err := api.rewriteDownloadLinks(filterOutput.Downloads); err != nil {
+			log.Error(ctx, "failed to rewrite download links", err, logData)
+			setErrorCode(w, r, err)
+			return
 		}
 	}
 
+	// filter outputs have no stored ETag, so it is generated from the filter output as it is returned,
+	// which changes whenever the output is updated, and differs for requests that are shown private links
+	eTag, err := filterOutput.Hash(nil)
+	if err != nil {
+		log.Error(ctx, "failed to generate filter output ETag", err, logData)
+		writeError(w, r, http.StatusInternalServerError, errInternal)
+		return
+	}
+
+	// private download links must not be stored by shared caches, even for published data,
+	// and only completed filter outputs can be reused without revalidation
+	cacheable := isPublished(filterOutput) && hideS3Links
+	completed := filterOutput.State == models.CompletedState
+	if api.writeNotModified(w, r, eTag, cacheable, completed) {
+		log.Info(ctx, "filter output not modified", logData)
+		return
+	}
+
 	bytes, err := json.Marshal(filterOutput)
 	if err != nil {
 		log.Error(ctx, "failed to marshal filter output into bytes", err, logData)
-		http.Error(w, InternalError, http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, errInternal)
 		return
 	}
 
 	setJSONContentType(w)
+	setETag(w, eTag)
+	api.setCacheControl(w, cacheable, completed)
 	w.WriteHeader(http.StatusOK)
 	_, err = w.Write(bytes)
 	if err != nil {
 		log.Error(ctx, "failed to write bytes for http response", err, logData)
-		setErrorCode(w, err)
+		setErrorCode(w, r, err)
 		return
 	}
 
@@ -127,7 +131,7 @@ func (api *FilterAPI) updateFilterOutputHandler(w http.ResponseWriter, r *http.R
 	filterOutput, err := models.CreateFilter(r.Body)
 	if err != nil {
 		log.Error(ctx, "unable to unmarshal request body", err, logData)
-		http.Error(w, BadRequest, http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, errBadRequestBody)
 		return
 	}
 	logData["filter_output"] = filterOutput
@@ -135,7 +139,7 @@ func (api *FilterAPI) updateFilterOutputHandler(w http.ResponseWriter, r *http.R
 	err = api.updateFilterOutput(ctx, filterOutputID, filterOutput)
 	if err != nil {
 		log.Error(ctx, "failed to update filter output", err, logData)
-		setErrorCode(w, err)
+		setErrorCode(w, r, err)
 		return
 	}
 
@@ -220,6 +224,25 @@ func downloadsAreGenerated(filterOutput *models.Filter) bool {
 	return false
 }
 
+// rewriteDownloadLinks rewrites the links of the downloads of a filter output to the download service
+func (api *FilterAPI) rewriteDownloadLinks(downloads *models.Downloads) error {
+	if downloads == nil {
+		return nil
+	}
+
+	for fileType, download := range map[string]*models.DownloadItem{"CSV": downloads.CSV, "XLS": downloads.XLS} {
+		if download == nil || download.HRef == "" {
+			continue
+		}
+		newDownloadLink, err := links.BuildDownloadLink(download.HRef, api.downloadServiceURL)
+		if err != nil {
+			return errors.Wrapf(err, "failed to rewrite %s download link %s", fileType, download.HRef)
+		}
+		download.HRef = newDownloadLink
+	}
+	return nil
+}
+
 func (api *FilterAPI) getOutput(ctx context.Context, filterID string, hideS3Links bool) (*models.Filter, error) {
 	logData := log.Data{"filter_output_id": filterID}
 
@@ -263,20 +286,26 @@ func (api *FilterAPI) getOutput(ctx context.Context, filterID string, hideS3Link
 
 	log.Info(ctx, "unauthenticated request to access unpublished filter output", logData)
 
+	// filter outputs are published by the instance published consumer when it is enabled, so they are not found until then
+	if api.enablePublishEventConsumer {
+		return nil, filters.ErrFilterOutputNotFound
+	}
+
 	filter, err := api.getFilterBlueprint(ctx, output.Links.FilterBlueprint.ID, mongo.AnyETag)
 	if err != nil {
 		log.Error(ctx, "failed to retrieve filter blueprint", err, logData)
 		return nil, filters.ErrFilterOutputNotFound
 	}
 
-	// filter has been published since output was last requested, so update output and return
+	// filter has been published since output was last requested, so only the published flag of the outputs is updated,
+	// leaving their downloads and state to the exporters
 	if filter.Published != nil && *filter.Published == models.Published {
-		output.Published = &models.Published
-		if err := api.dataStore.UpdateFilterOutput(ctx, output, output.UniqueTimestamp); err != nil {
-			log.Error(ctx, "error updating filter output", err, logData)
+		if _, err := api.dataStore.PublishFilterOutputs(ctx, filter.InstanceID); err != nil {
+			log.Error(ctx, "error publishing filter outputs", err, logData)
 			return nil, filters.ErrFilterOutputNotFound
 		}
 
+		output.Published = &models.Published
 		return output, nil
 	}
 
@@ -345,7 +374,7 @@ func (api *FilterAPI) addEventHandler(w http.ResponseWriter, r *http.Request) {
 	bytes, err := io.ReadAll(r.Body)
 	if err != nil {
 		log.Error(ctx, "failed to read request body", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, err)
 		return
 	}
 
@@ -353,7 +382,7 @@ func (api *FilterAPI) addEventHandler(w http.ResponseWriter, r *http.Request) {
 	err = json.Unmarshal(bytes, event)
 	if err != nil {
 		log.Error(ctx, "failed to parse json body", err)
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, err)
 		return
 	}
 
@@ -363,7 +392,7 @@ func (api *FilterAPI) addEventHandler(w http.ResponseWriter, r *http.Request) {
 	err = api.addEvents(ctx, []*models.Event{event}, filterOutputID, false)
 	if err != nil {
 		log.Error(ctx, "failed to add event to filter output", err, logData)
-		setErrorCode(w, err)
+		setErrorCode(w, r, err)
 		return
 	}
 
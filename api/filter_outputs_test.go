@@ -216,7 +216,7 @@ func TestFailedToGetFilterOutput(t *testing.T) {
 		So(w.Code, ShouldEqual, http.StatusInternalServerError)
 
 		response := w.Body.String()
-		So(response, ShouldResemble, internalErrResponse)
+		So(problemDetail(response), ShouldEqual, internalErrResponse)
 	})
 
 	Convey("When filter output does not exist, a not found is returned", t, func() {
@@ -229,7 +229,7 @@ func TestFailedToGetFilterOutput(t *testing.T) {
 		So(w.Code, ShouldEqual, http.StatusNotFound)
 
 		response := w.Body.String()
-		So(response, ShouldResemble, filters.ErrFilterOutputNotFound.Error()+"\n")
+		So(problemDetail(response), ShouldEqual, filters.ErrFilterOutputNotFound.Error())
 	})
 
 	Convey("When filter output is unpublished and the request is unauthenticated, a not found is returned", t, func() {
@@ -242,7 +242,40 @@ func TestFailedToGetFilterOutput(t *testing.T) {
 		So(w.Code, ShouldEqual, http.StatusNotFound)
 
 		response := w.Body.String()
-		So(response, ShouldResemble, filters.ErrFilterOutputNotFound.Error()+"\n")
+		So(problemDetail(response), ShouldEqual, filters.ErrFilterOutputNotFound.Error())
+	})
+
+	Convey("When filter output is unpublished, filters are published by events, and the request is unauthenticated, a not found is returned without checking the filter blueprint", t, func() {
+		r, err := http.NewRequest("GET", "http://localhost:22100/filter-outputs/12345678", http.NoBody)
+		So(err, ShouldBeNil)
+
+		config := cfg()
+		config.EnablePublishEventConsumer = true
+		mockDatastore := mock.NewDataStore().Unpublished().Mock
+
+		w := httptest.NewRecorder()
+		filterAPI := api.Setup(config, mux.NewRouter(), mockDatastore, &mock.FilterJob{}, mock.NewDatasetAPI(), filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+		filterAPI.Router.ServeHTTP(w, r)
+		So(w.Code, ShouldEqual, http.StatusNotFound)
+		So(mockDatastore.GetFilterCalls(), ShouldHaveLength, 0)
+		So(mockDatastore.PublishFilterOutputsCalls(), ShouldHaveLength, 0)
+		So(mockDatastore.UpdateFilterOutputCalls(), ShouldHaveLength, 0)
+	})
+
+	Convey("When filter output is unpublished, its dataset version has been published, and the request is unauthenticated, only the published flag of the filter outputs is updated", t, func() {
+		r, err := http.NewRequest("GET", "http://localhost:22100/filter-outputs/12345678", http.NoBody)
+		So(err, ShouldBeNil)
+
+		mockDatastore := mock.NewDataStore().Unpublished().Mock
+
+		w := httptest.NewRecorder()
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), mockDatastore, &mock.FilterJob{}, mock.NewDatasetAPI(), filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+		filterAPI.Router.ServeHTTP(w, r)
+		So(w.Code, ShouldEqual, http.StatusOK)
+		So(mockDatastore.PublishFilterBlueprintsCalls(), ShouldHaveLength, 1)
+		So(mockDatastore.PublishFilterOutputsCalls(), ShouldHaveLength, 1)
+		So(mockDatastore.PublishFilterOutputsCalls()[0].InstanceID, ShouldEqual, "12345678")
+		So(mockDatastore.UpdateFilterOutputCalls(), ShouldHaveLength, 0)
 	})
 }
 
@@ -419,7 +452,7 @@ func TestFailedToUpdateFilterOutput(t *testing.T) {
 		So(w.Code, ShouldEqual, http.StatusInternalServerError)
 
 		response := w.Body.String()
-		So(response, ShouldResemble, internalErrResponse)
+		So(problemDetail(response), ShouldEqual, internalErrResponse)
 
 		Convey("Then the request body has been drained", func() {
 			bytesRead, err := r.Body.Read(make([]byte, 1))
@@ -454,7 +487,7 @@ func TestFailedToUpdateFilterOutput(t *testing.T) {
 		So(w.Code, ShouldEqual, http.StatusForbidden)
 
 		response := w.Body.String()
-		So(response, ShouldResemble, "forbidden from updating the following fields: [downloads.csv.private]\n")
+		So(problemDetail(response), ShouldEqual, "forbidden from updating the following fields: [downloads.csv.private]")
 
 		Convey("Then the request body has been drained", func() {
 			bytesRead, err := r.Body.Read(make([]byte, 1))
@@ -473,7 +506,7 @@ func TestFailedToUpdateFilterOutput(t *testing.T) {
 		So(w.Code, ShouldEqual, http.StatusForbidden)
 
 		response := w.Body.String()
-		So(response, ShouldResemble, "forbidden from updating the following fields: [downloads.xls.private]\n")
+		So(problemDetail(response), ShouldEqual, "forbidden from updating the following fields: [downloads.xls.private]")
 
 		Convey("Then the request body has been drained", func() {
 			bytesRead, err := r.Body.Read(make([]byte, 1))
@@ -504,7 +537,7 @@ func TestFailedToUpdateFilterOutput_BadRequest(t *testing.T) {
 
 			Convey("Then the response contains the expected content", func() {
 				response := w.Body.String()
-				So(response, ShouldResemble, badRequestResponse)
+				So(problemDetail(response), ShouldEqual, badRequestResponse)
 			})
 
 			Convey("Then the request body has been drained", func() {
@@ -526,7 +559,7 @@ func TestFailedToUpdateFilterOutput_BadRequest(t *testing.T) {
 
 			Convey("Then the response contains the expected content", func() {
 				response := w.Body.String()
-				So(response, ShouldResemble, badRequestResponse)
+				So(problemDetail(response), ShouldEqual, badRequestResponse)
 			})
 
 			Convey("Then the request body has been drained", func() {
@@ -548,7 +581,7 @@ func TestFailedToUpdateFilterOutput_BadRequest(t *testing.T) {
 
 			Convey("Then the response contains the expected content", func() {
 				response := w.Body.String()
-				So(response, ShouldResemble, "forbidden from updating the following fields: [dataset.id dataset.edition dataset.version]\n")
+				So(problemDetail(response), ShouldEqual, "forbidden from updating the following fields: [dataset.id dataset.edition dataset.version]")
 			})
 
 			Convey("Then the request body has been drained", func() {
@@ -571,7 +604,7 @@ func TestFailedToUpdateFilterOutput_BadRequest(t *testing.T) {
 
 			Convey("Then the response contains the expected content", func() {
 				response := w.Body.String()
-				So(response, ShouldResemble, filters.ErrUnauthorised.Error()+"\n")
+				So(problemDetail(response), ShouldEqual, filters.ErrUnauthorised.Error())
 			})
 
 			Convey("Then the request body has been drained", func() {
@@ -593,7 +626,7 @@ func TestFailedToUpdateFilterOutput_BadRequest(t *testing.T) {
 
 			Convey("Then the response contains the expected content", func() {
 				response := w.Body.String()
-				So(response, ShouldResemble, "forbidden from updating the following fields: [downloads.csv]\n")
+				So(problemDetail(response), ShouldEqual, "forbidden from updating the following fields: [downloads.csv]")
 			})
 
 			Convey("Then the request body has been drained", func() {
@@ -615,7 +648,7 @@ func TestFailedToUpdateFilterOutput_BadRequest(t *testing.T) {
 
 			Convey("Then the response contains the expected content", func() {
 				response := w.Body.String()
-				So(response, ShouldResemble, "forbidden from updating the following fields: [downloads.xls]\n")
+				So(problemDetail(response), ShouldEqual, "forbidden from updating the following fields: [downloads.xls]")
 			})
 
 			Convey("Then the request body has been drained", func() {
@@ -0,0 +1,454 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+
+	datasetAPI "github.com/ONSdigital/dp-api-clients-go/v2/dataset"
+	"github.com/ONSdigital/dp-filter-api/filters"
+	"github.com/ONSdigital/dp-filter-api/models"
+	dphttp "github.com/ONSdigital/dp-net/http"
+	dprequest "github.com/ONSdigital/dp-net/request"
+	"github.com/ONSdigital/log.go/v2/log"
+	"github.com/gorilla/mux"
+)
+
+// filterPatch holds the state of a filter blueprint while the operations of a JSON Patch are applied to it
+type filterPatch struct {
+	filter *models.Filter
+
+	// version is the dataset version the filter blueprint has been moved to, if any
+	version *datasetAPI.Version
+
+	// patched holds the names of the dimensions whose selection has changed
+	patched map[string]bool
+
+	// limit bounds the expansion of the option selectors of every patch operation
+	limit *optionSelectorLimit
+}
+
+func (api *FilterAPI) patchFilterBlueprintHandler(w http.ResponseWriter, r *http.Request) {
+	defer dphttp.DrainBody(r)
+
+	vars := mux.Vars(r)
+	filterBlueprintID := vars["filter_blueprint_id"]
+	logData := log.Data{"filter_blueprint_id": filterBlueprintID}
+	ctx := r.Context()
+	log.Info(ctx, "patching filter blueprint", logData)
+
+	// eTag value must be present in If-Match header
+	eTag, err := getIfMatchForce(r)
+	if err != nil {
+		log.Error(ctx, "missing header", err, log.Data{"error": err.Error()})
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	// unmarshal and validate the patch array
+	patches, err := models.CreateFilterPatches(r.Body)
+	if err != nil {
+		log.Error(ctx, "error obtaining patch from request body", err, logData)
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+	logData["patch_list"] = patches
+
+	// check that the values are valid for their paths and the total values do not exceed the maximum allowed
+	if err = api.checkFilterPatchValues(patches); err != nil {
+		log.Error(ctx, "error validating patch operation values, no change has been applied", err, logData)
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	newFilter, err := api.patchFilterBlueprint(ctx, filterBlueprintID, patches, eTag)
+	if err != nil {
+		log.Error(ctx, "error patching filter blueprint, no change has been applied", err, logData)
+		setErrorCode(w, r, err)
+		return
+	}
+
+	bytes, err := json.Marshal(newFilter)
+	if err != nil {
+		log.Error(ctx, "failed to marshal patched filter blueprint into bytes", err, logData)
+		setErrorCode(w, r, err)
+		return
+	}
+
+	setJSONContentType(w)
+	setETag(w, newFilter.ETag)
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(bytes)
+	if err != nil {
+		log.Error(ctx, "failed to write bytes for http response", err, logData)
+		setErrorCode(w, r, err)
+		return
+	}
+
+	log.Info(ctx, "successfully patched filter blueprint", logData)
+}
+
+// checkFilterPatchValues validates the value of each patch operation for its path,
+// and that the overall option values do not exceed the maximum allowed
+func (api *FilterAPI) checkFilterPatchValues(patches []dprequest.Patch) error {
+	totalValues := 0
+	for _, patch := range patches {
+		path, err := models.ParsePatchPath(patch.Path)
+		if err != nil {
+			return err
+		}
+
+		// removing or moving a whole dimension does not take a value
+		if path.Target == models.PatchTargetDimension && (patch.Op == dprequest.OpRemove.String() || patch.Op == dprequest.OpMove.String()) {
+			continue
+		}
+
+		var values *models.DimensionOptions
+		switch path.Target {
+		case models.PatchTargetDatasetVersion:
+			if _, err := getPatchVersion(patch.Value); err != nil {
+				return err
+			}
+			continue
+		case models.PatchTargetDimensionOptions:
+			values, err = getOptionsFromInterface(patch.Value)
+			if err != nil {
+				return fmt.Errorf("values provided are not strings, valid option selectors or valid option ranges")
+			}
+		default:
+			values, err = getPatchDimension(patch.Value)
+			if err != nil {
+				return err
+			}
+		}
+
+		totalValues += len(values.Options) + len(values.Selectors) + len(values.Ranges)
+		if totalValues > api.maxRequestOptions {
+			return fmt.Errorf("a maximum of %d overall option values can be provied in a set of patch operations, which has been exceeded", api.maxRequestOptions)
+		}
+	}
+	return nil
+}
+
+// getPatchDimension returns the dimension selection provided as the value of a patch operation on a dimension
+func getPatchDimension(value interface{}) (*models.DimensionOptions, error) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil, models.ErrorParsingBody
+	}
+	if _, ok := value.(map[string]interface{}); !ok {
+		return nil, errors.New("value provided for a dimension is not an object with its options")
+	}
+	return models.CreateDimensionOptions(bytes.NewReader(b))
+}
+
+// getPatchVersion returns the dataset version provided as the value of a patch operation, which must be a positive integer
+func getPatchVersion(value interface{}) (int, error) {
+	version, ok := value.(float64)
+	if !ok || version < 1 || version != math.Trunc(version) || version > math.MaxInt32 {
+		return 0, fmt.Errorf("value provided for the dataset version is not a positive integer, got: %v", value)
+	}
+	return int(version), nil
+}
+
+// patchFilterBlueprint applies the operations of a JSON Patch to a copy of a filter blueprint, in order.
+// The patched filter blueprint is only validated against its dataset version and stored once every operation has been applied,
+// so either all the operations are applied or none of them are.
+func (api *FilterAPI) patchFilterBlueprint(ctx context.Context, filterBlueprintID string, patches []dprequest.Patch, eTag string) (*models.Filter, error) {
+	logData := log.Data{"filter_blueprint_id": filterBlueprintID}
+
+	newFilter, err := api.dataStore.RunTransaction(ctx, true, func(txCtx context.Context) (interface{}, error) {
+		currentFilter, err := api.getFilterBlueprint(txCtx, filterBlueprintID, eTag)
+		if err != nil {
+			return nil, err
+		}
+
+		p := &filterPatch{
+			filter:  copyFilterBlueprint(currentFilter),
+			patched: map[string]bool{},
+			limit:   api.newOptionSelectorLimit(),
+		}
+		for _, patch := range patches {
+			if err := api.applyFilterPatch(txCtx, p, patch); err != nil {
+				logData["failed_patch"] = patch
+				log.Error(txCtx, "failed to apply patch operation", err, logData)
+				return nil, err
+			}
+		}
+
+		if err := api.checkPatchedFilterBlueprint(txCtx, p); err != nil {
+			return nil, err
+		}
+
+		p.filter.ETag, err = api.dataStore.ReplaceFilter(txCtx, p.filter, currentFilter.UniqueTimestamp, eTag, currentFilter)
+		if err != nil {
+			return nil, err
+		}
+		return p.filter, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	f, ok := newFilter.(*models.Filter)
+	if !ok {
+		return nil, errors.New("returned filter blueprint is not a filter")
+	}
+	return f, nil
+}
+
+// copyFilterBlueprint returns a copy of a filter blueprint that can be patched without modifying the provided one
+func copyFilterBlueprint(filterBlueprint *models.Filter) *models.Filter {
+	newFilter := *filterBlueprint
+	if filterBlueprint.Dataset != nil {
+		dataset := *filterBlueprint.Dataset
+		newFilter.Dataset = &dataset
+	}
+	newFilter.Events = slices.Clone(filterBlueprint.Events)
+
+	newFilter.Dimensions = make([]models.Dimension, len(filterBlueprint.Dimensions))
+	for i, d := range filterBlueprint.Dimensions {
+		newFilter.Dimensions[i] = d
+		newFilter.Dimensions[i].Options = slices.Clone(d.Options)
+		newFilter.Dimensions[i].Ranges = slices.Clone(d.Ranges)
+	}
+	return &newFilter
+}
+
+// applyFilterPatch applies a single patch operation to the filter blueprint being patched
+func (api *FilterAPI) applyFilterPatch(ctx context.Context, p *filterPatch, patch dprequest.Patch) error {
+	path, err := models.ParsePatchPath(patch.Path)
+	if err != nil {
+		return filters.NewBadRequestErr(err.Error())
+	}
+
+	switch path.Target {
+	case models.PatchTargetDatasetVersion:
+		return api.applyDatasetVersionPatch(ctx, p, patch)
+	case models.PatchTargetDimensionOptions:
+		return api.applyDimensionOptionsPatch(ctx, p, path.Dimension, patch)
+	default:
+		return api.applyDimensionPatch(ctx, p, path.Dimension, patch)
+	}
+}
+
+// applyDatasetVersionPatch tests or replaces the dataset version of the filter blueprint.
+// Replacing it moves the filter blueprint onto the instance of the new version straight away,
+// so that any following operation expands option selectors against that instance.
+func (api *FilterAPI) applyDatasetVersionPatch(ctx context.Context, p *filterPatch, patch dprequest.Patch) error {
+	version, err := getPatchVersion(patch.Value)
+	if err != nil {
+		return filters.NewBadRequestErr(err.Error())
+	}
+
+	if patch.Op == dprequest.OpTest.String() {
+		if p.filter.Dataset.Version != version {
+			return models.ErrPatchTestFailed
+		}
+		return nil
+	}
+
+	if p.filter.Dataset.Version == version {
+		return nil
+	}
+	p.filter.Dataset.Version = version
+	p.version, err = api.setFilterVersion(ctx, p.filter)
+	if err != nil {
+		return filters.NewBadRequestErr(err.Error())
+	}
+	return nil
+}
+
+// applyDimensionPatch adds, removes, replaces, renames or tests a whole dimension of the filter blueprint
+func (api *FilterAPI) applyDimensionPatch(ctx context.Context, p *filterPatch, name string, patch dprequest.Patch) error {
+	dimension := findDimension(p.filter, name)
+
+	switch patch.Op {
+	case dprequest.OpRemove.String():
+		if dimension == nil {
+			return filters.ErrDimensionNotFound
+		}
+		removeDimension(p.filter, name)
+		delete(p.patched, name)
+		return nil
+
+	case dprequest.OpMove.String():
+		from, err := models.ParsePatchPath(patch.From)
+		if err != nil {
+			return filters.NewBadRequestErr(err.Error())
+		}
+		moved := findDimension(p.filter, from.Dimension)
+		if moved == nil {
+			return filters.ErrDimensionNotFound
+		}
+		if from.Dimension == name {
+			return nil
+		}
+		renamed := *moved
+		renamed.Name = name
+		renamed.URL = fmt.Sprintf("%s/filters/%s/dimensions/%s", api.host, p.filter.FilterID, name)
+		removeDimension(p.filter, from.Dimension)
+		delete(p.patched, from.Dimension)
+		setDimension(p.filter, renamed)
+		p.patched[name] = true
+		return nil
+	}
+
+	dimensionOptions, err := getPatchDimension(patch.Value)
+	if err != nil {
+		return filters.NewBadRequestErr(err.Error())
+	}
+	if patch.Op == dprequest.OpReplace.String() && dimension == nil {
+		return filters.ErrDimensionNotFound
+	}
+
+	newDimension, err := api.newPatchDimension(ctx, p.filter, name, dimensionOptions, p.limit)
+	if err != nil {
+		return err
+	}
+
+	if patch.Op == dprequest.OpTest.String() {
+		if dimension == nil || !dimension.SameSelection(&newDimension) {
+			return models.ErrPatchTestFailed
+		}
+		return nil
+	}
+
+	// adding a dimension that already exists replaces it, as defined by RFC 6902 for object members
+	setDimension(p.filter, newDimension)
+	p.patched[name] = true
+	return nil
+}
+
+// newPatchDimension creates a filter dimension from the selection provided by a patch operation,
+// expanding any option selectors and patterns into the option codes they select
+func (api *FilterAPI) newPatchDimension(ctx context.Context, filterBlueprint *models.Filter, name string, dimensionOptions *models.DimensionOptions, limit *optionSelectorLimit) (models.Dimension, error) {
+	// include is the default mode, so it is not stored
+	mode := dimensionOptions.Mode
+	if mode == models.DimensionModeInclude {
+		mode = ""
+	}
+
+	options, err := api.expandPatchOptions(ctx, filterBlueprint, name, dimensionOptions, limit)
+	if err != nil {
+		return models.Dimension{}, err
+	}
+
+	return models.Dimension{
+		URL:     fmt.Sprintf("%s/filters/%s/dimensions/%s", api.host, filterBlueprint.FilterID, name),
+		Name:    name,
+		Mode:    mode,
+		Options: options,
+		Ranges:  dimensionOptions.Ranges,
+	}, nil
+}
+
+// applyDimensionOptionsPatch adds or removes options and option ranges from the selection of a dimension of the filter blueprint.
+// In exclude mode, adding options means removing them from the excluded options, and vice versa.
+func (api *FilterAPI) applyDimensionOptionsPatch(ctx context.Context, p *filterPatch, name string, patch dprequest.Patch) error {
+	dimension := findDimension(p.filter, name)
+	if dimension == nil {
+		return filters.ErrDimensionNotFound
+	}
+
+	values, err := getOptionsFromInterface(patch.Value)
+	if err != nil {
+		return filters.NewBadRequestErr(err.Error())
+	}
+
+	options, err := api.expandPatchOptions(ctx, p.filter, name, values, p.limit)
+	if err != nil {
+		return err
+	}
+
+	selected := patch.Op == dprequest.OpAdd.String()
+	if selected != dimension.IsExclude() {
+		dimension.Options = RemoveDuplicateAndEmptyOptions(append(dimension.Options, options...))
+	} else {
+		dimension.Options = slices.DeleteFunc(dimension.Options, func(option string) bool {
+			return slices.Contains(options, option)
+		})
+	}
+
+	if len(values.Ranges) > 0 {
+		orderedOptions, err := api.getOrderedDimensionOptions(ctx, p.filter.Dataset, name)
+		if err != nil {
+			return err
+		}
+
+		// the ranges are stored when they select options in include mode, or when they leave options out in exclude mode
+		if selected != dimension.IsExclude() {
+			dimension.AddRanges(values.Ranges)
+		} else if err := dimension.RemoveRanges(values.Ranges, orderedOptions); err != nil {
+			return filters.NewBadRequestErr(err.Error())
+		}
+	}
+
+	p.patched[name] = true
+	return nil
+}
+
+// expandPatchOptions returns the option codes provided by a patch operation, along with the ones selected by its option selectors and patterns,
+// all of which are counted against the provided limit.
+func (api *FilterAPI) expandPatchOptions(ctx context.Context, filterBlueprint *models.Filter, name string, values *models.DimensionOptions, limit *optionSelectorLimit) ([]string, error) {
+	return api.expandOptions(ctx, filterBlueprint, name, values.Options, values.Selectors, limit)
+}
+
+// checkPatchedFilterBlueprint validates the patched filter blueprint against its dataset version.
+// Every dimension is validated if the version has changed, otherwise only the dimensions whose selection has changed,
+// returning the validation errors of all the invalid dimensions together.
+func (api *FilterAPI) checkPatchedFilterBlueprint(ctx context.Context, p *filterPatch) error {
+	if p.version != nil {
+		if err := api.checkFilterOptions(ctx, p.filter, p.version); err != nil {
+			return newValidationBadRequestErr(err)
+		}
+		return nil
+	}
+
+	var validationErrors models.ValidationErrors
+	for _, d := range p.filter.Dimensions {
+		if !p.patched[d.Name] {
+			continue
+		}
+
+		if err := api.checkNewFilterDimension(ctx, d.Name, d.Options, p.filter.Dataset); err != nil {
+			if err == filters.ErrVersionNotFound || err == filters.ErrDimensionsNotFound {
+				return err
+			}
+			addValidationErrors(&validationErrors, d.Name, newValidationBadRequestErr(err))
+			continue
+		}
+
+		if err := api.checkOptionRanges(ctx, p.filter.Dataset, d.Name, d.Ranges); err != nil {
+			if !addValidationErrors(&validationErrors, d.Name, err) {
+				return err
+			}
+		}
+	}
+
+	if len(validationErrors.Errors) > 0 {
+		return validationErrors
+	}
+	return nil
+}
+
+// setDimension replaces the dimension with the same name in the filter blueprint, or adds it if there is none
+func setDimension(filterBlueprint *models.Filter, dimension models.Dimension) {
+	if existing := findDimension(filterBlueprint, dimension.Name); existing != nil {
+		*existing = dimension
+		return
+	}
+	filterBlueprint.Dimensions = append(filterBlueprint.Dimensions, dimension)
+}
+
+// removeDimension removes the dimension with the provided name from the filter blueprint
+func removeDimension(filterBlueprint *models.Filter, name string) {
+	filterBlueprint.Dimensions = slices.DeleteFunc(filterBlueprint.Dimensions, func(d models.Dimension) bool {
+		return d.Name == name
+	})
+}
@@ -0,0 +1,219 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ONSdigital/dp-api-clients-go/v2/dataset"
+	"github.com/ONSdigital/dp-filter-api/api"
+	apimock "github.com/ONSdigital/dp-filter-api/api/mock"
+	"github.com/ONSdigital/dp-filter-api/mock"
+	"github.com/ONSdigital/dp-filter-api/models"
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// ageFilterStore returns a datastore mock whose filter blueprint only has an age dimension
+func ageFilterStore() *apimock.DataStoreMock {
+	ds := mock.NewDataStore().Mock
+	ds.GetFilterFunc = func(ctx context.Context, filterID string, eTagSelector string) (*models.Filter, error) {
+		return &models.Filter{
+			FilterID:   filterID,
+			Dataset:    &models.Dataset{ID: "123", Edition: "2017", Version: 1},
+			InstanceID: "12345678",
+			Published:  &models.Published,
+			Dimensions: []models.Dimension{{Name: "age", Options: []string{"33"}}},
+			ETag:       testETag,
+		}, nil
+	}
+	return ds
+}
+
+func TestPatchFilterBlueprint(t *testing.T) {
+	t.Parallel()
+
+	filterFlexAPIMock := &apimock.FilterFlexAPIMock{}
+
+	patchFilter := func(filterAPI *api.FilterAPI, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r, err := http.NewRequest("PATCH", "http://localhost:22100/filters/21312", strings.NewReader(body))
+		So(err, ShouldBeNil)
+		r.Header.Set("If-Match", testETag)
+		filterAPI.Router.ServeHTTP(w, r)
+		return w
+	}
+
+	Convey("Given a filter blueprint with age, time and 1_age dimensions", t, func() {
+		ds := mock.NewDataStore().Mock
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), ds, &mock.FilterJob{}, mock.NewDatasetAPI().Mock, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+
+		Convey("When a patch with test, add and remove operations is applied", func() {
+			w := patchFilter(filterAPI, `[
+				{"op": "test", "path": "/dataset/version", "value": 1},
+				{"op": "add", "path": "/dimensions/age/options/-", "value": ["27"]},
+				{"op": "remove", "path": "/dimensions/time"}
+			]`)
+
+			Convey("Then all the operations are applied within a transaction, storing the filter blueprint once", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Header().Get("ETag"), ShouldNotBeEmpty)
+				So(ds.RunTransactionCalls(), ShouldHaveLength, 1)
+				So(ds.ReplaceFilterCalls(), ShouldHaveLength, 1)
+
+				patched := ds.ReplaceFilterCalls()[0].UpdatedFilter
+				So(patched.Dimensions, ShouldHaveLength, 2)
+				So(patched.Dimensions[0].Name, ShouldEqual, "age")
+				So(patched.Dimensions[0].Options, ShouldResemble, []string{"33", "27"})
+				So(patched.Dimensions[1].Name, ShouldEqual, "1_age")
+			})
+		})
+
+		Convey("When a patch with a failing test operation is applied", func() {
+			w := patchFilter(filterAPI, `[
+				{"op": "remove", "path": "/dimensions/time"},
+				{"op": "test", "path": "/dimensions/age", "value": {"options": ["27"]}}
+			]`)
+
+			Convey("Then the response is 409 conflict and no operation is applied", func() {
+				So(w.Code, ShouldEqual, http.StatusConflict)
+				So(problemDetail(w.Body.String()), ShouldEqual, models.ErrPatchTestFailed.Error())
+				So(ds.ReplaceFilterCalls(), ShouldHaveLength, 0)
+			})
+		})
+
+		Convey("When a patch adds an option that is not in the dataset dimension", func() {
+			w := patchFilter(filterAPI, `[
+				{"op": "remove", "path": "/dimensions/time"},
+				{"op": "add", "path": "/dimensions/age/options/-", "value": ["99"]}
+			]`)
+
+			Convey("Then the response is 400 bad request and no operation is applied", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(problemErrors(w.Body.String()), ShouldResemble, validationErrorsResponse(models.NewOptionsNotFoundError("age", []string{"99"}, nil)))
+				So(ds.ReplaceFilterCalls(), ShouldHaveLength, 0)
+			})
+		})
+
+		Convey("When a patch removes a dimension that is not in the filter blueprint", func() {
+			w := patchFilter(filterAPI, `[{"op": "remove", "path": "/dimensions/sex"}]`)
+
+			Convey("Then the response is 404 not found", func() {
+				So(w.Code, ShouldEqual, http.StatusNotFound)
+				So(ds.ReplaceFilterCalls(), ShouldHaveLength, 0)
+			})
+		})
+	})
+
+	Convey("Given a filter blueprint with an age dimension", t, func() {
+		ds := ageFilterStore()
+		datasetAPIMock := mock.NewDatasetAPI().Mock
+		datasetAPIMock.GetVersionDimensionsFunc = func(ctx context.Context, userAuthToken string, serviceAuthToken string, collectionID string, id string, edition string, version string) (dataset.VersionDimensions, error) {
+			return dataset.VersionDimensions{Items: []dataset.VersionDimension{{Name: "age"}, {Name: "age_group"}}}, nil
+		}
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), ds, &mock.FilterJob{}, datasetAPIMock, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+
+		Convey("When the dimension is replaced", func() {
+			w := patchFilter(filterAPI, `[{"op": "replace", "path": "/dimensions/age", "value": {"mode": "exclude", "options": ["27"]}}]`)
+
+			Convey("Then the selection of the dimension is replaced", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(ds.ReplaceFilterCalls(), ShouldHaveLength, 1)
+
+				patched := ds.ReplaceFilterCalls()[0].UpdatedFilter
+				So(patched.Dimensions, ShouldHaveLength, 1)
+				So(patched.Dimensions[0].Mode, ShouldEqual, models.DimensionModeExclude)
+				So(patched.Dimensions[0].Options, ShouldResemble, []string{"27"})
+			})
+		})
+
+		Convey("When the dimension is moved to another dimension of the dataset", func() {
+			w := patchFilter(filterAPI, `[{"op": "move", "from": "/dimensions/age", "path": "/dimensions/age_group"}]`)
+
+			Convey("Then the dimension is renamed, keeping its options", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+
+				patched := ds.ReplaceFilterCalls()[0].UpdatedFilter
+				So(patched.Dimensions, ShouldHaveLength, 1)
+				So(patched.Dimensions[0].Name, ShouldEqual, "age_group")
+				So(patched.Dimensions[0].Options, ShouldResemble, []string{"33"})
+				So(patched.Dimensions[0].URL, ShouldEqual, hostURL.String()+"/filters/21312/dimensions/age_group")
+			})
+		})
+
+		Convey("When the dimension is moved to a dimension that is not in the dataset", func() {
+			w := patchFilter(filterAPI, `[{"op": "move", "from": "/dimensions/age", "path": "/dimensions/sex"}]`)
+
+			Convey("Then the response is 400 bad request and no operation is applied", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(ds.ReplaceFilterCalls(), ShouldHaveLength, 0)
+			})
+		})
+
+		Convey("When the dataset version is replaced", func() {
+			w := patchFilter(filterAPI, `[{"op": "replace", "path": "/dataset/version", "value": 2}]`)
+
+			Convey("Then the filter blueprint is moved onto the new version, validating its dimensions", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(datasetAPIMock.GetVersionCalls(), ShouldHaveLength, 1)
+				So(datasetAPIMock.GetVersionCalls()[0].Version, ShouldEqual, "2")
+
+				patched := ds.ReplaceFilterCalls()[0].UpdatedFilter
+				So(patched.Dataset.Version, ShouldEqual, 2)
+				So(*patched.Published, ShouldBeTrue)
+			})
+		})
+	})
+
+	Convey("Given a filter blueprint", t, func() {
+		ds := mock.NewDataStore().Mock
+		limitedCfg := cfg()
+		limitedCfg.MaxRequestOptions = 2
+		filterAPI := api.Setup(limitedCfg, mux.NewRouter(), ds, &mock.FilterJob{}, mock.NewDatasetAPI().Mock, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+
+		Convey("When a patch provides more option values than the maximum allowed across its operations", func() {
+			w := patchFilter(filterAPI, `[
+				{"op": "add", "path": "/dimensions/age/options/-", "value": ["27"]},
+				{"op": "add", "path": "/dimensions/sex", "value": {"options": ["male", "female"]}}
+			]`)
+
+			Convey("Then the response is 400 bad request", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(problemDetail(w.Body.String()), ShouldEqual, "a maximum of 2 overall option values can be provied in a set of patch operations, which has been exceeded")
+				So(ds.RunTransactionCalls(), ShouldHaveLength, 0)
+			})
+		})
+
+		Convey("When a patch has an operation that is not supported for its path", func() {
+			w := patchFilter(filterAPI, `[{"op": "replace", "path": "/dimensions/age/options/-", "value": ["27"]}]`)
+
+			Convey("Then the response is 400 bad request", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(problemDetail(w.Body.String()), ShouldEqual, "op 'replace' not supported. Supported op(s): [add remove]")
+			})
+		})
+
+		Convey("When a patch has a path that is not supported", func() {
+			w := patchFilter(filterAPI, `[{"op": "replace", "path": "/state", "value": "submitted"}]`)
+
+			Convey("Then the response is 400 bad request", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(w.Body.String(), ShouldContainSubstring, "provided path '/state' not supported")
+			})
+		})
+
+		Convey("When a patch is requested without an If-Match header", func() {
+			w := httptest.NewRecorder()
+			r, err := http.NewRequest("PATCH", "http://localhost:22100/filters/21312", strings.NewReader(`[{"op": "remove", "path": "/dimensions/time"}]`))
+			So(err, ShouldBeNil)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the response is 400 bad request", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(ds.RunTransactionCalls(), ShouldHaveLength, 0)
+			})
+		})
+	})
+}
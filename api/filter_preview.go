@@ -0,0 +1,245 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+
+	datasetAPI "github.com/ONSdigital/dp-api-clients-go/v2/dataset"
+	"github.com/ONSdigital/dp-filter-api/filters"
+	"github.com/ONSdigital/dp-filter-api/models"
+	"github.com/ONSdigital/dp-filter-api/mongo"
+	"github.com/ONSdigital/dp-filter-api/observations"
+	"github.com/ONSdigital/log.go/v2/log"
+	"github.com/gorilla/mux"
+)
+
+const (
+	defaultPreviewRows = 20
+	maxPreviewRows     = 100
+)
+
+func (api *FilterAPI) getFilterBlueprintPreviewHandler(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	filterID := vars["filter_blueprint_id"]
+	logData := log.Data{"filter_blueprint_id": filterID}
+	ctx := r.Context()
+	log.Info(ctx, "previewing filter blueprint output", logData)
+
+	rows, err := getPreviewRows(r)
+	if err != nil {
+		log.Error(ctx, "failed to obtain rows from request query parameters", err, logData)
+		setErrorCode(w, r, err)
+		return
+	}
+	logData["rows"] = rows
+
+	filterBlueprint, err := api.getFilterBlueprint(ctx, filterID, mongo.AnyETag)
+	if err != nil {
+		log.Error(ctx, "unable to get filter blueprint", err, logData)
+		setErrorCode(w, r, err)
+		return
+	}
+
+	preview, err := api.previewFilterOutput(ctx, filterBlueprint, rows)
+	if err != nil {
+		log.Error(ctx, "unable to preview filter blueprint output", err, logData)
+		setErrorCode(w, r, err)
+		return
+	}
+	logData["count"] = preview.Count
+
+	bytes, err := json.Marshal(preview)
+	if err != nil {
+		log.Error(ctx, "failed to marshal filter blueprint preview into bytes", err, logData)
+		setErrorCode(w, r, err)
+		return
+	}
+
+	setJSONContentType(w)
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(bytes)
+	if err != nil {
+		log.Error(ctx, "failed to write bytes for http response", err, logData)
+		setErrorCode(w, r, err)
+		return
+	}
+
+	log.Info(ctx, "previewed filter blueprint output", logData)
+}
+
+// getPreviewRows returns the number of rows requested for a preview, which must be between 1 and the maximum
+func getPreviewRows(r *http.Request) (int, error) {
+	rowsParameter := r.URL.Query().Get("rows")
+	if rowsParameter == "" {
+		return defaultPreviewRows, nil
+	}
+
+	rows, err := validatePositiveInt(rowsParameter)
+	if err != nil {
+		return 0, err
+	}
+	if rows == 0 || rows > maxPreviewRows {
+		return 0, filters.ErrInvalidQueryParameter
+	}
+	return rows, nil
+}
+
+// previewFilterOutput queries the observations of the dataset version of a filter blueprint, using its current dimension selections.
+// The observations endpoint accepts a single option per dimension, with a '*' wildcard for at most one of them,
+// so the preview varies the first dimension with more than one option selected, and uses the first selected option of the others.
+// The other dimensions with more than one option selected are held fixed, and reported in the preview, which is then partial.
+func (api *FilterAPI) previewFilterOutput(ctx context.Context, filterBlueprint *models.Filter, rows int) (*models.FilterPreview, error) {
+	if api.observationsAPI == nil {
+		return nil, filters.NewUnprocessableEntityErr("previews are not available, as no observations API is configured")
+	}
+
+	datasetDimensions, err := api.getDimensions(ctx, filterBlueprint.Dataset)
+	if err != nil {
+		return nil, err
+	}
+
+	filterDimensions := make(map[string]models.Dimension, len(filterBlueprint.Dimensions))
+	for _, d := range filterBlueprint.Dimensions {
+		filterDimensions[d.Name] = d
+	}
+
+	names := make([]string, 0, len(datasetDimensions.Items))
+	for _, d := range datasetDimensions.Items {
+		names = append(names, d.Name)
+	}
+	preview := models.NewFilterPreview(names)
+
+	query := url.Values{}
+	wildcard := ""
+	var wildcardOptions []string
+	for _, name := range names {
+		selectedOptions, allOptions, err := api.getPreviewOptions(ctx, filterBlueprint.Dataset, name, filterDimensions)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case wildcard == "" && (allOptions || len(selectedOptions) > 1):
+			wildcard = name
+			wildcardOptions = selectedOptions
+			query.Set(name, "*")
+		case allOptions:
+			option, totalCount, err := api.getFirstDimensionOption(ctx, filterBlueprint.Dataset, name)
+			if err != nil {
+				return nil, err
+			}
+			query.Set(name, option)
+			if totalCount > 1 {
+				preview.AddFixedDimension(name)
+			}
+		case len(selectedOptions) == 0:
+			// every option of the dimension is excluded, so the filter output would have no observations
+			return preview, nil
+		default:
+			query.Set(name, selectedOptions[0])
+			if len(selectedOptions) > 1 {
+				preview.AddFixedDimension(name)
+			}
+		}
+	}
+
+	result, err := api.observationsAPI.GetObservations(ctx,
+		getUserAuthToken(ctx),
+		api.serviceAuthToken,
+		getCollectionID(ctx),
+		filterBlueprint.Dataset.ID,
+		filterBlueprint.Dataset.Edition,
+		strconv.Itoa(filterBlueprint.Dataset.Version),
+		query)
+	if err != nil {
+		if apiErr, ok := err.(*observations.ErrInvalidObservationsResponse); ok {
+			if apiErr.Code() == http.StatusNotFound {
+				return preview, nil
+			}
+		}
+		return nil, err
+	}
+
+	for _, observation := range result.Observations {
+		if preview.Count == rows {
+			break
+		}
+
+		wildcardOption := ""
+		if option := observation.Dimensions[wildcard]; option != nil {
+			wildcardOption = option.ID
+		}
+
+		// the wildcard returns every option of the dimension, which may include the ones that are not selected
+		if wildcardOptions != nil && !slices.Contains(wildcardOptions, wildcardOption) {
+			continue
+		}
+
+		options := make([]string, len(names))
+		for i, name := range names {
+			options[i] = query.Get(name)
+			if name == wildcard {
+				options[i] = wildcardOption
+			}
+		}
+		preview.AddRow(observation.Observation, options)
+	}
+
+	return preview, nil
+}
+
+// getPreviewOptions returns the options selected for a dimension of a filter blueprint,
+// or true if every option is selected, as the dimension is not in the filter or has no options selected
+func (api *FilterAPI) getPreviewOptions(ctx context.Context, dataset *models.Dataset, name string, filterDimensions map[string]models.Dimension) ([]string, bool, error) {
+	dimension, ok := filterDimensions[name]
+	if !ok || (len(dimension.Options) == 0 && !dimension.HasRanges()) {
+		return nil, true, nil
+	}
+
+	selectedOptions, err := api.getSelectedOptions(ctx, dataset, dimension)
+	if err != nil {
+		return nil, false, err
+	}
+	return selectedOptions, false, nil
+}
+
+// getFirstDimensionOption returns the code of the first option of a dataset dimension, requesting a single option,
+// and the total number of options of the dimension
+func (api *FilterAPI) getFirstDimensionOption(ctx context.Context, dataset *models.Dataset, dimensionName string) (string, int, error) {
+	option := ""
+	totalCount := 0
+	processBatch := func(batch datasetAPI.Options) (abort bool, err error) {
+		if len(batch.Items) > 0 {
+			option = batch.Items[0].Option
+		}
+		totalCount = batch.TotalCount
+		return true, nil
+	}
+
+	err := api.datasetAPI.GetOptionsBatchProcess(ctx,
+		getUserAuthToken(ctx),
+		api.serviceAuthToken,
+		getCollectionID(ctx),
+		dataset.ID,
+		dataset.Edition,
+		strconv.Itoa(dataset.Version),
+		dimensionName,
+		nil,
+		processBatch,
+		1,
+		1)
+	if err != nil {
+		if apiErr, ok := err.(*datasetAPI.ErrInvalidDatasetAPIResponse); ok {
+			if apiErr.Code() == http.StatusNotFound {
+				return "", 0, filters.ErrDimensionOptionsNotFound
+			}
+		}
+		return "", 0, err
+	}
+
+	return option, totalCount, nil
+}
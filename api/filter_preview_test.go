@@ -0,0 +1,171 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ONSdigital/dp-api-clients-go/v2/dataset"
+	"github.com/ONSdigital/dp-filter-api/api"
+	apimock "github.com/ONSdigital/dp-filter-api/api/mock"
+	"github.com/ONSdigital/dp-filter-api/mock"
+	"github.com/ONSdigital/dp-filter-api/models"
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// previewObservationsAPI returns an observations API fake holding the observations of the age, sex and time dimensions
+func previewObservationsAPI() *mock.ObservationsAPI {
+	cell := func(age, sex, time, value string) mock.ObservationCell {
+		return mock.ObservationCell{Options: map[string]string{"age": age, "sex": sex, "time": time}, Value: value}
+	}
+	return mock.NewObservationsAPI([]mock.ObservationCell{
+		cell("33", "female", "2014", "10"),
+		cell("33", "male", "2014", "11"),
+		cell("33", "female", "2015", "12"),
+		cell("33", "male", "2015", "13"),
+		cell("33", "female", "2016", "14"),
+		cell("27", "female", "2014", "15"),
+	})
+}
+
+func TestGetFilterBlueprintPreview(t *testing.T) {
+	t.Parallel()
+
+	filterFlexAPIMock := &apimock.FilterFlexAPIMock{}
+
+	Convey("Given a filter blueprint for a dataset with a dimension that is not in the filter", t, func() {
+		ds := mock.NewDataStore().Mock
+		observationsAPI := previewObservationsAPI()
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), ds, &mock.FilterJob{}, estimateDatasetAPIMock(), filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false, api.WithObservationsAPI(observationsAPI.Mock))
+		w := httptest.NewRecorder()
+
+		Convey("When the filter output is previewed", func() {
+			r, err := http.NewRequest("GET", "http://localhost:22100/filters/12345678/preview", http.NoBody)
+			So(err, ShouldBeNil)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the observations are returned varying the first dimension with more than one option, and the other dimension with more than one option is reported as fixed", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+
+				var preview models.FilterPreview
+				So(json.Unmarshal(w.Body.Bytes(), &preview), ShouldBeNil)
+				So(preview.Headers, ShouldResemble, []string{"observation", "age", "sex", "time"})
+				So(preview.Rows, ShouldResemble, [][]string{
+					{"10", "33", "female", "2014"},
+					{"11", "33", "male", "2014"},
+				})
+				So(preview.Count, ShouldEqual, 2)
+				So(preview.Partial, ShouldBeTrue)
+				So(preview.FixedDimensions, ShouldResemble, []string{"time"})
+
+				So(observationsAPI.Mock.GetObservationsCalls(), ShouldHaveLength, 1)
+				So(observationsAPI.Mock.GetObservationsCalls()[0].DatasetID, ShouldEqual, "123")
+				So(observationsAPI.Mock.GetObservationsCalls()[0].Query.Encode(), ShouldEqual, "age=33&sex=%2A&time=2014")
+			})
+
+			Convey("Then neither a filter output nor a filter job is created", func() {
+				So(ds.CreateFilterOutputCalls(), ShouldHaveLength, 0)
+				So(ds.UpdateFilterCalls(), ShouldHaveLength, 0)
+			})
+		})
+
+		Convey("When the filter output is previewed with a number of rows", func() {
+			r, err := http.NewRequest("GET", "http://localhost:22100/filters/12345678/preview?rows=1", http.NoBody)
+			So(err, ShouldBeNil)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then no more than the requested rows are returned", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+
+				var preview models.FilterPreview
+				So(json.Unmarshal(w.Body.Bytes(), &preview), ShouldBeNil)
+				So(preview.Rows, ShouldResemble, [][]string{{"10", "33", "female", "2014"}})
+				So(preview.Count, ShouldEqual, 1)
+			})
+		})
+
+		for _, rows := range []string{"0", "-1", "abc", "101"} {
+			Convey("When the filter output is previewed with an invalid number of rows: "+rows, func() {
+				r, err := http.NewRequest("GET", "http://localhost:22100/filters/12345678/preview?rows="+rows, http.NoBody)
+				So(err, ShouldBeNil)
+				filterAPI.Router.ServeHTTP(w, r)
+
+				Convey("Then the response is 400 bad request", func() {
+					So(w.Code, ShouldEqual, http.StatusBadRequest)
+					So(problemDetail(w.Body.String()), ShouldEqual, invalidQueryParameterResponse)
+					So(observationsAPI.Mock.GetObservationsCalls(), ShouldHaveLength, 0)
+				})
+			})
+		}
+	})
+
+	Convey("Given a filter blueprint whose first dimension has some of its options selected", t, func() {
+		ds := mock.NewDataStore().Mock
+		ds.GetFilterFunc = func(ctx context.Context, filterID string, eTagSelector string) (*models.Filter, error) {
+			return &models.Filter{
+				FilterID:   filterID,
+				Dataset:    &models.Dataset{ID: "123", Edition: "2017", Version: 1},
+				InstanceID: "12345678",
+				Published:  &models.Published,
+				Dimensions: []models.Dimension{
+					{Name: "time", Options: []string{"2014", "2015"}},
+					{Name: "age", Options: []string{"33"}},
+				},
+				ETag: testETag,
+			}, nil
+		}
+		datasetAPIMock := &apimock.DatasetAPIMock{
+			GetVersionDimensionsFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, collectionID string, id string, edition string, version string) (dataset.VersionDimensions, error) {
+				return dataset.VersionDimensions{Items: []dataset.VersionDimension{{Name: "time"}, {Name: "age"}, {Name: "sex"}}}, nil
+			},
+			GetOptionsBatchProcessFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, collectionID string, id string, edition string, version string, dimension string, optionIDs *[]string, processBatch dataset.OptionsBatchProcessor, batchSize int, maxWorkers int) error {
+				_, err := processBatch(dataset.Options{Items: []dataset.Option{{Option: "female"}}, Count: 1, TotalCount: 2})
+				return err
+			},
+		}
+		observationsAPI := previewObservationsAPI()
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), ds, &mock.FilterJob{}, datasetAPIMock, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false, api.WithObservationsAPI(observationsAPI.Mock))
+		w := httptest.NewRecorder()
+
+		Convey("When the filter output is previewed", func() {
+			r, err := http.NewRequest("GET", "http://localhost:22100/filters/12345678/preview", http.NoBody)
+			So(err, ShouldBeNil)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then only the observations of the selected options are returned, using the first option of the dimension not in the filter, which is reported as fixed", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+
+				var preview models.FilterPreview
+				So(json.Unmarshal(w.Body.Bytes(), &preview), ShouldBeNil)
+				So(preview.Headers, ShouldResemble, []string{"observation", "time", "age", "sex"})
+				So(preview.Rows, ShouldResemble, [][]string{
+					{"10", "2014", "33", "female"},
+					{"12", "2015", "33", "female"},
+				})
+				So(preview.Partial, ShouldBeTrue)
+				So(preview.FixedDimensions, ShouldResemble, []string{"sex"})
+
+				So(datasetAPIMock.GetOptionsBatchProcessCalls(), ShouldHaveLength, 1)
+				So(datasetAPIMock.GetOptionsBatchProcessCalls()[0].Dimension, ShouldEqual, "sex")
+			})
+		})
+	})
+
+	Convey("Given a filter blueprint that does not exist", t, func() {
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), mock.NewDataStore().NotFound().Mock, &mock.FilterJob{}, estimateDatasetAPIMock(), filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false, api.WithObservationsAPI(previewObservationsAPI().Mock))
+		w := httptest.NewRecorder()
+
+		Convey("When the filter output is previewed", func() {
+			r, err := http.NewRequest("GET", "http://localhost:22100/filters/12345678/preview", http.NoBody)
+			So(err, ShouldBeNil)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the response is 404 not found", func() {
+				So(w.Code, ShouldEqual, http.StatusNotFound)
+			})
+		})
+	})
+}
@@ -0,0 +1,451 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	datasetAPI "github.com/ONSdigital/dp-api-clients-go/v2/dataset"
+	"github.com/ONSdigital/dp-filter-api/filters"
+	"github.com/ONSdigital/dp-filter-api/models"
+	dphttp "github.com/ONSdigital/dp-net/http"
+	dprequest "github.com/ONSdigital/dp-net/request"
+	"github.com/ONSdigital/log.go/v2/log"
+	"github.com/gorilla/mux"
+)
+
+const latestVersion = "latest"
+
+func (api *FilterAPI) postFilterBlueprintRebaseHandler(w http.ResponseWriter, r *http.Request) {
+	defer dphttp.DrainBody(r)
+
+	vars := mux.Vars(r)
+	filterID := vars["filter_blueprint_id"]
+	to := r.URL.Query().Get("to")
+	strictParam := r.URL.Query().Get("strict")
+	logData := log.Data{"filter_blueprint_id": filterID, "to": to, "strict": strictParam}
+	ctx := r.Context()
+	log.Info(ctx, "rebasing filter blueprint", logData)
+
+	// eTag value must be present in If-Match header
+	eTag, err := getIfMatchForce(r)
+	if err != nil {
+		log.Error(ctx, "missing header", err, log.Data{"error": err.Error()})
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if to == "" {
+		to = latestVersion
+	}
+
+	targetVersion := 0
+	if to != latestVersion {
+		targetVersion, err = strconv.Atoi(to)
+		if err != nil || targetVersion < 1 {
+			log.Error(ctx, "invalid target version", filters.ErrInvalidQueryParameter, logData)
+			setErrorCode(w, r, filters.ErrInvalidQueryParameter)
+			return
+		}
+	}
+
+	strict := false
+	if strictParam != "" {
+		strict, err = strconv.ParseBool(strictParam)
+		if err != nil {
+			log.Error(ctx, "invalid strict query parameter", err, logData)
+			setErrorCode(w, r, filters.ErrInvalidQueryParameter)
+			return
+		}
+	}
+
+	newFilter, report, err := api.rebaseFilterBlueprint(ctx, filterID, targetVersion, strict, eTag)
+	if err == filters.ErrLossyRebase {
+		log.Info(ctx, "refusing lossy rebase in strict mode", logData)
+		writeRebaseReport(ctx, w, r, http.StatusUnprocessableEntity, report, logData)
+		return
+	}
+	if err != nil {
+		log.Error(ctx, "failed to rebase filter blueprint", err, logData)
+		setErrorCode(w, r, err)
+		return
+	}
+	logData["report"] = report
+	log.Info(ctx, "filter blueprint rebased", logData)
+
+	bytes, err := json.Marshal(models.RebaseResponse{Filter: newFilter, Report: report})
+	if err != nil {
+		log.Error(ctx, "failed to marshal rebased filter blueprint into bytes", err, logData)
+		setErrorCode(w, r, err)
+		return
+	}
+
+	setJSONContentType(w)
+	setETag(w, newFilter.ETag)
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(bytes)
+	if err != nil {
+		log.Error(ctx, "failed to write bytes for http response", err, logData)
+		setErrorCode(w, r, err)
+		return
+	}
+}
+
+func writeRebaseReport(ctx context.Context, w http.ResponseWriter, r *http.Request, status int, report *models.RebaseReport, logData log.Data) {
+	bytes, err := json.Marshal(report)
+	if err != nil {
+		log.Error(ctx, "failed to marshal rebase report into bytes", err, logData)
+		setErrorCode(w, r, err)
+		return
+	}
+
+	setJSONContentType(w)
+	w.WriteHeader(status)
+	if _, err = w.Write(bytes); err != nil {
+		log.Error(ctx, "failed to write bytes for http response", err, logData)
+	}
+}
+
+// rebaseFilterBlueprint moves a filter blueprint onto the provided version of its dataset edition, or the latest published one if no version is provided.
+// Dimensions and options are carried over where possible, and the returned report describes anything that was renamed or dropped.
+// In strict mode, a rebase that would drop any dimension or option is refused with ErrLossyRebase, and the report is still returned.
+func (api *FilterAPI) rebaseFilterBlueprint(ctx context.Context, filterID string, targetVersion int, strict bool, eTag string) (*models.Filter, *models.RebaseReport, error) {
+	logData := log.Data{"filter_blueprint_id": filterID, "target_version": targetVersion, "strict": strict}
+
+	currentFilter, err := api.getFilterBlueprint(ctx, filterID, eTag)
+	if err != nil {
+		log.Error(ctx, "unable to get filter blueprint", err, logData)
+		return nil, nil, err
+	}
+
+	if targetVersion == 0 {
+		targetVersion, err = api.getLatestVersion(ctx, currentFilter.Dataset)
+		if err != nil {
+			log.Error(ctx, "unable to resolve latest published version", err, logData)
+			return nil, nil, err
+		}
+		logData["target_version"] = targetVersion
+	}
+
+	report := &models.RebaseReport{
+		FromVersion: currentFilter.Dataset.Version,
+		ToVersion:   targetVersion,
+		Dimensions:  []models.RebaseDimension{},
+	}
+
+	// nothing to rebase, all dimensions are kept as they are
+	if targetVersion == currentFilter.Dataset.Version {
+		for _, d := range currentFilter.Dimensions {
+			report.Dimensions = append(report.Dimensions, models.RebaseDimension{Name: d.Name, Status: models.RebaseDimensionKept})
+		}
+		return currentFilter, report, nil
+	}
+
+	targetDataset := &models.Dataset{ID: currentFilter.Dataset.ID, Edition: currentFilter.Dataset.Edition, Version: targetVersion}
+	version, err := api.getVersion(ctx, targetDataset)
+	if err != nil {
+		log.Error(ctx, "unable to retrieve target version document", err, logData)
+		return nil, nil, err
+	}
+
+	if version.State != publishedState && !dprequest.IsCallerPresent(ctx) {
+		log.Info(ctx, "unauthenticated request to rebase filter onto unpublished version", log.Data{"dataset": *targetDataset, "state": version.State})
+		return nil, nil, filters.ErrVersionNotFound
+	}
+
+	dimensions, err := api.rebaseDimensions(ctx, currentFilter, targetDataset, report)
+	if err != nil {
+		log.Error(ctx, "unable to rebase filter dimensions", err, logData)
+		return nil, nil, err
+	}
+	report.Lossy = report.IsLossy()
+	logData["report"] = report
+
+	if strict && report.Lossy {
+		return nil, report, filters.ErrLossyRebase
+	}
+
+	newFilter := *currentFilter
+	newFilter.Dataset = targetDataset
+	newFilter.Dimensions = dimensions
+	newFilter.InstanceID = version.ID
+	newFilter.Published = &models.Unpublished
+	if version.State == publishedState {
+		newFilter.Published = &models.Published
+	}
+	newFilter.Links.Version = &models.LinkObject{
+		HRef: version.Links.Self.URL,
+		ID:   strconv.Itoa(targetVersion),
+	}
+
+	newFilter.ETag, err = api.dataStore.ReplaceFilter(ctx, &newFilter, currentFilter.UniqueTimestamp, eTag, currentFilter)
+	if err != nil {
+		log.Error(ctx, "unable to update rebased filter blueprint", err, logData)
+		return nil, nil, err
+	}
+
+	return &newFilter, report, nil
+}
+
+// rebaseDimensions maps the dimensions of the current filter onto the dimensions of the target dataset version,
+// populating the provided report with the outcome for each dimension
+func (api *FilterAPI) rebaseDimensions(ctx context.Context, currentFilter *models.Filter, targetDataset *models.Dataset, report *models.RebaseReport) ([]models.Dimension, error) {
+	currentDimensions, err := api.getDimensions(ctx, currentFilter.Dataset)
+	if err != nil {
+		return nil, err
+	}
+
+	targetDimensions, err := api.getDimensions(ctx, targetDataset)
+	if err != nil {
+		return nil, err
+	}
+
+	// index target dimensions by name and by code list, so that renamed dimensions can be found
+	targetByName := make(map[string]datasetAPI.VersionDimension, len(targetDimensions.Items))
+	targetByCodeList := make(map[string]datasetAPI.VersionDimension, len(targetDimensions.Items))
+	for _, d := range targetDimensions.Items {
+		targetByName[d.Name] = d
+		if d.ID != "" {
+			targetByCodeList[d.ID] = d
+		}
+	}
+
+	currentCodeLists := make(map[string]string, len(currentDimensions.Items))
+	for _, d := range currentDimensions.Items {
+		currentCodeLists[d.Name] = d.ID
+	}
+
+	// names already used by the filter cannot be the target of a rename
+	used := make(map[string]bool, len(currentFilter.Dimensions))
+	for _, d := range currentFilter.Dimensions {
+		used[d.Name] = true
+	}
+
+	dimensions := []models.Dimension{}
+	for _, d := range currentFilter.Dimensions {
+		result := models.RebaseDimension{Name: d.Name, Status: models.RebaseDimensionKept}
+
+		target, found := targetByName[d.Name]
+		if !found {
+			if t, ok := targetByCodeList[currentCodeLists[d.Name]]; ok && currentCodeLists[d.Name] != "" && !used[t.Name] {
+				target, found = t, true
+				used[t.Name] = true
+				result.Status = models.RebaseDimensionRenamed
+				result.NewName = t.Name
+			}
+		}
+
+		if !found {
+			result.Status = models.RebaseDimensionDropped
+			report.Dimensions = append(report.Dimensions, result)
+			continue
+		}
+
+		options, err := api.rebaseOptions(ctx, d, currentFilter.Dataset, target.Name, targetDataset, &result)
+		if err != nil {
+			return nil, err
+		}
+
+		ranges, err := api.rebaseRanges(ctx, d, currentFilter.Dataset, target.Name, targetDataset, &result)
+		if err != nil {
+			return nil, err
+		}
+
+		// a dimension that had a selection which was entirely dropped cannot be kept.
+		// In exclude mode, dropping every excluded option selects the whole dimension instead.
+		hadSelection := len(d.Options) > 0 || d.HasRanges()
+		if hadSelection && len(options) == 0 && len(ranges) == 0 && !d.IsExclude() {
+			result.Status = models.RebaseDimensionDropped
+			report.Dimensions = append(report.Dimensions, result)
+			continue
+		}
+
+		report.Dimensions = append(report.Dimensions, result)
+		dimensions = append(dimensions, models.Dimension{
+			URL:        fmt.Sprintf("%s/filters/%s/dimensions/%s", api.host, currentFilter.FilterID, target.Name),
+			Name:       target.Name,
+			Mode:       d.Mode,
+			Options:    options,
+			Ranges:     ranges,
+			IsAreaType: d.IsAreaType,
+		})
+	}
+
+	return dimensions, nil
+}
+
+// rebaseOptions returns the options of a filter dimension that are available in the target dimension.
+// Options that are missing by code, but present with the same label, are renamed; any other missing options are dropped.
+func (api *FilterAPI) rebaseOptions(ctx context.Context, dimension models.Dimension, currentDataset *models.Dataset, targetName string, targetDataset *models.Dataset, result *models.RebaseDimension) ([]string, error) {
+	if len(dimension.Options) == 0 {
+		return []string{}, nil
+	}
+
+	targetOptions, err := api.getAllDimensionOptions(ctx, targetDataset, targetName)
+	if err != nil {
+		return nil, err
+	}
+
+	missing := []string{}
+	options := []string{}
+	for _, option := range dimension.Options {
+		if _, ok := targetOptions[option]; ok {
+			options = append(options, option)
+			continue
+		}
+		missing = append(missing, option)
+	}
+
+	if len(missing) == 0 {
+		return options, nil
+	}
+
+	// find the labels of the missing options, so that they can be matched against the target options
+	currentOptions, err := api.getAllDimensionOptions(ctx, currentDataset, dimension.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	// if more than one target option has the same label, the lowest code is chosen so that the outcome is deterministic
+	targetByLabel := make(map[string]string, len(targetOptions))
+	for code, label := range targetOptions {
+		if label == "" {
+			continue
+		}
+		if existing, exists := targetByLabel[label]; !exists || code < existing {
+			targetByLabel[label] = code
+		}
+	}
+
+	for _, option := range missing {
+		label := currentOptions[option]
+		if newCode, ok := targetByLabel[label]; ok && label != "" {
+			result.RenamedOptions = append(result.RenamedOptions, models.RenamedOption{From: option, To: newCode})
+			options = append(options, newCode)
+			continue
+		}
+		result.DroppedOptions = append(result.DroppedOptions, option)
+	}
+
+	return RemoveDuplicateAndEmptyOptions(options), nil
+}
+
+// rebaseRanges returns the option ranges of a filter dimension that are still valid in the target dimension.
+// Range boundaries are renamed in the same way as options. A range is dropped if any of its boundaries is missing
+// from the target dimension, or if its boundaries are no longer in order.
+func (api *FilterAPI) rebaseRanges(ctx context.Context, dimension models.Dimension, currentDataset *models.Dataset, targetName string, targetDataset *models.Dataset, result *models.RebaseDimension) ([]models.OptionRange, error) {
+	if !dimension.HasRanges() {
+		return nil, nil
+	}
+
+	boundaries := models.Dimension{Name: dimension.Name}
+	for _, r := range dimension.Ranges {
+		boundaries.Options = append(boundaries.Options, r.From, r.To)
+	}
+
+	boundaryResult := models.RebaseDimension{}
+	if _, err := api.rebaseOptions(ctx, boundaries, currentDataset, targetName, targetDataset, &boundaryResult); err != nil {
+		return nil, err
+	}
+
+	renamed := make(map[string]string, len(boundaryResult.RenamedOptions))
+	for _, o := range boundaryResult.RenamedOptions {
+		renamed[o.From] = o.To
+	}
+	dropped := make(map[string]bool, len(boundaryResult.DroppedOptions))
+	for _, o := range boundaryResult.DroppedOptions {
+		dropped[o] = true
+	}
+
+	targetOptions, err := api.getOrderedDimensionOptions(ctx, targetDataset, targetName)
+	if err != nil {
+		return nil, err
+	}
+
+	ranges := []models.OptionRange{}
+	for _, r := range dimension.Ranges {
+		if dropped[r.From] || dropped[r.To] {
+			result.DroppedRanges = append(result.DroppedRanges, r)
+			continue
+		}
+
+		newRange := r
+		if code, ok := renamed[r.From]; ok {
+			newRange.From = code
+		}
+		if code, ok := renamed[r.To]; ok {
+			newRange.To = code
+		}
+
+		if _, _, err := newRange.Resolve(targetOptions); err != nil {
+			result.DroppedRanges = append(result.DroppedRanges, r)
+			continue
+		}
+
+		if newRange != r {
+			result.RenamedRanges = append(result.RenamedRanges, models.RenamedRange{From: r, To: newRange})
+		}
+		ranges = append(ranges, newRange)
+	}
+
+	return ranges, nil
+}
+
+// getAllDimensionOptions returns a map of option codes to labels for all the options of a dimension in a dataset version
+func (api *FilterAPI) getAllDimensionOptions(ctx context.Context, dataset *models.Dataset, dimensionName string) (map[string]string, error) {
+	labels := map[string]string{}
+
+	processBatch := func(batch datasetAPI.Options) (abort bool, err error) {
+		for _, opt := range batch.Items {
+			labels[opt.Option] = opt.Label
+		}
+		return false, nil
+	}
+
+	err := api.datasetAPI.GetOptionsBatchProcess(ctx,
+		getUserAuthToken(ctx),
+		api.serviceAuthToken,
+		getCollectionID(ctx),
+		dataset.ID,
+		dataset.Edition,
+		strconv.Itoa(dataset.Version),
+		dimensionName,
+		nil,
+		processBatch,
+		api.maxDatasetOptions,
+		api.BatchMaxWorkers)
+	if err != nil {
+		if apiErr, ok := err.(*datasetAPI.ErrInvalidDatasetAPIResponse); ok {
+			if apiErr.Code() == http.StatusNotFound {
+				return nil, filters.ErrDimensionOptionsNotFound
+			}
+		}
+		return nil, err
+	}
+
+	return labels, nil
+}
+
+// getLatestVersion resolves the latest published version of the edition of the provided dataset.
+// The edition is requested without any auth token, as an authorised request is answered with the next state of the edition,
+// whose latest version may not have been published yet.
+func (api *FilterAPI) getLatestVersion(ctx context.Context, dataset *models.Dataset) (int, error) {
+	edition, err := api.datasetAPI.GetEdition(ctx, "", "", "", dataset.ID, dataset.Edition)
+	if err != nil {
+		if apiErr, ok := err.(*datasetAPI.ErrInvalidDatasetAPIResponse); ok {
+			if apiErr.Code() == http.StatusNotFound {
+				return 0, filters.ErrVersionNotFound
+			}
+		}
+		return 0, err
+	}
+
+	latest, err := strconv.Atoi(edition.Links.LatestVersion.ID)
+	if err != nil {
+		return 0, filters.ErrVersionNotFound
+	}
+
+	return latest, nil
+}
@@ -0,0 +1,326 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ONSdigital/dp-api-clients-go/v2/dataset"
+	"github.com/ONSdigital/dp-filter-api/api"
+	apimock "github.com/ONSdigital/dp-filter-api/api/mock"
+	"github.com/ONSdigital/dp-filter-api/filters"
+	"github.com/ONSdigital/dp-filter-api/mock"
+	"github.com/ONSdigital/dp-filter-api/models"
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// rebaseDatasetAPIMock returns a dataset API mock where version 2 renames the 'geography' dimension to 'area',
+// renames the age option '33' to '33b' and removes the area option 'K1'
+func rebaseDatasetAPIMock() *apimock.DatasetAPIMock {
+	dimensions := map[string][]dataset.VersionDimension{
+		"1": {{Name: "age", ID: "age-list"}, {Name: "geography", ID: "geo-list"}},
+		"2": {{Name: "age", ID: "age-list"}, {Name: "area", ID: "geo-list"}},
+	}
+	options := map[string][]dataset.Option{
+		"1/age":       {{Option: "27", Label: "27 years"}, {Option: "33", Label: "33 years"}},
+		"1/geography": {{Option: "K1", Label: "Wales"}, {Option: "K2", Label: "England"}},
+		"2/age":       {{Option: "27", Label: "27 years"}, {Option: "33b", Label: "33 years"}},
+		"2/area":      {{Option: "K2", Label: "England"}},
+	}
+
+	return &apimock.DatasetAPIMock{
+		GetEditionFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, collectionID string, datasetID string, edition string) (dataset.Edition, error) {
+			// an authorised request is answered with the next state of the edition, whose latest version is not published yet
+			if userAuthToken != "" || serviceAuthToken != "" {
+				return dataset.Edition{Edition: edition, State: "edition-confirmed", Links: dataset.Links{LatestVersion: dataset.Link{ID: "3"}}}, nil
+			}
+			return dataset.Edition{Edition: edition, State: "published", Links: dataset.Links{LatestVersion: dataset.Link{ID: "2"}}}, nil
+		},
+		GetVersionFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, downloadServiceAuthToken string, collectionID string, datasetID string, edition string, version string) (dataset.Version, error) {
+			return dataset.Version{ID: "instance-" + version, State: "published"}, nil
+		},
+		GetVersionDimensionsFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, collectionID string, id string, edition string, version string) (dataset.VersionDimensions, error) {
+			return dataset.VersionDimensions{Items: dimensions[version]}, nil
+		},
+		GetOptionsBatchProcessFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, collectionID string, id string, edition string, version string, dimension string, optionIDs *[]string, processBatch dataset.OptionsBatchProcessor, batchSize int, maxWorkers int) error {
+			items := options[version+"/"+dimension]
+			_, err := processBatch(dataset.Options{Items: items, Count: len(items), TotalCount: len(items)})
+			return err
+		},
+	}
+}
+
+func rebaseDataStoreMock() *apimock.DataStoreMock {
+	return &apimock.DataStoreMock{
+		GetFilterFunc: func(ctx context.Context, filterID string, eTagSelector string) (*models.Filter, error) {
+			return &models.Filter{
+				FilterID:   filterID,
+				Dataset:    &models.Dataset{ID: "123", Edition: "2017", Version: 1},
+				InstanceID: "instance-1",
+				Published:  &models.Published,
+				Dimensions: []models.Dimension{
+					{Name: "age", Options: []string{"27", "33"}},
+					{Name: "geography", Options: []string{"K1", "K2"}},
+				},
+				ETag: testETag,
+			}, nil
+		},
+		ReplaceFilterFunc: func(ctx context.Context, updatedFilter *models.Filter, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error) {
+			return testETag1, nil
+		},
+	}
+}
+
+func TestSuccessfulRebaseFilterBlueprint(t *testing.T) {
+	t.Parallel()
+
+	filterFlexAPIMock := &apimock.FilterFlexAPIMock{}
+
+	Convey("Given a filter blueprint for a superseded dataset version", t, func() {
+		w := httptest.NewRecorder()
+		mockDatastore := rebaseDataStoreMock()
+		datasetAPIMock := rebaseDatasetAPIMock()
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), mockDatastore, &mock.FilterJob{}, datasetAPIMock, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+
+		Convey("When a POST request is made to rebase it onto the latest version", func() {
+			r, err := http.NewRequest("POST", cfg().Host+"/filters/21312/rebase?to=latest", http.NoBody)
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag)
+
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the response is 200 OK with the new ETag", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Result().Header.Get("ETag"), ShouldEqual, testETag1)
+			})
+
+			Convey("Then the latest published version is resolved without any auth token, ignoring the unpublished next version", func() {
+				So(datasetAPIMock.GetEditionCalls(), ShouldHaveLength, 1)
+				So(datasetAPIMock.GetEditionCalls()[0].ServiceAuthToken, ShouldEqual, "")
+				So(datasetAPIMock.GetEditionCalls()[0].UserAuthToken, ShouldEqual, "")
+				So(datasetAPIMock.GetEditionCalls()[0].Edition, ShouldEqual, "2017")
+			})
+
+			Convey("Then the filter blueprint is moved to the new version with the mapped dimensions", func() {
+				So(mockDatastore.ReplaceFilterCalls(), ShouldHaveLength, 1)
+				rebased := mockDatastore.ReplaceFilterCalls()[0].UpdatedFilter
+				So(rebased.Dataset.Version, ShouldEqual, 2)
+				So(rebased.InstanceID, ShouldEqual, "instance-2")
+				So(rebased.Links.Version.ID, ShouldEqual, "2")
+				So(rebased.Dimensions, ShouldHaveLength, 2)
+				So(rebased.Dimensions[0].Name, ShouldEqual, "age")
+				So(rebased.Dimensions[0].Options, ShouldResemble, []string{"27", "33b"})
+				So(rebased.Dimensions[1].Name, ShouldEqual, "area")
+				So(rebased.Dimensions[1].Options, ShouldResemble, []string{"K2"})
+			})
+
+			Convey("Then the response contains a report of renamed and dropped dimensions and options", func() {
+				var response models.RebaseResponse
+				So(json.Unmarshal(w.Body.Bytes(), &response), ShouldBeNil)
+				So(response.Filter.Dataset.Version, ShouldEqual, 2)
+				So(response.Report, ShouldResemble, &models.RebaseReport{
+					FromVersion: 1,
+					ToVersion:   2,
+					Lossy:       true,
+					Dimensions: []models.RebaseDimension{
+						{Name: "age", Status: models.RebaseDimensionKept, RenamedOptions: []models.RenamedOption{{From: "33", To: "33b"}}},
+						{Name: "geography", NewName: "area", Status: models.RebaseDimensionRenamed, DroppedOptions: []string{"K1"}},
+					},
+				})
+			})
+		})
+
+		Convey("When a POST request is made to rebase it onto the version it already uses", func() {
+			r, err := http.NewRequest("POST", cfg().Host+"/filters/21312/rebase?to=1", http.NoBody)
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag)
+
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the response is 200 OK and the filter blueprint is not modified", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Result().Header.Get("ETag"), ShouldEqual, testETag)
+				So(mockDatastore.ReplaceFilterCalls(), ShouldHaveLength, 0)
+				So(datasetAPIMock.GetEditionCalls(), ShouldHaveLength, 0)
+			})
+		})
+	})
+}
+
+func TestFailedToRebaseFilterBlueprint(t *testing.T) {
+	t.Parallel()
+
+	filterFlexAPIMock := &apimock.FilterFlexAPIMock{}
+
+	Convey("Given a filter blueprint for a superseded dataset version", t, func() {
+		w := httptest.NewRecorder()
+		mockDatastore := rebaseDataStoreMock()
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), mockDatastore, &mock.FilterJob{}, rebaseDatasetAPIMock(), filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+
+		Convey("When a strict rebase would drop options", func() {
+			r, err := http.NewRequest("POST", cfg().Host+"/filters/21312/rebase?to=latest&strict=true", http.NoBody)
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag)
+
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the response is 422 unprocessable entity, containing the report", func() {
+				So(w.Code, ShouldEqual, http.StatusUnprocessableEntity)
+				var report models.RebaseReport
+				So(json.Unmarshal(w.Body.Bytes(), &report), ShouldBeNil)
+				So(report.Lossy, ShouldBeTrue)
+				So(report.Dimensions[1].DroppedOptions, ShouldResemble, []string{"K1"})
+			})
+
+			Convey("Then the filter blueprint is not modified", func() {
+				So(mockDatastore.ReplaceFilterCalls(), ShouldHaveLength, 0)
+			})
+		})
+
+		Convey("When the target version is not valid", func() {
+			r, err := http.NewRequest("POST", cfg().Host+"/filters/21312/rebase?to=newest", http.NoBody)
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag)
+
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the response is 400 bad request", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(problemDetail(w.Body.String()), ShouldEqual, invalidQueryParameterResponse)
+			})
+		})
+
+		Convey("When the strict parameter is not valid", func() {
+			r, err := http.NewRequest("POST", cfg().Host+"/filters/21312/rebase?strict=maybe", http.NoBody)
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag)
+
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the response is 400 bad request", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(problemDetail(w.Body.String()), ShouldEqual, invalidQueryParameterResponse)
+			})
+		})
+
+		Convey("When no If-Match header is provided", func() {
+			r, err := http.NewRequest("POST", cfg().Host+"/filters/21312/rebase", http.NoBody)
+			So(err, ShouldBeNil)
+
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the response is 400 bad request", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(problemDetail(w.Body.String()), ShouldEqual, filters.ErrNoIfMatchHeader.Error())
+			})
+		})
+
+		Convey("When the If-Match header does not match the current ETag", func() {
+			r, err := http.NewRequest("POST", cfg().Host+"/filters/21312/rebase", http.NoBody)
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", "wrong")
+
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the response is 409 conflict", func() {
+				So(w.Code, ShouldEqual, http.StatusConflict)
+				So(problemDetail(w.Body.String()), ShouldEqual, filerBlueprintConflictResponse)
+			})
+		})
+	})
+	Convey("Given a filter blueprint and an unpublished version of its dataset edition", t, func() {
+		w := httptest.NewRecorder()
+		mockDatastore := rebaseDataStoreMock()
+		datasetAPIMock := rebaseDatasetAPIMock()
+		datasetAPIMock.GetVersionFunc = func(ctx context.Context, userAuthToken string, serviceAuthToken string, downloadServiceAuthToken string, collectionID string, datasetID string, edition string, version string) (dataset.Version, error) {
+			return dataset.Version{ID: "instance-" + version, State: "associated"}, nil
+		}
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), mockDatastore, &mock.FilterJob{}, datasetAPIMock, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+
+		Convey("When an unauthenticated POST request is made to rebase it onto the unpublished version", func() {
+			r, err := http.NewRequest("POST", cfg().Host+"/filters/21312/rebase?to=2", http.NoBody)
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag)
+
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the response is 404 not found, without a report of the unpublished version", func() {
+				So(w.Code, ShouldEqual, http.StatusNotFound)
+				So(decodeProblem(w.Body.String()).Code, ShouldEqual, "version_not_found")
+				So(w.Body.String(), ShouldNotContainSubstring, "dimensions")
+				So(datasetAPIMock.GetVersionDimensionsCalls(), ShouldHaveLength, 0)
+				So(mockDatastore.ReplaceFilterCalls(), ShouldHaveLength, 0)
+			})
+		})
+
+		Convey("When an authenticated POST request is made to rebase it onto the unpublished version", func() {
+			r := createAuthenticatedRequest("POST", cfg().Host+"/filters/21312/rebase?to=2", http.NoBody)
+			r.Header.Set("If-Match", testETag)
+
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the filter blueprint is moved to the unpublished version", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(mockDatastore.ReplaceFilterCalls(), ShouldHaveLength, 1)
+				So(*mockDatastore.ReplaceFilterCalls()[0].UpdatedFilter.Published, ShouldBeFalse)
+			})
+		})
+	})
+}
+
+func TestRebaseFilterBlueprintWithOptionRanges(t *testing.T) {
+	t.Parallel()
+
+	filterFlexAPIMock := &apimock.FilterFlexAPIMock{}
+
+	Convey("Given a filter blueprint with option ranges for a superseded dataset version", t, func() {
+		w := httptest.NewRecorder()
+		mockDatastore := rebaseDataStoreMock()
+		mockDatastore.GetFilterFunc = func(ctx context.Context, filterID string, eTagSelector string) (*models.Filter, error) {
+			return &models.Filter{
+				FilterID:   filterID,
+				Dataset:    &models.Dataset{ID: "123", Edition: "2017", Version: 1},
+				InstanceID: "instance-1",
+				Published:  &models.Published,
+				Dimensions: []models.Dimension{
+					{Name: "age", Ranges: []models.OptionRange{{From: "27", To: "33"}}},
+					{Name: "geography", Options: []string{"K2"}, Ranges: []models.OptionRange{{From: "K1", To: "K2"}}},
+				},
+				ETag: testETag,
+			}, nil
+		}
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), mockDatastore, &mock.FilterJob{}, rebaseDatasetAPIMock(), filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+
+		Convey("When a POST request is made to rebase it onto the latest version", func() {
+			r, err := http.NewRequest("POST", cfg().Host+"/filters/21312/rebase?to=latest", http.NoBody)
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag)
+
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then ranges with renamed boundaries are kept, and ranges with missing boundaries are dropped", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(mockDatastore.ReplaceFilterCalls(), ShouldHaveLength, 1)
+				rebased := mockDatastore.ReplaceFilterCalls()[0].UpdatedFilter
+				So(rebased.Dimensions, ShouldHaveLength, 2)
+				So(rebased.Dimensions[0].Ranges, ShouldResemble, []models.OptionRange{{From: "27", To: "33b"}})
+				So(rebased.Dimensions[1].Options, ShouldResemble, []string{"K2"})
+				So(rebased.Dimensions[1].Ranges, ShouldBeEmpty)
+			})
+
+			Convey("Then the report contains the renamed and dropped ranges", func() {
+				var response models.RebaseResponse
+				So(json.Unmarshal(w.Body.Bytes(), &response), ShouldBeNil)
+				So(response.Report.Lossy, ShouldBeTrue)
+				So(response.Report.Dimensions[0].RenamedRanges, ShouldResemble, []models.RenamedRange{
+					{From: models.OptionRange{From: "27", To: "33"}, To: models.OptionRange{From: "27", To: "33b"}},
+				})
+				So(response.Report.Dimensions[1].DroppedRanges, ShouldResemble, []models.OptionRange{{From: "K1", To: "K2"}})
+			})
+		})
+	})
+}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/ONSdigital/dp-filter-api/filters"
+	"github.com/ONSdigital/dp-filter-api/models"
+	dphttp "github.com/ONSdigital/dp-net/http"
+	"github.com/ONSdigital/log.go/v2/log"
+	"github.com/gorilla/mux"
+)
+
+func (api *FilterAPI) postFilterBlueprintRestoreHandler(w http.ResponseWriter, r *http.Request) {
+	defer dphttp.DrainBody(r)
+
+	vars := mux.Vars(r)
+	filterID := vars["filter_blueprint_id"]
+	logData := log.Data{"filter_blueprint_id": filterID}
+	ctx := r.Context()
+	log.Info(ctx, "restoring filter blueprint", logData)
+
+	// eTag value must be present in If-Match header
+	eTag, err := getIfMatchForce(r)
+	if err != nil {
+		log.Error(ctx, "missing header", err, log.Data{"error": err.Error()})
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	restore, err := models.CreateRestoreFilter(r.Body)
+	if err != nil {
+		log.Error(ctx, "unable to unmarshal request body", err, logData)
+		writeError(w, r, http.StatusBadRequest, errBadRequestBody)
+		return
+	}
+	logData["target_e_tag"] = restore.ETag
+
+	restoredFilter, err := api.restoreFilterBlueprint(ctx, filterID, restore.ETag, eTag)
+	if err != nil {
+		log.Error(ctx, "failed to restore filter blueprint", err, logData)
+		setErrorCode(w, r, err)
+		return
+	}
+	log.Info(ctx, "filter blueprint restored", logData)
+
+	bytes, err := json.Marshal(restoredFilter)
+	if err != nil {
+		log.Error(ctx, "failed to marshal restored filter blueprint into bytes", err, logData)
+		setErrorCode(w, r, err)
+		return
+	}
+
+	setJSONContentType(w)
+	setETag(w, restoredFilter.ETag)
+	w.WriteHeader(http.StatusOK)
+	_, err = w.Write(bytes)
+	if err != nil {
+		log.Error(ctx, "failed to write bytes for http response", err, logData)
+		setErrorCode(w, r, err)
+		return
+	}
+}
+
+// restoreFilterBlueprint rolls back the dimensions and dataset version of a filter blueprint
+// to the state it had when the target eTag was generated
+func (api *FilterAPI) restoreFilterBlueprint(ctx context.Context, filterID, targetETag, eTag string) (*models.Filter, error) {
+	logData := log.Data{"filter_blueprint_id": filterID, "target_e_tag": targetETag}
+
+	currentFilter, err := api.getFilterBlueprint(ctx, filterID, eTag)
+	if err != nil {
+		log.Error(ctx, "unable to get filter blueprint", err, logData)
+		return nil, err
+	}
+
+	// nothing to restore if the filter blueprint is already in the requested state
+	if currentFilter.ETag == targetETag {
+		return currentFilter, nil
+	}
+
+	snapshot, err := api.dataStore.GetFilterSnapshot(ctx, filterID, targetETag)
+	if err != nil {
+		log.Error(ctx, "unable to get previous state of filter blueprint", err, logData)
+		return nil, err
+	}
+
+	newFilter := *currentFilter
+	newFilter.Dimensions = snapshot.Dimensions
+	logData["new_filter"] = newFilter
+
+	if snapshot.Dataset != nil && snapshot.Dataset.Version != currentFilter.Dataset.Version {
+		log.Info(ctx, "finding version details for filter after version change", logData)
+
+		newFilter.Dataset = snapshot.Dataset
+
+		version, err := api.getVersion(ctx, newFilter.Dataset)
+		if err != nil {
+			log.Error(ctx, "unable to retrieve version document", err, logData)
+			return nil, filters.NewBadRequestErr(err.Error())
+		}
+
+		newFilter.Published = &models.Unpublished
+		if version.State == publishedState {
+			newFilter.Published = &models.Published
+		}
+
+		newFilter.InstanceID = version.ID
+		newFilter.Links.Version = &models.LinkObject{
+			HRef: version.Links.Self.URL,
+			ID:   strconv.Itoa(version.Version),
+		}
+
+		// Check restored dimensions are still valid for the restored version
+		if err = api.checkFilterOptions(ctx, &newFilter, version); err != nil {
+			log.Error(ctx, "failed to select valid filter options", err, logData)
+			return nil, newValidationBadRequestErr(err)
+		}
+	}
+
+	newFilter.ETag, err = api.dataStore.ReplaceFilter(ctx, &newFilter, currentFilter.UniqueTimestamp, eTag, currentFilter)
+	if err != nil {
+		log.Error(ctx, "unable to restore filter blueprint", err, logData)
+		return nil, err
+	}
+
+	return &newFilter, nil
+}
@@ -0,0 +1,269 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ONSdigital/dp-api-clients-go/v2/dataset"
+	"github.com/ONSdigital/dp-filter-api/api"
+	apimock "github.com/ONSdigital/dp-filter-api/api/mock"
+	"github.com/ONSdigital/dp-filter-api/filters"
+	"github.com/ONSdigital/dp-filter-api/mock"
+	"github.com/ONSdigital/dp-filter-api/models"
+	"github.com/ONSdigital/dp-filter-api/mongo"
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestSuccessfulRestoreFilterBlueprint(t *testing.T) {
+	t.Parallel()
+
+	filterFlexAPIMock := &apimock.FilterFlexAPIMock{}
+
+	Convey("Given a filter blueprint with a previous state", t, func() {
+		testETag := "testETag"
+		testETagPrevious := "testETagPrevious"
+		testETagRestored := "testETagRestored"
+		w := httptest.NewRecorder()
+
+		previousVersion := 1
+		mockDatastore := &apimock.DataStoreMock{
+			GetFilterFunc: func(ctx context.Context, filterID string, eTagSelector string) (*models.Filter, error) {
+				return &models.Filter{FilterID: filterID, Dataset: &models.Dataset{ID: "123", Edition: "2017", Version: 2}, InstanceID: "12345678", Published: &models.Published, Dimensions: []models.Dimension{{Name: "age", Options: []string{"27"}}}, ETag: testETag}, nil
+			},
+			GetFilterSnapshotFunc: func(ctx context.Context, filterID string, eTag string) (*models.FilterSnapshot, error) {
+				return &models.FilterSnapshot{FilterID: filterID, ETag: eTag, Dataset: &models.Dataset{ID: "123", Edition: "2017", Version: previousVersion}, InstanceID: "87654321", Dimensions: []models.Dimension{{Name: "age", Options: []string{"27", "33"}}}}, nil
+			},
+			ReplaceFilterFunc: func(ctx context.Context, updatedFilter *models.Filter, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error) {
+				if eTagSelector != testETag {
+					return "", filters.ErrFilterBlueprintConflict
+				}
+				return testETagRestored, nil
+			},
+		}
+
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), mockDatastore, &mock.FilterJob{}, &mock.DatasetAPI{}, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+
+		Convey("When a POST request is made to the restore endpoint with a previous ETag", func() {
+			reader := strings.NewReader(`{"e_tag":"` + testETagPrevious + `"}`)
+			r, err := http.NewRequest("POST", cfg().Host+"/filters/21312/restore", reader)
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag)
+
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the response is 200 OK", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+			})
+
+			Convey("Then the restored ETag is returned in the ETag header", func() {
+				So(w.Result().Header.Get("ETag"), ShouldResemble, testETagRestored)
+			})
+
+			Convey("Then the previous state is requested for the provided ETag", func() {
+				So(mockDatastore.GetFilterSnapshotCalls(), ShouldHaveLength, 1)
+				So(mockDatastore.GetFilterSnapshotCalls()[0].FilterID, ShouldEqual, "21312")
+				So(mockDatastore.GetFilterSnapshotCalls()[0].ETag, ShouldEqual, testETagPrevious)
+			})
+
+			Convey("Then the filter blueprint is replaced with the previous dimensions and dataset version", func() {
+				So(mockDatastore.ReplaceFilterCalls(), ShouldHaveLength, 1)
+				restored := mockDatastore.ReplaceFilterCalls()[0].UpdatedFilter
+				So(restored.Dataset.Version, ShouldEqual, previousVersion)
+				So(restored.Dimensions, ShouldResemble, []models.Dimension{{Name: "age", Options: []string{"27", "33"}}})
+				So(*restored.Published, ShouldBeTrue)
+				So(restored.Links.Version, ShouldNotBeNil)
+				So(restored.Links.Version.ID, ShouldNotBeEmpty)
+			})
+
+			Convey("Then the response body contains the restored filter blueprint", func() {
+				var filter models.Filter
+				So(json.Unmarshal(w.Body.Bytes(), &filter), ShouldBeNil)
+				So(filter.Dataset.Version, ShouldEqual, previousVersion)
+				So(filter.Dimensions, ShouldHaveLength, 1)
+				So(filter.Dimensions[0].Options, ShouldResemble, []string{"27", "33"})
+			})
+
+			Convey("Then the request body has been drained", func() {
+				bytesRead, err := r.Body.Read(make([]byte, 1))
+				So(bytesRead, ShouldEqual, 0)
+				So(err, ShouldEqual, io.EOF)
+			})
+		})
+
+		Convey("When a POST request is made to the restore endpoint with the current ETag", func() {
+			reader := strings.NewReader(`{"e_tag":"` + testETag + `"}`)
+			r, err := http.NewRequest("POST", cfg().Host+"/filters/21312/restore", reader)
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag)
+
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the response is 200 OK and the ETag is unchanged", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Result().Header.Get("ETag"), ShouldResemble, testETag)
+			})
+
+			Convey("Then the filter blueprint is not modified", func() {
+				So(mockDatastore.GetFilterSnapshotCalls(), ShouldHaveLength, 0)
+				So(mockDatastore.ReplaceFilterCalls(), ShouldHaveLength, 0)
+			})
+		})
+	})
+}
+
+func TestRestoreFilterBlueprintAfterVersionChange(t *testing.T) {
+	t.Parallel()
+
+	filterFlexAPIMock := &apimock.FilterFlexAPIMock{}
+
+	Convey("Given a filter blueprint that keeps a snapshot of its state on every update", t, func() {
+		current := models.Filter{FilterID: "21312", Dataset: &models.Dataset{ID: "123", Edition: "2017", Version: 1}, InstanceID: "12345678", Published: &models.Published, Dimensions: []models.Dimension{{Name: "age", Options: []string{"27"}}}, ETag: testETag}
+		snapshots := map[string]*models.FilterSnapshot{}
+
+		mockDatastore := &apimock.DataStoreMock{
+			GetFilterFunc: func(ctx context.Context, filterID string, eTagSelector string) (*models.Filter, error) {
+				filter := current
+				dataset := *current.Dataset
+				filter.Dataset = &dataset
+				return &filter, nil
+			},
+			UpdateFilterFunc: func(ctx context.Context, updatedFilter *models.Filter, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error) {
+				snapshots[currentFilter.ETag] = models.NewFilterSnapshot(currentFilter)
+				return testETag1, nil
+			},
+			GetFilterSnapshotFunc: func(ctx context.Context, filterID string, eTag string) (*models.FilterSnapshot, error) {
+				snapshot, ok := snapshots[eTag]
+				if !ok {
+					return nil, filters.ErrFilterSnapshotNotFound
+				}
+				return snapshot, nil
+			},
+			ReplaceFilterFunc: func(ctx context.Context, updatedFilter *models.Filter, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error) {
+				return "testETagRestored", nil
+			},
+		}
+
+		datasetAPIMock := &apimock.DatasetAPIMock{
+			GetVersionFunc: func(ctx context.Context, userAuthToken, serviceAuthToken, downloadServiceAuthToken, collectionID, datasetID, edition, version string) (dataset.Version, error) {
+				v, err := mock.NewDatasetAPI().GetVersion(ctx, userAuthToken, serviceAuthToken, downloadServiceAuthToken, collectionID, datasetID, edition, version)
+				v.ID = "instance-" + version
+				return v, err
+			},
+			GetVersionDimensionsFunc:   mock.NewDatasetAPI().GetVersionDimensions,
+			GetOptionsBatchProcessFunc: mock.NewDatasetAPI().GetOptionsBatchProcess,
+		}
+
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), mockDatastore, &mock.FilterJob{}, datasetAPIMock, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+
+		Convey("When the dataset version is changed and the filter blueprint is then restored to its previous ETag", func() {
+			r, err := http.NewRequest("PUT", cfg().Host+"/filters/21312", strings.NewReader(`{"dataset":{"version":2}}`))
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag)
+			w := httptest.NewRecorder()
+			filterAPI.Router.ServeHTTP(w, r)
+			So(w.Code, ShouldEqual, http.StatusOK)
+
+			current.Dataset.Version = 2
+			current.InstanceID = "instance-2"
+			current.ETag = testETag1
+
+			r, err = http.NewRequest("POST", cfg().Host+"/filters/21312/restore", strings.NewReader(`{"e_tag":"`+testETag+`"}`))
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag1)
+			w = httptest.NewRecorder()
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the snapshot taken before the update holds the previous version", func() {
+				So(snapshots[testETag].Dataset.Version, ShouldEqual, 1)
+				So(snapshots[testETag].InstanceID, ShouldEqual, "12345678")
+			})
+
+			Convey("Then the filter blueprint is restored to the previous version", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(mockDatastore.ReplaceFilterCalls(), ShouldHaveLength, 1)
+				restored := mockDatastore.ReplaceFilterCalls()[0].UpdatedFilter
+				So(restored.Dataset.Version, ShouldEqual, 1)
+				So(restored.InstanceID, ShouldEqual, "instance-1")
+			})
+		})
+	})
+}
+
+func TestFailedToRestoreFilterBlueprint(t *testing.T) {
+	t.Parallel()
+
+	filterFlexAPIMock := &apimock.FilterFlexAPIMock{}
+
+	Convey("When no If-Match header is provided, a bad request is returned", t, func() {
+		reader := strings.NewReader(`{"e_tag":"previous"}`)
+		r, err := http.NewRequest("POST", cfg().Host+"/filters/21312/restore", reader)
+		So(err, ShouldBeNil)
+
+		w := httptest.NewRecorder()
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), mock.NewDataStore().Mock, &mock.FilterJob{}, &mock.DatasetAPI{}, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+		filterAPI.Router.ServeHTTP(w, r)
+		So(w.Code, ShouldEqual, http.StatusBadRequest)
+		So(problemDetail(w.Body.String()), ShouldEqual, filters.ErrNoIfMatchHeader.Error())
+	})
+
+	Convey("When the request body does not contain a target ETag, a bad request is returned", t, func() {
+		reader := strings.NewReader(`{}`)
+		r, err := http.NewRequest("POST", cfg().Host+"/filters/21312/restore", reader)
+		So(err, ShouldBeNil)
+		r.Header.Set("If-Match", testETag)
+
+		w := httptest.NewRecorder()
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), mock.NewDataStore().Mock, &mock.FilterJob{}, &mock.DatasetAPI{}, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+		filterAPI.Router.ServeHTTP(w, r)
+		So(w.Code, ShouldEqual, http.StatusBadRequest)
+		So(problemDetail(w.Body.String()), ShouldEqual, badRequestResponse)
+	})
+
+	Convey("When the target ETag does not correspond to a previous state, a bad request is returned", t, func() {
+		reader := strings.NewReader(`{"e_tag":"unknown"}`)
+		r, err := http.NewRequest("POST", cfg().Host+"/filters/21312/restore", reader)
+		So(err, ShouldBeNil)
+		r.Header.Set("If-Match", testETag)
+
+		w := httptest.NewRecorder()
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), mock.NewDataStore().SnapshotNotFound().Mock, &mock.FilterJob{}, &mock.DatasetAPI{}, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+		filterAPI.Router.ServeHTTP(w, r)
+		So(w.Code, ShouldEqual, http.StatusBadRequest)
+		So(problemDetail(w.Body.String()), ShouldEqual, filters.ErrFilterSnapshotNotFound.Error())
+	})
+
+	Convey("When the If-Match header does not match the current ETag, a conflict is returned", t, func() {
+		reader := strings.NewReader(`{"e_tag":"previous"}`)
+		r, err := http.NewRequest("POST", cfg().Host+"/filters/21312/restore", reader)
+		So(err, ShouldBeNil)
+		r.Header.Set("If-Match", "wrong")
+
+		w := httptest.NewRecorder()
+		mockDatastore := mock.NewDataStore().Mock
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), mockDatastore, &mock.FilterJob{}, &mock.DatasetAPI{}, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+		filterAPI.Router.ServeHTTP(w, r)
+		So(w.Code, ShouldEqual, http.StatusConflict)
+		So(problemDetail(w.Body.String()), ShouldEqual, filerBlueprintConflictResponse)
+		So(mockDatastore.ReplaceFilterCalls(), ShouldHaveLength, 0)
+	})
+
+	Convey("When the filter blueprint does not exist, a not found is returned", t, func() {
+		reader := strings.NewReader(`{"e_tag":"previous"}`)
+		r, err := http.NewRequest("POST", cfg().Host+"/filters/21312/restore", reader)
+		So(err, ShouldBeNil)
+		r.Header.Set("If-Match", mongo.AnyETag)
+
+		w := httptest.NewRecorder()
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), mock.NewDataStore().NotFound().Mock, &mock.FilterJob{}, &mock.DatasetAPI{}, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+		filterAPI.Router.ServeHTTP(w, r)
+		So(w.Code, ShouldEqual, http.StatusNotFound)
+		So(problemDetail(w.Body.String()), ShouldEqual, filterNotFoundResponse)
+	})
+}
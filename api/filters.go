@@ -5,8 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"regexp"
 	"strconv"
+	"strings"
 	"time"
 
 	datasetAPI "github.com/ONSdigital/dp-api-clients-go/v2/dataset"
@@ -29,9 +29,6 @@ var (
 	statusBadRequest          = "bad request"
 	statusUnprocessableEntity = "unprocessable entity"
 
-	incorrectDimensionOptions = regexp.MustCompile("incorrect dimension options chosen")
-	incorrectDimension        = regexp.MustCompile("incorrect dimensions chosen")
-
 	publishedState = "published"
 )
 
@@ -44,16 +41,16 @@ func (api *FilterAPI) postFilterBlueprintHandler(w http.ResponseWriter, r *http.
 
 	submitted := r.FormValue("submitted")
 	logData := log.Data{"submitted": submitted}
-	ctx := r.Context()
+	ctx := withLanguage(r.Context(), getAcceptLanguage(r))
 	log.Info(ctx, "create filter blueprint", logData)
 
 	filter, err := models.CreateNewFilter(r.Body)
 	if err != nil {
 		log.Error(ctx, "unable to unmarshal request body", err, logData)
 		if err, ok := err.(models.DuplicateDimensionError); ok {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			writeError(w, r, http.StatusBadRequest, err)
 		} else {
-			http.Error(w, BadRequest, http.StatusBadRequest)
+			writeError(w, r, http.StatusBadRequest, errBadRequestBody)
 		}
 		return
 	}
@@ -61,7 +58,7 @@ func (api *FilterAPI) postFilterBlueprintHandler(w http.ResponseWriter, r *http.
 	newFilter, err := api.createFilterBlueprint(ctx, filter, submitted)
 	if err != nil {
 		log.Error(ctx, "failed to create new filter", err, logData)
-		setErrorCode(w, err)
+		setErrorCode(w, r, err)
 		return
 	}
 	log.Info(ctx, "created filter blueprint", logData)
@@ -69,7 +66,7 @@ func (api *FilterAPI) postFilterBlueprintHandler(w http.ResponseWriter, r *http.
 	bytes, err := json.Marshal(newFilter)
 	if err != nil {
 		log.Error(ctx, "failed to marshal filter blueprint into bytes", err, logData)
-		setErrorCode(w, err)
+		setErrorCode(w, r, err)
 		return
 	}
 
@@ -79,7 +76,7 @@ func (api *FilterAPI) postFilterBlueprintHandler(w http.ResponseWriter, r *http.
 	_, err = w.Write(bytes)
 	if err != nil {
 		log.Error(ctx, "failed to write bytes for http response", err, logData)
-		setErrorCode(w, err)
+		setErrorCode(w, r, err)
 		return
 	}
 }
@@ -140,7 +137,14 @@ func (api *FilterAPI) createFilterBlueprint(ctx context.Context, filter *models.
 
 	if err = api.checkFilterOptions(ctx, newFilter, version); err != nil {
 		log.Error(ctx, "failed to select valid filter options", err, logData)
-		return nil, filters.NewBadRequestErr(err.Error())
+		return nil, newValidationBadRequestErr(err)
+	}
+
+	if submitted == filterSubmitted {
+		if err = api.checkMaxCells(ctx, newFilter); err != nil {
+			log.Error(ctx, "filter blueprint cannot be submitted", err, logData)
+			return nil, err
+		}
 	}
 
 	newFilter, err = api.dataStore.AddFilter(ctx, newFilter)
@@ -179,13 +183,43 @@ func (api *FilterAPI) getFilterBlueprintHandler(w http.ResponseWriter, r *http.R
 	ctx := r.Context()
 	log.Info(ctx, "getting filter blueprint", logData)
 
+	view, err := api.getFilterBlueprintView(r)
+	if err != nil {
+		log.Error(ctx, "failed to obtain fields and embed from request query parameters", err, logData)
+		setErrorCode(w, r, err)
+		return
+	}
+
 	filterBlueprint, err := api.getFilterBlueprint(ctx, filterID, mongo.AnyETag)
 	if err != nil {
 		log.Error(ctx, "unable to get filter blueprint", err, logData)
-		setErrorCode(w, err)
+		setErrorCode(w, r, err)
 		return
 	}
 
+	// the newer version check changes the representation, so it is done before comparing its ETag
+	if api.enableVersionCheck {
+		api.setNewerVersionAvailable(ctx, filterBlueprint)
+	}
+
+	language := getAcceptLanguage(r)
+	eTag := representationETag(filterBlueprint.ETag, language, filterBlueprint.NewerVersionAvailable)
+	if api.writeNotModified(w, r, eTag, isPublished(filterBlueprint), false) {
+		log.Info(ctx, "filter blueprint not modified", logData)
+		return
+	}
+
+	var embeddedDimensions *models.PublicDimensions
+	if view.embedDimensions {
+		logData["embed_options"] = view.embedOptions
+		embeddedDimensions, err = api.getEmbeddedDimensions(withLanguage(ctx, language), filterBlueprint, view)
+		if err != nil {
+			log.Error(ctx, "unable to get dimensions to embed in filter blueprint", err, logData)
+			setErrorCode(w, r, err)
+			return
+		}
+	}
+
 	filterBlueprint.ID = filterBlueprint.FilterID
 	filterBlueprint.Dimensions = nil
 	logData["filter_blueprint"] = filterBlueprint
@@ -199,7 +233,7 @@ func (api *FilterAPI) getFilterBlueprintHandler(w http.ResponseWriter, r *http.R
 			if err != nil {
 				log.Error(ctx, "failed to rewrite filter blueprint dimensions link", err, logData,
 					log.Data{"link_type": "Dimensions", "original_link": filterBlueprint.Links.Dimensions.HRef})
-				setErrorCode(w, err)
+				setErrorCode(w, r, err)
 				return
 			}
 			filterBlueprint.Links.Dimensions.HRef = newLink
@@ -210,7 +244,7 @@ func (api *FilterAPI) getFilterBlueprintHandler(w http.ResponseWriter, r *http.R
 			if err != nil {
 				log.Error(ctx, "failed to rewrite filter blueprint filterOutput link", err, logData,
 					log.Data{"link_type": "FilterOutput", "original_link": filterBlueprint.Links.FilterOutput.HRef})
-				setErrorCode(w, err)
+				setErrorCode(w, r, err)
 				return
 			}
 			filterBlueprint.Links.FilterOutput.HRef = newLink
@@ -221,7 +255,7 @@ func (api *FilterAPI) getFilterBlueprintHandler(w http.ResponseWriter, r *http.R
 			if err != nil {
 				log.Error(ctx, "failed to rewrite filter blueprint filterBlueprint link", err, logData,
 					log.Data{"link_type": "FilterBlueprint", "original_link": filterBlueprint.Links.FilterBlueprint.HRef})
-				setErrorCode(w, err)
+				setErrorCode(w, r, err)
 				return
 			}
 			filterBlueprint.Links.FilterBlueprint.HRef = newLink
@@ -232,7 +266,7 @@ func (api *FilterAPI) getFilterBlueprintHandler(w http.ResponseWriter, r *http.R
 			if err != nil {
 				log.Error(ctx, "failed to rewrite filter blueprint self link", err, logData,
 					log.Data{"link_type": "Self", "original_link": filterBlueprint.Links.Self.HRef})
-				setErrorCode(w, err)
+				setErrorCode(w, r, err)
 				return
 			}
 			filterBlueprint.Links.Self.HRef = newLink
@@ -243,32 +277,56 @@ func (api *FilterAPI) getFilterBlueprintHandler(w http.ResponseWriter, r *http.R
 			if err != nil {
 				log.Error(ctx, "failed to rewrite filter blueprint version link", err, logData,
 					log.Data{"link_type": "Version", "original_link": filterBlueprint.Links.Version.HRef})
-				setErrorCode(w, err)
+				setErrorCode(w, r, err)
 				return
 			}
 			filterBlueprint.Links.Version.HRef = newLink
 		}
+
+		if err := rewriteEmbeddedDimensionLinks(filterAPILinksBuilder, embeddedDimensions); err != nil {
+			log.Error(ctx, "failed to rewrite embedded dimension links", err, logData)
+			setErrorCode(w, r, err)
+			return
+		}
 	}
 
-	bytes, err := json.Marshal(filterBlueprint)
+	bytes, err := marshalFilterBlueprint(filterBlueprint, embeddedDimensions, view)
 	if err != nil {
 		log.Error(ctx, "failed to marshal filter blueprint into bytes", err, logData)
-		http.Error(w, InternalError, http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, errInternal)
 		return
 	}
 
 	setJSONContentType(w)
-	setETag(w, filterBlueprint.ETag)
+	setETag(w, eTag)
+	api.setCacheControl(w, isPublished(filterBlueprint), false)
 	w.WriteHeader(http.StatusOK)
 	_, err = w.Write(bytes)
 	if err != nil {
 		log.Error(ctx, "failed to write bytes for http response", err, logData)
-		setErrorCode(w, err)
+		setErrorCode(w, r, err)
 	}
 
 	log.Info(ctx, "got filter blueprint", logData)
 }
 
+// setNewerVersionAvailable flags whether a newer version than the one used by the filter blueprint has been published for its dataset edition.
+// The latest version is cached for a short time, and failing to determine it is not fatal, in which case the flag is left unset.
+func (api *FilterAPI) setNewerVersionAvailable(ctx context.Context, filterBlueprint *models.Filter) {
+	if filterBlueprint.Dataset == nil {
+		return
+	}
+
+	latest, err := api.getCachedLatestVersion(ctx, filterBlueprint.Dataset)
+	if err != nil {
+		log.Error(ctx, "unable to determine latest published version for filter blueprint", err, log.Data{"filter_blueprint_id": filterBlueprint.FilterID, "dataset": *filterBlueprint.Dataset})
+		return
+	}
+
+	newerVersionAvailable := latest > filterBlueprint.Dataset.Version
+	filterBlueprint.NewerVersionAvailable = &newerVersionAvailable
+}
+
 func (api *FilterAPI) postFilterBlueprintSubmitHandler(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	filterBlueprintID := vars["filter_blueprint_id"]
@@ -281,7 +339,7 @@ func (api *FilterAPI) postFilterBlueprintSubmitHandler(w http.ResponseWriter, r
 
 	err := errors.New("filter not of type flexible")
 	log.Error(ctx, "invalid filter type", err, logData)
-	http.Error(w, BadRequest, http.StatusBadRequest)
+	writeError(w, r, http.StatusBadRequest, errBadRequestBody)
 }
 
 func (api *FilterAPI) putFilterBlueprintHandler(w http.ResponseWriter, r *http.Request) {
@@ -291,14 +349,14 @@ func (api *FilterAPI) putFilterBlueprintHandler(w http.ResponseWriter, r *http.R
 	filterID := vars["filter_blueprint_id"]
 	submitted := r.URL.Query().Get("submitted")
 	logData := log.Data{"filter_blueprint_id": filterID, "submitted": submitted}
-	ctx := r.Context()
+	ctx := withLanguage(r.Context(), getAcceptLanguage(r))
 	log.Info(ctx, "updating filter blueprint", logData)
 
 	// eTag value must be present in If-Match header
 	eTag, err := getIfMatchForce(r)
 	if err != nil {
 		log.Error(ctx, "missing header", err, log.Data{"error": err.Error()})
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, err)
 		return
 	}
 
@@ -308,7 +366,7 @@ func (api *FilterAPI) putFilterBlueprintHandler(w http.ResponseWriter, r *http.R
 		// request can have an empty json in body for this PUT request
 		if submitted != filterSubmitted || err != models.ErrorNoData {
 			log.Error(ctx, "unable to unmarshal request body", err, logData)
-			http.Error(w, BadRequest, http.StatusBadRequest)
+			writeError(w, r, http.StatusBadRequest, errBadRequestBody)
 			return
 		}
 	}
@@ -317,7 +375,7 @@ func (api *FilterAPI) putFilterBlueprintHandler(w http.ResponseWriter, r *http.R
 	newFilter, err := api.updateFilterBlueprint(ctx, filter, submitted, eTag)
 	if err != nil {
 		log.Error(ctx, "failed to update filter blueprint", err, logData)
-		setErrorCode(w, err)
+		setErrorCode(w, r, err)
 		return
 	}
 	log.Info(ctx, "filter blueprint updated", logData)
@@ -325,7 +383,7 @@ func (api *FilterAPI) putFilterBlueprintHandler(w http.ResponseWriter, r *http.R
 	bytes, err := json.Marshal(newFilter)
 	if err != nil {
 		log.Error(ctx, "failed to marshal updated filter blueprint into bytes", err, logData)
-		setErrorCode(w, err)
+		setErrorCode(w, r, err)
 		return
 	}
 
@@ -335,7 +393,7 @@ func (api *FilterAPI) putFilterBlueprintHandler(w http.ResponseWriter, r *http.R
 	_, err = w.Write(bytes)
 	if err != nil {
 		log.Error(ctx, "failed to write bytes for http response", err, logData)
-		setErrorCode(w, err)
+		setErrorCode(w, r, err)
 		return
 	}
 }
@@ -367,26 +425,23 @@ func (api *FilterAPI) updateFilterBlueprint(ctx context.Context, filter *models.
 	if versionHasChanged {
 		log.Info(ctx, "finding new version details for filter after version change", logData)
 
-		version, err := api.getVersion(ctx, newFilter.Dataset)
+		version, err := api.setFilterVersion(ctx, newFilter)
 		if err != nil {
 			log.Error(ctx, "unable to retrieve version document", err, logData)
 			return nil, filters.NewBadRequestErr(err.Error())
 		}
 
-		newFilter.Published = &models.Unpublished
-		if version.State == "published" {
-			newFilter.Published = &models.Published
-		}
-
-		newFilter.InstanceID = version.ID
-		newFilter.Links.Version = &models.LinkObject{
-			HRef: version.Links.Self.URL,
-		}
-
 		// Check existing dimensions work for new version
 		if err = api.checkFilterOptions(ctx, newFilter, version); err != nil {
 			log.Error(ctx, "failed to select valid filter options", err, logData)
-			return nil, filters.NewBadRequestErr(err.Error())
+			return nil, newValidationBadRequestErr(err)
+		}
+	}
+
+	if submitted == filterSubmitted {
+		if err = api.checkMaxCells(ctx, newFilter); err != nil {
+			log.Error(ctx, "filter blueprint cannot be submitted", err, logData)
+			return nil, err
 		}
 	}
 
@@ -419,6 +474,25 @@ func (api *FilterAPI) updateFilterBlueprint(ctx context.Context, filter *models.
 	return newFilter, nil
 }
 
+// setFilterVersion sets the instance, the published state and the version link of a filter blueprint from its dataset version
+func (api *FilterAPI) setFilterVersion(ctx context.Context, filterBlueprint *models.Filter) (*datasetAPI.Version, error) {
+	version, err := api.getVersion(ctx, filterBlueprint.Dataset)
+	if err != nil {
+		return nil, err
+	}
+
+	filterBlueprint.Published = &models.Unpublished
+	if version.State == "published" {
+		filterBlueprint.Published = &models.Published
+	}
+
+	filterBlueprint.InstanceID = version.ID
+	filterBlueprint.Links.Version = &models.LinkObject{
+		HRef: version.Links.Self.URL,
+	}
+	return version, nil
+}
+
 func (api *FilterAPI) getFilterBlueprint(ctx context.Context, filterID, eTag string) (*models.Filter, error) {
 	logData := log.Data{"filter_blueprint_id": filterID}
 
@@ -439,26 +513,25 @@ func (api *FilterAPI) getFilterBlueprint(ctx context.Context, filterID, eTag str
 
 	log.Info(ctx, "unauthenticated request to access unpublished filter", logData)
 
+	// filter blueprints are published by the instance published consumer when it is enabled, so they are not found until then
+	if api.enablePublishEventConsumer {
+		return nil, filters.ErrFilterBlueprintNotFound
+	}
+
 	version, err := api.getVersion(ctx, currentFilter.Dataset)
 	if err != nil {
 		log.Error(ctx, "failed to retrieve version from dataset api", err, logData)
 		return nil, err
 	}
 
-	// version has been published since filter was last requested, so update filter and return
+	// version has been published since filter was last requested, so only its published flag is updated, and it is read again with its new eTag
 	if version.State == publishedState {
-		filter := currentFilter
-		filter.Published = &models.Published
-		filter.ETag, err = api.dataStore.UpdateFilter(ctx, filter, filter.UniqueTimestamp, currentFilter.ETag, currentFilter)
-		if err != nil {
-			log.Error(ctx, "error updating filter", err, logData)
-			if err == filters.ErrFilterBlueprintConflict {
-				return nil, filters.ErrFilterBlueprintConflict
-			}
-			return nil, filters.ErrFilterBlueprintNotFound
+		if _, err = api.dataStore.PublishFilterBlueprints(ctx, currentFilter.InstanceID); err != nil {
+			log.Error(ctx, "error publishing filter blueprints", err, logData)
+			return nil, err
 		}
 
-		return filter, nil
+		return api.dataStore.GetFilter(ctx, filterID, mongo.AnyETag)
 	}
 
 	// not authenticated, so return not found
@@ -485,18 +558,38 @@ func (api *FilterAPI) checkFilterOptions(ctx context.Context, newFilter *models.
 
 	log.Info(ctx, "dimensions retrieved from dataset API", logData)
 
+	// validate every dimension in the filter, so that the validation errors of all the invalid dimensions are returned together
+	var validationErrors models.ValidationErrors
 	if err = models.ValidateFilterDimensions(newFilter.Dimensions, datasetDimensions); err != nil {
 		log.Error(ctx, "filter dimensions failed validation", err, logData)
-		return err
+		if !errors.As(err, &validationErrors) {
+			return err
+		}
+	} else {
+		log.Info(ctx, "successfully validated filter dimensions", logData)
 	}
-	log.Info(ctx, "successfully validated filter dimensions", logData)
 
-	// check options for all dimensions in the filter
+	// check options for all valid dimensions in the filter
 	for _, filterDimension := range newFilter.Dimensions {
+		if hasValidationError(validationErrors, filterDimension.Name) {
+			continue
+		}
 		if err := api.checkNewFilterDimensionOptions(ctx, filterDimension, newFilter.Dataset, logData); err != nil {
-			return err
+			if !addValidationErrors(&validationErrors, filterDimension.Name, err) {
+				return err
+			}
+			continue
+		}
+		if err := api.checkOptionRanges(ctx, newFilter.Dataset, filterDimension.Name, filterDimension.Ranges); err != nil {
+			if !addValidationErrors(&validationErrors, filterDimension.Name, err) {
+				return err
+			}
 		}
 	}
+
+	if len(validationErrors.Errors) > 0 {
+		return validationErrors
+	}
 	return nil
 }
 
@@ -620,6 +713,9 @@ func (api *FilterAPI) createFilterOutputResource(ctx context.Context, newFilter
 	}
 	filterOutput.LastUpdated = time.Now()
 
+	// the language requested on submission is kept, so that the downloads are produced in that language
+	filterOutput.Language = getLanguage(ctx)
+
 	// Clear out any event information to output document
 	filterOutput.Events = []*models.Event{
 		{
@@ -640,6 +736,18 @@ func (api *FilterAPI) createFilterOutputResource(ctx context.Context, newFilter
 		filterOutput.Dimensions[i].URL = ""
 	}
 
+	// option ranges are resolved against the dataset version, so that the filter output only lists option codes
+	if err := api.expandFilterOutputRanges(ctx, &filterOutput); err != nil {
+		log.Error(ctx, "unable to expand filter output option ranges", err, log.Data{"filter_output": filterOutput})
+		return models.Filter{}, err
+	}
+
+	// dimensions in exclude mode are resolved into the options they include, which is what the filter output and its exporters expect
+	if err := api.includeFilterOutputExcludedOptions(ctx, &filterOutput); err != nil {
+		log.Error(ctx, "unable to resolve the options included by filter output dimensions in exclude mode", err, log.Data{"filter_output": filterOutput})
+		return models.Filter{}, err
+	}
+
 	if newFilter.Published == &models.Published {
 		filterOutput.Published = &models.Published
 	}
@@ -666,8 +774,10 @@ func setETag(w http.ResponseWriter, eTag string) {
 	w.Header().Set("ETag", eTag)
 }
 
+// getIfMatch returns the ETag of the filter blueprint provided in the If-Match header, without the suffix of its representation
 func getIfMatch(r *http.Request) string {
-	return r.Header.Get("If-Match")
+	eTag, _, _ := strings.Cut(r.Header.Get("If-Match"), representationSeparator)
+	return eTag
 }
 
 func getIfMatchForce(r *http.Request) (string, error) {
@@ -680,7 +790,8 @@ func getIfMatchForce(r *http.Request) (string, error) {
 }
 
 func createNewFilter(filter, currentFilter *models.Filter) (newFilter *models.Filter, versionHasChanged bool) {
-	newFilter = currentFilter
+	// work on a copy, so that the current filter is left untouched to be kept as a snapshot
+	newFilter = copyFilterBlueprint(currentFilter)
 
 	if filter.Dataset != nil {
 		if filter.Dataset.Version != 0 && filter.Dataset.Version != currentFilter.Dataset.Version {
@@ -695,70 +806,91 @@ func createNewFilter(filter, currentFilter *models.Filter) (newFilter *models.Fi
 }
 
 //nolint:gocyclo // cyclomatic complexity 22 of func `setErrorCode` is high (> 20), not necessary to refactor this case
-func setErrorCode(w http.ResponseWriter, err error, typ ...string) {
+func setErrorCode(w http.ResponseWriter, r *http.Request, err error, typ ...string) {
+	var validationErrors models.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		writeError(w, r, http.StatusBadRequest, validationErrors)
+		return
+	}
+
 	switch err {
 	case filters.ErrFilterBlueprintNotFound:
 		if len(typ) > 0 && typ[0] == statusBadRequest {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			writeError(w, r, http.StatusBadRequest, err)
 			return
 		}
-		http.Error(w, err.Error(), http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, err)
 		return
 	case filters.ErrDimensionNotFound:
 		if len(typ) > 0 && typ[0] == statusBadRequest {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			writeError(w, r, http.StatusBadRequest, err)
 			return
 		}
-		http.Error(w, err.Error(), http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, err)
 		return
 	case filters.ErrDimensionsNotFound:
 		fallthrough
 	case filters.ErrVersionNotFound:
 		if typ != nil {
 			if typ[0] == statusBadRequest {
-				http.Error(w, err.Error(), http.StatusBadRequest)
+				writeError(w, r, http.StatusBadRequest, err)
 				return
 			}
 			if typ[0] == statusUnprocessableEntity {
-				http.Error(w, "version for filter blueprint no longer exists", http.StatusUnprocessableEntity)
+				writeError(w, r, http.StatusUnprocessableEntity, errVersionNoLongerExists)
 				return
 			}
 		}
-		http.Error(w, err.Error(), http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, err)
 		return
 	case filters.ErrDimensionOptionNotFound:
 		fallthrough
+	case filters.ErrUnsupportedFilterType:
+		fallthrough
 	case filters.ErrFilterOutputNotFound:
-		http.Error(w, err.Error(), http.StatusNotFound)
+		writeError(w, r, http.StatusNotFound, err)
 		return
 	case filters.ErrUnauthorised:
-		http.Error(w, err.Error(), http.StatusUnauthorized)
+		writeError(w, r, http.StatusUnauthorized, err)
 		return
 	case filters.ErrInvalidQueryParameter:
-		http.Error(w, filters.ErrInvalidQueryParameter.Error(), http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, filters.ErrInvalidQueryParameter)
 		return
 	case filters.ErrBadRequest:
-		http.Error(w, BadRequest, http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, errBadRequestBody)
+		return
+	case filters.ErrFilterSnapshotNotFound:
+		writeError(w, r, http.StatusBadRequest, err)
+		return
+	case filters.ErrInvalidCursor:
+		writeError(w, r, http.StatusBadRequest, err)
 		return
 	case filters.ErrFilterBlueprintConflict:
-		http.Error(w, err.Error(), http.StatusConflict)
+		writeError(w, r, http.StatusConflict, err)
+	case models.ErrPatchTestFailed:
+		writeError(w, r, http.StatusConflict, err)
 	case filters.ErrFilterOutputConflict:
-		http.Error(w, err.Error(), http.StatusConflict)
+		writeError(w, r, http.StatusConflict, err)
+	case filters.ErrStaleCursor:
+		writeError(w, r, http.StatusConflict, err)
 	case filters.ErrInternalError:
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		writeError(w, r, http.StatusInternalServerError, err)
 		return
 
 	default:
 
 		switch err.(type) {
 		case filters.BadRequestErr:
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			writeError(w, r, http.StatusBadRequest, err)
 			return
 		case filters.ForbiddenErr:
-			http.Error(w, err.Error(), http.StatusForbidden)
+			writeError(w, r, http.StatusForbidden, err)
+			return
+		case filters.UnprocessableEntityErr:
+			writeError(w, r, http.StatusUnprocessableEntity, err)
 			return
 		default:
-			http.Error(w, InternalError, http.StatusInternalServerError)
+			writeError(w, r, http.StatusInternalServerError, errInternal)
 			return
 		}
 	}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -11,6 +12,7 @@ import (
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/ONSdigital/dp-api-clients-go/v2/dataset"
 	"github.com/ONSdigital/dp-filter-api/api"
@@ -283,7 +285,7 @@ func TestFailedToAddFilterBlueprint(t *testing.T) {
 		Convey("Then the response is 500 internal error, with the expected response body", func() {
 			So(w.Code, ShouldEqual, http.StatusInternalServerError)
 			response := w.Body.String()
-			So(response, ShouldResemble, internalErrResponse)
+			So(problemDetail(response), ShouldEqual, internalErrResponse)
 		})
 
 		Convey("Then the ETag header is empty", func() {
@@ -309,7 +311,7 @@ func TestFailedToAddFilterBlueprint(t *testing.T) {
 		Convey("Then the response is 500 internal error, with the expected response body", func() {
 			So(w.Code, ShouldEqual, http.StatusInternalServerError)
 			response := w.Body.String()
-			So(response, ShouldResemble, internalErrResponse)
+			So(problemDetail(response), ShouldEqual, internalErrResponse)
 		})
 
 		Convey("Then the ETag header is empty", func() {
@@ -335,7 +337,7 @@ func TestFailedToAddFilterBlueprint(t *testing.T) {
 		Convey("Then the response is 404 Not Found, with the expected response body", func() {
 			So(w.Code, ShouldEqual, http.StatusNotFound)
 			response := w.Body.String()
-			So(response, ShouldResemble, versionNotFoundResponse)
+			So(problemDetail(response), ShouldEqual, versionNotFoundResponse)
 		})
 
 		Convey("Then the ETag header is empty", func() {
@@ -361,7 +363,7 @@ func TestFailedToAddFilterBlueprint(t *testing.T) {
 		Convey("Then the response is 404 not found, with the expected response body", func() {
 			So(w.Code, ShouldEqual, http.StatusNotFound)
 			response := w.Body.String()
-			So(response, ShouldResemble, versionNotFoundResponse)
+			So(problemDetail(response), ShouldEqual, versionNotFoundResponse)
 		})
 
 		Convey("Then the ETag header is empty", func() {
@@ -395,7 +397,7 @@ func TestFailedToAddFilterBlueprint_BadJSON(t *testing.T) {
 			})
 
 			Convey("Then the response body contains the expected content", func() {
-				So(w.Body.String(), ShouldResemble, badRequestResponse)
+				So(problemDetail(w.Body.String()), ShouldEqual, badRequestResponse)
 			})
 
 			Convey("Then the ETag header is empty", func() {
@@ -421,7 +423,7 @@ func TestFailedToAddFilterBlueprint_BadJSON(t *testing.T) {
 			})
 
 			Convey("Then the response body contains the expected content", func() {
-				So(w.Body.String(), ShouldResemble, badRequestResponse)
+				So(problemDetail(w.Body.String()), ShouldEqual, badRequestResponse)
 			})
 
 			Convey("Then the ETag header is empty", func() {
@@ -447,7 +449,7 @@ func TestFailedToAddFilterBlueprint_BadJSON(t *testing.T) {
 			})
 
 			Convey("Then the response body contains the expected content", func() {
-				So(w.Body.String(), ShouldResemble, badRequestResponse)
+				So(problemDetail(w.Body.String()), ShouldEqual, badRequestResponse)
 			})
 
 			Convey("Then the ETag header is empty", func() {
@@ -473,7 +475,7 @@ func TestFailedToAddFilterBlueprint_BadJSON(t *testing.T) {
 			})
 
 			Convey("Then the response body contains the expected content", func() {
-				So(w.Body.String(), ShouldResemble, "incorrect dimensions chosen: [weight]\n")
+				So(problemErrors(w.Body.String()), ShouldResemble, validationErrorsResponse(models.NewDimensionNotFoundError("weight", []string{"age"})))
 			})
 
 			Convey("Then the ETag header is empty", func() {
@@ -499,7 +501,7 @@ func TestFailedToAddFilterBlueprint_BadJSON(t *testing.T) {
 			})
 
 			Convey("Then the response body contains the expected content", func() {
-				So(w.Body.String(), ShouldResemble, "incorrect dimension options chosen: [29]\n")
+				So(problemErrors(w.Body.String()), ShouldResemble, validationErrorsResponse(models.NewOptionsNotFoundError("age", []string{"29"}, []string{"27", "33"})))
 			})
 
 			Convey("Then the ETag header is empty", func() {
@@ -512,6 +514,38 @@ func TestFailedToAddFilterBlueprint_BadJSON(t *testing.T) {
 				So(err, ShouldEqual, io.EOF)
 			})
 		})
+
+		Convey("When a POST request is made to the filters endpoint with several invalid dimensions and dimension options", func() {
+			reader := strings.NewReader(`{"dataset":{"version":1, "edition":"1", "id":"1"} , "dimensions":[{"name": "agee", "options": ["27"]}, {"name": "age", "options": ["29","33","wrong"]}]}`)
+			r, err := http.NewRequest("POST", cfg().Host+"/filters", reader)
+			So(err, ShouldBeNil)
+
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the response is 400 bad request, listing every invalid dimension and option with the suggested alternatives", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(w.Header().Get("Content-Type"), ShouldEqual, "application/problem+json")
+				So(decodeProblem(w.Body.String()).Code, ShouldEqual, "invalid_dimensions")
+
+				var validationErrors models.ValidationErrors
+				So(json.Unmarshal(w.Body.Bytes(), &validationErrors), ShouldBeNil)
+				So(validationErrors.Errors, ShouldResemble, []models.ValidationError{
+					{
+						Dimension:   "agee",
+						Reason:      models.ValidationReasonDimensionNotFound,
+						Message:     "dimension agee is not a dimension of the dataset version",
+						Suggestions: []string{"age"},
+					},
+					{
+						Dimension:   "age",
+						Reason:      models.ValidationReasonOptionsNotFound,
+						Message:     "options [29 wrong] are not options of dimension age in the dataset version",
+						Options:     []string{"29", "wrong"},
+						Suggestions: []string{"27"},
+					},
+				})
+			})
+		})
 	})
 }
 
@@ -687,7 +721,7 @@ func TestFailedToGetFilterBlueprint(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldResemble, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, internalErrResponse)
+		So(problemDetail(response), ShouldEqual, internalErrResponse)
 	})
 
 	Convey("When a filter blueprint does not exist, a not found is returned", t, func() {
@@ -701,7 +735,28 @@ func TestFailedToGetFilterBlueprint(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldResemble, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, filterNotFoundResponse)
+		So(problemDetail(response), ShouldEqual, filterNotFoundResponse)
+	})
+
+	Convey("When a filter blueprint does not exist, the not found is rendered as the problem details of the request", t, func() {
+		r, err := http.NewRequest("GET", cfg().Host+"/filters/12345678", http.NoBody)
+		So(err, ShouldBeNil)
+		r.Header.Set("X-Request-Id", "test-request-id")
+
+		w := httptest.NewRecorder()
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), mock.NewDataStore().NotFound(), &mock.FilterJob{}, &mock.DatasetAPI{}, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+		filterAPI.Router.ServeHTTP(w, r)
+		So(w.Code, ShouldEqual, http.StatusNotFound)
+		So(w.Header().Get("Content-Type"), ShouldEqual, "application/problem+json")
+		So(decodeProblem(w.Body.String()), ShouldResemble, &filters.Problem{
+			Type:      "urn:dp-filter-api:problem:filter_blueprint_not_found",
+			Title:     "Not Found",
+			Status:    http.StatusNotFound,
+			Detail:    filterNotFoundResponse,
+			Instance:  "/filters/12345678",
+			Code:      "filter_blueprint_not_found",
+			RequestID: "test-request-id",
+		})
 	})
 
 	Convey("When filter blueprint is unpublished, and the request is unauthenticated, a not found is returned", t, func() {
@@ -715,7 +770,26 @@ func TestFailedToGetFilterBlueprint(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldResemble, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, filterNotFoundResponse)
+		So(problemDetail(response), ShouldEqual, filterNotFoundResponse)
+	})
+
+	Convey("When filter blueprint is unpublished, filters are published by events, and the request is unauthenticated, a not found is returned without checking the dataset API", t, func() {
+		r, err := http.NewRequest("GET", cfg().Host+"/filters/12345678", http.NoBody)
+		So(err, ShouldBeNil)
+
+		config := cfg()
+		config.EnablePublishEventConsumer = true
+		datasetAPIMock := &apimock.DatasetAPIMock{
+			GetVersionFunc: mock.NewDatasetAPI().GetVersion,
+		}
+
+		w := httptest.NewRecorder()
+		mockDatastore := mock.NewDataStore().Unpublished().Mock
+		filterAPI := api.Setup(config, mux.NewRouter(), mockDatastore, &mock.FilterJob{}, datasetAPIMock, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+		filterAPI.Router.ServeHTTP(w, r)
+		So(w.Code, ShouldEqual, http.StatusNotFound)
+		So(datasetAPIMock.GetVersionCalls(), ShouldHaveLength, 0)
+		So(mockDatastore.PublishFilterBlueprintsCalls(), ShouldHaveLength, 0)
 	})
 }
 
@@ -881,7 +955,7 @@ func TestFailedToUpdateFilterBlueprint(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldResemble, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, badRequestResponse)
+		So(problemDetail(response), ShouldEqual, badRequestResponse)
 
 		Convey("Then the request body has been drained", func() {
 			bytesRead, err := r.Body.Read(make([]byte, 1))
@@ -903,7 +977,7 @@ func TestFailedToUpdateFilterBlueprint(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldResemble, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, badRequestResponse)
+		So(problemDetail(response), ShouldEqual, badRequestResponse)
 
 		Convey("Then the request body has been drained", func() {
 			bytesRead, err := r.Body.Read(make([]byte, 1))
@@ -925,7 +999,7 @@ func TestFailedToUpdateFilterBlueprint(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldResemble, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, filterNotFoundResponse)
+		So(problemDetail(response), ShouldEqual, filterNotFoundResponse)
 
 		Convey("Then the request body has been drained", func() {
 			bytesRead, err := r.Body.Read(make([]byte, 1))
@@ -948,7 +1022,7 @@ func TestFailedToUpdateFilterBlueprint(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldResemble, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, filterNotFoundResponse)
+		So(problemDetail(response), ShouldEqual, filterNotFoundResponse)
 
 		Convey("Then the request body has been drained", func() {
 			bytesRead, err := r.Body.Read(make([]byte, 1))
@@ -970,7 +1044,7 @@ func TestFailedToUpdateFilterBlueprint(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldResemble, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, versionNotFoundResponse)
+		So(problemDetail(response), ShouldEqual, versionNotFoundResponse)
 
 		Convey("Then the request body has been drained", func() {
 			bytesRead, err := r.Body.Read(make([]byte, 1))
@@ -992,7 +1066,10 @@ func TestFailedToUpdateFilterBlueprint(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldResemble, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, "incorrect dimensions chosen: [time 1_age]\n")
+		So(problemErrors(response), ShouldResemble, validationErrorsResponse(
+			models.NewDimensionNotFoundError("time", []string{"age"}),
+			models.NewDimensionNotFoundError("1_age", []string{"age"}),
+		))
 
 		Convey("Then the request body has been drained", func() {
 			bytesRead, err := r.Body.Read(make([]byte, 1))
@@ -1014,7 +1091,7 @@ func TestFailedToUpdateFilterBlueprint(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldResemble, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, "incorrect dimension options chosen: [28]\n")
+		So(problemErrors(response), ShouldResemble, validationErrorsResponse(models.NewOptionsNotFoundError("age", []string{"28"}, []string{"27", "33"})))
 
 		Convey("Then the request body has been drained", func() {
 			bytesRead, err := r.Body.Read(make([]byte, 1))
@@ -1035,7 +1112,7 @@ func TestFailedToUpdateFilterBlueprint(t *testing.T) {
 		So(w.Result().Header.Get("ETag"), ShouldResemble, "")
 
 		response := w.Body.String()
-		So(response, ShouldResemble, "required If-Match header not provided\n")
+		So(problemDetail(response), ShouldEqual, "required If-Match header not provided")
 
 		Convey("Then the request body has been drained", func() {
 			bytesRead, err := r.Body.Read(make([]byte, 1))
@@ -1195,6 +1272,7 @@ func TestRequestForwardingMiddleware(t *testing.T) {
 
 			datastoreMock.GetFilterFunc = func(ctx context.Context, filterID, etag string) (*models.Filter, error) {
 				return &models.Filter{
+					Published: &models.Published,
 					Dataset: &models.Dataset{
 						Version: 1,
 					},
@@ -1263,6 +1341,7 @@ func TestRequestForwardingMiddleware(t *testing.T) {
 
 			datastoreMock.GetFilterFunc = func(ctx context.Context, filterID, etag string) (*models.Filter, error) {
 				return &models.Filter{
+					Published: &models.Published,
 					Dataset: &models.Dataset{
 						Version: 1,
 					},
@@ -1369,6 +1448,7 @@ func TestRequestForwardingMiddleware(t *testing.T) {
 
 			datastoreMock.GetFilterFunc = func(ctx context.Context, filterID, etag string) (*models.Filter, error) {
 				return &models.Filter{
+					Published: &models.Published,
 					Dataset: &models.Dataset{
 						Version: 1,
 					},
@@ -1667,6 +1747,7 @@ func TestRequestForwardingMiddleware(t *testing.T) {
 
 			datastoreMock.GetFilterFunc = func(ctx context.Context, filterID, etag string) (*models.Filter, error) {
 				return &models.Filter{
+					Published: &models.Published,
 					Dataset: &models.Dataset{
 						Version: 1,
 					},
@@ -2004,3 +2085,87 @@ func TestRequestForwardingMiddleware(t *testing.T) {
 		})
 	})
 }
+
+func TestGetFilterBlueprint_NewerVersionAvailable(t *testing.T) {
+	filterFlexAPIMock := &apimock.FilterFlexAPIMock{}
+
+	t.Parallel()
+
+	Convey("Given a filter blueprint for version 1 of a dataset edition, and the newer version check enabled", t, func() {
+		w := httptest.NewRecorder()
+		config := cfg()
+		config.EnableNewerVersionCheck = true
+		config.LatestVersionCacheTTL = time.Minute
+
+		latestVersion := "2"
+		datasetAPIMock := &apimock.DatasetAPIMock{
+			GetEditionFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, collectionID string, datasetID string, edition string) (dataset.Edition, error) {
+				if latestVersion == "" {
+					return dataset.Edition{}, errors.New("dataset API unavailable")
+				}
+				// an authorised request is answered with the next state of the edition, whose latest version is not published yet
+				if userAuthToken != "" || serviceAuthToken != "" {
+					return dataset.Edition{Edition: edition, Links: dataset.Links{LatestVersion: dataset.Link{ID: "3"}}}, nil
+				}
+				return dataset.Edition{Edition: edition, Links: dataset.Links{LatestVersion: dataset.Link{ID: latestVersion}}}, nil
+			},
+		}
+		filterAPI := api.Setup(config, mux.NewRouter(), &mock.DataStore{}, &mock.FilterJob{}, datasetAPIMock, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+
+		Convey("When a newer version has been published and a GET request is made to the filters endpoint", func() {
+			r, err := http.NewRequest("GET", cfg().Host+"/filters/12345678", http.NoBody)
+			So(err, ShouldBeNil)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the response flags that a newer version is available", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				var filter models.Filter
+				So(json.Unmarshal(w.Body.Bytes(), &filter), ShouldBeNil)
+				So(filter.NewerVersionAvailable, ShouldNotBeNil)
+				So(*filter.NewerVersionAvailable, ShouldBeTrue)
+			})
+		})
+
+		Convey("When the filter blueprint is already on the latest published version and a GET request is made to the filters endpoint", func() {
+			latestVersion = "1"
+			r, err := http.NewRequest("GET", cfg().Host+"/filters/12345678", http.NoBody)
+			So(err, ShouldBeNil)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the response flags that no newer version is available, even though a newer version is not published yet", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Body.String(), ShouldContainSubstring, `"newer_version_available":false`)
+			})
+
+			Convey("Then the latest version is requested from the published state of the edition only", func() {
+				So(datasetAPIMock.GetEditionCalls(), ShouldHaveLength, 1)
+				So(datasetAPIMock.GetEditionCalls()[0].UserAuthToken, ShouldEqual, "")
+				So(datasetAPIMock.GetEditionCalls()[0].ServiceAuthToken, ShouldEqual, "")
+			})
+		})
+
+		Convey("When GET requests are made to the filters endpoint for the same dataset edition", func() {
+			for i := 0; i < 2; i++ {
+				r, err := http.NewRequest("GET", cfg().Host+"/filters/12345678", http.NoBody)
+				So(err, ShouldBeNil)
+				filterAPI.Router.ServeHTTP(httptest.NewRecorder(), r)
+			}
+
+			Convey("Then the latest version is only requested from the dataset API once", func() {
+				So(datasetAPIMock.GetEditionCalls(), ShouldHaveLength, 1)
+			})
+		})
+
+		Convey("When dataset API fails and a GET request is made to the filters endpoint", func() {
+			latestVersion = ""
+			r, err := http.NewRequest("GET", cfg().Host+"/filters/12345678", http.NoBody)
+			So(err, ShouldBeNil)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the filter blueprint is still returned, without the flag", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Body.String(), ShouldNotContainSubstring, "newer_version_available")
+			})
+		})
+	})
+}
@@ -0,0 +1,187 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/ONSdigital/dp-filter-api/filters"
+	"github.com/ONSdigital/dp-filter-api/models"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// optionSelectorLimit bounds the number of options of a request, and the work done to expand its option selectors.
+// Each option selector or option pattern only counts as one value when the request is validated, so the options provided,
+// the options selected by option selectors and the options matched by option patterns are all counted against the
+// maximum number of options of a request, as are the hierarchy nodes walked to find the selected options.
+// It is shared by the dimensions of a request, which can be expanded concurrently.
+type optionSelectorLimit struct {
+	mu        sync.Mutex
+	max       int
+	options   int
+	hierarchy int
+}
+
+func (api *FilterAPI) newOptionSelectorLimit() *optionSelectorLimit {
+	return &optionSelectorLimit{max: api.maxRequestOptions}
+}
+
+// addOptions counts the provided number of options, failing once the maximum is exceeded
+func (l *optionSelectorLimit) addOptions(count int) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.options += count
+	if l.options > l.max {
+		return filters.NewBadRequestErr(fmt.Sprintf("the options provided, selected and matched by the request exceed the maximum of %d options", l.max))
+	}
+	return nil
+}
+
+// addHierarchyNode counts a request to the hierarchy API, failing once the maximum is exceeded
+func (l *optionSelectorLimit) addHierarchyNode() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.hierarchy++
+	if l.hierarchy > l.max {
+		return filters.NewBadRequestErr(fmt.Sprintf("option selectors walk more than the maximum of %d hierarchy nodes", l.max))
+	}
+	return nil
+}
+
+// expandOptionSelectors returns the option codes selected by the provided option selectors,
+// according to the hierarchy of the dimension for the provided instance.
+// A bad request error is returned as soon as the selectors exceed the provided limit, or the maximum of a request if it is nil.
+func (api *FilterAPI) expandOptionSelectors(ctx context.Context, instanceID, dimensionName string, selectors []models.OptionSelector, limit *optionSelectorLimit) ([]string, error) {
+	if api.hierarchyAPI == nil {
+		return nil, filters.NewUnprocessableEntityErr("option selectors are not available, as no hierarchy API is configured")
+	}
+	if limit == nil {
+		limit = api.newOptionSelectorLimit()
+	}
+
+	options := []string{}
+	for _, selector := range selectors {
+		codes, err := api.expandOptionSelector(ctx, instanceID, dimensionName, selector, limit)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, codes...)
+	}
+	return options, nil
+}
+
+// expandOptionSelector walks the dimension hierarchy below the selected code, breadth first,
+// collecting the codes that are included by the selector, until the provided limit is exceeded.
+func (api *FilterAPI) expandOptionSelector(ctx context.Context, instanceID, dimensionName string, selector models.OptionSelector, limit *optionSelectorLimit) ([]string, error) {
+	logData := log.Data{"instance_id": instanceID, "dimension": dimensionName, "selector": selector}
+
+	options := []string{}
+	include := func(code string) error {
+		options = append(options, code)
+		return limit.addOptions(1)
+	}
+
+	if selector.Include != models.IncludeLeaves {
+		if err := include(selector.Code); err != nil {
+			return nil, err
+		}
+	}
+
+	pending := []string{selector.Code}
+	for len(pending) > 0 {
+		code := pending[0]
+		pending = pending[1:]
+
+		if err := limit.addHierarchyNode(); err != nil {
+			log.Error(ctx, "option selector exceeds the maximum number of hierarchy nodes", err, logData)
+			return nil, err
+		}
+
+		node, err := api.hierarchyAPI.GetChild(ctx, instanceID, dimensionName, code)
+		if err != nil {
+			log.Error(ctx, "failed to get dimension hierarchy node from hierarchy api", err, logData)
+			var response interface{ Code() int }
+			if errors.As(err, &response) && response.Code() == http.StatusNotFound {
+				return nil, filters.NewBadRequestErr(fmt.Sprintf("option %s not found in the hierarchy of dimension %s", code, dimensionName))
+			}
+			return nil, err
+		}
+
+		// a selected code without children is its own leaf
+		if selector.Include == models.IncludeLeaves && code == selector.Code && len(node.Children) == 0 {
+			if err := include(code); err != nil {
+				return nil, err
+			}
+		}
+
+		for i := range node.Children {
+			childCode := node.Children[i].Links.Code.ID
+			hasChildren := node.Children[i].NumberofChildren > 0
+
+			if selector.Include != models.IncludeLeaves || !hasChildren {
+				if err := include(childCode); err != nil {
+					log.Error(ctx, "option selector exceeds the maximum number of options", err, logData)
+					return nil, err
+				}
+			}
+			if selector.Include != models.IncludeChildren && hasChildren {
+				pending = append(pending, childCode)
+			}
+		}
+	}
+
+	logData["expanded_options_count"] = len(options)
+	log.Info(ctx, "expanded option selector against dimension hierarchy", logData)
+	return options, nil
+}
+
+// getOptionsFromInterface obtains the option codes, option selectors and option ranges from a list of patch values,
+// where each value is either an option code, an option selector object or an option range object.
+func getOptionsFromInterface(elements interface{}) (*models.DimensionOptions, error) {
+	dimensionOptions := &models.DimensionOptions{Options: []string{}}
+
+	values, ok := elements.([]interface{})
+	if !ok {
+		return dimensionOptions, errors.New("Missing list of items")
+	}
+
+	for _, value := range values {
+		switch v := value.(type) {
+		case string:
+			dimensionOptions.Options = append(dimensionOptions.Options, v)
+		case map[string]interface{}:
+			if _, isRange := v["from"]; isRange {
+				from, fromOK := v["from"].(string)
+				to, toOK := v["to"].(string)
+				if !fromOK || !toOK || len(v) != 2 {
+					return dimensionOptions, fmt.Errorf("invalid option range in list, got: %v", v)
+				}
+				optionRange := models.OptionRange{From: from, To: to}
+				if err := optionRange.Validate(); err != nil {
+					return dimensionOptions, err
+				}
+				dimensionOptions.Ranges = append(dimensionOptions.Ranges, optionRange)
+				continue
+			}
+
+			code, codeOK := v["code"].(string)
+			include, includeOK := v["include"].(string)
+			if !codeOK || !includeOK || len(v) != 2 {
+				return dimensionOptions, fmt.Errorf("invalid option selector in list, got: %v", v)
+			}
+			selector := models.OptionSelector{Code: code, Include: include}
+			if err := selector.Validate(); err != nil {
+				return dimensionOptions, err
+			}
+			dimensionOptions.Selectors = append(dimensionOptions.Selectors, selector)
+		default:
+			return dimensionOptions, fmt.Errorf("non string item in list, got: %v", v)
+		}
+	}
+
+	return dimensionOptions, nil
+}
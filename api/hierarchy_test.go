@@ -0,0 +1,273 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ONSdigital/dp-filter-api/api"
+	apimock "github.com/ONSdigital/dp-filter-api/api/mock"
+	"github.com/ONSdigital/dp-filter-api/mock"
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// ageHierarchy is a dimension hierarchy where option '27' has a single child '33', which has no children
+var ageHierarchy = map[string][]string{"27": {"33"}}
+
+func TestSuccessfulAddFilterBlueprintDimension_OptionSelectors(t *testing.T) {
+	t.Parallel()
+
+	filterFlexAPIMock := &apimock.FilterFlexAPIMock{}
+
+	Convey("Given a filter blueprint for a dataset with a hierarchical dimension", t, func() {
+		ds := mock.NewDataStore().Mock
+		datasetAPIMock := mock.NewDatasetAPI().Mock
+		hierarchyAPIMock := mock.NewHierarchyAPI(ageHierarchy).Mock
+		w := httptest.NewRecorder()
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), ds, &mock.FilterJob{}, datasetAPIMock, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false, api.WithHierarchyAPI(hierarchyAPIMock))
+
+		Convey("When a dimension is added selecting a code and its descendants", func() {
+			reader := strings.NewReader(`{"selectors":[{"code":"27","include":"descendants"}]}`)
+			r, err := http.NewRequest("POST", "http://localhost:22100/filters/12345678/dimensions/age", reader)
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the selector is expanded against the hierarchy of the filter instance, without requesting leaf nodes", func() {
+				So(w.Code, ShouldEqual, http.StatusCreated)
+				So(hierarchyAPIMock.GetChildCalls(), ShouldHaveLength, 1)
+				So(hierarchyAPIMock.GetChildCalls()[0].InstanceID, ShouldEqual, "12345678")
+				So(hierarchyAPIMock.GetChildCalls()[0].Name, ShouldEqual, "age")
+				So(hierarchyAPIMock.GetChildCalls()[0].Code, ShouldEqual, "27")
+			})
+
+			Convey("Then the expanded options are validated and stored", func() {
+				So(*datasetAPIMock.GetOptionsBatchProcessCalls()[0].OptionIDs, ShouldResemble, []string{"27", "33"})
+				So(ds.AddFilterDimensionCalls(), ShouldHaveLength, 1)
+				So(ds.AddFilterDimensionCalls()[0].Options, ShouldResemble, []string{"27", "33"})
+			})
+		})
+
+		Convey("When a dimension is added with explicit options and a selector for the leaves of a code", func() {
+			reader := strings.NewReader(`{"options":["27"],"selectors":[{"code":"27","include":"leaves"}]}`)
+			r, err := http.NewRequest("POST", "http://localhost:22100/filters/12345678/dimensions/age", reader)
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then both the explicit options and the leaves are stored", func() {
+				So(w.Code, ShouldEqual, http.StatusCreated)
+				So(ds.AddFilterDimensionCalls()[0].Options, ShouldResemble, []string{"27", "33"})
+			})
+		})
+	})
+}
+
+func TestSuccessfulPatchFilterBlueprintDimension_OptionSelectors(t *testing.T) {
+	t.Parallel()
+
+	filterFlexAPIMock := &apimock.FilterFlexAPIMock{}
+
+	Convey("Given a filter blueprint for a dataset with a hierarchical dimension", t, func() {
+		ds := mock.NewDataStore().Mock
+		hierarchyAPIMock := mock.NewHierarchyAPI(ageHierarchy).Mock
+		w := httptest.NewRecorder()
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), ds, &mock.FilterJob{}, mock.NewDatasetAPI().Mock, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false, api.WithHierarchyAPI(hierarchyAPIMock))
+
+		Convey("When an 'add' patch operation selects a code and its children", func() {
+			reader := strings.NewReader(`[{"op":"add", "path": "/options/-", "value": [{"code":"27","include":"children"}]}]`)
+			r, err := http.NewRequest("PATCH", "http://localhost:22100/filters/12345678/dimensions/age", reader)
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then only the direct children are requested from the hierarchy and the new options are added", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(hierarchyAPIMock.GetChildCalls(), ShouldHaveLength, 1)
+				So(ds.AddFilterDimensionOptionsCalls(), ShouldHaveLength, 1)
+				So(ds.AddFilterDimensionOptionsCalls()[0].Options, ShouldResemble, []string{"27"})
+			})
+		})
+
+		Convey("When a 'remove' patch operation selects the leaves of a code", func() {
+			reader := strings.NewReader(`[{"op":"remove", "path": "/options/-", "value": [{"code":"27","include":"leaves"}]}]`)
+			r, err := http.NewRequest("PATCH", "http://localhost:22100/filters/12345678/dimensions/age", reader)
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the leaves are removed", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(ds.RemoveFilterDimensionOptionsCalls(), ShouldHaveLength, 1)
+				So(ds.RemoveFilterDimensionOptionsCalls()[0].Options, ShouldResemble, []string{"33"})
+			})
+		})
+	})
+}
+
+func TestFailedToApplyOptionSelectors(t *testing.T) {
+	t.Parallel()
+
+	filterFlexAPIMock := &apimock.FilterFlexAPIMock{}
+
+	Convey("Given a filter blueprint for a dataset with a hierarchical dimension", t, func() {
+		ds := mock.NewDataStore().Mock
+		w := httptest.NewRecorder()
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), ds, &mock.FilterJob{}, mock.NewDatasetAPI().Mock, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false, api.WithHierarchyAPI(mock.NewHierarchyAPI(ageHierarchy)))
+
+		Convey("When a selector for a code that is not in the hierarchy is provided", func() {
+			reader := strings.NewReader(`{"selectors":[{"code":"99","include":"children"}]}`)
+			r, err := http.NewRequest("POST", "http://localhost:22100/filters/12345678/dimensions/age", reader)
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the response is 400 bad request and nothing is stored", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(problemDetail(w.Body.String()), ShouldEqual, "option 99 not found in the hierarchy of dimension age")
+				So(ds.AddFilterDimensionCalls(), ShouldHaveLength, 0)
+			})
+		})
+
+		Convey("When a selector with an unsupported include value is provided", func() {
+			reader := strings.NewReader(`{"selectors":[{"code":"27","include":"siblings"}]}`)
+			r, err := http.NewRequest("POST", "http://localhost:22100/filters/12345678/dimensions/age", reader)
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the response is 400 bad request", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(w.Body.String(), ShouldContainSubstring, "invalid include value provided for option selector 27")
+			})
+		})
+
+		Convey("When a patch operation contains an invalid option selector", func() {
+			reader := strings.NewReader(`[{"op":"add", "path": "/options/-", "value": [{"code":"27"}]}]`)
+			r, err := http.NewRequest("PATCH", "http://localhost:22100/filters/12345678/dimensions/age", reader)
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the response is 400 bad request and no change is applied", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(problemDetail(w.Body.String()), ShouldEqual, "values provided are not strings, valid option selectors or valid option ranges")
+				So(ds.RunTransactionCalls(), ShouldHaveLength, 0)
+			})
+		})
+	})
+
+	Convey("Given an API without a hierarchy API", t, func() {
+		ds := mock.NewDataStore().Mock
+		w := httptest.NewRecorder()
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), ds, &mock.FilterJob{}, mock.NewDatasetAPI().Mock, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+
+		Convey("When a selector is provided", func() {
+			reader := strings.NewReader(`{"selectors":[{"code":"27","include":"children"}]}`)
+			r, err := http.NewRequest("POST", "http://localhost:22100/filters/12345678/dimensions/age", reader)
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the response is 422 unprocessable entity and nothing is stored", func() {
+				So(w.Code, ShouldEqual, http.StatusUnprocessableEntity)
+				So(problemDetail(w.Body.String()), ShouldEqual, "option selectors are not available, as no hierarchy API is configured")
+				So(ds.AddFilterDimensionCalls(), ShouldHaveLength, 0)
+			})
+		})
+	})
+}
+
+func TestOptionSelectorsExceedMaximumOptions(t *testing.T) {
+	t.Parallel()
+
+	filterFlexAPIMock := &apimock.FilterFlexAPIMock{}
+
+	// a dimension hierarchy where option '27' has a single child '33', and option '1' is the root of a chain of three nodes with a single leaf
+	hierarchy := map[string][]string{"27": {"33"}, "1": {"2"}, "2": {"3"}, "3": {"4"}}
+
+	Convey("Given a filter blueprint for a dataset with a hierarchical dimension, and a maximum of 2 options per request", t, func() {
+		ds := mock.NewDataStore().Mock
+		hierarchyAPIMock := mock.NewHierarchyAPI(hierarchy).Mock
+		testCfg := cfg()
+		testCfg.MaxRequestOptions = 2
+		w := httptest.NewRecorder()
+		filterAPI := api.Setup(testCfg, mux.NewRouter(), ds, &mock.FilterJob{}, mock.NewDatasetAPI().Mock, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false, api.WithHierarchyAPI(hierarchyAPIMock))
+
+		Convey("When a single selector selects more options than the maximum", func() {
+			reader := strings.NewReader(`{"selectors":[{"code":"1","include":"descendants"}]}`)
+			r, err := http.NewRequest("POST", "http://localhost:22100/filters/12345678/dimensions/age", reader)
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the response is 400 bad request and nothing is stored", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(problemDetail(w.Body.String()), ShouldEqual, "the options provided, selected and matched by the request exceed the maximum of 2 options")
+				So(ds.AddFilterDimensionCalls(), ShouldHaveLength, 0)
+			})
+		})
+
+		Convey("When a dimension is added with an option and a selector that are within the maximum separately, but not together", func() {
+			reader := strings.NewReader(`{"options":["1"],"selectors":[{"code":"27","include":"children"}]}`)
+			r, err := http.NewRequest("POST", "http://localhost:22100/filters/12345678/dimensions/age", reader)
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the response is 400 bad request and nothing is stored", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(problemDetail(w.Body.String()), ShouldEqual, "the options provided, selected and matched by the request exceed the maximum of 2 options")
+				So(ds.AddFilterDimensionCalls(), ShouldHaveLength, 0)
+			})
+		})
+
+		Convey("When a dimension is added with an option and a pattern that are within the maximum separately, but not together", func() {
+			reader := strings.NewReader(`{"options":["1","pattern:*"]}`)
+			r, err := http.NewRequest("POST", "http://localhost:22100/filters/12345678/dimensions/age", reader)
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the response is 400 bad request and nothing is stored", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(problemDetail(w.Body.String()), ShouldEqual, "the options provided, selected and matched by the request exceed the maximum of 2 options")
+				So(ds.AddFilterDimensionCalls(), ShouldHaveLength, 0)
+			})
+		})
+
+		Convey("When a selector walks more hierarchy nodes than the maximum to find its leaves", func() {
+			reader := strings.NewReader(`{"selectors":[{"code":"1","include":"leaves"}]}`)
+			r, err := http.NewRequest("POST", "http://localhost:22100/filters/12345678/dimensions/age", reader)
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the walk stops without requesting any further node, and the response is 400 bad request", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(problemDetail(w.Body.String()), ShouldEqual, "option selectors walk more than the maximum of 2 hierarchy nodes")
+				So(hierarchyAPIMock.GetChildCalls(), ShouldHaveLength, 2)
+				So(ds.AddFilterDimensionCalls(), ShouldHaveLength, 0)
+			})
+		})
+
+		Convey("When the selectors of several patch operations select more options than the maximum overall", func() {
+			reader := strings.NewReader(`[
+				{"op":"add", "path": "/options/-", "value": [{"code":"27","include":"children"}]},
+				{"op":"add", "path": "/options/-", "value": [{"code":"33","include":"children"}]}
+			]`)
+			r, err := http.NewRequest("PATCH", "http://localhost:22100/filters/12345678/dimensions/age", reader)
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the first operation is within the maximum, but the response is 400 bad request and no change is applied", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(problemDetail(w.Body.String()), ShouldEqual, "the options provided, selected and matched by the request exceed the maximum of 2 options")
+				So(ds.AddFilterDimensionOptionsCalls(), ShouldHaveLength, 1)
+			})
+		})
+	})
+}
@@ -0,0 +1,152 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/ONSdigital/dp-filter-api/filters"
+	"github.com/ONSdigital/dp-filter-api/models"
+	dprequest "github.com/ONSdigital/dp-net/request"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+const (
+	idempotencyKeyHeader      = "Idempotency-Key"
+	idempotentReplayedHeader  = "Idempotent-Replayed"
+	maxIdempotencyKeyLength   = 255
+	idempotencyRecordedStatus = http.StatusMultipleChoices
+)
+
+// idempotent wraps a handler with side effects so that a request made with an Idempotency-Key header is only processed once.
+// The key is reserved before the request is processed, so that a concurrent retry is refused with a conflict while it is in progress,
+// until the lease of the reservation expires, after which the request is assumed to have been abandoned and a retry is processed.
+// The successful response to the request is stored for the configured window, and replayed to any retry with the same key and body,
+// while a retry with the same key but a different body is refused.
+// Requests without the header are not recorded, and the key of failed requests is released, so they can be retried as usual.
+func (api *FilterAPI) idempotent(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(idempotencyKeyHeader)
+		if key == "" || api.idempotencyKeyTTL <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := r.Context()
+		logData := log.Data{"idempotency_key": key, "method": r.Method, "path": r.URL.Path}
+
+		if len(key) > maxIdempotencyKeyLength {
+			log.Error(ctx, "idempotency key is too long", filters.ErrInvalidIdempotencyKey, logData)
+			writeError(w, r, http.StatusBadRequest, filters.ErrInvalidIdempotencyKey)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			log.Error(ctx, "failed to read request body", err, logData)
+			writeError(w, r, http.StatusBadRequest, errBadRequestBody)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		scope := idempotencyScope(r)
+		requestHash := models.HashIdempotentRequest([]byte(r.URL.RawQuery), body)
+
+		record := models.NewIdempotencyRecord(key, scope, requestHash)
+		existing, err := api.dataStore.ReserveIdempotencyRecord(ctx, record, record.CreatedAt.Add(-api.idempotencyKeyTTL), record.CreatedAt.Add(-api.idempotencyKeyLease))
+		if err != nil {
+			if errors.Is(err, filters.ErrIdempotencyKeyInUse) {
+				log.Error(ctx, "idempotency key is reserved by a request in progress", err, logData)
+				writeError(w, r, http.StatusConflict, err)
+				return
+			}
+			log.Error(ctx, "failed to reserve idempotency key", err, logData)
+			setErrorCode(w, r, err)
+			return
+		}
+
+		if existing != nil {
+			if existing.RequestHash != requestHash {
+				log.Error(ctx, "idempotency key reused for a different request", filters.ErrIdempotencyKeyReused, logData)
+				writeError(w, r, http.StatusUnprocessableEntity, filters.ErrIdempotencyKeyReused)
+				return
+			}
+
+			if existing.InProgress {
+				log.Error(ctx, "idempotency key is reserved by a request in progress", filters.ErrIdempotencyKeyInUse, logData)
+				writeError(w, r, http.StatusConflict, filters.ErrIdempotencyKeyInUse)
+				return
+			}
+
+			log.Info(ctx, "replaying response of request with idempotency key", logData)
+			replayIdempotencyRecord(w, existing)
+			return
+		}
+
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		// the request has been processed even if the client has gone away meanwhile, so its outcome is recorded regardless
+		ctx = context.WithoutCancel(ctx)
+
+		if rec.status < http.StatusOK || rec.status >= idempotencyRecordedStatus {
+			if err := api.dataStore.DeleteIdempotencyRecord(ctx, record); err != nil {
+				// the key stays reserved until its lease expires, so retries are refused with a conflict until then
+				log.Error(ctx, "failed to release idempotency key of failed request", err, logData)
+			}
+			return
+		}
+
+		record.SetResponse(rec.status, rec.Header().Clone(), rec.body.Bytes())
+		if err := api.dataStore.SaveIdempotencyRecord(ctx, record); err != nil {
+			// the response has already been written, and retries are processed again once the lease of the key expires
+			log.Error(ctx, "failed to save idempotency record", err, logData)
+		}
+	})
+}
+
+// idempotencyScope returns the scope of an idempotency key, which is the method and path of the request and the identity of the caller,
+// so that the same key used for another resource, or by another user or service, is not replayed the response of a different request
+func idempotencyScope(r *http.Request) string {
+	scope := r.Method + " " + r.URL.Path
+	if caller := dprequest.Caller(r.Context()); caller != "" {
+		scope += " " + caller
+	}
+	return scope
+}
+
+// replayIdempotencyRecord writes the stored response of a request made with an idempotency key
+func replayIdempotencyRecord(w http.ResponseWriter, record *models.IdempotencyRecord) {
+	for name, values := range record.Header {
+		for _, value := range values {
+			w.Header().Add(name, value)
+		}
+	}
+	w.Header().Set(idempotentReplayedHeader, "true")
+	w.WriteHeader(record.StatusCode)
+	_, _ = w.Write(record.Body)
+}
+
+// responseRecorder writes a response while keeping its status and body, so that it can be stored
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	body        bytes.Buffer
+	wroteHeader bool
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	if !rec.wroteHeader {
+		rec.status = status
+		rec.wroteHeader = true
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	rec.wroteHeader = true
+	rec.body.Write(b)
+	return rec.ResponseWriter.Write(b)
+}
@@ -0,0 +1,293 @@
+package api_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-filter-api/api"
+	apimock "github.com/ONSdigital/dp-filter-api/api/mock"
+	"github.com/ONSdigital/dp-filter-api/filters"
+	"github.com/ONSdigital/dp-filter-api/mock"
+	"github.com/ONSdigital/dp-filter-api/models"
+	dprequest "github.com/ONSdigital/dp-net/request"
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestIdempotentPostFilterBlueprint(t *testing.T) {
+	t.Parallel()
+
+	filterFlexAPIMock := &apimock.FilterFlexAPIMock{}
+	newFilter := `{"dataset":{"version":1, "edition":"1", "id":"1"}}`
+
+	Convey("Given idempotency keys are replayed for an hour, and reserved for a minute while in progress", t, func() {
+		config := cfg()
+		config.IdempotencyKeyTTL = time.Hour
+		config.IdempotencyKeyLease = time.Minute
+		ds := mock.NewDataStore().Mock
+		filterAPI := api.Setup(config, mux.NewRouter(), ds, &mock.FilterJob{}, &mock.DatasetAPI{}, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+
+		post := func(body, key string) *httptest.ResponseRecorder {
+			r, err := http.NewRequest("POST", cfg().Host+"/filters?submitted=true", strings.NewReader(body))
+			So(err, ShouldBeNil)
+			if key != "" {
+				r.Header.Set("Idempotency-Key", key)
+			}
+			w := httptest.NewRecorder()
+			filterAPI.Router.ServeHTTP(w, r)
+			return w
+		}
+
+		Convey("When a filter blueprint is created and submitted with an idempotency key, and the request is retried with the same key", func() {
+			first := post(newFilter, "key-1")
+			retry := post(newFilter, "key-1")
+
+			Convey("Then the filter blueprint and its filter output are only created once", func() {
+				So(first.Code, ShouldEqual, http.StatusCreated)
+				So(ds.AddFilterCalls(), ShouldHaveLength, 1)
+				So(ds.CreateFilterOutputCalls(), ShouldHaveLength, 1)
+				So(ds.SaveIdempotencyRecordCalls(), ShouldHaveLength, 1)
+			})
+
+			Convey("Then the original response is replayed", func() {
+				So(retry.Code, ShouldEqual, http.StatusCreated)
+				So(retry.Body.String(), ShouldEqual, first.Body.String())
+				So(retry.Header().Get("ETag"), ShouldEqual, first.Header().Get("ETag"))
+				So(retry.Header().Get("Content-Type"), ShouldEqual, first.Header().Get("Content-Type"))
+				So(retry.Header().Get("Idempotent-Replayed"), ShouldEqual, "true")
+				So(first.Header().Get("Idempotent-Replayed"), ShouldBeEmpty)
+			})
+		})
+
+		Convey("When the same idempotency key is used for a request with a different body", func() {
+			post(newFilter, "key-2")
+			w := post(`{"dataset":{"version":2, "edition":"1", "id":"1"}}`, "key-2")
+
+			Convey("Then 422 unprocessable entity is returned and the request is not processed", func() {
+				So(w.Code, ShouldEqual, http.StatusUnprocessableEntity)
+				So(decodeProblem(w.Body.String()).Code, ShouldEqual, "idempotency_key_reused")
+				So(ds.AddFilterCalls(), ShouldHaveLength, 1)
+			})
+		})
+
+		Convey("When requests are made without an idempotency key", func() {
+			post(newFilter, "")
+			post(newFilter, "")
+
+			Convey("Then every request is processed and nothing is recorded", func() {
+				So(ds.AddFilterCalls(), ShouldHaveLength, 2)
+				So(ds.ReserveIdempotencyRecordCalls(), ShouldHaveLength, 0)
+				So(ds.SaveIdempotencyRecordCalls(), ShouldHaveLength, 0)
+			})
+		})
+
+		Convey("When a request with an idempotency key fails", func() {
+			w := post(`{"dataset":`, "key-3")
+
+			Convey("Then the failure is not recorded and the key is released, so the request can be retried with the same key", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(ds.SaveIdempotencyRecordCalls(), ShouldHaveLength, 0)
+				So(ds.DeleteIdempotencyRecordCalls(), ShouldHaveLength, 1)
+
+				retry := post(newFilter, "key-3")
+				So(retry.Code, ShouldEqual, http.StatusCreated)
+				So(ds.AddFilterCalls(), ShouldHaveLength, 1)
+			})
+		})
+
+		Convey("When the idempotency key is too long", func() {
+			w := post(newFilter, strings.Repeat("k", 256))
+
+			Convey("Then 400 bad request is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(decodeProblem(w.Body.String()).Code, ShouldEqual, "invalid_idempotency_key")
+				So(ds.AddFilterCalls(), ShouldHaveLength, 0)
+			})
+		})
+
+		Convey("When a request is retried while the request with the same idempotency key is in progress", func() {
+			ds.ReserveIdempotencyRecordFunc = func(ctx context.Context, record *models.IdempotencyRecord, expiredBefore, leaseExpiredBefore time.Time) (*models.IdempotencyRecord, error) {
+				return models.NewIdempotencyRecord(record.Key, record.Scope, record.RequestHash), nil
+			}
+			w := post(newFilter, "key-5")
+
+			Convey("Then 409 conflict is returned and the retry is not processed", func() {
+				So(w.Code, ShouldEqual, http.StatusConflict)
+				So(decodeProblem(w.Body.String()).Code, ShouldEqual, "idempotency_key_in_use")
+				So(ds.AddFilterCalls(), ShouldHaveLength, 0)
+				So(ds.DeleteIdempotencyRecordCalls(), ShouldHaveLength, 0)
+			})
+		})
+
+		Convey("When the idempotency key is reserved concurrently by another request", func() {
+			ds.ReserveIdempotencyRecordFunc = func(ctx context.Context, record *models.IdempotencyRecord, expiredBefore, leaseExpiredBefore time.Time) (*models.IdempotencyRecord, error) {
+				return nil, filters.ErrIdempotencyKeyInUse
+			}
+			w := post(newFilter, "key-6")
+
+			Convey("Then 409 conflict is returned and the request is not processed", func() {
+				So(w.Code, ShouldEqual, http.StatusConflict)
+				So(ds.AddFilterCalls(), ShouldHaveLength, 0)
+			})
+		})
+
+		Convey("When the same idempotency key is used by different callers", func() {
+			for _, caller := range []string{"user-1", "user-2"} {
+				r, err := http.NewRequest("POST", cfg().Host+"/filters", strings.NewReader(newFilter))
+				So(err, ShouldBeNil)
+				r = r.WithContext(dprequest.SetCaller(r.Context(), caller))
+				r.Header.Set("Idempotency-Key", "key-7")
+				filterAPI.Router.ServeHTTP(httptest.NewRecorder(), r)
+			}
+
+			Convey("Then the key is scoped to each caller, and both requests are processed", func() {
+				So(ds.AddFilterCalls(), ShouldHaveLength, 2)
+				So(ds.ReserveIdempotencyRecordCalls()[0].Record.Scope, ShouldEqual, "POST /filters user-1")
+				So(ds.ReserveIdempotencyRecordCalls()[1].Record.Scope, ShouldEqual, "POST /filters user-2")
+			})
+		})
+
+		Convey("When the client cancels requests with an idempotency key while they are processed", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			for key, body := range map[string]string{"key-8": newFilter, "key-8-failed": `{"dataset":`} {
+				r, err := http.NewRequestWithContext(ctx, "POST", cfg().Host+"/filters?submitted=true", strings.NewReader(body))
+				So(err, ShouldBeNil)
+				r.Header.Set("Idempotency-Key", key)
+				filterAPI.Router.ServeHTTP(httptest.NewRecorder(), r)
+			}
+
+			Convey("Then the outcome of the requests is still recorded, so that retries are not refused with a conflict", func() {
+				So(ds.SaveIdempotencyRecordCalls(), ShouldHaveLength, 1)
+				So(ds.SaveIdempotencyRecordCalls()[0].Ctx.Err(), ShouldBeNil)
+				So(ds.DeleteIdempotencyRecordCalls(), ShouldHaveLength, 1)
+				So(ds.DeleteIdempotencyRecordCalls()[0].Ctx.Err(), ShouldBeNil)
+
+				retry := post(newFilter, "key-8")
+				So(retry.Code, ShouldEqual, http.StatusCreated)
+				So(retry.Header().Get("Idempotent-Replayed"), ShouldEqual, "true")
+				So(ds.AddFilterCalls(), ShouldHaveLength, 1)
+			})
+		})
+
+		Convey("When a request is retried after the lease of a reservation abandoned in progress has expired", func() {
+			abandoned := models.NewIdempotencyRecord("key-9", "POST /filters", models.HashIdempotentRequest([]byte("submitted=true"), []byte(newFilter)))
+			abandoned.CreatedAt = abandoned.CreatedAt.Add(-2 * time.Minute)
+			_, err := ds.ReserveIdempotencyRecord(context.Background(), abandoned, abandoned.CreatedAt.Add(-time.Hour), abandoned.CreatedAt.Add(-time.Minute))
+			So(err, ShouldBeNil)
+
+			w := post(newFilter, "key-9")
+
+			Convey("Then the reservation is taken over and the retry is processed", func() {
+				So(w.Code, ShouldEqual, http.StatusCreated)
+				So(w.Header().Get("Idempotent-Replayed"), ShouldBeEmpty)
+				So(ds.AddFilterCalls(), ShouldHaveLength, 1)
+			})
+
+			Convey("Then the abandoned request can no longer record its outcome over the reservation of the retry", func() {
+				abandoned.SetResponse(http.StatusCreated, http.Header{}, []byte("{}"))
+				So(ds.SaveIdempotencyRecord(context.Background(), abandoned), ShouldEqual, filters.ErrIdempotencyRecordNotFound)
+
+				replay := post(newFilter, "key-9")
+				So(replay.Header().Get("Idempotent-Replayed"), ShouldEqual, "true")
+				So(replay.Body.String(), ShouldEqual, w.Body.String())
+			})
+		})
+
+		Convey("When a request is retried while the lease of a reservation in progress has not expired", func() {
+			inProgress := models.NewIdempotencyRecord("key-10", "POST /filters", models.HashIdempotentRequest([]byte("submitted=true"), []byte(newFilter)))
+			inProgress.CreatedAt = inProgress.CreatedAt.Add(-30 * time.Second)
+			_, err := ds.ReserveIdempotencyRecord(context.Background(), inProgress, inProgress.CreatedAt.Add(-time.Hour), inProgress.CreatedAt.Add(-time.Minute))
+			So(err, ShouldBeNil)
+
+			w := post(newFilter, "key-10")
+
+			Convey("Then 409 conflict is returned and the retry is not processed", func() {
+				So(w.Code, ShouldEqual, http.StatusConflict)
+				So(ds.AddFilterCalls(), ShouldHaveLength, 0)
+			})
+		})
+
+		Convey("When the idempotency key cannot be reserved", func() {
+			ds.ReserveIdempotencyRecordFunc = func(ctx context.Context, record *models.IdempotencyRecord, expiredBefore, leaseExpiredBefore time.Time) (*models.IdempotencyRecord, error) {
+				return nil, errors.New("mongo is down")
+			}
+			w := post(newFilter, "key-4")
+
+			Convey("Then 500 internal server error is returned and the request is not processed", func() {
+				So(w.Code, ShouldEqual, http.StatusInternalServerError)
+				So(ds.AddFilterCalls(), ShouldHaveLength, 0)
+			})
+		})
+	})
+
+	Convey("Given idempotency keys are disabled", t, func() {
+		config := cfg()
+		config.IdempotencyKeyTTL = 0
+		ds := mock.NewDataStore().Mock
+		filterAPI := api.Setup(config, mux.NewRouter(), ds, &mock.FilterJob{}, &mock.DatasetAPI{}, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+
+		Convey("When a request is retried with the same idempotency key", func() {
+			for range 2 {
+				r, err := http.NewRequest("POST", cfg().Host+"/filters", strings.NewReader(newFilter))
+				So(err, ShouldBeNil)
+				r.Header.Set("Idempotency-Key", "key-1")
+				filterAPI.Router.ServeHTTP(httptest.NewRecorder(), r)
+			}
+
+			Convey("Then both requests are processed", func() {
+				So(ds.AddFilterCalls(), ShouldHaveLength, 2)
+				So(ds.ReserveIdempotencyRecordCalls(), ShouldHaveLength, 0)
+			})
+		})
+	})
+}
+
+func TestIdempotentSubmitFilterBlueprint(t *testing.T) {
+	t.Parallel()
+
+	filterFlexAPIMock := &apimock.FilterFlexAPIMock{}
+
+	Convey("Given idempotency keys are replayed for an hour, and reserved for a minute while in progress", t, func() {
+		config := cfg()
+		config.IdempotencyKeyTTL = time.Hour
+		config.IdempotencyKeyLease = time.Minute
+		ds := mock.NewDataStore().Mock
+		filterAPI := api.Setup(config, mux.NewRouter(), ds, &mock.FilterJob{}, &mock.DatasetAPI{}, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+
+		Convey("When a filter blueprint is submitted twice with the same idempotency key", func() {
+			var codes []int
+			for range 2 {
+				r := createAuthenticatedRequest("PUT", cfg().Host+"/filters/12345678?submitted=true", strings.NewReader("{}"))
+				r.Header.Set("If-Match", "*")
+				r.Header.Set("Idempotency-Key", "submit-1")
+				w := httptest.NewRecorder()
+				filterAPI.Router.ServeHTTP(w, r)
+				codes = append(codes, w.Code)
+			}
+
+			Convey("Then only one filter output is created, and the response is replayed", func() {
+				So(codes, ShouldResemble, []int{http.StatusOK, http.StatusOK})
+				So(ds.CreateFilterOutputCalls(), ShouldHaveLength, 1)
+			})
+		})
+
+		Convey("When a filter blueprint is submitted twice with different idempotency keys", func() {
+			for _, key := range []string{"submit-2", "submit-3"} {
+				r := createAuthenticatedRequest("PUT", cfg().Host+"/filters/12345678?submitted=true", strings.NewReader("{}"))
+				r.Header.Set("If-Match", "*")
+				r.Header.Set("Idempotency-Key", key)
+				filterAPI.Router.ServeHTTP(httptest.NewRecorder(), r)
+			}
+
+			Convey("Then both submissions are processed", func() {
+				So(ds.CreateFilterOutputCalls(), ShouldHaveLength, 2)
+			})
+		})
+	})
+}
@@ -0,0 +1,257 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	datasetAPI "github.com/ONSdigital/dp-api-clients-go/v2/dataset"
+	"github.com/ONSdigital/dp-filter-api/filters"
+	"github.com/ONSdigital/dp-filter-api/models"
+	dprequest "github.com/ONSdigital/dp-net/request"
+)
+
+const (
+	includeLabels = "labels"
+
+	// maximum number of labels kept in the cache before the expired ones are evicted
+	maxCachedLabels = 100000
+)
+
+// getIncludeLabels returns true if labels have been requested with the include query parameter,
+// which accepts a comma separated list of values, or if Welsh has been requested with the Accept-Language header
+func getIncludeLabels(r *http.Request) (bool, error) {
+	labels := getAcceptLanguage(r) == dprequest.LangCY
+
+	include := r.URL.Query().Get("include")
+	if include == "" {
+		return labels, nil
+	}
+
+	for _, value := range strings.Split(include, ",") {
+		if strings.TrimSpace(value) != includeLabels {
+			return false, filters.ErrInvalidQueryParameter
+		}
+		labels = true
+	}
+	return labels, nil
+}
+
+type cachedLabel struct {
+	label   string
+	expires time.Time
+}
+
+// labelCache keeps the labels of dataset dimensions and dimension options for a limited time,
+// so that they are not requested from the Dataset API every time a filter is labelled
+type labelCache struct {
+	mu     sync.Mutex
+	ttl    time.Duration
+	labels map[string]cachedLabel
+}
+
+func newLabelCache(ttl time.Duration) *labelCache {
+	return &labelCache{
+		ttl:    ttl,
+		labels: map[string]cachedLabel{},
+	}
+}
+
+func (c *labelCache) get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached, ok := c.labels[key]
+	if !ok || time.Now().After(cached.expires) {
+		return "", false
+	}
+	return cached.label, true
+}
+
+func (c *labelCache) set(key, label string) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if len(c.labels) >= maxCachedLabels {
+		for k, cached := range c.labels {
+			if now.After(cached.expires) {
+				delete(c.labels, k)
+			}
+		}
+		// if every label is still valid, the cache is emptied so that it does not grow unbounded
+		if len(c.labels) >= maxCachedLabels {
+			c.labels = map[string]cachedLabel{}
+		}
+	}
+	c.labels[key] = cachedLabel{label: label, expires: now.Add(c.ttl)}
+}
+
+func dimensionLabelKey(language string, dataset *models.Dataset, dimensionName string) string {
+	return fmt.Sprintf("%s/%s/%s/%d/%s", language, dataset.ID, dataset.Edition, dataset.Version, dimensionName)
+}
+
+func optionLabelKey(language string, dataset *models.Dataset, dimensionName, option string) string {
+	return fmt.Sprintf("%s/%s/%s/%d/%s/options/%s", language, dataset.ID, dataset.Edition, dataset.Version, dimensionName, option)
+}
+
+// getDimensionLabels returns a map of dimension names to labels for the provided dimensions of a dataset version,
+// in the language of the context. The dataset dimensions are only requested if any label is not cached.
+func (api *FilterAPI) getDimensionLabels(ctx context.Context, dataset *models.Dataset, dimensionNames []string) (map[string]string, error) {
+	language := getLanguage(ctx)
+	labels := make(map[string]string, len(dimensionNames))
+	missing := false
+	for _, name := range dimensionNames {
+		label, ok := api.labels.get(dimensionLabelKey(language, dataset, name))
+		if !ok {
+			missing = true
+			break
+		}
+		labels[name] = label
+	}
+
+	if !missing {
+		return labels, nil
+	}
+
+	dimensions, err := api.getDimensions(ctx, dataset)
+	if err != nil {
+		return nil, err
+	}
+
+	untranslated := []string{}
+	for _, d := range dimensions.Items {
+		if d.Label == "" && language != dprequest.DefaultLang {
+			untranslated = append(untranslated, d.Name)
+			continue
+		}
+		api.labels.set(dimensionLabelKey(language, dataset, d.Name), d.Label)
+		labels[d.Name] = d.Label
+	}
+
+	if len(untranslated) > 0 {
+		fallback, err := api.getDimensionLabels(withLanguage(ctx, dprequest.DefaultLang), dataset, untranslated)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range untranslated {
+			api.labels.set(dimensionLabelKey(language, dataset, name), fallback[name])
+			labels[name] = fallback[name]
+		}
+	}
+	return labels, nil
+}
+
+// getOptionLabels returns a map of option codes to labels for the provided options of a dataset dimension,
+// in the language of the context. Only the options whose label is not cached are requested from the Dataset API.
+func (api *FilterAPI) getOptionLabels(ctx context.Context, dataset *models.Dataset, dimensionName string, options []string) (map[string]string, error) {
+	language := getLanguage(ctx)
+	labels := make(map[string]string, len(options))
+	missing := []string{}
+	for _, option := range options {
+		label, ok := api.labels.get(optionLabelKey(language, dataset, dimensionName, option))
+		if !ok {
+			missing = append(missing, option)
+			continue
+		}
+		labels[option] = label
+	}
+
+	if len(missing) == 0 {
+		return labels, nil
+	}
+
+	untranslated := []string{}
+	processBatch := func(batch datasetAPI.Options) (abort bool, err error) {
+		for _, opt := range batch.Items {
+			if opt.Label == "" && language != dprequest.DefaultLang {
+				untranslated = append(untranslated, opt.Option)
+				continue
+			}
+			api.labels.set(optionLabelKey(language, dataset, dimensionName, opt.Option), opt.Label)
+			labels[opt.Option] = opt.Label
+		}
+		return false, nil
+	}
+
+	err := api.datasetAPI.GetOptionsBatchProcess(ctx,
+		getUserAuthToken(ctx),
+		api.serviceAuthToken,
+		getCollectionID(ctx),
+		dataset.ID,
+		dataset.Edition,
+		strconv.Itoa(dataset.Version),
+		dimensionName,
+		&missing,
+		processBatch,
+		api.maxDatasetOptions,
+		api.BatchMaxWorkers)
+	if err != nil {
+		if apiErr, ok := err.(*datasetAPI.ErrInvalidDatasetAPIResponse); ok {
+			if apiErr.Code() == http.StatusNotFound {
+				return nil, filters.ErrDimensionOptionsNotFound
+			}
+		}
+		return nil, err
+	}
+
+	if len(untranslated) > 0 {
+		fallback, err := api.getOptionLabels(withLanguage(ctx, dprequest.DefaultLang), dataset, dimensionName, untranslated)
+		if err != nil {
+			return nil, err
+		}
+		for _, option := range untranslated {
+			api.labels.set(optionLabelKey(language, dataset, dimensionName, option), fallback[option])
+			labels[option] = fallback[option]
+		}
+	}
+	return labels, nil
+}
+
+// labelPublicDimensions sets the label of each of the provided public dimensions of a filter
+func (api *FilterAPI) labelPublicDimensions(ctx context.Context, dataset *models.Dataset, dimensions []*models.PublicDimension) error {
+	names := make([]string, len(dimensions))
+	for i, d := range dimensions {
+		names[i] = d.Name
+	}
+
+	labels, err := api.getDimensionLabels(ctx, dataset, names)
+	if err != nil {
+		return err
+	}
+
+	for _, d := range dimensions {
+		d.Label = labels[d.Name]
+	}
+	return nil
+}
+
+// labelPublicDimensionOptions sets the label of each of the provided public options of a filter dimension
+func (api *FilterAPI) labelPublicDimensionOptions(ctx context.Context, dataset *models.Dataset, dimensionName string, options []*models.PublicDimensionOption) error {
+	if len(options) == 0 {
+		return nil
+	}
+
+	codes := make([]string, len(options))
+	for i, o := range options {
+		codes[i] = o.Option
+	}
+
+	labels, err := api.getOptionLabels(ctx, dataset, dimensionName, codes)
+	if err != nil {
+		return err
+	}
+
+	for _, o := range options {
+		o.Label = labels[o.Option]
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-api-clients-go/v2/dataset"
+	"github.com/ONSdigital/dp-filter-api/api"
+	apimock "github.com/ONSdigital/dp-filter-api/api/mock"
+	"github.com/ONSdigital/dp-filter-api/mock"
+	"github.com/ONSdigital/dp-filter-api/models"
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// labelsDatasetAPIMock returns a dataset API mock that provides labels for the dimensions and options of the mocked filter blueprint
+func labelsDatasetAPIMock() *apimock.DatasetAPIMock {
+	optionLabels := map[string]string{"27": "27 years", "33": "33 years"}
+
+	return &apimock.DatasetAPIMock{
+		GetVersionDimensionsFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, collectionID string, id string, edition string, version string) (dataset.VersionDimensions, error) {
+			return dataset.VersionDimensions{Items: []dataset.VersionDimension{
+				{Name: "age", Label: "Age"},
+				{Name: "time", Label: "Time"},
+				{Name: "1_age", Label: "Age group"},
+			}}, nil
+		},
+		GetOptionsBatchProcessFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, collectionID string, id string, edition string, version string, dimension string, optionIDs *[]string, processBatch dataset.OptionsBatchProcessor, batchSize int, maxWorkers int) error {
+			items := []dataset.Option{}
+			for _, option := range *optionIDs {
+				if label, ok := optionLabels[option]; ok {
+					items = append(items, dataset.Option{Option: option, Label: label})
+				}
+			}
+			_, err := processBatch(dataset.Options{Items: items, Count: len(items), TotalCount: len(items)})
+			return err
+		},
+	}
+}
+
+func TestGetFilterBlueprintDimensions_IncludeLabels(t *testing.T) {
+	t.Parallel()
+
+	filterFlexAPIMock := &apimock.FilterFlexAPIMock{}
+
+	Convey("Given a filter blueprint with dimensions and a label cache", t, func() {
+		labelsCfg := cfg()
+		labelsCfg.LabelCacheTTL = time.Minute
+		datasetAPIMock := labelsDatasetAPIMock()
+		filterAPI := api.Setup(labelsCfg, mux.NewRouter(), mock.NewDataStore().Mock, &mock.FilterJob{}, datasetAPIMock, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+
+		Convey("When the dimensions are requested with labels", func() {
+			w := httptest.NewRecorder()
+			r, err := http.NewRequest("GET", "http://localhost:22100/filters/12345678/dimensions?include=labels", http.NoBody)
+			So(err, ShouldBeNil)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the dimension labels from the dataset are returned", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				var dimensions models.PublicDimensions
+				So(json.Unmarshal(w.Body.Bytes(), &dimensions), ShouldBeNil)
+				So(dimensions.Items, ShouldHaveLength, 3)
+				So(dimensions.Items[0].Name, ShouldEqual, "1_age")
+				So(dimensions.Items[0].Label, ShouldEqual, "Age group")
+				So(dimensions.Items[1].Label, ShouldEqual, "Age")
+				So(dimensions.Items[2].Label, ShouldEqual, "Time")
+			})
+
+			Convey("Then a single dimension requested with labels uses the cached labels", func() {
+				w = httptest.NewRecorder()
+				r, err = http.NewRequest("GET", "http://localhost:22100/filters/12345678/dimensions/age?include=labels", http.NoBody)
+				So(err, ShouldBeNil)
+				filterAPI.Router.ServeHTTP(w, r)
+
+				So(w.Code, ShouldEqual, http.StatusOK)
+				var dimension models.PublicDimension
+				So(json.Unmarshal(w.Body.Bytes(), &dimension), ShouldBeNil)
+				So(dimension.Name, ShouldEqual, "1_age")
+				So(dimension.Label, ShouldEqual, "Age group")
+				So(datasetAPIMock.GetVersionDimensionsCalls(), ShouldHaveLength, 1)
+			})
+		})
+
+		Convey("When the dimensions are requested without labels", func() {
+			w := httptest.NewRecorder()
+			r, err := http.NewRequest("GET", "http://localhost:22100/filters/12345678/dimensions", http.NoBody)
+			So(err, ShouldBeNil)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then no labels are returned and the dataset API is not called", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Body.String(), ShouldNotContainSubstring, `"label"`)
+				So(datasetAPIMock.GetVersionDimensionsCalls(), ShouldHaveLength, 0)
+			})
+		})
+
+		Convey("When the dimensions are requested with an unsupported include value", func() {
+			w := httptest.NewRecorder()
+			r, err := http.NewRequest("GET", "http://localhost:22100/filters/12345678/dimensions?include=descriptions", http.NoBody)
+			So(err, ShouldBeNil)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the response is 400 bad request", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(problemDetail(w.Body.String()), ShouldEqual, invalidQueryParameterResponse)
+			})
+		})
+	})
+}
+
+func TestGetFilterBlueprintDimensionOptions_IncludeLabels(t *testing.T) {
+	t.Parallel()
+
+	filterFlexAPIMock := &apimock.FilterFlexAPIMock{}
+
+	Convey("Given a filter blueprint with dimension options and a label cache", t, func() {
+		labelsCfg := cfg()
+		labelsCfg.LabelCacheTTL = time.Minute
+		datasetAPIMock := labelsDatasetAPIMock()
+		filterAPI := api.Setup(labelsCfg, mux.NewRouter(), mock.NewDataStore().Mock, &mock.FilterJob{}, datasetAPIMock, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+
+		Convey("When the dimension options are requested with labels", func() {
+			w := httptest.NewRecorder()
+			r, err := http.NewRequest("GET", "http://localhost:22100/filters/12345678/dimensions/age/options?include=labels", http.NoBody)
+			So(err, ShouldBeNil)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the option labels from the dataset are returned", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				var options models.PublicDimensionOptions
+				So(json.Unmarshal(w.Body.Bytes(), &options), ShouldBeNil)
+				So(options.Items, ShouldHaveLength, 1)
+				So(options.Items[0].Option, ShouldEqual, "33")
+				So(options.Items[0].Label, ShouldEqual, "33 years")
+				So(*datasetAPIMock.GetOptionsBatchProcessCalls()[0].OptionIDs, ShouldResemble, []string{"33"})
+			})
+
+			Convey("Then a single option requested with labels uses the cached labels", func() {
+				w = httptest.NewRecorder()
+				r, err = http.NewRequest("GET", "http://localhost:22100/filters/12345678/dimensions/age/options/33?include=labels", http.NoBody)
+				So(err, ShouldBeNil)
+				filterAPI.Router.ServeHTTP(w, r)
+
+				So(w.Code, ShouldEqual, http.StatusOK)
+				var option models.PublicDimensionOption
+				So(json.Unmarshal(w.Body.Bytes(), &option), ShouldBeNil)
+				So(option.Label, ShouldEqual, "33 years")
+				So(datasetAPIMock.GetOptionsBatchProcessCalls(), ShouldHaveLength, 1)
+			})
+		})
+	})
+}
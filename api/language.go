@@ -0,0 +1,61 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+
+	dprequest "github.com/ONSdigital/dp-net/request"
+)
+
+const (
+	acceptLanguageHeader  = "Accept-Language"
+	contentLanguageHeader = "Content-Language"
+)
+
+// getAcceptLanguage returns the supported language preferred by the Accept-Language header of the request,
+// which is English if the header is not provided or does not contain any supported language
+func getAcceptLanguage(r *http.Request) string {
+	language := dprequest.DefaultLang
+	quality := 0.0
+
+	for _, value := range strings.Split(r.Header.Get(acceptLanguageHeader), ",") {
+		tag, params, _ := strings.Cut(value, ";")
+
+		q := 1.0
+		if weight, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(weight, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+
+		// regional variants, like cy-GB, are served in their base language
+		base, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		if (base == dprequest.LangEN || base == dprequest.LangCY) && q > quality {
+			language = base
+			quality = q
+		}
+	}
+	return language
+}
+
+// withLanguage returns a copy of the context with the language in which content is requested,
+// which is forwarded to the Dataset API when labels are requested
+func withLanguage(ctx context.Context, language string) context.Context {
+	return context.WithValue(ctx, dprequest.LocaleContextKey, language)
+}
+
+// getLanguage returns the language in which content is requested, or English if not set in the context
+func getLanguage(ctx context.Context) string {
+	if language, ok := ctx.Value(dprequest.LocaleContextKey).(string); ok && language != "" {
+		return language
+	}
+	return dprequest.DefaultLang
+}
+
+func setContentLanguage(w http.ResponseWriter, language string) {
+	w.Header().Set(contentLanguageHeader, language)
+}
@@ -0,0 +1,144 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ONSdigital/dp-api-clients-go/v2/dataset"
+	"github.com/ONSdigital/dp-filter-api/api"
+	apimock "github.com/ONSdigital/dp-filter-api/api/mock"
+	"github.com/ONSdigital/dp-filter-api/mock"
+	"github.com/ONSdigital/dp-filter-api/models"
+	dprequest "github.com/ONSdigital/dp-net/request"
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// welshDatasetAPIMock returns a dataset API mock that provides Welsh labels, apart from the time dimension,
+// when Welsh is requested in the context
+func welshDatasetAPIMock() *apimock.DatasetAPIMock {
+	datasetAPIMock := labelsDatasetAPIMock()
+	datasetAPIMock.GetVersionDimensionsFunc = func(ctx context.Context, userAuthToken string, serviceAuthToken string, collectionID string, id string, edition string, version string) (dataset.VersionDimensions, error) {
+		if ctx.Value(dprequest.LocaleContextKey) == dprequest.LangCY {
+			return dataset.VersionDimensions{Items: []dataset.VersionDimension{
+				{Name: "age", Label: "Oedran"},
+				{Name: "time", Label: ""},
+				{Name: "1_age", Label: "Grŵp oedran"},
+			}}, nil
+		}
+		return labelsDatasetAPIMock().GetVersionDimensions(ctx, userAuthToken, serviceAuthToken, collectionID, id, edition, version)
+	}
+	datasetAPIMock.GetOptionsBatchProcessFunc = func(ctx context.Context, userAuthToken string, serviceAuthToken string, collectionID string, id string, edition string, version string, dimension string, optionIDs *[]string, processBatch dataset.OptionsBatchProcessor, batchSize int, maxWorkers int) error {
+		if ctx.Value(dprequest.LocaleContextKey) == dprequest.LangCY {
+			_, err := processBatch(dataset.Options{Items: []dataset.Option{{Option: "33", Label: "33 oed"}}, Count: 1, TotalCount: 1})
+			return err
+		}
+		return labelsDatasetAPIMock().GetOptionsBatchProcess(ctx, userAuthToken, serviceAuthToken, collectionID, id, edition, version, dimension, optionIDs, processBatch, batchSize, maxWorkers)
+	}
+	return datasetAPIMock
+}
+
+func TestGetFilterBlueprintDimensions_Welsh(t *testing.T) {
+	t.Parallel()
+
+	filterFlexAPIMock := &apimock.FilterFlexAPIMock{}
+
+	Convey("Given a filter blueprint for a dataset with Welsh labels", t, func() {
+		datasetAPIMock := welshDatasetAPIMock()
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), mock.NewDataStore().Mock, &mock.FilterJob{}, datasetAPIMock, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+		w := httptest.NewRecorder()
+
+		Convey("When the dimensions are requested in Welsh", func() {
+			r, err := http.NewRequest("GET", "http://localhost:22100/filters/12345678/dimensions", http.NoBody)
+			So(err, ShouldBeNil)
+			r.Header.Set("Accept-Language", "cy-GB, en;q=0.5")
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the Welsh labels are returned, falling back to English for labels not available in Welsh", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Header().Get("Content-Language"), ShouldEqual, dprequest.LangCY)
+
+				var dimensions models.PublicDimensions
+				So(json.Unmarshal(w.Body.Bytes(), &dimensions), ShouldBeNil)
+				So(dimensions.Items, ShouldHaveLength, 3)
+				So(dimensions.Items[0].Label, ShouldEqual, "Grŵp oedran")
+				So(dimensions.Items[1].Label, ShouldEqual, "Oedran")
+				So(dimensions.Items[2].Label, ShouldEqual, "Time")
+				So(datasetAPIMock.GetVersionDimensionsCalls(), ShouldHaveLength, 2)
+			})
+		})
+
+		Convey("When the dimension options are requested in Welsh", func() {
+			r, err := http.NewRequest("GET", "http://localhost:22100/filters/12345678/dimensions/age/options", http.NoBody)
+			So(err, ShouldBeNil)
+			r.Header.Set("Accept-Language", "cy")
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the Welsh option labels are returned", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Header().Get("Content-Language"), ShouldEqual, dprequest.LangCY)
+
+				var options models.PublicDimensionOptions
+				So(json.Unmarshal(w.Body.Bytes(), &options), ShouldBeNil)
+				So(options.Items, ShouldHaveLength, 1)
+				So(options.Items[0].Label, ShouldEqual, "33 oed")
+			})
+		})
+
+		Convey("When the dimensions are requested preferring English", func() {
+			r, err := http.NewRequest("GET", "http://localhost:22100/filters/12345678/dimensions", http.NoBody)
+			So(err, ShouldBeNil)
+			r.Header.Set("Accept-Language", "en-GB, cy;q=0.8")
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then no labels are returned unless requested with the include query parameter", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Body.String(), ShouldNotContainSubstring, `"label"`)
+				So(datasetAPIMock.GetVersionDimensionsCalls(), ShouldHaveLength, 0)
+			})
+		})
+	})
+}
+
+func TestSubmitFilterBlueprint_Language(t *testing.T) {
+	t.Parallel()
+
+	filterFlexAPIMock := &apimock.FilterFlexAPIMock{}
+
+	Convey("Given a filter API", t, func() {
+		ds := mock.NewDataStore().Mock
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), ds, &mock.FilterJob{}, mock.NewDatasetAPI().Mock, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+		w := httptest.NewRecorder()
+
+		Convey("When a filter blueprint is submitted in Welsh", func() {
+			reader := strings.NewReader(`{"dataset":{"version":1, "edition":"1", "id":"1"} }`)
+			r, err := http.NewRequest("POST", cfg().Host+"/filters?submitted=true", reader)
+			So(err, ShouldBeNil)
+			r.Header.Set("Accept-Language", "cy")
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the language is recorded on the filter output", func() {
+				So(w.Code, ShouldEqual, http.StatusCreated)
+				So(ds.CreateFilterOutputCalls(), ShouldHaveLength, 1)
+				So(ds.CreateFilterOutputCalls()[0].Filter.Language, ShouldEqual, dprequest.LangCY)
+			})
+		})
+
+		Convey("When a filter blueprint is submitted without a language", func() {
+			reader := strings.NewReader(`{"dataset":{"version":1, "edition":"1", "id":"1"} }`)
+			r, err := http.NewRequest("POST", cfg().Host+"/filters?submitted=true", reader)
+			So(err, ShouldBeNil)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the filter output is recorded in English", func() {
+				So(w.Code, ShouldEqual, http.StatusCreated)
+				So(ds.CreateFilterOutputCalls(), ShouldHaveLength, 1)
+				So(ds.CreateFilterOutputCalls()[0].Filter.Language, ShouldEqual, dprequest.LangEN)
+			})
+		})
+	})
+}
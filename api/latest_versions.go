@@ -0,0 +1,88 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ONSdigital/dp-filter-api/models"
+)
+
+// maximum number of dataset editions whose latest version is kept in the cache before the expired ones are evicted
+const maxCachedLatestVersions = 10000
+
+type cachedLatestVersion struct {
+	version int
+	expires time.Time
+}
+
+// latestVersionCache keeps the latest published version of dataset editions for a limited time,
+// so that the Dataset API is not requested every time a filter blueprint is flagged with newer_version_available
+type latestVersionCache struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	versions map[string]cachedLatestVersion
+}
+
+func newLatestVersionCache(ttl time.Duration) *latestVersionCache {
+	return &latestVersionCache{
+		ttl:      ttl,
+		versions: map[string]cachedLatestVersion{},
+	}
+}
+
+func (c *latestVersionCache) get(key string) (int, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached, ok := c.versions[key]
+	if !ok || time.Now().After(cached.expires) {
+		return 0, false
+	}
+	return cached.version, true
+}
+
+func (c *latestVersionCache) set(key string, version int) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if len(c.versions) >= maxCachedLatestVersions {
+		for k, cached := range c.versions {
+			if now.After(cached.expires) {
+				delete(c.versions, k)
+			}
+		}
+		// if every version is still valid, the cache is emptied so that it does not grow unbounded
+		if len(c.versions) >= maxCachedLatestVersions {
+			c.versions = map[string]cachedLatestVersion{}
+		}
+	}
+	c.versions[key] = cachedLatestVersion{version: version, expires: now.Add(c.ttl)}
+}
+
+func latestVersionKey(dataset *models.Dataset) string {
+	return fmt.Sprintf("%s/%s", dataset.ID, dataset.Edition)
+}
+
+// getCachedLatestVersion resolves the latest published version of the edition of the provided dataset,
+// requesting it from the Dataset API only if it is not cached
+func (api *FilterAPI) getCachedLatestVersion(ctx context.Context, dataset *models.Dataset) (int, error) {
+	key := latestVersionKey(dataset)
+	if latest, ok := api.latestVersions.get(key); ok {
+		return latest, nil
+	}
+
+	latest, err := api.getLatestVersion(ctx, dataset)
+	if err != nil {
+		return 0, err
+	}
+
+	api.latestVersions.set(key, latest)
+	return latest, nil
+}
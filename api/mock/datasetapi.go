@@ -16,32 +16,38 @@ var _ api.DatasetAPI = &DatasetAPIMock{}
 
 // DatasetAPIMock is a mock implementation of api.DatasetAPI.
 //
-// 	func TestSomethingThatUsesDatasetAPI(t *testing.T) {
+//	func TestSomethingThatUsesDatasetAPI(t *testing.T) {
 //
-// 		// make and configure a mocked api.DatasetAPI
-// 		mockedDatasetAPI := &DatasetAPIMock{
-// 			GetFunc: func(ctx context.Context, userToken string, svcToken string, collectionID string, datasetID string) (datasetAPI.DatasetDetails, error) {
-// 				panic("mock out the Get method")
-// 			},
-// 			GetOptionsBatchProcessFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, collectionID string, id string, edition string, version string, dimension string, optionIDs *[]string, processBatch datasetAPI.OptionsBatchProcessor, batchSize int, maxWorkers int) error {
-// 				panic("mock out the GetOptionsBatchProcess method")
-// 			},
-// 			GetVersionFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, downloadServiceAuthToken string, collectionID string, datasetID string, edition string, version string) (datasetAPI.Version, error) {
-// 				panic("mock out the GetVersion method")
-// 			},
-// 			GetVersionDimensionsFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, collectionID string, id string, edition string, version string) (datasetAPI.VersionDimensions, error) {
-// 				panic("mock out the GetVersionDimensions method")
-// 			},
-// 		}
+//		// make and configure a mocked api.DatasetAPI
+//		mockedDatasetAPI := &DatasetAPIMock{
+//			GetFunc: func(ctx context.Context, userToken string, svcToken string, collectionID string, datasetID string) (datasetAPI.DatasetDetails, error) {
+//				panic("mock out the Get method")
+//			},
+//			GetEditionFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, collectionID string, datasetID string, edition string) (datasetAPI.Edition, error) {
+//				panic("mock out the GetEdition method")
+//			},
+//			GetOptionsBatchProcessFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, collectionID string, id string, edition string, version string, dimension string, optionIDs *[]string, processBatch datasetAPI.OptionsBatchProcessor, batchSize int, maxWorkers int) error {
+//				panic("mock out the GetOptionsBatchProcess method")
+//			},
+//			GetVersionFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, downloadServiceAuthToken string, collectionID string, datasetID string, edition string, version string) (datasetAPI.Version, error) {
+//				panic("mock out the GetVersion method")
+//			},
+//			GetVersionDimensionsFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, collectionID string, id string, edition string, version string) (datasetAPI.VersionDimensions, error) {
+//				panic("mock out the GetVersionDimensions method")
+//			},
+//		}
 //
-// 		// use mockedDatasetAPI in code that requires api.DatasetAPI
-// 		// and then make assertions.
+//		// use mockedDatasetAPI in code that requires api.DatasetAPI
+//		// and then make assertions.
 //
-// 	}
+//	}
 type DatasetAPIMock struct {
 	// GetFunc mocks the Get method.
 	GetFunc func(ctx context.Context, userToken string, svcToken string, collectionID string, datasetID string) (datasetAPI.DatasetDetails, error)
 
+	// GetEditionFunc mocks the GetEdition method.
+	GetEditionFunc func(ctx context.Context, userAuthToken string, serviceAuthToken string, collectionID string, datasetID string, edition string) (datasetAPI.Edition, error)
+
 	// GetOptionsBatchProcessFunc mocks the GetOptionsBatchProcess method.
 	GetOptionsBatchProcessFunc func(ctx context.Context, userAuthToken string, serviceAuthToken string, collectionID string, id string, edition string, version string, dimension string, optionIDs *[]string, processBatch datasetAPI.OptionsBatchProcessor, batchSize int, maxWorkers int) error
 
@@ -66,6 +72,21 @@ type DatasetAPIMock struct {
 			// DatasetID is the datasetID argument value.
 			DatasetID string
 		}
+		// GetEdition holds details about calls to the GetEdition method.
+		GetEdition []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// UserAuthToken is the userAuthToken argument value.
+			UserAuthToken string
+			// ServiceAuthToken is the serviceAuthToken argument value.
+			ServiceAuthToken string
+			// CollectionID is the collectionID argument value.
+			CollectionID string
+			// DatasetID is the datasetID argument value.
+			DatasetID string
+			// Edition is the edition argument value.
+			Edition string
+		}
 		// GetOptionsBatchProcess holds details about calls to the GetOptionsBatchProcess method.
 		GetOptionsBatchProcess []struct {
 			// Ctx is the ctx argument value.
@@ -131,6 +152,7 @@ type DatasetAPIMock struct {
 		}
 	}
 	lockGet                    sync.RWMutex
+	lockGetEdition             sync.RWMutex
 	lockGetOptionsBatchProcess sync.RWMutex
 	lockGetVersion             sync.RWMutex
 	lockGetVersionDimensions   sync.RWMutex
@@ -162,7 +184,8 @@ func (mock *DatasetAPIMock) Get(ctx context.Context, userToken string, svcToken
 
 // GetCalls gets all the calls that were made to Get.
 // Check the length with:
-//     len(mockedDatasetAPI.GetCalls())
+//
+//	len(mockedDatasetAPI.GetCalls())
 func (mock *DatasetAPIMock) GetCalls() []struct {
 	Ctx          context.Context
 	UserToken    string
@@ -183,6 +206,58 @@ func (mock *DatasetAPIMock) GetCalls() []struct {
 	return calls
 }
 
+// GetEdition calls GetEditionFunc.
+func (mock *DatasetAPIMock) GetEdition(ctx context.Context, userAuthToken string, serviceAuthToken string, collectionID string, datasetID string, edition string) (datasetAPI.Edition, error) {
+	if mock.GetEditionFunc == nil {
+		panic("DatasetAPIMock.GetEditionFunc: method is nil but DatasetAPI.GetEdition was just called")
+	}
+	callInfo := struct {
+		Ctx              context.Context
+		UserAuthToken    string
+		ServiceAuthToken string
+		CollectionID     string
+		DatasetID        string
+		Edition          string
+	}{
+		Ctx:              ctx,
+		UserAuthToken:    userAuthToken,
+		ServiceAuthToken: serviceAuthToken,
+		CollectionID:     collectionID,
+		DatasetID:        datasetID,
+		Edition:          edition,
+	}
+	mock.lockGetEdition.Lock()
+	mock.calls.GetEdition = append(mock.calls.GetEdition, callInfo)
+	mock.lockGetEdition.Unlock()
+	return mock.GetEditionFunc(ctx, userAuthToken, serviceAuthToken, collectionID, datasetID, edition)
+}
+
+// GetEditionCalls gets all the calls that were made to GetEdition.
+// Check the length with:
+//
+//	len(mockedDatasetAPI.GetEditionCalls())
+func (mock *DatasetAPIMock) GetEditionCalls() []struct {
+	Ctx              context.Context
+	UserAuthToken    string
+	ServiceAuthToken string
+	CollectionID     string
+	DatasetID        string
+	Edition          string
+} {
+	var calls []struct {
+		Ctx              context.Context
+		UserAuthToken    string
+		ServiceAuthToken string
+		CollectionID     string
+		DatasetID        string
+		Edition          string
+	}
+	mock.lockGetEdition.RLock()
+	calls = mock.calls.GetEdition
+	mock.lockGetEdition.RUnlock()
+	return calls
+}
+
 // GetOptionsBatchProcess calls GetOptionsBatchProcessFunc.
 func (mock *DatasetAPIMock) GetOptionsBatchProcess(ctx context.Context, userAuthToken string, serviceAuthToken string, collectionID string, id string, edition string, version string, dimension string, optionIDs *[]string, processBatch datasetAPI.OptionsBatchProcessor, batchSize int, maxWorkers int) error {
 	if mock.GetOptionsBatchProcessFunc == nil {
@@ -223,7 +298,8 @@ func (mock *DatasetAPIMock) GetOptionsBatchProcess(ctx context.Context, userAuth
 
 // GetOptionsBatchProcessCalls gets all the calls that were made to GetOptionsBatchProcess.
 // Check the length with:
-//     len(mockedDatasetAPI.GetOptionsBatchProcessCalls())
+//
+//	len(mockedDatasetAPI.GetOptionsBatchProcessCalls())
 func (mock *DatasetAPIMock) GetOptionsBatchProcessCalls() []struct {
 	Ctx              context.Context
 	UserAuthToken    string
@@ -290,7 +366,8 @@ func (mock *DatasetAPIMock) GetVersion(ctx context.Context, userAuthToken string
 
 // GetVersionCalls gets all the calls that were made to GetVersion.
 // Check the length with:
-//     len(mockedDatasetAPI.GetVersionCalls())
+//
+//	len(mockedDatasetAPI.GetVersionCalls())
 func (mock *DatasetAPIMock) GetVersionCalls() []struct {
 	Ctx                      context.Context
 	UserAuthToken            string
@@ -347,7 +424,8 @@ func (mock *DatasetAPIMock) GetVersionDimensions(ctx context.Context, userAuthTo
 
 // GetVersionDimensionsCalls gets all the calls that were made to GetVersionDimensions.
 // Check the length with:
-//     len(mockedDatasetAPI.GetVersionDimensionsCalls())
+//
+//	len(mockedDatasetAPI.GetVersionDimensionsCalls())
 func (mock *DatasetAPIMock) GetVersionDimensionsCalls() []struct {
 	Ctx              context.Context
 	UserAuthToken    string
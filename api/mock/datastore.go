@@ -10,6 +10,7 @@ import (
 	mongodriver "github.com/ONSdigital/dp-mongodb/v3/mongodb"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"sync"
+	"time"
 )
 
 // Ensure, that DataStoreMock does implement api.DataStore.
@@ -28,7 +29,7 @@ var _ api.DataStore = &DataStoreMock{}
 //			AddFilterFunc: func(ctx context.Context, filter *models.Filter) (*models.Filter, error) {
 //				panic("mock out the AddFilter method")
 //			},
-//			AddFilterDimensionFunc: func(ctx context.Context, filterID string, name string, options []string, dimensions []models.Dimension, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error) {
+//			AddFilterDimensionFunc: func(ctx context.Context, filterID string, name string, mode string, options []string, ranges []models.OptionRange, dimensions []models.Dimension, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error) {
 //				panic("mock out the AddFilterDimension method")
 //			},
 //			AddFilterDimensionOptionFunc: func(ctx context.Context, filterID string, name string, option string, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error) {
@@ -40,6 +41,9 @@ var _ api.DataStore = &DataStoreMock{}
 //			CreateFilterOutputFunc: func(ctx context.Context, filter *models.Filter) error {
 //				panic("mock out the CreateFilterOutput method")
 //			},
+//			DeleteIdempotencyRecordFunc: func(ctx context.Context, record *models.IdempotencyRecord) error {
+//				panic("mock out the DeleteIdempotencyRecord method")
+//			},
 //			GetFilterFunc: func(ctx context.Context, filterID string, eTagSelector string) (*models.Filter, error) {
 //				panic("mock out the GetFilter method")
 //			},
@@ -49,6 +53,15 @@ var _ api.DataStore = &DataStoreMock{}
 //			GetFilterOutputFunc: func(ctx context.Context, filterOutputID string) (*models.Filter, error) {
 //				panic("mock out the GetFilterOutput method")
 //			},
+//			GetFilterSnapshotFunc: func(ctx context.Context, filterID string, eTag string) (*models.FilterSnapshot, error) {
+//				panic("mock out the GetFilterSnapshot method")
+//			},
+//			PublishFilterBlueprintsFunc: func(ctx context.Context, instanceID string) (int, error) {
+//				panic("mock out the PublishFilterBlueprints method")
+//			},
+//			PublishFilterOutputsFunc: func(ctx context.Context, instanceID string) ([]string, error) {
+//				panic("mock out the PublishFilterOutputs method")
+//			},
 //			RemoveFilterDimensionFunc: func(ctx context.Context, filterID string, name string, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error) {
 //				panic("mock out the RemoveFilterDimension method")
 //			},
@@ -58,12 +71,24 @@ var _ api.DataStore = &DataStoreMock{}
 //			RemoveFilterDimensionOptionsFunc: func(ctx context.Context, filterID string, name string, options []string, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error) {
 //				panic("mock out the RemoveFilterDimensionOptions method")
 //			},
+//			ReplaceFilterFunc: func(ctx context.Context, updatedFilter *models.Filter, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error) {
+//				panic("mock out the ReplaceFilter method")
+//			},
+//			ReserveIdempotencyRecordFunc: func(ctx context.Context, record *models.IdempotencyRecord, expiredBefore time.Time, leaseExpiredBefore time.Time) (*models.IdempotencyRecord, error) {
+//				panic("mock out the ReserveIdempotencyRecord method")
+//			},
 //			RunTransactionFunc: func(ctx context.Context, retry bool, fn mongodriver.TransactionFunc) (interface{}, error) {
 //				panic("mock out the RunTransaction method")
 //			},
+//			SaveIdempotencyRecordFunc: func(ctx context.Context, record *models.IdempotencyRecord) error {
+//				panic("mock out the SaveIdempotencyRecord method")
+//			},
 //			UpdateFilterFunc: func(ctx context.Context, updatedFilter *models.Filter, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error) {
 //				panic("mock out the UpdateFilter method")
 //			},
+//			UpdateFilterDimensionFunc: func(ctx context.Context, filterID string, dimension models.Dimension, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error) {
+//				panic("mock out the UpdateFilterDimension method")
+//			},
 //			UpdateFilterOutputFunc: func(ctx context.Context, filter *models.Filter, timestamp primitive.Timestamp) error {
 //				panic("mock out the UpdateFilterOutput method")
 //			},
@@ -81,7 +106,7 @@ type DataStoreMock struct {
 	AddFilterFunc func(ctx context.Context, filter *models.Filter) (*models.Filter, error)
 
 	// AddFilterDimensionFunc mocks the AddFilterDimension method.
-	AddFilterDimensionFunc func(ctx context.Context, filterID string, name string, options []string, dimensions []models.Dimension, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error)
+	AddFilterDimensionFunc func(ctx context.Context, filterID string, name string, mode string, options []string, ranges []models.OptionRange, dimensions []models.Dimension, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error)
 
 	// AddFilterDimensionOptionFunc mocks the AddFilterDimensionOption method.
 	AddFilterDimensionOptionFunc func(ctx context.Context, filterID string, name string, option string, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error)
@@ -92,6 +117,9 @@ type DataStoreMock struct {
 	// CreateFilterOutputFunc mocks the CreateFilterOutput method.
 	CreateFilterOutputFunc func(ctx context.Context, filter *models.Filter) error
 
+	// DeleteIdempotencyRecordFunc mocks the DeleteIdempotencyRecord method.
+	DeleteIdempotencyRecordFunc func(ctx context.Context, record *models.IdempotencyRecord) error
+
 	// GetFilterFunc mocks the GetFilter method.
 	GetFilterFunc func(ctx context.Context, filterID string, eTagSelector string) (*models.Filter, error)
 
@@ -101,6 +129,15 @@ type DataStoreMock struct {
 	// GetFilterOutputFunc mocks the GetFilterOutput method.
 	GetFilterOutputFunc func(ctx context.Context, filterOutputID string) (*models.Filter, error)
 
+	// GetFilterSnapshotFunc mocks the GetFilterSnapshot method.
+	GetFilterSnapshotFunc func(ctx context.Context, filterID string, eTag string) (*models.FilterSnapshot, error)
+
+	// PublishFilterBlueprintsFunc mocks the PublishFilterBlueprints method.
+	PublishFilterBlueprintsFunc func(ctx context.Context, instanceID string) (int, error)
+
+	// PublishFilterOutputsFunc mocks the PublishFilterOutputs method.
+	PublishFilterOutputsFunc func(ctx context.Context, instanceID string) ([]string, error)
+
 	// RemoveFilterDimensionFunc mocks the RemoveFilterDimension method.
 	RemoveFilterDimensionFunc func(ctx context.Context, filterID string, name string, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error)
 
@@ -110,12 +147,24 @@ type DataStoreMock struct {
 	// RemoveFilterDimensionOptionsFunc mocks the RemoveFilterDimensionOptions method.
 	RemoveFilterDimensionOptionsFunc func(ctx context.Context, filterID string, name string, options []string, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error)
 
+	// ReplaceFilterFunc mocks the ReplaceFilter method.
+	ReplaceFilterFunc func(ctx context.Context, updatedFilter *models.Filter, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error)
+
+	// ReserveIdempotencyRecordFunc mocks the ReserveIdempotencyRecord method.
+	ReserveIdempotencyRecordFunc func(ctx context.Context, record *models.IdempotencyRecord, expiredBefore time.Time, leaseExpiredBefore time.Time) (*models.IdempotencyRecord, error)
+
 	// RunTransactionFunc mocks the RunTransaction method.
 	RunTransactionFunc func(ctx context.Context, retry bool, fn mongodriver.TransactionFunc) (interface{}, error)
 
+	// SaveIdempotencyRecordFunc mocks the SaveIdempotencyRecord method.
+	SaveIdempotencyRecordFunc func(ctx context.Context, record *models.IdempotencyRecord) error
+
 	// UpdateFilterFunc mocks the UpdateFilter method.
 	UpdateFilterFunc func(ctx context.Context, updatedFilter *models.Filter, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error)
 
+	// UpdateFilterDimensionFunc mocks the UpdateFilterDimension method.
+	UpdateFilterDimensionFunc func(ctx context.Context, filterID string, dimension models.Dimension, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error)
+
 	// UpdateFilterOutputFunc mocks the UpdateFilterOutput method.
 	UpdateFilterOutputFunc func(ctx context.Context, filter *models.Filter, timestamp primitive.Timestamp) error
 
@@ -145,8 +194,12 @@ type DataStoreMock struct {
 			FilterID string
 			// Name is the name argument value.
 			Name string
+			// Mode is the mode argument value.
+			Mode string
 			// Options is the options argument value.
 			Options []string
+			// Ranges is the ranges argument value.
+			Ranges []models.OptionRange
 			// Dimensions is the dimensions argument value.
 			Dimensions []models.Dimension
 			// Timestamp is the timestamp argument value.
@@ -197,6 +250,13 @@ type DataStoreMock struct {
 			// Filter is the filter argument value.
 			Filter *models.Filter
 		}
+		// DeleteIdempotencyRecord holds details about calls to the DeleteIdempotencyRecord method.
+		DeleteIdempotencyRecord []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Record is the record argument value.
+			Record *models.IdempotencyRecord
+		}
 		// GetFilter holds details about calls to the GetFilter method.
 		GetFilter []struct {
 			// Ctx is the ctx argument value.
@@ -224,6 +284,29 @@ type DataStoreMock struct {
 			// FilterOutputID is the filterOutputID argument value.
 			FilterOutputID string
 		}
+		// GetFilterSnapshot holds details about calls to the GetFilterSnapshot method.
+		GetFilterSnapshot []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// FilterID is the filterID argument value.
+			FilterID string
+			// ETag is the eTag argument value.
+			ETag string
+		}
+		// PublishFilterBlueprints holds details about calls to the PublishFilterBlueprints method.
+		PublishFilterBlueprints []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// InstanceID is the instanceID argument value.
+			InstanceID string
+		}
+		// PublishFilterOutputs holds details about calls to the PublishFilterOutputs method.
+		PublishFilterOutputs []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// InstanceID is the instanceID argument value.
+			InstanceID string
+		}
 		// RemoveFilterDimension holds details about calls to the RemoveFilterDimension method.
 		RemoveFilterDimension []struct {
 			// Ctx is the ctx argument value.
@@ -273,6 +356,30 @@ type DataStoreMock struct {
 			// CurrentFilter is the currentFilter argument value.
 			CurrentFilter *models.Filter
 		}
+		// ReplaceFilter holds details about calls to the ReplaceFilter method.
+		ReplaceFilter []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// UpdatedFilter is the updatedFilter argument value.
+			UpdatedFilter *models.Filter
+			// Timestamp is the timestamp argument value.
+			Timestamp primitive.Timestamp
+			// ETagSelector is the eTagSelector argument value.
+			ETagSelector string
+			// CurrentFilter is the currentFilter argument value.
+			CurrentFilter *models.Filter
+		}
+		// ReserveIdempotencyRecord holds details about calls to the ReserveIdempotencyRecord method.
+		ReserveIdempotencyRecord []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Record is the record argument value.
+			Record *models.IdempotencyRecord
+			// ExpiredBefore is the expiredBefore argument value.
+			ExpiredBefore time.Time
+			// LeaseExpiredBefore is the leaseExpiredBefore argument value.
+			LeaseExpiredBefore time.Time
+		}
 		// RunTransaction holds details about calls to the RunTransaction method.
 		RunTransaction []struct {
 			// Ctx is the ctx argument value.
@@ -282,6 +389,13 @@ type DataStoreMock struct {
 			// Fn is the fn argument value.
 			Fn mongodriver.TransactionFunc
 		}
+		// SaveIdempotencyRecord holds details about calls to the SaveIdempotencyRecord method.
+		SaveIdempotencyRecord []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Record is the record argument value.
+			Record *models.IdempotencyRecord
+		}
 		// UpdateFilter holds details about calls to the UpdateFilter method.
 		UpdateFilter []struct {
 			// Ctx is the ctx argument value.
@@ -295,6 +409,21 @@ type DataStoreMock struct {
 			// CurrentFilter is the currentFilter argument value.
 			CurrentFilter *models.Filter
 		}
+		// UpdateFilterDimension holds details about calls to the UpdateFilterDimension method.
+		UpdateFilterDimension []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// FilterID is the filterID argument value.
+			FilterID string
+			// Dimension is the dimension argument value.
+			Dimension models.Dimension
+			// Timestamp is the timestamp argument value.
+			Timestamp primitive.Timestamp
+			// ETagSelector is the eTagSelector argument value.
+			ETagSelector string
+			// CurrentFilter is the currentFilter argument value.
+			CurrentFilter *models.Filter
+		}
 		// UpdateFilterOutput holds details about calls to the UpdateFilterOutput method.
 		UpdateFilterOutput []struct {
 			// Ctx is the ctx argument value.
@@ -311,14 +440,22 @@ type DataStoreMock struct {
 	lockAddFilterDimensionOption     sync.RWMutex
 	lockAddFilterDimensionOptions    sync.RWMutex
 	lockCreateFilterOutput           sync.RWMutex
+	lockDeleteIdempotencyRecord      sync.RWMutex
 	lockGetFilter                    sync.RWMutex
 	lockGetFilterDimension           sync.RWMutex
 	lockGetFilterOutput              sync.RWMutex
+	lockGetFilterSnapshot            sync.RWMutex
+	lockPublishFilterBlueprints      sync.RWMutex
+	lockPublishFilterOutputs         sync.RWMutex
 	lockRemoveFilterDimension        sync.RWMutex
 	lockRemoveFilterDimensionOption  sync.RWMutex
 	lockRemoveFilterDimensionOptions sync.RWMutex
+	lockReplaceFilter                sync.RWMutex
+	lockReserveIdempotencyRecord     sync.RWMutex
 	lockRunTransaction               sync.RWMutex
+	lockSaveIdempotencyRecord        sync.RWMutex
 	lockUpdateFilter                 sync.RWMutex
+	lockUpdateFilterDimension        sync.RWMutex
 	lockUpdateFilterOutput           sync.RWMutex
 }
 
@@ -399,7 +536,7 @@ func (mock *DataStoreMock) AddFilterCalls() []struct {
 }
 
 // AddFilterDimension calls AddFilterDimensionFunc.
-func (mock *DataStoreMock) AddFilterDimension(ctx context.Context, filterID string, name string, options []string, dimensions []models.Dimension, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error) {
+func (mock *DataStoreMock) AddFilterDimension(ctx context.Context, filterID string, name string, mode string, options []string, ranges []models.OptionRange, dimensions []models.Dimension, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error) {
 	if mock.AddFilterDimensionFunc == nil {
 		panic("DataStoreMock.AddFilterDimensionFunc: method is nil but DataStore.AddFilterDimension was just called")
 	}
@@ -407,7 +544,9 @@ func (mock *DataStoreMock) AddFilterDimension(ctx context.Context, filterID stri
 		Ctx           context.Context
 		FilterID      string
 		Name          string
+		Mode          string
 		Options       []string
+		Ranges        []models.OptionRange
 		Dimensions    []models.Dimension
 		Timestamp     primitive.Timestamp
 		ETagSelector  string
@@ -416,7 +555,9 @@ func (mock *DataStoreMock) AddFilterDimension(ctx context.Context, filterID stri
 		Ctx:           ctx,
 		FilterID:      filterID,
 		Name:          name,
+		Mode:          mode,
 		Options:       options,
+		Ranges:        ranges,
 		Dimensions:    dimensions,
 		Timestamp:     timestamp,
 		ETagSelector:  eTagSelector,
@@ -425,7 +566,7 @@ func (mock *DataStoreMock) AddFilterDimension(ctx context.Context, filterID stri
 	mock.lockAddFilterDimension.Lock()
 	mock.calls.AddFilterDimension = append(mock.calls.AddFilterDimension, callInfo)
 	mock.lockAddFilterDimension.Unlock()
-	return mock.AddFilterDimensionFunc(ctx, filterID, name, options, dimensions, timestamp, eTagSelector, currentFilter)
+	return mock.AddFilterDimensionFunc(ctx, filterID, name, mode, options, ranges, dimensions, timestamp, eTagSelector, currentFilter)
 }
 
 // AddFilterDimensionCalls gets all the calls that were made to AddFilterDimension.
@@ -436,7 +577,9 @@ func (mock *DataStoreMock) AddFilterDimensionCalls() []struct {
 	Ctx           context.Context
 	FilterID      string
 	Name          string
+	Mode          string
 	Options       []string
+	Ranges        []models.OptionRange
 	Dimensions    []models.Dimension
 	Timestamp     primitive.Timestamp
 	ETagSelector  string
@@ -446,7 +589,9 @@ func (mock *DataStoreMock) AddFilterDimensionCalls() []struct {
 		Ctx           context.Context
 		FilterID      string
 		Name          string
+		Mode          string
 		Options       []string
+		Ranges        []models.OptionRange
 		Dimensions    []models.Dimension
 		Timestamp     primitive.Timestamp
 		ETagSelector  string
@@ -606,6 +751,42 @@ func (mock *DataStoreMock) CreateFilterOutputCalls() []struct {
 	return calls
 }
 
+// DeleteIdempotencyRecord calls DeleteIdempotencyRecordFunc.
+func (mock *DataStoreMock) DeleteIdempotencyRecord(ctx context.Context, record *models.IdempotencyRecord) error {
+	if mock.DeleteIdempotencyRecordFunc == nil {
+		panic("DataStoreMock.DeleteIdempotencyRecordFunc: method is nil but DataStore.DeleteIdempotencyRecord was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Record *models.IdempotencyRecord
+	}{
+		Ctx:    ctx,
+		Record: record,
+	}
+	mock.lockDeleteIdempotencyRecord.Lock()
+	mock.calls.DeleteIdempotencyRecord = append(mock.calls.DeleteIdempotencyRecord, callInfo)
+	mock.lockDeleteIdempotencyRecord.Unlock()
+	return mock.DeleteIdempotencyRecordFunc(ctx, record)
+}
+
+// DeleteIdempotencyRecordCalls gets all the calls that were made to DeleteIdempotencyRecord.
+// Check the length with:
+//
+//	len(mockedDataStore.DeleteIdempotencyRecordCalls())
+func (mock *DataStoreMock) DeleteIdempotencyRecordCalls() []struct {
+	Ctx    context.Context
+	Record *models.IdempotencyRecord
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Record *models.IdempotencyRecord
+	}
+	mock.lockDeleteIdempotencyRecord.RLock()
+	calls = mock.calls.DeleteIdempotencyRecord
+	mock.lockDeleteIdempotencyRecord.RUnlock()
+	return calls
+}
+
 // GetFilter calls GetFilterFunc.
 func (mock *DataStoreMock) GetFilter(ctx context.Context, filterID string, eTagSelector string) (*models.Filter, error) {
 	if mock.GetFilterFunc == nil {
@@ -726,6 +907,118 @@ func (mock *DataStoreMock) GetFilterOutputCalls() []struct {
 	return calls
 }
 
+// GetFilterSnapshot calls GetFilterSnapshotFunc.
+func (mock *DataStoreMock) GetFilterSnapshot(ctx context.Context, filterID string, eTag string) (*models.FilterSnapshot, error) {
+	if mock.GetFilterSnapshotFunc == nil {
+		panic("DataStoreMock.GetFilterSnapshotFunc: method is nil but DataStore.GetFilterSnapshot was just called")
+	}
+	callInfo := struct {
+		Ctx      context.Context
+		FilterID string
+		ETag     string
+	}{
+		Ctx:      ctx,
+		FilterID: filterID,
+		ETag:     eTag,
+	}
+	mock.lockGetFilterSnapshot.Lock()
+	mock.calls.GetFilterSnapshot = append(mock.calls.GetFilterSnapshot, callInfo)
+	mock.lockGetFilterSnapshot.Unlock()
+	return mock.GetFilterSnapshotFunc(ctx, filterID, eTag)
+}
+
+// GetFilterSnapshotCalls gets all the calls that were made to GetFilterSnapshot.
+// Check the length with:
+//
+//	len(mockedDataStore.GetFilterSnapshotCalls())
+func (mock *DataStoreMock) GetFilterSnapshotCalls() []struct {
+	Ctx      context.Context
+	FilterID string
+	ETag     string
+} {
+	var calls []struct {
+		Ctx      context.Context
+		FilterID string
+		ETag     string
+	}
+	mock.lockGetFilterSnapshot.RLock()
+	calls = mock.calls.GetFilterSnapshot
+	mock.lockGetFilterSnapshot.RUnlock()
+	return calls
+}
+
+// PublishFilterBlueprints calls PublishFilterBlueprintsFunc.
+func (mock *DataStoreMock) PublishFilterBlueprints(ctx context.Context, instanceID string) (int, error) {
+	if mock.PublishFilterBlueprintsFunc == nil {
+		panic("DataStoreMock.PublishFilterBlueprintsFunc: method is nil but DataStore.PublishFilterBlueprints was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		InstanceID string
+	}{
+		Ctx:        ctx,
+		InstanceID: instanceID,
+	}
+	mock.lockPublishFilterBlueprints.Lock()
+	mock.calls.PublishFilterBlueprints = append(mock.calls.PublishFilterBlueprints, callInfo)
+	mock.lockPublishFilterBlueprints.Unlock()
+	return mock.PublishFilterBlueprintsFunc(ctx, instanceID)
+}
+
+// PublishFilterBlueprintsCalls gets all the calls that were made to PublishFilterBlueprints.
+// Check the length with:
+//
+//	len(mockedDataStore.PublishFilterBlueprintsCalls())
+func (mock *DataStoreMock) PublishFilterBlueprintsCalls() []struct {
+	Ctx        context.Context
+	InstanceID string
+} {
+	var calls []struct {
+		Ctx        context.Context
+		InstanceID string
+	}
+	mock.lockPublishFilterBlueprints.RLock()
+	calls = mock.calls.PublishFilterBlueprints
+	mock.lockPublishFilterBlueprints.RUnlock()
+	return calls
+}
+
+// PublishFilterOutputs calls PublishFilterOutputsFunc.
+func (mock *DataStoreMock) PublishFilterOutputs(ctx context.Context, instanceID string) ([]string, error) {
+	if mock.PublishFilterOutputsFunc == nil {
+		panic("DataStoreMock.PublishFilterOutputsFunc: method is nil but DataStore.PublishFilterOutputs was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		InstanceID string
+	}{
+		Ctx:        ctx,
+		InstanceID: instanceID,
+	}
+	mock.lockPublishFilterOutputs.Lock()
+	mock.calls.PublishFilterOutputs = append(mock.calls.PublishFilterOutputs, callInfo)
+	mock.lockPublishFilterOutputs.Unlock()
+	return mock.PublishFilterOutputsFunc(ctx, instanceID)
+}
+
+// PublishFilterOutputsCalls gets all the calls that were made to PublishFilterOutputs.
+// Check the length with:
+//
+//	len(mockedDataStore.PublishFilterOutputsCalls())
+func (mock *DataStoreMock) PublishFilterOutputsCalls() []struct {
+	Ctx        context.Context
+	InstanceID string
+} {
+	var calls []struct {
+		Ctx        context.Context
+		InstanceID string
+	}
+	mock.lockPublishFilterOutputs.RLock()
+	calls = mock.calls.PublishFilterOutputs
+	mock.lockPublishFilterOutputs.RUnlock()
+	return calls
+}
+
 // RemoveFilterDimension calls RemoveFilterDimensionFunc.
 func (mock *DataStoreMock) RemoveFilterDimension(ctx context.Context, filterID string, name string, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error) {
 	if mock.RemoveFilterDimensionFunc == nil {
@@ -890,6 +1183,98 @@ func (mock *DataStoreMock) RemoveFilterDimensionOptionsCalls() []struct {
 	return calls
 }
 
+// ReplaceFilter calls ReplaceFilterFunc.
+func (mock *DataStoreMock) ReplaceFilter(ctx context.Context, updatedFilter *models.Filter, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error) {
+	if mock.ReplaceFilterFunc == nil {
+		panic("DataStoreMock.ReplaceFilterFunc: method is nil but DataStore.ReplaceFilter was just called")
+	}
+	callInfo := struct {
+		Ctx           context.Context
+		UpdatedFilter *models.Filter
+		Timestamp     primitive.Timestamp
+		ETagSelector  string
+		CurrentFilter *models.Filter
+	}{
+		Ctx:           ctx,
+		UpdatedFilter: updatedFilter,
+		Timestamp:     timestamp,
+		ETagSelector:  eTagSelector,
+		CurrentFilter: currentFilter,
+	}
+	mock.lockReplaceFilter.Lock()
+	mock.calls.ReplaceFilter = append(mock.calls.ReplaceFilter, callInfo)
+	mock.lockReplaceFilter.Unlock()
+	return mock.ReplaceFilterFunc(ctx, updatedFilter, timestamp, eTagSelector, currentFilter)
+}
+
+// ReplaceFilterCalls gets all the calls that were made to ReplaceFilter.
+// Check the length with:
+//
+//	len(mockedDataStore.ReplaceFilterCalls())
+func (mock *DataStoreMock) ReplaceFilterCalls() []struct {
+	Ctx           context.Context
+	UpdatedFilter *models.Filter
+	Timestamp     primitive.Timestamp
+	ETagSelector  string
+	CurrentFilter *models.Filter
+} {
+	var calls []struct {
+		Ctx           context.Context
+		UpdatedFilter *models.Filter
+		Timestamp     primitive.Timestamp
+		ETagSelector  string
+		CurrentFilter *models.Filter
+	}
+	mock.lockReplaceFilter.RLock()
+	calls = mock.calls.ReplaceFilter
+	mock.lockReplaceFilter.RUnlock()
+	return calls
+}
+
+// ReserveIdempotencyRecord calls ReserveIdempotencyRecordFunc.
+func (mock *DataStoreMock) ReserveIdempotencyRecord(ctx context.Context, record *models.IdempotencyRecord, expiredBefore time.Time, leaseExpiredBefore time.Time) (*models.IdempotencyRecord, error) {
+	if mock.ReserveIdempotencyRecordFunc == nil {
+		panic("DataStoreMock.ReserveIdempotencyRecordFunc: method is nil but DataStore.ReserveIdempotencyRecord was just called")
+	}
+	callInfo := struct {
+		Ctx                context.Context
+		Record             *models.IdempotencyRecord
+		ExpiredBefore      time.Time
+		LeaseExpiredBefore time.Time
+	}{
+		Ctx:                ctx,
+		Record:             record,
+		ExpiredBefore:      expiredBefore,
+		LeaseExpiredBefore: leaseExpiredBefore,
+	}
+	mock.lockReserveIdempotencyRecord.Lock()
+	mock.calls.ReserveIdempotencyRecord = append(mock.calls.ReserveIdempotencyRecord, callInfo)
+	mock.lockReserveIdempotencyRecord.Unlock()
+	return mock.ReserveIdempotencyRecordFunc(ctx, record, expiredBefore, leaseExpiredBefore)
+}
+
+// ReserveIdempotencyRecordCalls gets all the calls that were made to ReserveIdempotencyRecord.
+// Check the length with:
+//
+//	len(mockedDataStore.ReserveIdempotencyRecordCalls())
+func (mock *DataStoreMock) ReserveIdempotencyRecordCalls() []struct {
+	Ctx                context.Context
+	Record             *models.IdempotencyRecord
+	ExpiredBefore      time.Time
+	LeaseExpiredBefore time.Time
+} {
+	var calls []struct {
+		Ctx                context.Context
+		Record             *models.IdempotencyRecord
+		ExpiredBefore      time.Time
+		LeaseExpiredBefore time.Time
+	}
+	mock.lockReserveIdempotencyRecord.RLock()
+	calls = mock.calls.ReserveIdempotencyRecord
+	mock.lockReserveIdempotencyRecord.RUnlock()
+	return calls
+}
+
 // RunTransaction calls RunTransactionFunc.
 func (mock *DataStoreMock) RunTransaction(ctx context.Context, retry bool, fn mongodriver.TransactionFunc) (interface{}, error) {
 	if mock.RunTransactionFunc == nil {
@@ -930,6 +1315,42 @@ func (mock *DataStoreMock) RunTransactionCalls() []struct {
 	return calls
 }
 
+// SaveIdempotencyRecord calls SaveIdempotencyRecordFunc.
+func (mock *DataStoreMock) SaveIdempotencyRecord(ctx context.Context, record *models.IdempotencyRecord) error {
+	if mock.SaveIdempotencyRecordFunc == nil {
+		panic("DataStoreMock.SaveIdempotencyRecordFunc: method is nil but DataStore.SaveIdempotencyRecord was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Record *models.IdempotencyRecord
+	}{
+		Ctx:    ctx,
+		Record: record,
+	}
+	mock.lockSaveIdempotencyRecord.Lock()
+	mock.calls.SaveIdempotencyRecord = append(mock.calls.SaveIdempotencyRecord, callInfo)
+	mock.lockSaveIdempotencyRecord.Unlock()
+	return mock.SaveIdempotencyRecordFunc(ctx, record)
+}
+
+// SaveIdempotencyRecordCalls gets all the calls that were made to SaveIdempotencyRecord.
+// Check the length with:
+//
+//	len(mockedDataStore.SaveIdempotencyRecordCalls())
+func (mock *DataStoreMock) SaveIdempotencyRecordCalls() []struct {
+	Ctx    context.Context
+	Record *models.IdempotencyRecord
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Record *models.IdempotencyRecord
+	}
+	mock.lockSaveIdempotencyRecord.RLock()
+	calls = mock.calls.SaveIdempotencyRecord
+	mock.lockSaveIdempotencyRecord.RUnlock()
+	return calls
+}
+
 // UpdateFilter calls UpdateFilterFunc.
 func (mock *DataStoreMock) UpdateFilter(ctx context.Context, updatedFilter *models.Filter, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error) {
 	if mock.UpdateFilterFunc == nil {
@@ -978,6 +1399,58 @@ func (mock *DataStoreMock) UpdateFilterCalls() []struct {
 	return calls
 }
 
+// UpdateFilterDimension calls UpdateFilterDimensionFunc.
+func (mock *DataStoreMock) UpdateFilterDimension(ctx context.Context, filterID string, dimension models.Dimension, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error) {
+	if mock.UpdateFilterDimensionFunc == nil {
+		panic("DataStoreMock.UpdateFilterDimensionFunc: method is nil but DataStore.UpdateFilterDimension was just called")
+	}
+	callInfo := struct {
+		Ctx           context.Context
+		FilterID      string
+		Dimension     models.Dimension
+		Timestamp     primitive.Timestamp
+		ETagSelector  string
+		CurrentFilter *models.Filter
+	}{
+		Ctx:           ctx,
+		FilterID:      filterID,
+		Dimension:     dimension,
+		Timestamp:     timestamp,
+		ETagSelector:  eTagSelector,
+		CurrentFilter: currentFilter,
+	}
+	mock.lockUpdateFilterDimension.Lock()
+	mock.calls.UpdateFilterDimension = append(mock.calls.UpdateFilterDimension, callInfo)
+	mock.lockUpdateFilterDimension.Unlock()
+	return mock.UpdateFilterDimensionFunc(ctx, filterID, dimension, timestamp, eTagSelector, currentFilter)
+}
+
+// UpdateFilterDimensionCalls gets all the calls that were made to UpdateFilterDimension.
+// Check the length with:
+//
+//	len(mockedDataStore.UpdateFilterDimensionCalls())
+func (mock *DataStoreMock) UpdateFilterDimensionCalls() []struct {
+	Ctx           context.Context
+	FilterID      string
+	Dimension     models.Dimension
+	Timestamp     primitive.Timestamp
+	ETagSelector  string
+	CurrentFilter *models.Filter
+} {
+	var calls []struct {
+		Ctx           context.Context
+		FilterID      string
+		Dimension     models.Dimension
+		Timestamp     primitive.Timestamp
+		ETagSelector  string
+		CurrentFilter *models.Filter
+	}
+	mock.lockUpdateFilterDimension.RLock()
+	calls = mock.calls.UpdateFilterDimension
+	mock.lockUpdateFilterDimension.RUnlock()
+	return calls
+}
+
 // UpdateFilterOutput calls UpdateFilterOutputFunc.
 func (mock *DataStoreMock) UpdateFilterOutput(ctx context.Context, filter *models.Filter, timestamp primitive.Timestamp) error {
 	if mock.UpdateFilterOutputFunc == nil {
@@ -0,0 +1,95 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"context"
+	"github.com/ONSdigital/dp-api-clients-go/v2/hierarchy"
+	"github.com/ONSdigital/dp-filter-api/api"
+	"sync"
+)
+
+// Ensure, that HierarchyAPIMock does implement api.HierarchyAPI.
+// If this is not the case, regenerate this file with moq.
+var _ api.HierarchyAPI = &HierarchyAPIMock{}
+
+// HierarchyAPIMock is a mock implementation of api.HierarchyAPI.
+//
+//	func TestSomethingThatUsesHierarchyAPI(t *testing.T) {
+//
+//		// make and configure a mocked api.HierarchyAPI
+//		mockedHierarchyAPI := &HierarchyAPIMock{
+//			GetChildFunc: func(ctx context.Context, instanceID string, name string, code string) (hierarchy.Model, error) {
+//				panic("mock out the GetChild method")
+//			},
+//		}
+//
+//		// use mockedHierarchyAPI in code that requires api.HierarchyAPI
+//		// and then make assertions.
+//
+//	}
+type HierarchyAPIMock struct {
+	// GetChildFunc mocks the GetChild method.
+	GetChildFunc func(ctx context.Context, instanceID string, name string, code string) (hierarchy.Model, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// GetChild holds details about calls to the GetChild method.
+		GetChild []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// InstanceID is the instanceID argument value.
+			InstanceID string
+			// Name is the name argument value.
+			Name string
+			// Code is the code argument value.
+			Code string
+		}
+	}
+	lockGetChild sync.RWMutex
+}
+
+// GetChild calls GetChildFunc.
+func (mock *HierarchyAPIMock) GetChild(ctx context.Context, instanceID string, name string, code string) (hierarchy.Model, error) {
+	if mock.GetChildFunc == nil {
+		panic("HierarchyAPIMock.GetChildFunc: method is nil but HierarchyAPI.GetChild was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		InstanceID string
+		Name       string
+		Code       string
+	}{
+		Ctx:        ctx,
+		InstanceID: instanceID,
+		Name:       name,
+		Code:       code,
+	}
+	mock.lockGetChild.Lock()
+	mock.calls.GetChild = append(mock.calls.GetChild, callInfo)
+	mock.lockGetChild.Unlock()
+	return mock.GetChildFunc(ctx, instanceID, name, code)
+}
+
+// GetChildCalls gets all the calls that were made to GetChild.
+// Check the length with:
+//
+//	len(mockedHierarchyAPI.GetChildCalls())
+func (mock *HierarchyAPIMock) GetChildCalls() []struct {
+	Ctx        context.Context
+	InstanceID string
+	Name       string
+	Code       string
+} {
+	var calls []struct {
+		Ctx        context.Context
+		InstanceID string
+		Name       string
+		Code       string
+	}
+	mock.lockGetChild.RLock()
+	calls = mock.calls.GetChild
+	mock.lockGetChild.RUnlock()
+	return calls
+}
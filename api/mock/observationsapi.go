@@ -0,0 +1,120 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"context"
+	"github.com/ONSdigital/dp-filter-api/api"
+	"github.com/ONSdigital/dp-filter-api/observations"
+	"net/url"
+	"sync"
+)
+
+// Ensure, that ObservationsAPIMock does implement api.ObservationsAPI.
+// If this is not the case, regenerate this file with moq.
+var _ api.ObservationsAPI = &ObservationsAPIMock{}
+
+// ObservationsAPIMock is a mock implementation of api.ObservationsAPI.
+//
+//	func TestSomethingThatUsesObservationsAPI(t *testing.T) {
+//
+//		// make and configure a mocked api.ObservationsAPI
+//		mockedObservationsAPI := &ObservationsAPIMock{
+//			GetObservationsFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, collectionID string, datasetID string, edition string, version string, query url.Values) (observations.Observations, error) {
+//				panic("mock out the GetObservations method")
+//			},
+//		}
+//
+//		// use mockedObservationsAPI in code that requires api.ObservationsAPI
+//		// and then make assertions.
+//
+//	}
+type ObservationsAPIMock struct {
+	// GetObservationsFunc mocks the GetObservations method.
+	GetObservationsFunc func(ctx context.Context, userAuthToken string, serviceAuthToken string, collectionID string, datasetID string, edition string, version string, query url.Values) (observations.Observations, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// GetObservations holds details about calls to the GetObservations method.
+		GetObservations []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// UserAuthToken is the userAuthToken argument value.
+			UserAuthToken string
+			// ServiceAuthToken is the serviceAuthToken argument value.
+			ServiceAuthToken string
+			// CollectionID is the collectionID argument value.
+			CollectionID string
+			// DatasetID is the datasetID argument value.
+			DatasetID string
+			// Edition is the edition argument value.
+			Edition string
+			// Version is the version argument value.
+			Version string
+			// Query is the query argument value.
+			Query url.Values
+		}
+	}
+	lockGetObservations sync.RWMutex
+}
+
+// GetObservations calls GetObservationsFunc.
+func (mock *ObservationsAPIMock) GetObservations(ctx context.Context, userAuthToken string, serviceAuthToken string, collectionID string, datasetID string, edition string, version string, query url.Values) (observations.Observations, error) {
+	if mock.GetObservationsFunc == nil {
+		panic("ObservationsAPIMock.GetObservationsFunc: method is nil but ObservationsAPI.GetObservations was just called")
+	}
+	callInfo := struct {
+		Ctx              context.Context
+		UserAuthToken    string
+		ServiceAuthToken string
+		CollectionID     string
+		DatasetID        string
+		Edition          string
+		Version          string
+		Query            url.Values
+	}{
+		Ctx:              ctx,
+		UserAuthToken:    userAuthToken,
+		ServiceAuthToken: serviceAuthToken,
+		CollectionID:     collectionID,
+		DatasetID:        datasetID,
+		Edition:          edition,
+		Version:          version,
+		Query:            query,
+	}
+	mock.lockGetObservations.Lock()
+	mock.calls.GetObservations = append(mock.calls.GetObservations, callInfo)
+	mock.lockGetObservations.Unlock()
+	return mock.GetObservationsFunc(ctx, userAuthToken, serviceAuthToken, collectionID, datasetID, edition, version, query)
+}
+
+// GetObservationsCalls gets all the calls that were made to GetObservations.
+// Check the length with:
+//
+//	len(mockedObservationsAPI.GetObservationsCalls())
+func (mock *ObservationsAPIMock) GetObservationsCalls() []struct {
+	Ctx              context.Context
+	UserAuthToken    string
+	ServiceAuthToken string
+	CollectionID     string
+	DatasetID        string
+	Edition          string
+	Version          string
+	Query            url.Values
+} {
+	var calls []struct {
+		Ctx              context.Context
+		UserAuthToken    string
+		ServiceAuthToken string
+		CollectionID     string
+		DatasetID        string
+		Edition          string
+		Version          string
+		Query            url.Values
+	}
+	mock.lockGetObservations.RLock()
+	calls = mock.calls.GetObservations
+	mock.lockGetObservations.RUnlock()
+	return calls
+}
@@ -0,0 +1,126 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/ONSdigital/dp-filter-api/filters"
+	"github.com/ONSdigital/dp-filter-api/models"
+)
+
+// supported values of the sort query parameter for the options of a filter dimension
+const (
+	sortOptionsByCode         = "code"
+	sortOptionsByLabel        = "label"
+	sortOptionsByDatasetOrder = "dataset_order"
+)
+
+// optionsQuery holds the search and sort criteria requested for the options of a filter dimension
+type optionsQuery struct {
+	search string
+	sortBy string
+}
+
+// getOptionsQuery returns the search and sort criteria from the q and sort query parameters.
+// Options are sorted by code if no sort is provided.
+func getOptionsQuery(r *http.Request) (optionsQuery, error) {
+	query := optionsQuery{
+		search: strings.TrimSpace(r.URL.Query().Get("q")),
+		sortBy: r.URL.Query().Get("sort"),
+	}
+
+	switch query.sortBy {
+	case "":
+		query.sortBy = sortOptionsByCode
+	case sortOptionsByCode, sortOptionsByLabel, sortOptionsByDatasetOrder:
+	default:
+		return optionsQuery{}, filters.ErrInvalidQueryParameter
+	}
+	return query, nil
+}
+
+// needsLabels returns true if the option labels are required to search or sort the options
+func (q optionsQuery) needsLabels() bool {
+	return q.search != "" || q.sortBy == sortOptionsByLabel
+}
+
+// searchOptions returns the options whose code or label contains the search term, ignoring case
+func searchOptions(options []string, labels map[string]string, search string) []string {
+	search = strings.ToLower(search)
+
+	found := []string{}
+	for _, option := range options {
+		if strings.Contains(strings.ToLower(option), search) || strings.Contains(strings.ToLower(labels[option]), search) {
+			found = append(found, option)
+		}
+	}
+	return found
+}
+
+// sortOptionsByDatasetPosition sorts the options in the order of the dataset dimension.
+// Options that are not available in the dataset dimension are sorted by code after the rest.
+func sortOptionsByDatasetPosition(options, orderedOptions []string) {
+	positions := make(map[string]int, len(orderedOptions))
+	for i, option := range orderedOptions {
+		positions[option] = i
+	}
+
+	sort.SliceStable(options, func(i, j int) bool {
+		pi, iok := positions[options[i]]
+		pj, jok := positions[options[j]]
+		if iok && jok {
+			return pi < pj
+		}
+		if iok != jok {
+			return iok
+		}
+		return options[i] < options[j]
+	})
+}
+
+// sortOptionsByLabels sorts the options by label, and by code for options with the same label
+func sortOptionsByLabels(options []string, labels map[string]string) {
+	sort.SliceStable(options, func(i, j int) bool {
+		li, lj := labels[options[i]], labels[options[j]]
+		if li != lj {
+			return li < lj
+		}
+		return options[i] < options[j]
+	})
+}
+
+// queryOptions searches and sorts the options selected by a filter dimension, according to the provided query
+func (api *FilterAPI) queryOptions(ctx context.Context, dataset *models.Dataset, dimensionName string, options []string, query optionsQuery) ([]string, error) {
+	if len(options) == 0 {
+		return options, nil
+	}
+
+	var labels map[string]string
+	if query.needsLabels() {
+		var err error
+		labels, err = api.getOptionLabels(ctx, dataset, dimensionName, options)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if query.search != "" {
+		options = searchOptions(options, labels, query.search)
+	}
+
+	switch query.sortBy {
+	case sortOptionsByLabel:
+		sortOptionsByLabels(options, labels)
+	case sortOptionsByDatasetOrder:
+		orderedOptions, err := api.getOrderedDimensionOptions(ctx, dataset, dimensionName)
+		if err != nil {
+			return nil, err
+		}
+		sortOptionsByDatasetPosition(options, orderedOptions)
+	default:
+		sort.Strings(options)
+	}
+	return options, nil
+}
@@ -0,0 +1,136 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/ONSdigital/dp-api-clients-go/v2/dataset"
+	"github.com/ONSdigital/dp-filter-api/api"
+	apimock "github.com/ONSdigital/dp-filter-api/api/mock"
+	"github.com/ONSdigital/dp-filter-api/mock"
+	"github.com/ONSdigital/dp-filter-api/models"
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// geographyDatasetAPIMock returns a dataset API mock with a geography dimension whose options are not in alphabetical order
+func geographyDatasetAPIMock() *apimock.DatasetAPIMock {
+	geography := []dataset.Option{
+		{Option: "W06000015", Label: "Cardiff"},
+		{Option: "E08000003", Label: "Manchester"},
+		{Option: "E08000001", Label: "Bolton"},
+	}
+
+	return &apimock.DatasetAPIMock{
+		GetOptionsBatchProcessFunc: func(ctx context.Context, userAuthToken string, serviceAuthToken string, collectionID string, id string, edition string, version string, dimension string, optionIDs *[]string, processBatch dataset.OptionsBatchProcessor, batchSize int, maxWorkers int) error {
+			items := []dataset.Option{}
+			for _, option := range geography {
+				if optionIDs == nil || slices.Contains(*optionIDs, option.Option) {
+					items = append(items, option)
+				}
+			}
+			_, err := processBatch(dataset.Options{Items: items, Count: len(items), TotalCount: len(items)})
+			return err
+		},
+	}
+}
+
+func optionCodes(options models.PublicDimensionOptions) []string {
+	codes := []string{}
+	for _, item := range options.Items {
+		codes = append(codes, item.Option)
+	}
+	return codes
+}
+
+func TestGetFilterBlueprintDimensionOptions_SearchAndSort(t *testing.T) {
+	t.Parallel()
+
+	filterFlexAPIMock := &apimock.FilterFlexAPIMock{}
+
+	Convey("Given a filter blueprint with a geography dimension", t, func() {
+		ds := mock.NewDataStore().Mock
+		ds.GetFilterFunc = func(ctx context.Context, filterID string, eTagSelector string) (*models.Filter, error) {
+			return &models.Filter{
+				FilterID:   filterID,
+				Dataset:    &models.Dataset{ID: "123", Edition: "2017", Version: 1},
+				InstanceID: "12345678",
+				Published:  &models.Published,
+				Dimensions: []models.Dimension{{Name: "geography", Options: []string{"W06000015", "E08000003", "E08000001"}}},
+				ETag:       testETag,
+			}, nil
+		}
+		datasetAPIMock := geographyDatasetAPIMock()
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), ds, &mock.FilterJob{}, datasetAPIMock, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+		w := httptest.NewRecorder()
+
+		getOptions := func(query string) models.PublicDimensionOptions {
+			r, err := http.NewRequest("GET", "http://localhost:22100/filters/12345678/dimensions/geography/options"+query, http.NoBody)
+			So(err, ShouldBeNil)
+			filterAPI.Router.ServeHTTP(w, r)
+			So(w.Code, ShouldEqual, http.StatusOK)
+
+			var options models.PublicDimensionOptions
+			So(json.Unmarshal(w.Body.Bytes(), &options), ShouldBeNil)
+			return options
+		}
+
+		Convey("When the options are requested without a sort", func() {
+			options := getOptions("")
+
+			Convey("Then the options are sorted by code, without requesting the dataset options", func() {
+				So(optionCodes(options), ShouldResemble, []string{"E08000001", "E08000003", "W06000015"})
+				So(datasetAPIMock.GetOptionsBatchProcessCalls(), ShouldHaveLength, 0)
+			})
+		})
+
+		Convey("When the options are sorted by label", func() {
+			options := getOptions("?sort=label")
+
+			Convey("Then the options are sorted by their dataset labels", func() {
+				So(optionCodes(options), ShouldResemble, []string{"E08000001", "W06000015", "E08000003"})
+			})
+		})
+
+		Convey("When the options are sorted in dataset order", func() {
+			options := getOptions("?sort=dataset_order")
+
+			Convey("Then the options are sorted as in the dataset dimension", func() {
+				So(optionCodes(options), ShouldResemble, []string{"W06000015", "E08000003", "E08000001"})
+			})
+		})
+
+		Convey("When the options are searched by label, ignoring case", func() {
+			options := getOptions("?q=manc")
+
+			Convey("Then only the matching options are returned and counted", func() {
+				So(optionCodes(options), ShouldResemble, []string{"E08000003"})
+				So(options.TotalCount, ShouldEqual, 1)
+			})
+		})
+
+		Convey("When the options are searched by code prefix", func() {
+			options := getOptions("?q=E08&sort=dataset_order")
+
+			Convey("Then the matching options are returned in the requested order", func() {
+				So(optionCodes(options), ShouldResemble, []string{"E08000003", "E08000001"})
+				So(options.TotalCount, ShouldEqual, 2)
+			})
+		})
+
+		Convey("When the options are requested with an unsupported sort", func() {
+			r, err := http.NewRequest("GET", "http://localhost:22100/filters/12345678/dimensions/geography/options?sort=size", http.NoBody)
+			So(err, ShouldBeNil)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the response is 400 bad request", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(problemDetail(w.Body.String()), ShouldEqual, invalidQueryParameterResponse)
+			})
+		})
+	})
+}
@@ -0,0 +1,133 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"slices"
+
+	"github.com/ONSdigital/dp-filter-api/filters"
+	"github.com/ONSdigital/dp-filter-api/models"
+	"github.com/ONSdigital/dp-net/v2/links"
+)
+
+// cursor is the opaque position in a list of dimensions or options of a filter blueprint from which a page is returned.
+// It holds the key of the item the page starts after, or ends before, and the ETag of the filter blueprint the list was read from,
+// so that iterating through the list is refused, rather than inconsistent, if the filter blueprint changes.
+type cursor struct {
+	Key    string `json:"key"`
+	ETag   string `json:"etag"`
+	Before bool   `json:"before,omitempty"`
+}
+
+// encode returns the value of the cursor query parameter for the cursor
+func (c cursor) encode() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// getCursor returns the cursor from the query parameters of a request, or nil if no cursor is provided.
+// A cursor cannot be combined with an offset.
+func getCursor(r *http.Request) (*cursor, error) {
+	value := r.URL.Query().Get("cursor")
+	if value == "" {
+		return nil, nil
+	}
+
+	if r.URL.Query().Get("offset") != "" {
+		return nil, filters.ErrInvalidCursor
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, filters.ErrInvalidCursor
+	}
+
+	var c cursor
+	if err := json.Unmarshal(b, &c); err != nil || c.Key == "" {
+		return nil, filters.ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// checkCursor returns an error if the cursor was issued for a previous version of the filter blueprint
+func checkCursor(c *cursor, eTag string) error {
+	if c != nil && c.ETag != eTag {
+		return filters.ErrStaleCursor
+	}
+	return nil
+}
+
+// page is a page of the keys of a list, sorted in the order the list is returned in
+type page struct {
+	keys   []string
+	offset int
+	total  int
+}
+
+// paginate returns the page of keys after, or before, the cursor if one is provided, or from the offset otherwise
+func paginate(keys []string, offset, limit int, c *cursor) (page, error) {
+	if c == nil {
+		return page{keys: slice(keys, offset, limit), offset: offset, total: len(keys)}, nil
+	}
+
+	i := slices.Index(keys, c.Key)
+	if i < 0 {
+		// the key is only missing if the list is requested with different criteria than the cursor was issued for
+		return page{}, filters.ErrInvalidCursor
+	}
+
+	if c.Before {
+		offset = max(i-limit, 0)
+		return page{keys: keys[offset:i], offset: offset, total: len(keys)}, nil
+	}
+	return page{keys: slice(keys, i+1, limit), offset: i + 1, total: len(keys)}, nil
+}
+
+// links returns the links to the pages before and after the page of the list at the provided URL, requested with the provided query parameters,
+// or nil if the page holds the whole list or no keys at all
+func (p page) links(listURL string, query url.Values, eTag string) *models.PageLinks {
+	if len(p.keys) == 0 || len(p.keys) == p.total {
+		return nil
+	}
+
+	links := &models.PageLinks{}
+	if p.offset > 0 {
+		links.Prev = &models.LinkObject{HRef: cursorURL(listURL, query, cursor{Key: p.keys[0], ETag: eTag, Before: true})}
+	}
+	if p.offset+len(p.keys) < p.total {
+		links.Next = &models.LinkObject{HRef: cursorURL(listURL, query, cursor{Key: p.keys[len(p.keys)-1], ETag: eTag})}
+	}
+	return links
+}
+
+// cursorURL returns the URL of a list with the provided query parameters and cursor, instead of any offset or previous cursor
+func cursorURL(listURL string, query url.Values, c cursor) string {
+	pageQuery := url.Values{}
+	for name, values := range query {
+		pageQuery[name] = values
+	}
+	pageQuery.Del("offset")
+	pageQuery.Set("cursor", c.encode())
+	return listURL + "?" + pageQuery.Encode()
+}
+
+// rewritePageLinks rewrites the links to the pages before and after a page, as the links of its items are
+func rewritePageLinks(builder *links.Builder, pageLinks *models.PageLinks) error {
+	if pageLinks == nil {
+		return nil
+	}
+
+	for _, link := range []*models.LinkObject{pageLinks.Prev, pageLinks.Next} {
+		if link == nil {
+			continue
+		}
+		newLink, err := builder.BuildLink(link.HRef)
+		if err != nil {
+			return err
+		}
+		link.HRef = newLink
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ONSdigital/dp-filter-api/api"
+	apimock "github.com/ONSdigital/dp-filter-api/api/mock"
+	"github.com/ONSdigital/dp-filter-api/mock"
+	"github.com/ONSdigital/dp-filter-api/models"
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCursorPaginationOfFilterBlueprintDimensions(t *testing.T) {
+	t.Parallel()
+
+	filterFlexAPIMock := &apimock.FilterFlexAPIMock{}
+
+	Convey("Given a filter blueprint with three dimensions", t, func() {
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), &mock.DataStore{}, &mock.FilterJob{}, &mock.DatasetAPI{}, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+
+		get := func(url string) (*httptest.ResponseRecorder, models.PublicDimensions) {
+			r, err := http.NewRequest("GET", url, http.NoBody)
+			So(err, ShouldBeNil)
+			w := httptest.NewRecorder()
+			filterAPI.Router.ServeHTTP(w, r)
+
+			var dimensions models.PublicDimensions
+			if w.Code == http.StatusOK {
+				So(json.Unmarshal(w.Body.Bytes(), &dimensions), ShouldBeNil)
+			}
+			return w, dimensions
+		}
+
+		Convey("When the next links are followed from the first page of one dimension", func() {
+			var counts []int
+			var pages []models.PublicDimensions
+			url := host + "/filters/12345678/dimensions?limit=1"
+			for url != "" && len(pages) < 5 {
+				w, dimensions := get(url)
+				So(w.Code, ShouldEqual, http.StatusOK)
+				pages = append(pages, dimensions)
+				counts = append(counts, len(dimensions.Items))
+
+				url = ""
+				if dimensions.Links != nil && dimensions.Links.Next != nil {
+					url = dimensions.Links.Next.HRef
+				}
+			}
+
+			Convey("Then every dimension is returned once, in order, with the total count", func() {
+				So(pages, ShouldHaveLength, 3)
+				So(counts, ShouldResemble, []int{1, 1, 1})
+				So(pages[0].Items[0].Name, ShouldEqual, "1_age")
+				So(pages[1].Items[0].Name, ShouldEqual, "age")
+				So(pages[2].Items[0].Name, ShouldEqual, "time")
+				for i, page := range pages {
+					So(page.Offset, ShouldEqual, i)
+					So(page.TotalCount, ShouldEqual, 3)
+				}
+			})
+
+			Convey("Then only the first page has no link to a previous page", func() {
+				So(pages[0].Links.Prev, ShouldBeNil)
+				So(pages[1].Links.Prev, ShouldNotBeNil)
+				So(pages[2].Links.Prev, ShouldNotBeNil)
+			})
+
+			Convey("Then following the previous link of the last page returns the page before it", func() {
+				w, dimensions := get(pages[2].Links.Prev.HRef)
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(dimensions.Items, ShouldHaveLength, 1)
+				So(dimensions.Items[0].Name, ShouldEqual, "age")
+				So(dimensions.Offset, ShouldEqual, 1)
+			})
+		})
+
+		Convey("When a page is requested with a cursor issued for a previous version of the filter blueprint", func() {
+			w, _ := get(host + "/filters/12345678/dimensions?limit=1&cursor=" + encodeCursor(`{"key":"1_age","etag":"previousETag"}`))
+
+			Convey("Then 409 conflict is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusConflict)
+				So(decodeProblem(w.Body.String()).Code, ShouldEqual, "stale_cursor")
+			})
+		})
+
+		Convey("When a page is requested with a cursor that cannot be decoded", func() {
+			w, _ := get(host + "/filters/12345678/dimensions?cursor=not-a-cursor")
+
+			Convey("Then 400 bad request is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(decodeProblem(w.Body.String()).Code, ShouldEqual, "invalid_cursor")
+			})
+		})
+
+		Convey("When a page is requested with a cursor for a dimension that is not in the filter blueprint", func() {
+			w, _ := get(host + "/filters/12345678/dimensions?cursor=" + encodeCursor(`{"key":"sex","etag":"testETag0"}`))
+
+			Convey("Then 400 bad request is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(decodeProblem(w.Body.String()).Code, ShouldEqual, "invalid_cursor")
+			})
+		})
+
+		Convey("When a page is requested with both a cursor and an offset", func() {
+			w, _ := get(host + "/filters/12345678/dimensions?offset=1&cursor=" + encodeCursor(`{"key":"1_age","etag":"testETag0"}`))
+
+			Convey("Then 400 bad request is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(decodeProblem(w.Body.String()).Code, ShouldEqual, "invalid_cursor")
+			})
+		})
+
+		Convey("When all the dimensions fit in a single page", func() {
+			w, dimensions := get(host + "/filters/12345678/dimensions")
+
+			Convey("Then no page links are returned", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(dimensions.Links, ShouldBeNil)
+			})
+		})
+	})
+}
+
+func TestCursorPaginationOfFilterBlueprintDimensionOptions(t *testing.T) {
+	t.Parallel()
+
+	filterFlexAPIMock := &apimock.FilterFlexAPIMock{}
+
+	Convey("Given a filter blueprint with a dimension with two options", t, func() {
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), &mock.DataStore{}, &mock.FilterJob{}, &mock.DatasetAPI{}, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+
+		get := func(url string) (*httptest.ResponseRecorder, models.PublicDimensionOptions) {
+			r, err := http.NewRequest("GET", url, http.NoBody)
+			So(err, ShouldBeNil)
+			w := httptest.NewRecorder()
+			filterAPI.Router.ServeHTTP(w, r)
+
+			var options models.PublicDimensionOptions
+			if w.Code == http.StatusOK {
+				So(json.Unmarshal(w.Body.Bytes(), &options), ShouldBeNil)
+			}
+			return w, options
+		}
+
+		Convey("When the next link of the first page of one option is followed", func() {
+			w, first := get(host + "/filters/12345678/dimensions/time/options?limit=1")
+			So(w.Code, ShouldEqual, http.StatusOK)
+			So(first.Links.Next, ShouldNotBeNil)
+
+			w, second := get(first.Links.Next.HRef)
+
+			Convey("Then the second option is returned, with a link back to the first page and no next link", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(first.Items[0].Option, ShouldEqual, "2014")
+				So(second.Items, ShouldHaveLength, 1)
+				So(second.Items[0].Option, ShouldEqual, "2015")
+				So(second.Offset, ShouldEqual, 1)
+				So(second.TotalCount, ShouldEqual, 2)
+				So(second.Links.Next, ShouldBeNil)
+				So(second.Links.Prev, ShouldResemble, pageLink("/filters/12345678/dimensions/time/options", `{"key":"2015","etag":"testETag0","before":true}`, "limit=1"))
+			})
+		})
+
+		Convey("When a page is requested with a cursor issued for a previous version of the filter blueprint", func() {
+			w, _ := get(host + "/filters/12345678/dimensions/time/options?cursor=" + encodeCursor(`{"key":"2014","etag":"previousETag"}`))
+
+			Convey("Then 409 conflict is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusConflict)
+				So(decodeProblem(w.Body.String()).Code, ShouldEqual, "stale_cursor")
+			})
+		})
+	})
+}
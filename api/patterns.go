@@ -0,0 +1,231 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/ONSdigital/dp-filter-api/filters"
+	"github.com/ONSdigital/dp-filter-api/models"
+	"github.com/ONSdigital/dp-filter-api/mongo"
+	dprequest "github.com/ONSdigital/dp-net/request"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// getDryRun returns the value of the dry_run query parameter, which is false if it is not provided
+func getDryRun(r *http.Request) (bool, error) {
+	dryRun := r.URL.Query().Get("dry_run")
+	if dryRun == "" {
+		return false, nil
+	}
+
+	value, err := strconv.ParseBool(dryRun)
+	if err != nil {
+		return false, filters.ErrInvalidQueryParameter
+	}
+	return value, nil
+}
+
+// splitOptionPatterns separates the option codes from the option patterns in the provided list of options
+func splitOptionPatterns(options []string) (codes, patterns []string) {
+	codes = []string{}
+	for _, option := range options {
+		if models.IsOptionPattern(option) {
+			patterns = append(patterns, option)
+			continue
+		}
+		codes = append(codes, option)
+	}
+	return codes, patterns
+}
+
+// matchOptionPatterns evaluates the provided option patterns against the options of the dataset dimension
+func (api *FilterAPI) matchOptionPatterns(ctx context.Context, dataset *models.Dataset, dimensionName string, patterns []string) (*models.OptionPatternMatches, error) {
+	matches := &models.OptionPatternMatches{Items: []models.OptionPatternMatch{}}
+	if len(patterns) == 0 {
+		return matches, nil
+	}
+
+	for _, pattern := range patterns {
+		if err := models.ValidateOptionPattern(pattern); err != nil {
+			return nil, filters.NewBadRequestErr(err.Error())
+		}
+	}
+
+	orderedOptions, err := api.getOrderedDimensionOptions(ctx, dataset, dimensionName)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pattern := range patterns {
+		options := models.MatchOptionPattern(pattern, orderedOptions)
+		matches.Items = append(matches.Items, models.OptionPatternMatch{Pattern: pattern, Options: options, Count: len(options)})
+	}
+	matches.Count = len(matches.Items)
+
+	return matches, nil
+}
+
+// getOptionPatternMatches evaluates the provided option patterns against the options of a filter blueprint dimension, without modifying it
+func (api *FilterAPI) getOptionPatternMatches(ctx context.Context, filterBlueprintID, dimensionName string, patterns []string) (*models.OptionPatternMatches, error) {
+	filterBlueprint, err := api.getFilterBlueprint(ctx, filterBlueprintID, mongo.AnyETag)
+	if err != nil {
+		return nil, err
+	}
+
+	if findDimension(filterBlueprint, dimensionName) == nil {
+		return nil, filters.ErrDimensionNotFound
+	}
+
+	return api.matchOptionPatterns(ctx, filterBlueprint.Dataset, dimensionName, patterns)
+}
+
+// checkOptionPatternMatches validates that every pattern matched at least one option,
+// and that the overall number of matched options does not exceed the maximum allowed in a request
+func (api *FilterAPI) checkOptionPatternMatches(matches *models.OptionPatternMatches) error {
+	total := 0
+	for _, match := range matches.Items {
+		if match.Count == 0 {
+			return filters.NewBadRequestErr(fmt.Sprintf("option pattern %s does not match any dimension option", match.Pattern))
+		}
+		total += match.Count
+	}
+
+	if total > api.maxRequestOptions {
+		return filters.NewBadRequestErr(fmt.Sprintf("option patterns match %d options, which exceeds the maximum of %d", total, api.maxRequestOptions))
+	}
+	return nil
+}
+
+// expandOptions returns the provided option codes, along with the options selected by the provided option selectors
+// and the options matched by any option pattern. All of them are counted against the provided limit,
+// or the maximum of a request if it is nil.
+func (api *FilterAPI) expandOptions(ctx context.Context, filterBlueprint *models.Filter, dimensionName string, options []string, selectors []models.OptionSelector, limit *optionSelectorLimit) ([]string, error) {
+	if limit == nil {
+		limit = api.newOptionSelectorLimit()
+	}
+
+	codes, patterns := splitOptionPatterns(RemoveDuplicateAndEmptyOptions(options))
+	if err := limit.addOptions(len(codes)); err != nil {
+		return nil, err
+	}
+
+	if len(selectors) > 0 {
+		expandedOptions, err := api.expandOptionSelectors(ctx, filterBlueprint.InstanceID, dimensionName, selectors, limit)
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, expandedOptions...)
+	}
+
+	if len(patterns) > 0 {
+		matches, err := api.matchOptionPatterns(ctx, filterBlueprint.Dataset, dimensionName, patterns)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := api.checkOptionPatternMatches(matches); err != nil {
+			return nil, err
+		}
+
+		for _, match := range matches.Items {
+			if err := limit.addOptions(match.Count); err != nil {
+				return nil, err
+			}
+			codes = append(codes, match.Options...)
+		}
+	}
+
+	return RemoveDuplicateAndEmptyOptions(codes), nil
+}
+
+// writeOptionPatternMatch writes the dimension options matched by the provided option or pattern, without modifying the filter blueprint
+func (api *FilterAPI) writeOptionPatternMatch(w http.ResponseWriter, r *http.Request, filterBlueprintID, dimensionName, pattern string, logData log.Data) {
+	ctx := r.Context()
+
+	matches, err := api.getOptionPatternMatches(ctx, filterBlueprintID, dimensionName, []string{pattern})
+	if err != nil {
+		log.Error(ctx, "error matching option pattern", err, logData)
+		setErrorCodeFromError(w, r, err)
+		return
+	}
+
+	setJSONContentType(w)
+	if err = WriteJSONBody(ctx, matches.Items[0], w, logData); err != nil {
+		log.Error(ctx, "error writing JSON body for option pattern dry run", err, logData)
+		setErrorCode(w, r, err)
+		return
+	}
+
+	log.Info(ctx, "option pattern dry run completed", logData)
+}
+
+// addFilterBlueprintDimensionOptionPattern adds the dimension options matched by the provided pattern to a filter blueprint dimension,
+// and writes the matched options as the response
+func (api *FilterAPI) addFilterBlueprintDimensionOptionPattern(w http.ResponseWriter, r *http.Request, filterBlueprintID, dimensionName, pattern, eTag string, logData log.Data) {
+	ctx := r.Context()
+
+	matches, err := api.getOptionPatternMatches(ctx, filterBlueprintID, dimensionName, []string{pattern})
+	if err == nil {
+		err = api.checkOptionPatternMatches(matches)
+	}
+	if err != nil {
+		log.Error(ctx, "error matching option pattern", err, logData)
+		setErrorCodeFromErrorExpectDimension(w, r, err)
+		return
+	}
+	match := matches.Items[0]
+	logData["matched_options_count"] = match.Count
+
+	newETag, err := api.addFilterBlueprintDimensionOptions(ctx, filterBlueprintID, dimensionName, eTag, match.Options, nil, nil, logData)
+	if err != nil {
+		log.Error(ctx, "error adding filter blueprint dimension options matched by pattern", err, logData)
+		setErrorCodeFromErrorExpectDimension(w, r, err)
+		return
+	}
+
+	setJSONContentType(w)
+	setETag(w, newETag)
+	w.WriteHeader(http.StatusCreated)
+	if err = WriteJSONBody(ctx, match, w, logData); err != nil {
+		log.Error(ctx, "failed to write bytes for http response", err, logData)
+		return
+	}
+
+	log.Info(ctx, "added dimension options matched by pattern to filter blueprint", logData)
+}
+
+// writePatchOptionPatternMatches writes the dimension options matched by the option patterns provided in a list of patch operations,
+// without modifying the filter blueprint
+func (api *FilterAPI) writePatchOptionPatternMatches(w http.ResponseWriter, r *http.Request, filterBlueprintID, dimensionName string, patches []dprequest.Patch, logData log.Data) {
+	ctx := r.Context()
+
+	patterns := []string{}
+	for _, patch := range patches {
+		values, err := getOptionsFromInterface(patch.Value)
+		if err != nil {
+			log.Error(ctx, "error obtaining options from patch value", err, logData)
+			writeError(w, r, http.StatusBadRequest, err)
+			return
+		}
+		_, valuePatterns := splitOptionPatterns(values.Options)
+		patterns = append(patterns, valuePatterns...)
+	}
+
+	matches, err := api.getOptionPatternMatches(ctx, filterBlueprintID, dimensionName, patterns)
+	if err != nil {
+		log.Error(ctx, "error matching option patterns", err, logData)
+		setErrorCodeFromError(w, r, err)
+		return
+	}
+
+	setJSONContentType(w)
+	if err = WriteJSONBody(ctx, matches, w, logData); err != nil {
+		log.Error(ctx, "error writing JSON body for option patterns dry run", err, logData)
+		setErrorCode(w, r, err)
+		return
+	}
+
+	log.Info(ctx, "option patterns dry run completed", logData)
+}
@@ -0,0 +1,189 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ONSdigital/dp-filter-api/api"
+	apimock "github.com/ONSdigital/dp-filter-api/api/mock"
+	"github.com/ONSdigital/dp-filter-api/mock"
+	"github.com/ONSdigital/dp-filter-api/models"
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAddFilterBlueprintDimensionOption_Pattern(t *testing.T) {
+	t.Parallel()
+
+	filterFlexAPIMock := &apimock.FilterFlexAPIMock{}
+
+	Convey("Given a filter blueprint with a dimension", t, func() {
+		ds := mock.NewDataStore().Mock
+		w := httptest.NewRecorder()
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), ds, &mock.FilterJob{}, mock.NewDatasetAPI().Mock, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+
+		Convey("When an option pattern is added", func() {
+			r, err := http.NewRequest("POST", "http://localhost:22100/filters/12345678/dimensions/age/options/pattern:2*", http.NoBody)
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the matched options are added, and returned in the response", func() {
+				So(w.Code, ShouldEqual, http.StatusCreated)
+				So(ds.AddFilterDimensionOptionsCalls(), ShouldHaveLength, 1)
+				So(ds.AddFilterDimensionOptionsCalls()[0].Options, ShouldResemble, []string{"27"})
+
+				var match models.OptionPatternMatch
+				So(json.Unmarshal(w.Body.Bytes(), &match), ShouldBeNil)
+				So(match, ShouldResemble, models.OptionPatternMatch{Pattern: "pattern:2*", Options: []string{"27"}, Count: 1})
+			})
+		})
+
+		Convey("When an option pattern is requested as a dry run", func() {
+			r, err := http.NewRequest("POST", "http://localhost:22100/filters/12345678/dimensions/age/options/pattern:*?dry_run=true", http.NoBody)
+			So(err, ShouldBeNil)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the matched options are returned, without an If-Match header, and nothing is added", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				var match models.OptionPatternMatch
+				So(json.Unmarshal(w.Body.Bytes(), &match), ShouldBeNil)
+				So(match, ShouldResemble, models.OptionPatternMatch{Pattern: "pattern:*", Options: []string{"27", "33"}, Count: 2})
+				So(ds.AddFilterDimensionOptionsCalls(), ShouldHaveLength, 0)
+			})
+		})
+
+		Convey("When an option pattern that does not match any option is added", func() {
+			r, err := http.NewRequest("POST", "http://localhost:22100/filters/12345678/dimensions/age/options/pattern:9*", http.NoBody)
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the response is 400 bad request and nothing is added", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(problemDetail(w.Body.String()), ShouldEqual, "option pattern pattern:9* does not match any dimension option")
+				So(ds.AddFilterDimensionOptionsCalls(), ShouldHaveLength, 0)
+			})
+		})
+
+		Convey("When the dry_run query parameter is not valid", func() {
+			r, err := http.NewRequest("POST", "http://localhost:22100/filters/12345678/dimensions/age/options/pattern:*?dry_run=maybe", http.NoBody)
+			So(err, ShouldBeNil)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the response is 400 bad request", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(problemDetail(w.Body.String()), ShouldEqual, invalidQueryParameterResponse)
+			})
+		})
+	})
+
+	Convey("Given a maximum number of options per request lower than the options matched by a pattern", t, func() {
+		ds := mock.NewDataStore().Mock
+		w := httptest.NewRecorder()
+		limitedCfg := cfg()
+		limitedCfg.MaxRequestOptions = 1
+		filterAPI := api.Setup(limitedCfg, mux.NewRouter(), ds, &mock.FilterJob{}, mock.NewDatasetAPI().Mock, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+
+		Convey("When the option pattern is added", func() {
+			r, err := http.NewRequest("POST", "http://localhost:22100/filters/12345678/dimensions/age/options/pattern:*", http.NoBody)
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the response is 400 bad request and nothing is added", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(problemDetail(w.Body.String()), ShouldEqual, "option patterns match 2 options, which exceeds the maximum of 1")
+				So(ds.AddFilterDimensionOptionsCalls(), ShouldHaveLength, 0)
+			})
+		})
+	})
+}
+
+func TestRemoveFilterBlueprintDimensionOption_Pattern(t *testing.T) {
+	t.Parallel()
+
+	filterFlexAPIMock := &apimock.FilterFlexAPIMock{}
+
+	Convey("Given a filter blueprint with a dimension", t, func() {
+		ds := mock.NewDataStore().Mock
+		w := httptest.NewRecorder()
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), ds, &mock.FilterJob{}, mock.NewDatasetAPI().Mock, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+
+		Convey("When an encoded option pattern is removed", func() {
+			r, err := http.NewRequest("DELETE", "http://localhost:22100/filters/12345678/dimensions/age/options/pattern:3%3F", http.NoBody)
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the matched options are removed", func() {
+				So(w.Code, ShouldEqual, http.StatusNoContent)
+				So(ds.RemoveFilterDimensionOptionsCalls(), ShouldHaveLength, 1)
+				So(ds.RemoveFilterDimensionOptionsCalls()[0].Options, ShouldResemble, []string{"33"})
+			})
+		})
+
+		Convey("When an option code containing a wildcard character, without the pattern prefix, is removed", func() {
+			r, err := http.NewRequest("DELETE", "http://localhost:22100/filters/12345678/dimensions/age/options/3%3F", http.NoBody)
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then it is looked up as an option code instead of matched as a pattern, so it is not found", func() {
+				So(w.Code, ShouldEqual, http.StatusNotFound)
+				So(ds.RemoveFilterDimensionOptionsCalls(), ShouldHaveLength, 0)
+			})
+		})
+	})
+}
+
+func TestPatchFilterBlueprintDimension_Patterns(t *testing.T) {
+	t.Parallel()
+
+	filterFlexAPIMock := &apimock.FilterFlexAPIMock{}
+
+	Convey("Given a filter blueprint with a dimension", t, func() {
+		ds := mock.NewDataStore().Mock
+		w := httptest.NewRecorder()
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), ds, &mock.FilterJob{}, mock.NewDatasetAPI().Mock, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+
+		Convey("When a patch with option patterns is requested as a dry run", func() {
+			body := `[{"op":"add","path":"/options/-","value":["pattern:2*","33"]},{"op":"remove","path":"/options/-","value":["pattern:9*"]}]`
+			r, err := http.NewRequest("PATCH", "http://localhost:22100/filters/12345678/dimensions/age?dry_run=true", strings.NewReader(body))
+			So(err, ShouldBeNil)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the options matched by each pattern are returned and nothing is changed", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				var matches models.OptionPatternMatches
+				So(json.Unmarshal(w.Body.Bytes(), &matches), ShouldBeNil)
+				So(matches, ShouldResemble, models.OptionPatternMatches{
+					Items: []models.OptionPatternMatch{
+						{Pattern: "pattern:2*", Options: []string{"27"}, Count: 1},
+						{Pattern: "pattern:9*", Options: []string{}, Count: 0},
+					},
+					Count: 2,
+				})
+				So(ds.AddFilterDimensionOptionsCalls(), ShouldHaveLength, 0)
+				So(ds.RemoveFilterDimensionOptionsCalls(), ShouldHaveLength, 0)
+			})
+		})
+
+		Convey("When a patch adds an option pattern", func() {
+			body := `[{"op":"add","path":"/options/-","value":["pattern:2*"]}]`
+			r, err := http.NewRequest("PATCH", "http://localhost:22100/filters/12345678/dimensions/age", strings.NewReader(body))
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the matched options are added", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(ds.AddFilterDimensionOptionsCalls(), ShouldHaveLength, 1)
+				So(ds.AddFilterDimensionOptionsCalls()[0].Options, ShouldResemble, []string{"27"})
+			})
+		})
+	})
+}
@@ -0,0 +1,38 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/ONSdigital/dp-filter-api/filters"
+)
+
+var (
+	errBadRequestBody        = problemErr{err: filters.ErrBadRequest, msg: BadRequest}
+	errInternal              = problemErr{err: filters.ErrInternalError, msg: InternalError}
+	errVersionNoLongerExists = problemErr{err: filters.ErrVersionNotFound, msg: "version for filter blueprint no longer exists"}
+)
+
+// problemErr wraps one of the filters package errors, responding with a message of its own as the detail of the problem
+type problemErr struct {
+	err error
+	msg string
+}
+
+func (e problemErr) Error() string {
+	return e.msg
+}
+
+// Message returns the message rendered as the detail of the problem
+func (e problemErr) Message() string {
+	return e.msg
+}
+
+// Unwrap returns the wrapped error, from which the code of the problem is obtained
+func (e problemErr) Unwrap() error {
+	return e.err
+}
+
+// writeError responds to a request with the problem details of an error, as an application/problem+json body
+func writeError(w http.ResponseWriter, r *http.Request, status int, err error) {
+	filters.WriteProblem(w, r, status, err)
+}
@@ -0,0 +1,171 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"strconv"
+
+	datasetAPI "github.com/ONSdigital/dp-api-clients-go/v2/dataset"
+	"github.com/ONSdigital/dp-filter-api/filters"
+	"github.com/ONSdigital/dp-filter-api/models"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// getOrderedDimensionOptions returns the codes of all the options of a dataset dimension, in the order provided by the Dataset API
+func (api *FilterAPI) getOrderedDimensionOptions(ctx context.Context, dataset *models.Dataset, dimensionName string) ([]string, error) {
+	var orderedOptions []string
+
+	// batches may be processed in any order, so each option is placed according to the batch offset
+	processBatch := func(batch datasetAPI.Options) (abort bool, err error) {
+		if orderedOptions == nil {
+			orderedOptions = make([]string, batch.TotalCount)
+		}
+		for i := range batch.Items {
+			if pos := batch.Offset + i; pos < len(orderedOptions) {
+				orderedOptions[pos] = batch.Items[i].Option
+			}
+		}
+		return false, nil
+	}
+
+	err := api.datasetAPI.GetOptionsBatchProcess(ctx,
+		getUserAuthToken(ctx),
+		api.serviceAuthToken,
+		getCollectionID(ctx),
+		dataset.ID,
+		dataset.Edition,
+		strconv.Itoa(dataset.Version),
+		dimensionName,
+		nil,
+		processBatch,
+		api.maxDatasetOptions,
+		api.BatchMaxWorkers)
+	if err != nil {
+		if apiErr, ok := err.(*datasetAPI.ErrInvalidDatasetAPIResponse); ok {
+			if apiErr.Code() == http.StatusNotFound {
+				return nil, filters.ErrDimensionOptionsNotFound
+			}
+		}
+		return nil, err
+	}
+
+	return orderedOptions, nil
+}
+
+// getSelectedOptions returns the options selected by a filter dimension. The dataset dimension options are only requested
+// when the selection depends on them, which is the case for dimensions in exclude mode or with option ranges.
+func (api *FilterAPI) getSelectedOptions(ctx context.Context, dataset *models.Dataset, dimension models.Dimension) ([]string, error) {
+	if !dimension.IsExclude() && !dimension.HasRanges() {
+		return dimension.Options, nil
+	}
+
+	orderedOptions, err := api.getOrderedDimensionOptions(ctx, dataset, dimension.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return dimension.SelectedOptions(orderedOptions), nil
+}
+
+// checkOptionRanges validates that the boundaries of the provided ranges are options of the dataset dimension,
+// and that each range starts before it ends
+func (api *FilterAPI) checkOptionRanges(ctx context.Context, dataset *models.Dataset, dimensionName string, ranges []models.OptionRange) error {
+	if len(ranges) == 0 {
+		return nil
+	}
+
+	orderedOptions, err := api.getOrderedDimensionOptions(ctx, dataset, dimensionName)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range ranges {
+		if _, _, err := r.Resolve(orderedOptions); err != nil {
+			log.Error(ctx, "invalid option range", err, log.Data{"dimension": dimensionName, "range": r})
+			return filters.NewBadRequestErr(err.Error())
+		}
+	}
+	return nil
+}
+
+// addFilterBlueprintDimensionRanges adds the options listed by the provided ranges to the selection of a filter dimension.
+// In exclude mode, this means removing the options from the excluded ones.
+func (api *FilterAPI) addFilterBlueprintDimensionRanges(ctx context.Context, filterBlueprintID, dimensionName, eTag string, ranges []models.OptionRange) (newETag string, err error) {
+	return api.updateFilterBlueprintDimensionRanges(ctx, filterBlueprintID, dimensionName, eTag, ranges, true)
+}
+
+// removeFilterBlueprintDimensionRanges removes the options listed by the provided ranges from the selection of a filter dimension.
+// In exclude mode, this means adding the options to the excluded ones.
+func (api *FilterAPI) removeFilterBlueprintDimensionRanges(ctx context.Context, filterBlueprintID, dimensionName, eTag string, ranges []models.OptionRange) (newETag string, err error) {
+	return api.updateFilterBlueprintDimensionRanges(ctx, filterBlueprintID, dimensionName, eTag, ranges, false)
+}
+
+func (api *FilterAPI) updateFilterBlueprintDimensionRanges(ctx context.Context, filterBlueprintID, dimensionName, eTag string, ranges []models.OptionRange, selected bool) (newETag string, err error) {
+	filterBlueprint, err := api.getFilterBlueprint(ctx, filterBlueprintID, eTag)
+	if err != nil {
+		return "", err
+	}
+
+	dimension := findDimension(filterBlueprint, dimensionName)
+	if dimension == nil {
+		return "", filters.ErrDimensionNotFound
+	}
+
+	orderedOptions, err := api.getOrderedDimensionOptions(ctx, filterBlueprint.Dataset, dimensionName)
+	if err != nil {
+		return "", err
+	}
+
+	// copy the dimension, so that the current filter blueprint is not modified
+	updated := *dimension
+	updated.Options = append([]string{}, dimension.Options...)
+	updated.Ranges = append([]models.OptionRange{}, dimension.Ranges...)
+
+	// the ranges are stored when they select options in include mode, or when they leave options out in exclude mode
+	if selected != dimension.IsExclude() {
+		for _, r := range ranges {
+			if _, _, err := r.Resolve(orderedOptions); err != nil {
+				return "", filters.NewBadRequestErr(err.Error())
+			}
+		}
+		updated.AddRanges(ranges)
+	} else if err := updated.RemoveRanges(ranges, orderedOptions); err != nil {
+		return "", filters.NewBadRequestErr(err.Error())
+	}
+
+	if reflect.DeepEqual(updated.Options, dimension.Options) && reflect.DeepEqual(updated.Ranges, dimension.Ranges) {
+		log.Info(ctx, "option ranges do not change the dimension, nothing to update", log.Data{"dimension": dimensionName})
+		return filterBlueprint.ETag, nil
+	}
+
+	return api.dataStore.UpdateFilterDimension(ctx, filterBlueprintID, updated, filterBlueprint.UniqueTimestamp, filterBlueprint.ETag, filterBlueprint)
+}
+
+// expandFilterOutputRanges replaces the option ranges of the filter output dimensions with the options they list
+// in the dataset version of the filter output, so that consumers of filter outputs do not need to resolve them
+func (api *FilterAPI) expandFilterOutputRanges(ctx context.Context, filterOutput *models.Filter) error {
+	dimensions := make([]models.Dimension, len(filterOutput.Dimensions))
+	copy(dimensions, filterOutput.Dimensions)
+
+	for i := range dimensions {
+		if !dimensions[i].HasRanges() {
+			continue
+		}
+
+		orderedOptions, err := api.getOrderedDimensionOptions(ctx, filterOutput.Dataset, dimensions[i].Name)
+		if err != nil {
+			return err
+		}
+
+		options, err := dimensions[i].ExpandRanges(orderedOptions)
+		if err != nil {
+			return filters.NewBadRequestErr(err.Error())
+		}
+		dimensions[i].Options = RemoveDuplicateAndEmptyOptions(options)
+		dimensions[i].Ranges = nil
+	}
+
+	filterOutput.Dimensions = dimensions
+	return nil
+}
@@ -0,0 +1,222 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ONSdigital/dp-filter-api/api"
+	apimock "github.com/ONSdigital/dp-filter-api/api/mock"
+	"github.com/ONSdigital/dp-filter-api/mock"
+	"github.com/ONSdigital/dp-filter-api/models"
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// rangeDataStoreMock returns a datastore mock with a filter blueprint whose 'age' dimension selects the options from '27' to '33'
+func rangeDataStoreMock() *apimock.DataStoreMock {
+	ds := mock.NewDataStore().Mock
+	ds.GetFilterFunc = func(ctx context.Context, filterID string, eTagSelector string) (*models.Filter, error) {
+		return &models.Filter{
+			FilterID:   filterID,
+			Dataset:    &models.Dataset{ID: "123", Edition: "2017", Version: 1},
+			InstanceID: "12345678",
+			Published:  &models.Published,
+			Dimensions: []models.Dimension{{Name: "age", Options: []string{}, Ranges: []models.OptionRange{{From: "27", To: "33"}}}},
+			ETag:       testETag,
+		}, nil
+	}
+	return ds
+}
+
+func TestAddFilterBlueprintDimension_OptionRanges(t *testing.T) {
+	t.Parallel()
+
+	filterFlexAPIMock := &apimock.FilterFlexAPIMock{}
+
+	Convey("Given a filter blueprint", t, func() {
+		ds := mock.NewDataStore().Mock
+		w := httptest.NewRecorder()
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), ds, &mock.FilterJob{}, mock.NewDatasetAPI().Mock, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+
+		Convey("When a dimension is added with an option range", func() {
+			reader := strings.NewReader(`{"ranges":[{"from":"27","to":"33"}]}`)
+			r, err := http.NewRequest("POST", "http://localhost:22100/filters/12345678/dimensions/age", reader)
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the range is stored as a range", func() {
+				So(w.Code, ShouldEqual, http.StatusCreated)
+				So(ds.AddFilterDimensionCalls(), ShouldHaveLength, 1)
+				So(ds.AddFilterDimensionCalls()[0].Ranges, ShouldResemble, []models.OptionRange{{From: "27", To: "33"}})
+			})
+		})
+
+		Convey("When a dimension is added with a range boundary that is not a dimension option", func() {
+			reader := strings.NewReader(`{"ranges":[{"from":"27","to":"99"}]}`)
+			r, err := http.NewRequest("POST", "http://localhost:22100/filters/12345678/dimensions/age", reader)
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the response is 400 bad request and nothing is stored", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(problemDetail(w.Body.String()), ShouldEqual, "range boundary 99 not found in the dimension options")
+				So(ds.AddFilterDimensionCalls(), ShouldHaveLength, 0)
+			})
+		})
+
+		Convey("When a dimension is added with a range that starts after it ends", func() {
+			reader := strings.NewReader(`{"ranges":[{"from":"33","to":"27"}]}`)
+			r, err := http.NewRequest("POST", "http://localhost:22100/filters/12345678/dimensions/age", reader)
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the response is 400 bad request and nothing is stored", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(ds.AddFilterDimensionCalls(), ShouldHaveLength, 0)
+			})
+		})
+	})
+}
+
+func TestPatchFilterBlueprintDimension_OptionRanges(t *testing.T) {
+	t.Parallel()
+
+	filterFlexAPIMock := &apimock.FilterFlexAPIMock{}
+
+	Convey("Given a filter blueprint with an option range", t, func() {
+		ds := rangeDataStoreMock()
+		w := httptest.NewRecorder()
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), ds, &mock.FilterJob{}, mock.NewDatasetAPI().Mock, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+
+		Convey("When a patch removes part of the range", func() {
+			body := `[{"op":"remove","path":"/options/-","value":[{"from":"33","to":"33"}]}]`
+			r, err := http.NewRequest("PATCH", "http://localhost:22100/filters/12345678/dimensions/age", strings.NewReader(body))
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the stored range is cut down to the remaining options", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(ds.UpdateFilterDimensionCalls(), ShouldHaveLength, 1)
+				So(ds.UpdateFilterDimensionCalls()[0].Dimension.Ranges, ShouldResemble, []models.OptionRange{{From: "27", To: "27"}})
+				So(ds.RemoveFilterDimensionOptionsCalls(), ShouldHaveLength, 0)
+			})
+		})
+
+		Convey("When a patch adds the range that is already stored", func() {
+			body := `[{"op":"add","path":"/options/-","value":[{"from":"27","to":"33"}]}]`
+			r, err := http.NewRequest("PATCH", "http://localhost:22100/filters/12345678/dimensions/age", strings.NewReader(body))
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then nothing is updated", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(ds.UpdateFilterDimensionCalls(), ShouldHaveLength, 0)
+			})
+		})
+
+		Convey("When a patch adds an invalid range", func() {
+			body := `[{"op":"add","path":"/options/-","value":[{"from":"33","to":"27"}]}]`
+			r, err := http.NewRequest("PATCH", "http://localhost:22100/filters/12345678/dimensions/age", strings.NewReader(body))
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the response is 400 bad request and nothing is updated", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(ds.UpdateFilterDimensionCalls(), ShouldHaveLength, 0)
+			})
+		})
+
+		Convey("When a patch value is a range without an end", func() {
+			body := `[{"op":"add","path":"/options/-","value":[{"from":"27"}]}]`
+			r, err := http.NewRequest("PATCH", "http://localhost:22100/filters/12345678/dimensions/age", strings.NewReader(body))
+			So(err, ShouldBeNil)
+			r.Header.Set("If-Match", testETag)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the response is 400 bad request", func() {
+				So(w.Code, ShouldEqual, http.StatusBadRequest)
+				So(problemDetail(w.Body.String()), ShouldEqual, "values provided are not strings, valid option selectors or valid option ranges")
+			})
+		})
+	})
+}
+
+func TestGetFilterBlueprintDimensionOptions_OptionRanges(t *testing.T) {
+	t.Parallel()
+
+	filterFlexAPIMock := &apimock.FilterFlexAPIMock{}
+
+	Convey("Given a filter blueprint with an option range", t, func() {
+		w := httptest.NewRecorder()
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), rangeDataStoreMock(), &mock.FilterJob{}, mock.NewDatasetAPI().Mock, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+
+		Convey("When the dimension options are requested", func() {
+			r, err := http.NewRequest("GET", "http://localhost:22100/filters/12345678/dimensions/age/options", http.NoBody)
+			So(err, ShouldBeNil)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the options listed by the range are returned", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				var options models.PublicDimensionOptions
+				So(json.Unmarshal(w.Body.Bytes(), &options), ShouldBeNil)
+				So(options.Items, ShouldHaveLength, 2)
+				So(options.Items[0].Option, ShouldEqual, "27")
+				So(options.Items[1].Option, ShouldEqual, "33")
+			})
+		})
+
+		Convey("When an option listed by the range is requested", func() {
+			r, err := http.NewRequest("GET", "http://localhost:22100/filters/12345678/dimensions/age/options/33", http.NoBody)
+			So(err, ShouldBeNil)
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the option is found", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+			})
+		})
+	})
+}
+
+func TestSubmitFilterBlueprint_OptionRanges(t *testing.T) {
+	t.Parallel()
+
+	filterFlexAPIMock := &apimock.FilterFlexAPIMock{}
+
+	Convey("Given a new filter blueprint with an option range that is submitted", t, func() {
+		ds := mock.NewDataStore().Mock
+		ds.AddFilterFunc = func(ctx context.Context, filter *models.Filter) (*models.Filter, error) {
+			filter.ETag = testETag
+			return filter, nil
+		}
+		w := httptest.NewRecorder()
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), ds, &mock.FilterJob{}, mock.NewDatasetAPI().Mock, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+
+		reader := strings.NewReader(`{"dataset":{"version":1,"edition":"1","id":"1"},"dimensions":[{"name":"age","ranges":[{"from":"27","to":"33"}]}]}`)
+		r, err := http.NewRequest("POST", cfg().Host+"/filters?submitted=true", reader)
+		So(err, ShouldBeNil)
+		filterAPI.Router.ServeHTTP(w, r)
+
+		Convey("Then the filter blueprint keeps the range", func() {
+			So(w.Code, ShouldEqual, http.StatusCreated)
+			So(ds.AddFilterCalls(), ShouldHaveLength, 1)
+			So(ds.AddFilterCalls()[0].Filter.Dimensions[0].Ranges, ShouldResemble, []models.OptionRange{{From: "27", To: "33"}})
+		})
+
+		Convey("Then the filter output lists the options selected by the range", func() {
+			So(ds.CreateFilterOutputCalls(), ShouldHaveLength, 1)
+			dimension := ds.CreateFilterOutputCalls()[0].Filter.Dimensions[0]
+			So(dimension.Options, ShouldResemble, []string{"27", "33"})
+			So(dimension.Ranges, ShouldBeEmpty)
+		})
+	})
+}
@@ -0,0 +1,186 @@
+package api_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ONSdigital/dp-filter-api/api"
+	apimock "github.com/ONSdigital/dp-filter-api/api/mock"
+	"github.com/ONSdigital/dp-filter-api/middleware"
+	"github.com/ONSdigital/dp-filter-api/mock"
+	"github.com/ONSdigital/dp-filter-api/models"
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// contractCase is a request to the API, with the status it is expected to be responded with
+type contractCase struct {
+	method  string
+	path    string
+	body    string
+	ifMatch string
+	status  int
+	// noContentType sends the body without a Content-Type header, as some clients do
+	noContentType bool
+}
+
+func (c contractCase) String() string {
+	request := c.method + " " + c.path
+	if c.ifMatch != "" {
+		request += " with If-Match " + c.ifMatch
+	}
+	if c.body != "" {
+		request += " with body " + c.body
+	}
+	if c.noContentType {
+		request += " without Content-Type"
+	}
+	return request
+}
+
+// TestSwaggerContract validates the responses of the API against swagger.yaml, so that the specification does not drift from the API.
+// A response that does not match the specification is replaced with 500 internal server error by the swagger validator.
+func TestSwaggerContract(t *testing.T) {
+	t.Parallel()
+
+	validator, err := middleware.NewSwaggerValidator(context.Background(), "../swagger.yaml", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const (
+		newFilter = `{"dataset":{"version":1,"edition":"2017","id":"123"},"dimensions":[{"name":"age","options":["33"]}]}`
+		// cursors issued for the options of the time dimension, for the current and a previous filter blueprint ETag
+		cursor      = "eyJrZXkiOiIyMDE0IiwiZXRhZyI6InRlc3RFVGFnMCJ9"
+		staleCursor = "eyJrZXkiOiIyMDE0IiwiZXRhZyI6InByZXZpb3VzRVRhZyJ9"
+	)
+
+	serve := func(dataStore api.DataStore, c contractCase) *httptest.ResponseRecorder {
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), dataStore, &mock.FilterJob{}, &mock.DatasetAPI{}, &apimock.FilterFlexAPIMock{}, hostURL, datasetAPIURL, parsedDownloadServiceURL, false, api.WithHierarchyAPI(&mock.HierarchyAPI{}), api.WithObservationsAPI(&mock.ObservationsAPI{}))
+
+		var body io.Reader = http.NoBody
+		if c.body != "" {
+			body = strings.NewReader(c.body)
+		}
+		r := createAuthenticatedRequest(c.method, "http://localhost:22100"+c.path, body)
+		if c.body != "" && !c.noContentType {
+			r.Header.Set("Content-Type", "application/json")
+		}
+		r.Header.Set("If-Match", "*")
+		if c.ifMatch != "" {
+			r.Header.Set("If-Match", c.ifMatch)
+		}
+
+		w := httptest.NewRecorder()
+		validator.Validate(filterAPI.Router).ServeHTTP(w, r)
+		return w
+	}
+
+	Convey("Given a published filter blueprint and a completed filter output", t, func() {
+		dataStore := mock.NewDataStore()
+		dataStore.Mock.AddFilterFunc = func(ctx context.Context, filter *models.Filter) (*models.Filter, error) {
+			filter.ETag = testETag
+			return filter, nil
+		}
+		dataStore.Mock.CreateFilterOutputFunc = func(ctx context.Context, filter *models.Filter) error {
+			return nil
+		}
+		dataStore.Mock.GetFilterOutputFunc = func(ctx context.Context, filterOutputID string) (*models.Filter, error) {
+			output, err := dataStore.GetFilterOutput(ctx, filterOutputID)
+			output.Dataset = &models.Dataset{ID: "123", Edition: "2017", Version: 1}
+			return output, err
+		}
+
+		for _, c := range []contractCase{
+			{method: "POST", path: "/filters", body: newFilter, status: http.StatusCreated},
+			{method: "POST", path: "/filters?submitted=true", body: newFilter, status: http.StatusCreated},
+			{method: "POST", path: "/filters", body: newFilter, status: http.StatusCreated, noContentType: true},
+			{method: "POST", path: "/filters", body: `{"dataset":{"version":1,"edition":"2017","id":"123"},"dimensions":[{"name":"age","options":["44"]}]}`, status: http.StatusBadRequest},
+			{method: "POST", path: "/filters", body: `{"dataset":{"version":1}}`, status: http.StatusBadRequest},
+			{method: "GET", path: "/filters/12345678", status: http.StatusOK},
+			{method: "GET", path: "/filters/12345678?fields=dataset&embed=options&limit=1", status: http.StatusOK},
+			{method: "PUT", path: "/filters/12345678", body: `{"dimensions":[{"name":"age","options":["33"]}]}`, status: http.StatusOK},
+			{method: "PUT", path: "/filters/12345678", body: `{"dimensions":[{"name":"age","options":["33"]}]}`, ifMatch: "previousETag", status: http.StatusConflict},
+			{method: "PUT", path: "/filters/12345678?submitted=true", body: `{}`, status: http.StatusOK},
+			{method: "PATCH", path: "/filters/12345678", body: `[{"op":"add","path":"/dimensions/age/options/-","value":["33"]}]`, status: http.StatusOK},
+			{method: "PATCH", path: "/filters/12345678", body: `[{"op":"move","path":"/dimensions/age"}]`, status: http.StatusBadRequest},
+			{method: "POST", path: "/filters/12345678/copy", status: http.StatusCreated},
+			{method: "POST", path: "/filters/12345678/rebase?to=latest", status: http.StatusOK},
+			{method: "POST", path: "/filters/12345678/rebase?to=newest", status: http.StatusBadRequest},
+			{method: "POST", path: "/filters/12345678/restore", body: `{"e_tag":"testETag0"}`, status: http.StatusOK},
+			{method: "POST", path: "/filters/12345678/submit", status: http.StatusBadRequest},
+			{method: "GET", path: "/filters/12345678/estimate", status: http.StatusOK},
+			{method: "GET", path: "/filters/12345678/preview", status: http.StatusOK},
+			{method: "GET", path: "/filters/12345678/dimensions", status: http.StatusOK},
+			{method: "GET", path: "/filters/12345678/dimensions?limit=1", status: http.StatusOK},
+			{method: "PUT", path: "/filters/12345678/dimensions", body: `{"items":[{"name":"age","options":["33"]}]}`, status: http.StatusOK},
+			{method: "PUT", path: "/filters/12345678/dimensions", body: `{"items":[{"name":"age","options":["44"]}]}`, status: http.StatusBadRequest},
+			{method: "POST", path: "/filters/12345678/dimensions", body: `{}`, status: http.StatusBadRequest},
+			{method: "GET", path: "/filters/12345678/dimensions/age", status: http.StatusOK},
+			{method: "POST", path: "/filters/12345678/dimensions/age", body: `{"options":["33"]}`, status: http.StatusCreated},
+			{method: "PUT", path: "/filters/12345678/dimensions/age", body: `{"options":["33"]}`, status: http.StatusBadRequest},
+			{method: "PATCH", path: "/filters/12345678/dimensions/age", body: `[{"op":"add","path":"/options/-","value":["33"]}]`, status: http.StatusOK},
+			{method: "PATCH", path: "/filters/12345678/dimensions/age?dry_run=true", body: `[{"op":"add","path":"/options/-","value":["pattern:3*"]}]`, status: http.StatusOK},
+			{method: "DELETE", path: "/filters/12345678/dimensions/age", status: http.StatusNoContent},
+			{method: "GET", path: "/filters/12345678/dimensions/time/options?limit=1", status: http.StatusOK},
+			{method: "GET", path: "/filters/12345678/dimensions/time/options?cursor=" + cursor, status: http.StatusOK},
+			{method: "GET", path: "/filters/12345678/dimensions/time/options?cursor=" + staleCursor, status: http.StatusConflict},
+			{method: "DELETE", path: "/filters/12345678/dimensions/age/options", status: http.StatusBadRequest},
+			{method: "GET", path: "/filters/12345678/dimensions/age/options/33", status: http.StatusOK},
+			{method: "POST", path: "/filters/12345678/dimensions/age/options/33", status: http.StatusCreated},
+			{method: "POST", path: "/filters/12345678/dimensions/age/options/pattern:*?dry_run=true", status: http.StatusOK},
+			{method: "DELETE", path: "/filters/12345678/dimensions/age/options/33", status: http.StatusNoContent},
+			{method: "GET", path: "/filter-outputs/12345678", status: http.StatusOK},
+			{method: "PUT", path: "/filter-outputs/12345678", body: `{"state":"completed"}`, status: http.StatusOK},
+			{method: "POST", path: "/filter-outputs/12345678/events", body: `{"type":"FilterOutputCSVGenEnd","time":"2016-07-17T08:38:25.316Z"}`, status: http.StatusCreated},
+		} {
+			Convey(fmt.Sprintf("When %s is requested", c), func() {
+				w := serve(dataStore.Mock, c)
+
+				Convey(fmt.Sprintf("Then the %d response matches the swagger specification", c.status), func() {
+					So(w.Body.String(), ShouldNotContainSubstring, "does not match the swagger specification")
+					So(w.Code, ShouldEqual, c.status)
+				})
+			})
+		}
+	})
+
+	Convey("Given a filter blueprint and a filter output that do not exist", t, func() {
+		dataStore := mock.NewDataStore().NotFound()
+
+		for _, c := range []contractCase{
+			{method: "GET", path: "/filters/12345678", status: http.StatusNotFound},
+			{method: "PUT", path: "/filters/12345678", body: `{"dimensions":[]}`, status: http.StatusNotFound},
+			{method: "PATCH", path: "/filters/12345678", body: `[{"op":"add","path":"/dimensions/age/options/-","value":["33"]}]`, status: http.StatusNotFound},
+			{method: "GET", path: "/filters/12345678/estimate", status: http.StatusNotFound},
+			{method: "GET", path: "/filters/12345678/dimensions", status: http.StatusNotFound},
+			{method: "GET", path: "/filters/12345678/dimensions/age", status: http.StatusBadRequest},
+			{method: "GET", path: "/filters/12345678/dimensions/age/options", status: http.StatusNotFound},
+			{method: "GET", path: "/filter-outputs/12345678", status: http.StatusNotFound},
+		} {
+			Convey(fmt.Sprintf("When %s is requested", c), func() {
+				w := serve(dataStore.Mock, c)
+
+				Convey(fmt.Sprintf("Then the %d response matches the swagger specification", c.status), func() {
+					So(w.Body.String(), ShouldNotContainSubstring, "does not match the swagger specification")
+					So(w.Code, ShouldEqual, c.status)
+				})
+			})
+		}
+	})
+
+	Convey("Given a request that does not match the swagger specification", t, func() {
+		w := serve(mock.NewDataStore().Mock, contractCase{method: "GET", path: "/filters/12345678/dimensions?limit=ten"})
+
+		Convey("Then 400 bad request is returned, naming the schema violation", func() {
+			So(w.Code, ShouldEqual, http.StatusBadRequest)
+			So(decodeProblem(w.Body.String()).Code, ShouldEqual, "schema_violation")
+			So(problemDetail(w.Body.String()), ShouldStartWith, `parameter "limit" in query has an error`)
+		})
+	})
+}
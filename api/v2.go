@@ -0,0 +1,200 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/ONSdigital/dp-filter-api/filters"
+	"github.com/ONSdigital/dp-filter-api/middleware"
+	"github.com/ONSdigital/dp-filter-api/models"
+	"github.com/ONSdigital/dp-filter-api/mongo"
+	dprequest "github.com/ONSdigital/dp-net/request"
+	"github.com/ONSdigital/dp-net/v2/links"
+	"github.com/ONSdigital/log.go/v2/log"
+	"github.com/gorilla/mux"
+)
+
+// filter types served by the filter flex API, which only implements version 1 of the API
+const (
+	filterTypeFlexible     = "flexible"
+	filterTypeMultivariate = "multivariate"
+)
+
+// setupV2 mounts the routes of version 2 of the API under /v2, alongside the unversioned routes of version 1.
+// Both versions serve the same filter blueprints and outputs, so clients can migrate from one to the other gradually.
+// Version 2 only reads filter blueprints and outputs, with the dimensions and options selected in them, and only links to other version 2 resources.
+// Listing dimensions and options, and every change to filters, is only served by version 1.
+// Flexible and multivariate filters are proxied to the filter flex API, which only implements version 1, so /v2 is stripped from their path.
+func (api *FilterAPI) setupV2(assert *middleware.Assert) {
+	v2 := api.Router.PathPrefix("/v2").Subrouter()
+	v2.Handle("/filters/{filter_blueprint_id}", http.StripPrefix("/v2", assert.FilterType(http.HandlerFunc(api.getFilterBlueprintV2Handler)))).Methods("GET")
+	v2.Handle("/filter-outputs/{filter_output_id}", http.StripPrefix("/v2", assert.FilterOutputType(http.HandlerFunc(api.getFilterOutputV2Handler)))).Methods("GET")
+}
+
+// isFlexibleFilter returns true if the filter blueprint or output is served by the filter flex API
+func isFlexibleFilter(filter *models.Filter) bool {
+	return filter.Type == filterTypeFlexible || filter.Type == filterTypeMultivariate
+}
+
+func (api *FilterAPI) getFilterBlueprintV2Handler(w http.ResponseWriter, r *http.Request) {
+	filterBlueprintID := mux.Vars(r)["filter_blueprint_id"]
+	logData := log.Data{"filter_blueprint_id": filterBlueprintID, "api_version": 2}
+	ctx := r.Context()
+	log.Info(ctx, "getting filter blueprint", logData)
+
+	filter, err := api.getFilterBlueprint(ctx, filterBlueprintID, mongo.AnyETag)
+	if err != nil {
+		log.Error(ctx, "unable to get filter blueprint", err, logData)
+		setErrorCode(w, r, err)
+		return
+	}
+
+	// flexible filters are proxied to the filter flex API, unless dataset types are not asserted
+	if isFlexibleFilter(filter) {
+		logData["filter_type"] = filter.Type
+		log.Error(ctx, "filter blueprint is not served by version 2 of the API", filters.ErrUnsupportedFilterType, logData)
+		setErrorCode(w, r, filters.ErrUnsupportedFilterType)
+		return
+	}
+
+	// the newer version check changes the representation, so it is done before comparing its ETag
+	if api.enableVersionCheck {
+		api.setNewerVersionAvailable(ctx, filter)
+	}
+
+	eTag := representationETag(filter.ETag, getAcceptLanguage(r), filter.NewerVersionAvailable)
+	if api.writeNotModified(w, r, eTag, isPublished(filter), false) {
+		log.Info(ctx, "filter blueprint not modified", logData)
+		return
+	}
+
+	blueprint := models.NewFilterBlueprintV2(filter, api.host.String())
+
+	if api.enableURLRewriting {
+		if err := api.rewriteFilterBlueprintV2Links(r, blueprint); err != nil {
+			log.Error(ctx, "failed to rewrite filter blueprint links", err, logData)
+			setErrorCode(w, r, err)
+			return
+		}
+	}
+
+	b, err := json.Marshal(blueprint)
+	if err != nil {
+		log.Error(ctx, "failed to marshal filter blueprint into bytes", err, logData)
+		writeError(w, r, http.StatusInternalServerError, errInternal)
+		return
+	}
+
+	setJSONContentType(w)
+	setETag(w, eTag)
+	api.setCacheControl(w, isPublished(filter), false)
+	w.WriteHeader(http.StatusOK)
+	if _, err = w.Write(b); err != nil {
+		log.Error(ctx, "failed to write bytes for http response", err, logData)
+		return
+	}
+
+	log.Info(ctx, "got filter blueprint", logData)
+}
+
+func (api *FilterAPI) getFilterOutputV2Handler(w http.ResponseWriter, r *http.Request) {
+	filterOutputID := mux.Vars(r)["filter_output_id"]
+	logData := log.Data{"filter_output_id": filterOutputID, "api_version": 2}
+	ctx := r.Context()
+	log.Info(ctx, "getting filter output", logData)
+
+	hideS3Links := r.Header.Get(dprequest.DownloadServiceHeaderKey) != api.downloadServiceToken
+	output, err := api.getOutput(ctx, filterOutputID, hideS3Links)
+	if err != nil {
+		log.Error(ctx, "unable to get filter output", err, logData)
+		setErrorCode(w, r, err)
+		return
+	}
+
+	// flexible filters are proxied to the filter flex API, unless dataset types are not asserted
+	if isFlexibleFilter(output) {
+		logData["filter_type"] = output.Type
+		log.Error(ctx, "filter output is not served by version 2 of the API", filters.ErrUnsupportedFilterType, logData)
+		setErrorCode(w, r, filters.ErrUnsupportedFilterType)
+		return
+	}
+
+	filterOutput := models.NewFilterOutputV2(output, api.host.String())
+
+	if api.enableURLRewriting {
+		if err := api.rewriteFilterOutputV2Links(r, filterOutput); err != nil {
+			log.Error(ctx, "failed to rewrite filter output links", err, logData)
+			setErrorCode(w, r, err)
+			return
+		}
+	}
+
+	// as for version 1, the ETag is generated from the filter output as it is returned, including its download links
+	eTag, err := output.Hash(nil)
+	if err != nil {
+		log.Error(ctx, "failed to generate filter output ETag", err, logData)
+		writeError(w, r, http.StatusInternalServerError, errInternal)
+		return
+	}
+
+	// private download links must not be stored by shared caches, even for published data,
+	// and only completed filter outputs can be reused without revalidation
+	cacheable := isPublished(output) && hideS3Links
+	completed := output.State == models.CompletedState
+	if api.writeNotModified(w, r, eTag, cacheable, completed) {
+		log.Info(ctx, "filter output not modified", logData)
+		return
+	}
+
+	b, err := json.Marshal(filterOutput)
+	if err != nil {
+		log.Error(ctx, "failed to marshal filter output into bytes", err, logData)
+		writeError(w, r, http.StatusInternalServerError, errInternal)
+		return
+	}
+
+	setJSONContentType(w)
+	setETag(w, eTag)
+	api.setCacheControl(w, cacheable, completed)
+	w.WriteHeader(http.StatusOK)
+	if _, err = w.Write(b); err != nil {
+		log.Error(ctx, "failed to write bytes for http response", err, logData)
+		return
+	}
+
+	log.Info(ctx, "got filter output", logData)
+}
+
+// rewriteFilterBlueprintV2Links rewrites the links of a filter blueprint from the forwarded headers of a request
+func (api *FilterAPI) rewriteFilterBlueprintV2Links(r *http.Request, blueprint *models.FilterBlueprintV2) error {
+	if err := rewriteLinks(links.FromHeadersOrDefault(&r.Header, api.host), blueprint.Links.Self, blueprint.Links.FilterOutput); err != nil {
+		return err
+	}
+	return rewriteLinks(links.FromHeadersOrDefault(&r.Header, api.DatasetAPIURL), blueprint.Links.Version)
+}
+
+// rewriteFilterOutputV2Links rewrites the links of a filter output from the forwarded headers of a request, and its download links to the download service
+func (api *FilterAPI) rewriteFilterOutputV2Links(r *http.Request, filterOutput *models.FilterOutputV2) error {
+	if err := rewriteLinks(links.FromHeadersOrDefault(&r.Header, api.host), filterOutput.Links.Self, filterOutput.Links.FilterBlueprint); err != nil {
+		return err
+	}
+	if err := rewriteLinks(links.FromHeadersOrDefault(&r.Header, api.DatasetAPIURL), filterOutput.Links.Version); err != nil {
+		return err
+	}
+	return api.rewriteDownloadLinks(filterOutput.Downloads)
+}
+
+// rewriteLinks rewrites the provided links with a links builder, ignoring any link that is not set
+func rewriteLinks(builder *links.Builder, linkObjects ...*models.LinkObject) error {
+	for _, link := range linkObjects {
+		if link == nil || link.HRef == "" {
+			continue
+		}
+		newLink, err := builder.BuildLink(link.HRef)
+		if err != nil {
+			return err
+		}
+		link.HRef = newLink
+	}
+	return nil
+}
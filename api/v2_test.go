@@ -0,0 +1,220 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ONSdigital/dp-filter-api/api"
+	apimock "github.com/ONSdigital/dp-filter-api/api/mock"
+	"github.com/ONSdigital/dp-filter-api/mock"
+	"github.com/ONSdigital/dp-filter-api/models"
+	"github.com/gorilla/mux"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGetFilterBlueprintV2(t *testing.T) {
+	t.Parallel()
+
+	filterFlexAPIMock := &apimock.FilterFlexAPIMock{}
+
+	Convey("Given a published filter blueprint with three dimensions", t, func() {
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), &mock.DataStore{}, &mock.FilterJob{}, &mock.DatasetAPI{}, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+
+		Convey("When the filter blueprint is requested from version 2 of the API", func() {
+			r, err := http.NewRequest("GET", "http://localhost:22100/v2/filters/12345678", http.NoBody)
+			So(err, ShouldBeNil)
+			w := httptest.NewRecorder()
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the version 2 representation of the filter blueprint is returned, with its ETag", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Header().Get("ETag"), ShouldEqual, testETag)
+
+				var fields map[string]json.RawMessage
+				So(json.Unmarshal(w.Body.Bytes(), &fields), ShouldBeNil)
+				So(fields, ShouldNotContainKey, "instance_id")
+				So(fields, ShouldNotContainKey, "filter_id")
+				So(fields, ShouldNotContainKey, "downloads")
+				So(fields, ShouldNotContainKey, "events")
+
+				var blueprint models.FilterBlueprintV2
+				So(json.Unmarshal(w.Body.Bytes(), &blueprint), ShouldBeNil)
+				So(blueprint.Published, ShouldBeTrue)
+				So(blueprint.Dataset.ID, ShouldEqual, "123")
+				So(blueprint.Links.Self.HRef, ShouldEqual, host+"/v2/filters/")
+				So(blueprint.Dimensions, ShouldHaveLength, 3)
+				So(blueprint.Dimensions[1], ShouldResemble, models.DimensionV2{Name: "time", Options: []string{"2014", "2015"}})
+			})
+
+			Convey("Then none of its links leave version 2 of the API, other than to the dataset version", func() {
+				So(w.Body.String(), ShouldNotContainSubstring, host+"/filters/")
+			})
+		})
+
+		Convey("When the filter blueprint is requested from version 2 of the API with its current ETag", func() {
+			r, err := http.NewRequest("GET", "http://localhost:22100/v2/filters/12345678", http.NoBody)
+			So(err, ShouldBeNil)
+			r.Header.Set("If-None-Match", testETag)
+			w := httptest.NewRecorder()
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then 304 not modified is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusNotModified)
+				So(w.Body.String(), ShouldBeEmpty)
+			})
+		})
+	})
+
+	Convey("Given a filter blueprint that does not exist", t, func() {
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), mock.NewDataStore().NotFound().Mock, &mock.FilterJob{}, &mock.DatasetAPI{}, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+
+		Convey("When the filter blueprint is requested from version 2 of the API", func() {
+			r, err := http.NewRequest("GET", "http://localhost:22100/v2/filters/12345678", http.NoBody)
+			So(err, ShouldBeNil)
+			w := httptest.NewRecorder()
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then 404 not found is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusNotFound)
+				So(decodeProblem(w.Body.String()).Code, ShouldEqual, "filter_blueprint_not_found")
+			})
+		})
+	})
+
+	Convey("Given a flexible filter blueprint, and dataset types are asserted", t, func() {
+		config := cfg()
+		config.AssertDatasetType = true
+		datastoreMock := mock.NewDataStore().Mock
+		datastoreMock.GetFilterFunc = func(ctx context.Context, filterID, eTagSelector string) (*models.Filter, error) {
+			return &models.Filter{FilterID: filterID, Type: "flexible", Published: &models.Published}, nil
+		}
+		filterFlexAPIMock := &apimock.FilterFlexAPIMock{
+			ForwardRequestFunc: func(r *http.Request) (*http.Response, error) {
+				return &http.Response{Body: io.NopCloser(strings.NewReader("flex body")), StatusCode: http.StatusOK}, nil
+			},
+		}
+		filterAPI := api.Setup(config, mux.NewRouter(), datastoreMock, &mock.FilterJob{}, &mock.DatasetAPI{}, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+
+		Convey("When the filter blueprint is requested from version 2 of the API", func() {
+			r, err := http.NewRequest("GET", "http://localhost:22100/v2/filters/12345678?fields=dataset", http.NoBody)
+			So(err, ShouldBeNil)
+			w := httptest.NewRecorder()
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the request is proxied to version 1 of the filter flex API", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Body.String(), ShouldEqual, "flex body")
+				So(filterFlexAPIMock.ForwardRequestCalls(), ShouldHaveLength, 1)
+				So(filterFlexAPIMock.ForwardRequestCalls()[0].Request.URL.Path, ShouldEqual, "/filters/12345678")
+				So(filterFlexAPIMock.ForwardRequestCalls()[0].Request.URL.RawQuery, ShouldEqual, "fields=dataset")
+			})
+		})
+	})
+
+	Convey("Given a flexible filter blueprint, and dataset types are not asserted", t, func() {
+		datastoreMock := mock.NewDataStore().Mock
+		datastoreMock.GetFilterFunc = func(ctx context.Context, filterID, eTagSelector string) (*models.Filter, error) {
+			return &models.Filter{FilterID: filterID, Type: "flexible", Published: &models.Published}, nil
+		}
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), datastoreMock, &mock.FilterJob{}, &mock.DatasetAPI{}, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+
+		Convey("When the filter blueprint is requested from version 2 of the API", func() {
+			r, err := http.NewRequest("GET", "http://localhost:22100/v2/filters/12345678", http.NoBody)
+			So(err, ShouldBeNil)
+			w := httptest.NewRecorder()
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then 404 not found is returned, as flexible filters are only served by the filter flex API", func() {
+				So(w.Code, ShouldEqual, http.StatusNotFound)
+				So(decodeProblem(w.Body.String()).Code, ShouldEqual, "unsupported_filter_type")
+				So(filterFlexAPIMock.ForwardRequestCalls(), ShouldHaveLength, 0)
+			})
+		})
+	})
+}
+
+func TestGetFilterOutputV2(t *testing.T) {
+	t.Parallel()
+
+	filterFlexAPIMock := &apimock.FilterFlexAPIMock{}
+
+	Convey("Given a completed filter output", t, func() {
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), &mock.DataStore{}, &mock.FilterJob{}, &mock.DatasetAPI{}, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+
+		Convey("When the filter output is requested from version 2 of the API", func() {
+			r, err := http.NewRequest("GET", "http://localhost:22100/v2/filter-outputs/12345678", http.NoBody)
+			So(err, ShouldBeNil)
+			w := httptest.NewRecorder()
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the version 2 representation of the filter output is returned, without its S3 download links", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Header().Get("ETag"), ShouldNotBeEmpty)
+
+				var fields map[string]json.RawMessage
+				So(json.Unmarshal(w.Body.Bytes(), &fields), ShouldBeNil)
+				So(fields, ShouldNotContainKey, "instance_id")
+				So(fields, ShouldNotContainKey, "filter_id")
+
+				var output models.FilterOutputV2
+				So(json.Unmarshal(w.Body.Bytes(), &output), ShouldBeNil)
+				So(output.ID, ShouldEqual, "12345678")
+				So(output.State, ShouldEqual, "completed")
+				So(output.Published, ShouldBeTrue)
+				So(output.Links.Self.HRef, ShouldEqual, host+"/v2/filter-outputs/12345678")
+				So(output.Dimensions, ShouldResemble, []models.DimensionV2{{Name: "time"}})
+				So(output.Downloads.CSV, ShouldResemble, &models.DownloadItem{HRef: "/filter-outputs/87654321.csv", Size: "12mb"})
+			})
+		})
+	})
+
+	Convey("Given a filter output that does not exist", t, func() {
+		filterAPI := api.Setup(cfg(), mux.NewRouter(), mock.NewDataStore().NotFound().Mock, &mock.FilterJob{}, &mock.DatasetAPI{}, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+
+		Convey("When the filter output is requested from version 2 of the API", func() {
+			r, err := http.NewRequest("GET", "http://localhost:22100/v2/filter-outputs/12345678", http.NoBody)
+			So(err, ShouldBeNil)
+			w := httptest.NewRecorder()
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then 404 not found is returned", func() {
+				So(w.Code, ShouldEqual, http.StatusNotFound)
+				So(decodeProblem(w.Body.String()).Code, ShouldEqual, "filter_output_not_found")
+			})
+		})
+	})
+
+	Convey("Given a multivariate filter output, and dataset types are asserted", t, func() {
+		config := cfg()
+		config.AssertDatasetType = true
+		datastoreMock := mock.NewDataStore().Mock
+		datastoreMock.GetFilterOutputFunc = func(ctx context.Context, filterOutputID string) (*models.Filter, error) {
+			return &models.Filter{FilterID: filterOutputID, Type: "multivariate", Published: &models.Published}, nil
+		}
+		filterFlexAPIMock := &apimock.FilterFlexAPIMock{
+			ForwardRequestFunc: func(r *http.Request) (*http.Response, error) {
+				return &http.Response{Body: io.NopCloser(strings.NewReader("flex body")), StatusCode: http.StatusOK}, nil
+			},
+		}
+		filterAPI := api.Setup(config, mux.NewRouter(), datastoreMock, &mock.FilterJob{}, &mock.DatasetAPI{}, filterFlexAPIMock, hostURL, datasetAPIURL, parsedDownloadServiceURL, false)
+
+		Convey("When the filter output is requested from version 2 of the API", func() {
+			r, err := http.NewRequest("GET", "http://localhost:22100/v2/filter-outputs/12345678", http.NoBody)
+			So(err, ShouldBeNil)
+			w := httptest.NewRecorder()
+			filterAPI.Router.ServeHTTP(w, r)
+
+			Convey("Then the request is proxied to version 1 of the filter flex API", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Body.String(), ShouldEqual, "flex body")
+				So(filterFlexAPIMock.ForwardRequestCalls(), ShouldHaveLength, 1)
+				So(filterFlexAPIMock.ForwardRequestCalls()[0].Request.URL.Path, ShouldEqual, "/filter-outputs/12345678")
+			})
+		})
+	})
+}
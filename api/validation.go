@@ -0,0 +1,46 @@
+package api
+
+import (
+	"errors"
+
+	"github.com/ONSdigital/dp-filter-api/filters"
+	"github.com/ONSdigital/dp-filter-api/models"
+)
+
+// newValidationBadRequestErr keeps the validation errors of the dimensions as they are, so that they are rendered in the errors member of the problem,
+// and turns any other error into a BadRequestErr
+func newValidationBadRequestErr(err error) error {
+	var validationErrors models.ValidationErrors
+	if errors.As(err, &validationErrors) {
+		return validationErrors
+	}
+	return filters.NewBadRequestErr(err.Error())
+}
+
+// addValidationErrors adds the validation errors of a dimension to the provided ones, so that every invalid dimension is reported.
+// A BadRequestErr is added as an invalid selection of the dimension.
+// It returns false if the error is not a validation error, in which case it is not added.
+func addValidationErrors(validationErrors *models.ValidationErrors, dimension string, err error) bool {
+	var dimensionErrors models.ValidationErrors
+	if errors.As(err, &dimensionErrors) {
+		validationErrors.Errors = append(validationErrors.Errors, dimensionErrors.Errors...)
+		return true
+	}
+
+	var badRequestErr filters.BadRequestErr
+	if errors.As(err, &badRequestErr) {
+		validationErrors.Errors = append(validationErrors.Errors, models.NewInvalidSelectionError(dimension, err))
+		return true
+	}
+	return false
+}
+
+// hasValidationError returns true if any of the validation errors is for the provided dimension
+func hasValidationError(validationErrors models.ValidationErrors, dimension string) bool {
+	for _, validationError := range validationErrors.Errors {
+		if validationError.Dimension == dimension {
+			return true
+		}
+	}
+	return false
+}
@@ -11,36 +11,54 @@ import (
 
 // Config is the filing resource handler config
 type Config struct {
-	BindAddr                   string        `envconfig:"BIND_ADDR"`
-	Brokers                    []string      `envconfig:"KAFKA_ADDR"`
-	FilterOutputSubmittedTopic string        `envconfig:"FILTER_JOB_SUBMITTED_TOPIC"`
-	Host                       string        `envconfig:"HOST"`
-	KafkaMaxBytes              int           `envconfig:"KAFKA_MAX_BYTES"`
-	KafkaVersion               string        `envconfig:"KAFKA_VERSION"`
-	KafkaSecProtocol           string        `envconfig:"KAFKA_SEC_PROTO"`
-	KafkaSecCACerts            string        `envconfig:"KAFKA_SEC_CA_CERTS"`
-	KafkaSecClientCert         string        `envconfig:"KAFKA_SEC_CLIENT_CERT"`
-	KafkaSecClientKey          string        `envconfig:"KAFKA_SEC_CLIENT_KEY"             json:"-"`
-	KafkaSecSkipVerify         bool          `envconfig:"KAFKA_SEC_SKIP_VERIFY"`
-	ShutdownTimeout            time.Duration `envconfig:"SHUTDOWN_TIMEOUT"`
-	DatasetAPIURL              string        `envconfig:"DATASET_API_URL"`
-	HealthCheckInterval        time.Duration `envconfig:"HEALTHCHECK_INTERVAL"`
-	HealthCheckCriticalTimeout time.Duration `envconfig:"HEALTHCHECK_CRITICAL_TIMEOUT"`
-	OTBatchTimeout             time.Duration `encconfig:"OTEL_BATCH_TIMEOUT"`
-	OTServiceName              string        `envconfig:"OTEL_SERVICE_NAME"`
-	OTExporterOTLPEndpoint     string        `envconfig:"OTEL_EXPORTER_OTLP_ENDPOINT"`
-	ServiceAuthToken           string        `envconfig:"SERVICE_AUTH_TOKEN"               json:"-"`
-	ZebedeeURL                 string        `envconfig:"ZEBEDEE_URL"`
-	EnablePrivateEndpoints     bool          `envconfig:"ENABLE_PRIVATE_ENDPOINTS"`
-	EnableURLRewriting         bool          `envconfig:"ENABLE_URL_REWRITING"`
-	DownloadServiceURL         string        `envconfig:"DOWNLOAD_SERVICE_URL"`
-	DownloadServiceSecretKey   string        `envconfig:"DOWNLOAD_SERVICE_SECRET_KEY"      json:"-"`
-	MaxRequestOptions          int           `envconfig:"MAX_REQUEST_OPTIONS"`
-	MaxDatasetOptions          int           `envconfig:"MAX_DATASET_OPTIONS"`
-	BatchMaxWorkers            int           `envconfig:"BATCH_MAX_WORKERS"`
-	DefaultMaxLimit            int           `envconfig:"DEFAULT_MAXIMUM_LIMIT"`
-	AssertDatasetType          bool          `envconfig:"ASSERT_DATASET_TYPE"`
-	FilterFlexAPIURL           string        `envconfig:"FILTER_FLEX_API_URL"`
+	BindAddr                   string           `envconfig:"BIND_ADDR"`
+	Brokers                    []string         `envconfig:"KAFKA_ADDR"`
+	FilterOutputSubmittedTopic string           `envconfig:"FILTER_JOB_SUBMITTED_TOPIC"`
+	InstancePublishedTopic     string           `envconfig:"INSTANCE_PUBLISHED_TOPIC"`
+	InstancePublishedGroup     string           `envconfig:"INSTANCE_PUBLISHED_GROUP"`
+	FilterOutputPublishedTopic string           `envconfig:"FILTER_OUTPUT_PUBLISHED_TOPIC"`
+	Host                       string           `envconfig:"HOST"`
+	KafkaMaxBytes              int              `envconfig:"KAFKA_MAX_BYTES"`
+	KafkaVersion               string           `envconfig:"KAFKA_VERSION"`
+	KafkaSecProtocol           string           `envconfig:"KAFKA_SEC_PROTO"`
+	KafkaSecCACerts            string           `envconfig:"KAFKA_SEC_CA_CERTS"`
+	KafkaSecClientCert         string           `envconfig:"KAFKA_SEC_CLIENT_CERT"`
+	KafkaSecClientKey          string           `envconfig:"KAFKA_SEC_CLIENT_KEY"             json:"-"`
+	KafkaSecSkipVerify         bool             `envconfig:"KAFKA_SEC_SKIP_VERIFY"`
+	ShutdownTimeout            time.Duration    `envconfig:"SHUTDOWN_TIMEOUT"`
+	DatasetAPIURL              string           `envconfig:"DATASET_API_URL"`
+	HierarchyAPIURL            string           `envconfig:"HIERARCHY_API_URL"`
+	HealthCheckInterval        time.Duration    `envconfig:"HEALTHCHECK_INTERVAL"`
+	HealthCheckCriticalTimeout time.Duration    `envconfig:"HEALTHCHECK_CRITICAL_TIMEOUT"`
+	OTBatchTimeout             time.Duration    `encconfig:"OTEL_BATCH_TIMEOUT"`
+	OTServiceName              string           `envconfig:"OTEL_SERVICE_NAME"`
+	OTExporterOTLPEndpoint     string           `envconfig:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+	ServiceAuthToken           string           `envconfig:"SERVICE_AUTH_TOKEN"               json:"-"`
+	ZebedeeURL                 string           `envconfig:"ZEBEDEE_URL"`
+	EnablePrivateEndpoints     bool             `envconfig:"ENABLE_PRIVATE_ENDPOINTS"`
+	EnableURLRewriting         bool             `envconfig:"ENABLE_URL_REWRITING"`
+	DownloadServiceURL         string           `envconfig:"DOWNLOAD_SERVICE_URL"`
+	DownloadServiceSecretKey   string           `envconfig:"DOWNLOAD_SERVICE_SECRET_KEY"      json:"-"`
+	MaxRequestOptions          int              `envconfig:"MAX_REQUEST_OPTIONS"`
+	MaxDatasetOptions          int              `envconfig:"MAX_DATASET_OPTIONS"`
+	BatchMaxWorkers            int              `envconfig:"BATCH_MAX_WORKERS"`
+	DefaultMaxLimit            int              `envconfig:"DEFAULT_MAXIMUM_LIMIT"`
+	AssertDatasetType          bool             `envconfig:"ASSERT_DATASET_TYPE"`
+	FilterFlexAPIURL           string           `envconfig:"FILTER_FLEX_API_URL"`
+	EnableNewerVersionCheck    bool             `envconfig:"ENABLE_NEWER_VERSION_CHECK"`
+	EnablePublishEventConsumer bool             `envconfig:"ENABLE_PUBLISH_EVENT_CONSUMER"`
+	LabelCacheTTL              time.Duration    `envconfig:"LABEL_CACHE_TTL"`
+	LatestVersionCacheTTL      time.Duration    `envconfig:"LATEST_VERSION_CACHE_TTL"`
+	MaxXLSXRows                int              `envconfig:"MAX_XLSX_ROWS"`
+	MaxCells                   int64            `envconfig:"MAX_CELLS"`
+	MaxDatasetCells            map[string]int64 `envconfig:"MAX_DATASET_CELLS"`
+	PublishedCacheMaxAge       time.Duration    `envconfig:"PUBLISHED_CACHE_MAX_AGE"`
+	IdempotencyKeyTTL          time.Duration    `envconfig:"IDEMPOTENCY_KEY_TTL"`
+	IdempotencyKeyLease        time.Duration    `envconfig:"IDEMPOTENCY_KEY_LEASE"`
+	MaxEmbeddedOptions         int              `envconfig:"MAX_EMBEDDED_OPTIONS"`
+	FilterHistoryTTL           time.Duration    `envconfig:"FILTER_HISTORY_TTL"`
+	EnableSwaggerValidation    bool             `envconfig:"ENABLE_SWAGGER_VALIDATION"`
+	SwaggerSpecPath            string           `envconfig:"SWAGGER_SPEC_PATH"`
 	MongoConfig
 }
 
@@ -54,8 +72,10 @@ type MongoConfig struct {
 var cfg *Config
 
 const (
-	FiltersCollection = "FiltersCollection"
-	OutputsCollection = "OutputsCollection"
+	FiltersCollection       = "FiltersCollection"
+	OutputsCollection       = "OutputsCollection"
+	FilterHistoryCollection = "FilterHistoryCollection"
+	IdempotencyCollection   = "IdempotencyCollection"
 )
 
 // Get configures the application and returns the configuration
@@ -70,9 +90,13 @@ func Get() (*Config, error) {
 		Brokers:                    []string{"localhost:9092", "localhost:9093", "localhost:9094"},
 		KafkaVersion:               "1.0.2",
 		FilterOutputSubmittedTopic: "filter-job-submitted",
+		InstancePublishedTopic:     "instance-published",
+		InstancePublishedGroup:     "dp-filter-api",
+		FilterOutputPublishedTopic: "filter-output-published",
 		KafkaMaxBytes:              2000000,
 		ShutdownTimeout:            5 * time.Second,
 		DatasetAPIURL:              "http://localhost:22000",
+		HierarchyAPIURL:            "http://localhost:22600",
 		HealthCheckInterval:        30 * time.Second,
 		HealthCheckCriticalTimeout: 90 * time.Second,
 		OTBatchTimeout:             5 * time.Second,
@@ -89,13 +113,27 @@ func Get() (*Config, error) {
 		DownloadServiceSecretKey:   "QB0108EZ-825D-412C-9B1D-41EF7747F462",
 		AssertDatasetType:          false,
 		FilterFlexAPIURL:           "http://localhost:27100",
+		EnableNewerVersionCheck:    false,
+		EnablePublishEventConsumer: false,
+		LabelCacheTTL:              10 * time.Minute, // Time that dimension and option labels obtained from Dataset API are cached for
+		LatestVersionCacheTTL:      time.Minute,      // Time that the latest published version of a dataset edition is cached for, when checking for newer versions
+		MaxXLSXRows:                1048575,          // Maximum number of observation rows in an XLSX download, which is skipped for larger filters. One row of the sheet is used by the header
+		MaxCells:                   0,                // Maximum number of cells of a submitted filter, unless a maximum is configured for its dataset. Zero means no maximum
+		MaxDatasetCells:            map[string]int64{},
+		PublishedCacheMaxAge:       time.Minute,         // Time that completed filter outputs of published data can be cached for by clients and shared caches
+		IdempotencyKeyTTL:          24 * time.Hour,      // Time that the responses of requests made with an Idempotency-Key header are replayed for. Zero disables idempotency keys
+		IdempotencyKeyLease:        time.Minute,         // Time after which the key of a request still in progress can be taken over by a retry, as the request is assumed to have been abandoned
+		MaxEmbeddedOptions:         100,                 // Maximum number of options embedded for each dimension of a filter blueprint
+		FilterHistoryTTL:           30 * 24 * time.Hour, // Time that previous states of filter blueprints are kept for, so that they can be restored. Zero keeps them forever
+		EnableSwaggerValidation:    false,               // Whether requests are rejected when they do not match the swagger specification
+		SwaggerSpecPath:            "swagger.yaml",      // Path of the swagger specification that requests are validated against
 		MongoConfig: MongoConfig{
 			MongoDriverConfig: mongodriver.MongoDriverConfig{
 				ClusterEndpoint:               "localhost:27017",
 				Username:                      "",
 				Password:                      "",
 				Database:                      "filters",
-				Collections:                   map[string]string{FiltersCollection: "filters", OutputsCollection: "filterOutputs"},
+				Collections:                   map[string]string{FiltersCollection: "filters", OutputsCollection: "filterOutputs", FilterHistoryCollection: "filterHistory", IdempotencyCollection: "idempotencyKeys"},
 				ReplicaSet:                    "",
 				IsStrongReadConcernEnabled:    false,
 				IsWriteConcernMajorityEnabled: true,
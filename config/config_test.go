@@ -26,11 +26,14 @@ func TestSpec(t *testing.T) {
 				So(cfg.KafkaVersion, ShouldEqual, "1.0.2")
 				So(cfg.KafkaSecProtocol, ShouldEqual, "")
 				So(cfg.FilterOutputSubmittedTopic, ShouldEqual, "filter-job-submitted")
+				So(cfg.InstancePublishedTopic, ShouldEqual, "instance-published")
+				So(cfg.InstancePublishedGroup, ShouldEqual, "dp-filter-api")
+				So(cfg.FilterOutputPublishedTopic, ShouldEqual, "filter-output-published")
 				So(cfg.KafkaMaxBytes, ShouldEqual, 2000000)
 				So(cfg.ShutdownTimeout, ShouldEqual, 5*time.Second)
 				So(cfg.MongoConfig.ClusterEndpoint, ShouldEqual, "localhost:27017")
 				So(cfg.MongoConfig.Database, ShouldEqual, "filters")
-				So(cfg.MongoConfig.Collections, ShouldResemble, map[string]string{FiltersCollection: "filters", OutputsCollection: "filterOutputs"})
+				So(cfg.MongoConfig.Collections, ShouldResemble, map[string]string{FiltersCollection: "filters", OutputsCollection: "filterOutputs", FilterHistoryCollection: "filterHistory", IdempotencyCollection: "idempotencyKeys"})
 				So(cfg.MongoConfig.IsStrongReadConcernEnabled, ShouldEqual, false)
 				So(cfg.MongoConfig.IsWriteConcernMajorityEnabled, ShouldEqual, true)
 				So(cfg.MongoConfig.ConnectTimeout, ShouldEqual, 5*time.Second)
@@ -48,7 +51,22 @@ func TestSpec(t *testing.T) {
 				So(cfg.MaxDatasetOptions, ShouldEqual, 200)
 				So(cfg.DefaultMaxLimit, ShouldEqual, 1000)
 				So(cfg.FilterFlexAPIURL, ShouldEqual, "http://localhost:27100")
+				So(cfg.HierarchyAPIURL, ShouldEqual, "http://localhost:22600")
 				So(cfg.EnableURLRewriting, ShouldEqual, false)
+				So(cfg.EnableNewerVersionCheck, ShouldBeFalse)
+				So(cfg.EnablePublishEventConsumer, ShouldBeFalse)
+				So(cfg.LabelCacheTTL, ShouldEqual, 10*time.Minute)
+				So(cfg.LatestVersionCacheTTL, ShouldEqual, time.Minute)
+				So(cfg.MaxXLSXRows, ShouldEqual, 1048575)
+				So(cfg.MaxCells, ShouldEqual, 0)
+				So(cfg.MaxDatasetCells, ShouldBeEmpty)
+				So(cfg.PublishedCacheMaxAge, ShouldEqual, time.Minute)
+				So(cfg.IdempotencyKeyTTL, ShouldEqual, 24*time.Hour)
+				So(cfg.IdempotencyKeyLease, ShouldEqual, time.Minute)
+				So(cfg.MaxEmbeddedOptions, ShouldEqual, 100)
+				So(cfg.FilterHistoryTTL, ShouldEqual, 30*24*time.Hour)
+				So(cfg.EnableSwaggerValidation, ShouldBeFalse)
+				So(cfg.SwaggerSpecPath, ShouldEqual, "swagger.yaml")
 			})
 		})
 	})
@@ -6,21 +6,31 @@ import (
 )
 
 var (
-	ErrVersionNotFound          = errors.New("version not found")
-	ErrInvalidQueryParameter    = errors.New("invalid query parameter")
-	ErrFilterBlueprintNotFound  = errors.New("filter blueprint not found")
-	ErrFilterBlueprintConflict  = errors.New("conflict while updating filter blueprint")
-	ErrDimensionNotFound        = errors.New("dimension not found")
-	ErrDimensionsNotFound       = errors.New("dimensions not found")
-	ErrDimensionOptionNotFound  = errors.New("option not found")
-	ErrDimensionOptionsNotFound = errors.New("dimension options not found")
-	ErrFilterOutputNotFound     = errors.New("filter output not found")
-	ErrFilterOutputConflict     = errors.New("conflict while updating filter output")
-	ErrBadRequest               = errors.New("invalid request body")
-	ErrForbidden                = errors.New("forbidden")
-	ErrUnauthorised             = errors.New("unauthorised")
-	ErrInternalError            = errors.New("internal server error")
-	ErrNoIfMatchHeader          = errors.New("required If-Match header not provided")
+	ErrVersionNotFound           = errors.New("version not found")
+	ErrInvalidQueryParameter     = errors.New("invalid query parameter")
+	ErrFilterBlueprintNotFound   = errors.New("filter blueprint not found")
+	ErrFilterBlueprintConflict   = errors.New("conflict while updating filter blueprint")
+	ErrDimensionNotFound         = errors.New("dimension not found")
+	ErrDimensionsNotFound        = errors.New("dimensions not found")
+	ErrDimensionOptionNotFound   = errors.New("option not found")
+	ErrDimensionOptionsNotFound  = errors.New("dimension options not found")
+	ErrFilterOutputNotFound      = errors.New("filter output not found")
+	ErrFilterOutputConflict      = errors.New("conflict while updating filter output")
+	ErrBadRequest                = errors.New("invalid request body")
+	ErrForbidden                 = errors.New("forbidden")
+	ErrUnauthorised              = errors.New("unauthorised")
+	ErrInternalError             = errors.New("internal server error")
+	ErrNoIfMatchHeader           = errors.New("required If-Match header not provided")
+	ErrFilterSnapshotNotFound    = errors.New("no previous state of the filter blueprint found for the provided e_tag")
+	ErrLossyRebase               = errors.New("rebase would drop dimensions or options from the filter blueprint")
+	ErrIdempotencyRecordNotFound = errors.New("idempotency record not found")
+	ErrInvalidIdempotencyKey     = errors.New("invalid Idempotency-Key header")
+	ErrIdempotencyKeyReused      = errors.New("idempotency key has already been used for a different request")
+	ErrIdempotencyKeyInUse       = errors.New("a request with the same idempotency key is already in progress")
+	ErrInvalidCursor             = errors.New("invalid cursor")
+	ErrStaleCursor               = errors.New("cursor is stale as the filter blueprint has changed since it was issued")
+	ErrUnsupportedFilterType     = errors.New("filter type is not supported by this version of the API")
+	ErrSchemaViolation           = errors.New("request does not match the swagger specification")
 )
 
 func NewBadRequestErr(text string) error {
@@ -49,6 +59,19 @@ func (e ForbiddenErr) Error() string {
 	return e.s
 }
 
+func NewUnprocessableEntityErr(text string) error {
+	return UnprocessableEntityErr{text}
+}
+
+// UnprocessableEntityErr is returned when a valid request cannot be processed, explaining why
+type UnprocessableEntityErr struct {
+	s string
+}
+
+func (e UnprocessableEntityErr) Error() string {
+	return e.s
+}
+
 func GetErrorStatusCode(err error) int {
 	switch err {
 	case ErrFilterBlueprintNotFound:
@@ -61,10 +84,20 @@ func GetErrorStatusCode(err error) int {
 		return http.StatusBadRequest
 	case ErrBadRequest:
 		return http.StatusBadRequest
+	case ErrFilterSnapshotNotFound:
+		return http.StatusBadRequest
 	case ErrFilterBlueprintConflict:
 		return http.StatusConflict
 	case ErrFilterOutputConflict:
 		return http.StatusConflict
+	case ErrInvalidCursor:
+		return http.StatusBadRequest
+	case ErrStaleCursor:
+		return http.StatusConflict
+	case ErrUnsupportedFilterType:
+		return http.StatusNotFound
+	case ErrSchemaViolation:
+		return http.StatusBadRequest
 	case ErrInternalError:
 		return http.StatusInternalServerError
 
@@ -75,6 +108,8 @@ func GetErrorStatusCode(err error) int {
 			return http.StatusBadRequest
 		case ForbiddenErr:
 			return http.StatusForbidden
+		case UnprocessableEntityErr:
+			return http.StatusUnprocessableEntity
 		default:
 			return http.StatusInternalServerError
 		}
@@ -0,0 +1,153 @@
+package filters
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	dprequest "github.com/ONSdigital/dp-net/request"
+)
+
+// ProblemContentType is the media type of error responses, as defined by RFC 7807
+const ProblemContentType = "application/problem+json"
+
+// problemTypePrefix prefixes the code of an error to build the URI identifying its problem type
+const problemTypePrefix = "urn:dp-filter-api:problem:"
+
+// Problem holds the details of an error response, as defined by RFC 7807.
+// The machine-readable code of the error, the id of the request and any list of errors are extension members.
+type Problem struct {
+	Type      string      `json:"type"`
+	Title     string      `json:"title"`
+	Status    int         `json:"status"`
+	Detail    string      `json:"detail,omitempty"`
+	Instance  string      `json:"instance,omitempty"`
+	Code      string      `json:"code"`
+	RequestID string      `json:"request_id,omitempty"`
+	Errors    interface{} `json:"errors,omitempty"`
+}
+
+// problemCoder is implemented by errors that provide their own machine-readable code
+type problemCoder interface {
+	ProblemCode() string
+}
+
+// errorLister is implemented by errors holding a list of errors, which are rendered in the errors member of a problem
+type errorLister interface {
+	ErrorList() interface{}
+}
+
+// messager is implemented by errors whose message to the client differs from the error logged
+type messager interface {
+	Message() string
+}
+
+// errorCode pairs a sentinel error with its machine-readable code
+type errorCode struct {
+	err  error
+	code string
+}
+
+// errorCodes holds the machine-readable code of each sentinel error. They are checked in order,
+// so an error wrapping several sentinel errors is given the code of the first one listed.
+var errorCodes = []errorCode{
+	{ErrVersionNotFound, "version_not_found"},
+	{ErrInvalidQueryParameter, "invalid_query_parameter"},
+	{ErrFilterBlueprintNotFound, "filter_blueprint_not_found"},
+	{ErrFilterBlueprintConflict, "filter_blueprint_conflict"},
+	{ErrDimensionNotFound, "dimension_not_found"},
+	{ErrDimensionsNotFound, "dimensions_not_found"},
+	{ErrDimensionOptionNotFound, "option_not_found"},
+	{ErrDimensionOptionsNotFound, "dimension_options_not_found"},
+	{ErrFilterOutputNotFound, "filter_output_not_found"},
+	{ErrFilterOutputConflict, "filter_output_conflict"},
+	{ErrBadRequest, "invalid_request_body"},
+	{ErrForbidden, "forbidden"},
+	{ErrUnauthorised, "unauthorised"},
+	{ErrInternalError, "internal_error"},
+	{ErrNoIfMatchHeader, "if_match_header_required"},
+	{ErrFilterSnapshotNotFound, "filter_snapshot_not_found"},
+	{ErrLossyRebase, "lossy_rebase"},
+	{ErrInvalidIdempotencyKey, "invalid_idempotency_key"},
+	{ErrIdempotencyKeyReused, "idempotency_key_reused"},
+	{ErrIdempotencyKeyInUse, "idempotency_key_in_use"},
+	{ErrInvalidCursor, "invalid_cursor"},
+	{ErrStaleCursor, "stale_cursor"},
+	{ErrUnsupportedFilterType, "unsupported_filter_type"},
+	{ErrSchemaViolation, "schema_violation"},
+}
+
+// statusCodes holds the code of errors that have no code of their own, for each status
+var statusCodes = map[int]string{
+	http.StatusBadRequest:          "bad_request",
+	http.StatusUnauthorized:        "unauthorised",
+	http.StatusForbidden:           "forbidden",
+	http.StatusNotFound:            "not_found",
+	http.StatusConflict:            "conflict",
+	http.StatusUnprocessableEntity: "unprocessable_entity",
+	http.StatusInternalServerError: "internal_error",
+}
+
+// ErrorCode returns the machine-readable code of an error responded with the provided status.
+// The code of a sentinel error is used if the error is, or wraps, one of them, in the order of errorCodes.
+func ErrorCode(err error, status int) string {
+	var coder problemCoder
+	if errors.As(err, &coder) {
+		return coder.ProblemCode()
+	}
+
+	for _, c := range errorCodes {
+		if errors.Is(err, c.err) {
+			return c.code
+		}
+	}
+
+	if code, ok := statusCodes[status]; ok {
+		return code
+	}
+	return strings.ReplaceAll(strings.ToLower(http.StatusText(status)), " ", "_")
+}
+
+// NewProblem returns the problem details of an error responded with the provided status to a request
+func NewProblem(r *http.Request, status int, err error) *Problem {
+	code := ErrorCode(err, status)
+	problem := &Problem{
+		Type:      problemTypePrefix + code,
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    err.Error(),
+		Instance:  r.URL.Path,
+		Code:      code,
+		RequestID: dprequest.GetRequestId(r.Context()),
+	}
+
+	var m messager
+	if errors.As(err, &m) {
+		problem.Detail = m.Message()
+	}
+
+	var lister errorLister
+	if errors.As(err, &lister) {
+		problem.Errors = lister.ErrorList()
+	}
+
+	if problem.RequestID == "" {
+		problem.RequestID = r.Header.Get(dprequest.RequestHeaderKey)
+	}
+	return problem
+}
+
+// WriteProblem responds to a request with the problem details of an error, as an application/problem+json body
+func WriteProblem(w http.ResponseWriter, r *http.Request, status int, err error) {
+	b, marshalErr := json.Marshal(NewProblem(r, status, err))
+	if marshalErr != nil {
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	_, _ = w.Write(b)
+}
@@ -0,0 +1,105 @@
+package filters
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	dprequest "github.com/ONSdigital/dp-net/request"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type testValidationErr struct {
+	errs []string
+}
+
+func (e testValidationErr) Error() string          { return "validation failed" }
+func (e testValidationErr) ProblemCode() string    { return "invalid_test" }
+func (e testValidationErr) ErrorList() interface{} { return e.errs }
+
+type testMessageErr struct {
+	err error
+}
+
+func (e testMessageErr) Error() string   { return "internal details: " + e.err.Error() }
+func (e testMessageErr) Message() string { return "unable to fulfil request" }
+func (e testMessageErr) Unwrap() error   { return e.err }
+
+func TestErrorCode(t *testing.T) {
+	Convey("The code of a sentinel error is returned, even if it is wrapped", t, func() {
+		So(ErrorCode(ErrFilterBlueprintNotFound, http.StatusNotFound), ShouldEqual, "filter_blueprint_not_found")
+		So(ErrorCode(fmt.Errorf("get filter: %w", ErrVersionNotFound), http.StatusUnprocessableEntity), ShouldEqual, "version_not_found")
+	})
+
+	Convey("The code of the first sentinel error listed is always returned for an error wrapping several of them", t, func() {
+		err := errors.Join(ErrDimensionNotFound, ErrFilterBlueprintNotFound)
+		for i := 0; i < 20; i++ {
+			So(ErrorCode(err, http.StatusNotFound), ShouldEqual, "filter_blueprint_not_found")
+		}
+		So(ErrorCode(fmt.Errorf("%w: %w", ErrInternalError, ErrBadRequest), http.StatusBadRequest), ShouldEqual, "invalid_request_body")
+	})
+
+	Convey("The code provided by an error is returned", t, func() {
+		So(ErrorCode(testValidationErr{}, http.StatusBadRequest), ShouldEqual, "invalid_test")
+	})
+
+	Convey("The code of the status is returned for any other error", t, func() {
+		So(ErrorCode(NewBadRequestErr("bad option"), http.StatusBadRequest), ShouldEqual, "bad_request")
+		So(ErrorCode(errors.New("boom"), http.StatusInternalServerError), ShouldEqual, "internal_error")
+		So(ErrorCode(errors.New("too many"), http.StatusTooManyRequests), ShouldEqual, "too_many_requests")
+	})
+}
+
+func TestWriteProblem(t *testing.T) {
+	Convey("Given a request with a request id", t, func() {
+		r := httptest.NewRequest(http.MethodGet, "/filters/123?limit=1", http.NoBody)
+		r = r.WithContext(dprequest.WithRequestId(r.Context(), "request-1"))
+		w := httptest.NewRecorder()
+
+		Convey("When a sentinel error is written", func() {
+			WriteProblem(w, r, http.StatusNotFound, ErrFilterBlueprintNotFound)
+
+			Convey("Then the problem details are responded as application/problem+json", func() {
+				So(w.Code, ShouldEqual, http.StatusNotFound)
+				So(w.Header().Get("Content-Type"), ShouldEqual, ProblemContentType)
+
+				var problem Problem
+				So(json.Unmarshal(w.Body.Bytes(), &problem), ShouldBeNil)
+				So(problem, ShouldResemble, Problem{
+					Type:      "urn:dp-filter-api:problem:filter_blueprint_not_found",
+					Title:     "Not Found",
+					Status:    http.StatusNotFound,
+					Detail:    "filter blueprint not found",
+					Instance:  "/filters/123",
+					Code:      "filter_blueprint_not_found",
+					RequestID: "request-1",
+				})
+			})
+		})
+
+		Convey("When an error with a message and a list of errors is written", func() {
+			err := testMessageErr{err: testValidationErr{errs: []string{"a", "b"}}}
+			WriteProblem(w, r, http.StatusBadRequest, err)
+
+			Convey("Then the message is the detail and the errors are listed", func() {
+				problem := NewProblem(r, http.StatusBadRequest, err)
+				So(problem.Detail, ShouldEqual, "unable to fulfil request")
+				So(problem.Code, ShouldEqual, "invalid_test")
+				So(problem.Errors, ShouldResemble, []string{"a", "b"})
+				So(w.Body.String(), ShouldContainSubstring, `"errors":["a","b"]`)
+			})
+		})
+	})
+
+	Convey("Given a request with the request id only in its header", t, func() {
+		r := httptest.NewRequest(http.MethodGet, "/filters/123", http.NoBody)
+		r.Header.Set(dprequest.RequestHeaderKey, "request-2")
+
+		Convey("Then the request id of the problem is taken from the header", func() {
+			So(NewProblem(r, http.StatusInternalServerError, ErrInternalError).RequestID, ShouldEqual, "request-2")
+		})
+	})
+}
@@ -0,0 +1,159 @@
+package instancePublished
+
+import (
+	"context"
+	"time"
+
+	"github.com/ONSdigital/dp-filter-api/schema"
+	kafka "github.com/ONSdigital/dp-kafka/v2"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+//go:generate moq -out mock/store.go -pkg mock . Store
+
+// Retry intervals of the instance published events that fail to be handled, doubled after every failure up to the maximum
+var (
+	InitialRetryInterval = time.Second
+	MaxRetryInterval     = time.Minute
+)
+
+// Store defines the required methods from the filter store to publish the filters of an instance
+type Store interface {
+	PublishFilterBlueprints(ctx context.Context, instanceID string) (int, error)
+	PublishFilterOutputs(ctx context.Context, instanceID string) ([]string, error)
+}
+
+// Handler publishes the filter blueprints and outputs of a dataset instance when the instance is published
+type Handler struct {
+	Store Store
+	// Output receives a filter output published message for every filter output that is published
+	Output chan []byte
+}
+
+type instancePublished struct {
+	InstanceID string `avro:"instance_id"`
+	DatasetID  string `avro:"dataset_id"`
+	Edition    string `avro:"edition"`
+	Version    string `avro:"version"`
+}
+
+type filterOutputPublished struct {
+	FilterOutputID string `avro:"filter_output_id"`
+	InstanceID     string `avro:"instance_id"`
+	DatasetID      string `avro:"dataset_id"`
+	Edition        string `avro:"edition"`
+	Version        string `avro:"version"`
+}
+
+// NewHandler returns a handler for instance published events, using the provided store,
+// which notifies the published filter outputs on the provided output channel
+func NewHandler(store Store, output chan []byte) *Handler {
+	return &Handler{Store: store, Output: output}
+}
+
+// Handle unmarshals an instance published event, flips the published flag on every filter blueprint
+// and filter output created for that instance, then notifies every published filter output
+func (h *Handler) Handle(ctx context.Context, data []byte) error {
+	event, err := unmarshal(data)
+	if err != nil {
+		return err
+	}
+
+	logData := log.Data{"instance_id": event.InstanceID, "dataset_id": event.DatasetID, "edition": event.Edition, "version": event.Version}
+	if event.InstanceID == "" {
+		log.Info(ctx, "ignoring instance published event without an instance id", logData)
+		return nil
+	}
+
+	blueprints, err := h.Store.PublishFilterBlueprints(ctx, event.InstanceID)
+	logData["filter_blueprints_published"] = blueprints
+	if err != nil {
+		log.Error(ctx, "failed to publish filter blueprints", err, logData)
+		return err
+	}
+
+	outputs, err := h.Store.PublishFilterOutputs(ctx, event.InstanceID)
+	logData["filter_output_ids"] = outputs
+	if err != nil {
+		log.Error(ctx, "failed to publish filter outputs", err, logData)
+		return err
+	}
+
+	for _, output := range outputs {
+		bytes, err := schema.FilterOutputPublishedSchema.Marshal(&filterOutputPublished{
+			FilterOutputID: output,
+			InstanceID:     event.InstanceID,
+			DatasetID:      event.DatasetID,
+			Edition:        event.Edition,
+			Version:        event.Version,
+		})
+		if err != nil {
+			log.Error(ctx, "failed to marshal filter output published message", err, logData)
+			return err
+		}
+		h.Output <- bytes
+	}
+
+	log.Info(ctx, "published filters for instance", logData)
+	return nil
+}
+
+func unmarshal(data []byte) (*instancePublished, error) {
+	var event instancePublished
+	if err := schema.InstancePublishedSchema.Unmarshal(data, &event); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// Consume handles every message received by the provided consumer in a new go-routine, until the consumer is closed.
+// Messages are committed only once handled: a failure is retried with an increasing interval, so that no
+// filter is left unpublished, and the message is released without being committed if the consumer is closed meanwhile.
+// Messages that are not valid instance published events can never be handled, so they are logged and committed.
+func Consume(ctx context.Context, consumer kafka.IConsumerGroup, handler *Handler) {
+	go func() {
+		for {
+			select {
+			case message, ok := <-consumer.Channels().Upstream:
+				if !ok {
+					return
+				}
+				if !handleWithRetry(ctx, consumer, handler, message) {
+					message.Release()
+					return
+				}
+				message.CommitAndRelease()
+			case <-consumer.Channels().Closer:
+				return
+			}
+		}
+	}()
+}
+
+// handleWithRetry handles the provided message until it succeeds, returning false if the consumer is closed first
+func handleWithRetry(ctx context.Context, consumer kafka.IConsumerGroup, handler *Handler, message kafka.Message) bool {
+	if _, err := unmarshal(message.GetData()); err != nil {
+		log.Error(ctx, "ignoring message that is not a valid instance published event", err, log.Data{"offset": message.Offset()})
+		return true
+	}
+
+	retryInterval := InitialRetryInterval
+	for {
+		err := handler.Handle(ctx, message.GetData())
+		if err == nil {
+			return true
+		}
+		log.Error(ctx, "failed to handle instance published event, retrying", err, log.Data{"offset": message.Offset(), "retry_interval": retryInterval.String()})
+
+		select {
+		case <-time.After(retryInterval):
+		case <-consumer.Channels().Closer:
+			return false
+		}
+
+		retryInterval *= 2
+		if retryInterval > MaxRetryInterval {
+			retryInterval = MaxRetryInterval
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package instancePublished_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ONSdigital/dp-filter-api/instancePublished"
+	"github.com/ONSdigital/dp-filter-api/instancePublished/mock"
+	"github.com/ONSdigital/dp-filter-api/schema"
+	kafka "github.com/ONSdigital/dp-kafka/v2"
+	"github.com/ONSdigital/dp-kafka/v2/kafkatest"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func newStoreMock() *mock.StoreMock {
+	return &mock.StoreMock{
+		PublishFilterBlueprintsFunc: func(ctx context.Context, instanceID string) (int, error) {
+			return 1, nil
+		},
+		PublishFilterOutputsFunc: func(ctx context.Context, instanceID string) ([]string, error) {
+			return []string{"output-1"}, nil
+		},
+	}
+}
+
+type instancePublishedEvent struct {
+	InstanceID string `avro:"instance_id"`
+	DatasetID  string `avro:"dataset_id"`
+	Edition    string `avro:"edition"`
+	Version    string `avro:"version"`
+}
+
+type filterOutputPublishedEvent struct {
+	FilterOutputID string `avro:"filter_output_id"`
+	InstanceID     string `avro:"instance_id"`
+	DatasetID      string `avro:"dataset_id"`
+	Edition        string `avro:"edition"`
+	Version        string `avro:"version"`
+}
+
+func marshalEvent(event instancePublishedEvent) []byte {
+	bytes, err := schema.InstancePublishedSchema.Marshal(event)
+	So(err, ShouldBeNil)
+	return bytes
+}
+
+func TestHandle(t *testing.T) {
+	ctx := context.Background()
+
+	Convey("Given a handler with a working store", t, func() {
+		store := newStoreMock()
+		output := make(chan []byte, 1)
+		handler := instancePublished.NewHandler(store, output)
+
+		Convey("When an instance published event is handled", func() {
+			err := handler.Handle(ctx, marshalEvent(instancePublishedEvent{InstanceID: "instance-1", DatasetID: "cpih01", Edition: "time-series", Version: "1"}))
+
+			Convey("Then the filter blueprints and outputs for the instance are published", func() {
+				So(err, ShouldBeNil)
+				So(store.PublishFilterBlueprintsCalls(), ShouldHaveLength, 1)
+				So(store.PublishFilterBlueprintsCalls()[0].InstanceID, ShouldEqual, "instance-1")
+				So(store.PublishFilterOutputsCalls(), ShouldHaveLength, 1)
+				So(store.PublishFilterOutputsCalls()[0].InstanceID, ShouldEqual, "instance-1")
+			})
+
+			Convey("Then a filter output published message is sent for the published filter output", func() {
+				So(output, ShouldHaveLength, 1)
+				var message filterOutputPublishedEvent
+				So(schema.FilterOutputPublishedSchema.Unmarshal(<-output, &message), ShouldBeNil)
+				So(message, ShouldResemble, filterOutputPublishedEvent{FilterOutputID: "output-1", InstanceID: "instance-1", DatasetID: "cpih01", Edition: "time-series", Version: "1"})
+			})
+		})
+
+		Convey("When an event without an instance id is handled", func() {
+			err := handler.Handle(ctx, marshalEvent(instancePublishedEvent{DatasetID: "cpih01"}))
+
+			Convey("Then it is ignored", func() {
+				So(err, ShouldBeNil)
+				So(store.PublishFilterBlueprintsCalls(), ShouldHaveLength, 0)
+				So(store.PublishFilterOutputsCalls(), ShouldHaveLength, 0)
+			})
+		})
+	})
+
+	Convey("Given a handler with a store that fails to publish filter blueprints", t, func() {
+		errStore := errors.New("store error")
+		store := newStoreMock()
+		store.PublishFilterBlueprintsFunc = func(ctx context.Context, instanceID string) (int, error) {
+			return 0, errStore
+		}
+		handler := instancePublished.NewHandler(store, make(chan []byte, 1))
+
+		Convey("When an instance published event is handled", func() {
+			err := handler.Handle(ctx, marshalEvent(instancePublishedEvent{InstanceID: "instance-1"}))
+
+			Convey("Then the error is returned and filter outputs are not published", func() {
+				So(err, ShouldEqual, errStore)
+				So(store.PublishFilterOutputsCalls(), ShouldHaveLength, 0)
+			})
+		})
+	})
+}
+
+// waitFor returns true as soon as the provided condition is met, or false if it is not met within a second
+func waitFor(condition func() bool) bool {
+	deadline := time.Now().Add(time.Second)
+	for !condition() && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	return condition()
+}
+
+func TestConsume(t *testing.T) {
+	instancePublished.InitialRetryInterval = time.Millisecond
+	instancePublished.MaxRetryInterval = 2 * time.Millisecond
+
+	Convey("Given a consumer with an instance published message", t, func() {
+		channels := kafka.CreateConsumerGroupChannels(1)
+		consumer := &kafkatest.IConsumerGroupMock{
+			ChannelsFunc: func() *kafka.ConsumerGroupChannels { return channels },
+		}
+		store := newStoreMock()
+		message := kafkatest.NewMessage(marshalEvent(instancePublishedEvent{InstanceID: "instance-1"}), 0)
+
+		Convey("When the messages are consumed", func() {
+			instancePublished.Consume(context.Background(), consumer, instancePublished.NewHandler(store, make(chan []byte, 1)))
+			channels.Upstream <- message
+
+			Convey("Then the message is handled and committed", func() {
+				So(waitFor(message.IsCommitted), ShouldBeTrue)
+				So(store.PublishFilterBlueprintsCalls(), ShouldHaveLength, 1)
+				close(channels.Closer)
+			})
+		})
+
+		Convey("When the store fails to publish the filters a couple of times", func() {
+			var mu sync.Mutex
+			failures := 2
+			store.PublishFilterBlueprintsFunc = func(ctx context.Context, instanceID string) (int, error) {
+				mu.Lock()
+				defer mu.Unlock()
+				if failures > 0 {
+					failures--
+					return 0, errors.New("store error")
+				}
+				return 1, nil
+			}
+			instancePublished.Consume(context.Background(), consumer, instancePublished.NewHandler(store, make(chan []byte, 1)))
+			channels.Upstream <- message
+
+			Convey("Then the message is retried and committed only once handled", func() {
+				So(waitFor(message.IsCommitted), ShouldBeTrue)
+				So(store.PublishFilterBlueprintsCalls(), ShouldHaveLength, 3)
+				close(channels.Closer)
+			})
+		})
+
+		Convey("When the store keeps failing and the consumer is closed", func() {
+			store.PublishFilterBlueprintsFunc = func(ctx context.Context, instanceID string) (int, error) {
+				return 0, errors.New("store error")
+			}
+			instancePublished.Consume(context.Background(), consumer, instancePublished.NewHandler(store, make(chan []byte, 1)))
+			channels.Upstream <- message
+			So(waitFor(func() bool { return len(store.PublishFilterBlueprintsCalls()) > 0 }), ShouldBeTrue)
+			close(channels.Closer)
+
+			Convey("Then the message is released without being committed, so that it is consumed again", func() {
+				So(waitFor(func() bool {
+					select {
+					case <-message.UpstreamDone():
+						return true
+					default:
+						return false
+					}
+				}), ShouldBeTrue)
+				So(message.IsCommitted(), ShouldBeFalse)
+			})
+		})
+	})
+
+	Convey("Given a consumer with a message that is not an instance published event", t, func() {
+		channels := kafka.CreateConsumerGroupChannels(1)
+		consumer := &kafkatest.IConsumerGroupMock{
+			ChannelsFunc: func() *kafka.ConsumerGroupChannels { return channels },
+		}
+		store := newStoreMock()
+		message := kafkatest.NewMessage([]byte("not avro"), 0)
+
+		Convey("When the messages are consumed", func() {
+			instancePublished.Consume(context.Background(), consumer, instancePublished.NewHandler(store, make(chan []byte, 1)))
+			channels.Upstream <- message
+
+			Convey("Then the message is committed without being retried", func() {
+				So(waitFor(message.IsCommitted), ShouldBeTrue)
+				So(store.PublishFilterBlueprintsCalls(), ShouldHaveLength, 0)
+				close(channels.Closer)
+			})
+		})
+	})
+}
@@ -0,0 +1,132 @@
+// Code generated by moq; DO NOT EDIT.
+// github.com/matryer/moq
+
+package mock
+
+import (
+	"context"
+	"github.com/ONSdigital/dp-filter-api/instancePublished"
+	"sync"
+)
+
+// Ensure, that StoreMock does implement instancePublished.Store.
+// If this is not the case, regenerate this file with moq.
+var _ instancePublished.Store = &StoreMock{}
+
+// StoreMock is a mock implementation of instancePublished.Store.
+//
+//	func TestSomethingThatUsesStore(t *testing.T) {
+//
+//		// make and configure a mocked instancePublished.Store
+//		mockedStore := &StoreMock{
+//			PublishFilterBlueprintsFunc: func(ctx context.Context, instanceID string) (int, error) {
+//				panic("mock out the PublishFilterBlueprints method")
+//			},
+//			PublishFilterOutputsFunc: func(ctx context.Context, instanceID string) ([]string, error) {
+//				panic("mock out the PublishFilterOutputs method")
+//			},
+//		}
+//
+//		// use mockedStore in code that requires instancePublished.Store
+//		// and then make assertions.
+//
+//	}
+type StoreMock struct {
+	// PublishFilterBlueprintsFunc mocks the PublishFilterBlueprints method.
+	PublishFilterBlueprintsFunc func(ctx context.Context, instanceID string) (int, error)
+
+	// PublishFilterOutputsFunc mocks the PublishFilterOutputs method.
+	PublishFilterOutputsFunc func(ctx context.Context, instanceID string) ([]string, error)
+
+	// calls tracks calls to the methods.
+	calls struct {
+		// PublishFilterBlueprints holds details about calls to the PublishFilterBlueprints method.
+		PublishFilterBlueprints []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// InstanceID is the instanceID argument value.
+			InstanceID string
+		}
+		// PublishFilterOutputs holds details about calls to the PublishFilterOutputs method.
+		PublishFilterOutputs []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// InstanceID is the instanceID argument value.
+			InstanceID string
+		}
+	}
+	lockPublishFilterBlueprints sync.RWMutex
+	lockPublishFilterOutputs    sync.RWMutex
+}
+
+// PublishFilterBlueprints calls PublishFilterBlueprintsFunc.
+func (mock *StoreMock) PublishFilterBlueprints(ctx context.Context, instanceID string) (int, error) {
+	if mock.PublishFilterBlueprintsFunc == nil {
+		panic("StoreMock.PublishFilterBlueprintsFunc: method is nil but Store.PublishFilterBlueprints was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		InstanceID string
+	}{
+		Ctx:        ctx,
+		InstanceID: instanceID,
+	}
+	mock.lockPublishFilterBlueprints.Lock()
+	mock.calls.PublishFilterBlueprints = append(mock.calls.PublishFilterBlueprints, callInfo)
+	mock.lockPublishFilterBlueprints.Unlock()
+	return mock.PublishFilterBlueprintsFunc(ctx, instanceID)
+}
+
+// PublishFilterBlueprintsCalls gets all the calls that were made to PublishFilterBlueprints.
+// Check the length with:
+//
+//	len(mockedStore.PublishFilterBlueprintsCalls())
+func (mock *StoreMock) PublishFilterBlueprintsCalls() []struct {
+	Ctx        context.Context
+	InstanceID string
+} {
+	var calls []struct {
+		Ctx        context.Context
+		InstanceID string
+	}
+	mock.lockPublishFilterBlueprints.RLock()
+	calls = mock.calls.PublishFilterBlueprints
+	mock.lockPublishFilterBlueprints.RUnlock()
+	return calls
+}
+
+// PublishFilterOutputs calls PublishFilterOutputsFunc.
+func (mock *StoreMock) PublishFilterOutputs(ctx context.Context, instanceID string) ([]string, error) {
+	if mock.PublishFilterOutputsFunc == nil {
+		panic("StoreMock.PublishFilterOutputsFunc: method is nil but Store.PublishFilterOutputs was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		InstanceID string
+	}{
+		Ctx:        ctx,
+		InstanceID: instanceID,
+	}
+	mock.lockPublishFilterOutputs.Lock()
+	mock.calls.PublishFilterOutputs = append(mock.calls.PublishFilterOutputs, callInfo)
+	mock.lockPublishFilterOutputs.Unlock()
+	return mock.PublishFilterOutputsFunc(ctx, instanceID)
+}
+
+// PublishFilterOutputsCalls gets all the calls that were made to PublishFilterOutputs.
+// Check the length with:
+//
+//	len(mockedStore.PublishFilterOutputsCalls())
+func (mock *StoreMock) PublishFilterOutputsCalls() []struct {
+	Ctx        context.Context
+	InstanceID string
+} {
+	var calls []struct {
+		Ctx        context.Context
+		InstanceID string
+	}
+	mock.lockPublishFilterOutputs.RLock()
+	calls = mock.calls.PublishFilterOutputs
+	mock.lockPublishFilterOutputs.RUnlock()
+	return calls
+}
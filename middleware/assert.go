@@ -24,7 +24,6 @@ const (
 )
 
 type Assert struct {
-	respond       responder
 	DatasetAPI    datasetAPIClient
 	FilterFlexAPI filterFlexAPIClient
 	store         datastore
@@ -32,14 +31,13 @@ type Assert struct {
 	enabled       bool
 }
 
-func NewAssert(r responder, d datasetAPIClient, f filterFlexAPIClient, ds datastore, t string, e bool) *Assert {
+func NewAssert(d datasetAPIClient, f filterFlexAPIClient, ds datastore, t string, e bool) *Assert {
 	return &Assert{
 		svcAuthToken:  t,
 		DatasetAPI:    d,
 		FilterFlexAPI: f,
 		store:         ds,
 		enabled:       e,
-		respond:       r,
 	}
 }
 
@@ -58,7 +56,7 @@ func (a *Assert) FilterOutputType(next http.Handler) http.Handler {
 		ctx := r.Context()
 		filterOutput, err := a.store.GetFilterOutput(ctx, filterOutputID)
 		if err != nil {
-			a.respond.Error(ctx, w, filters.GetErrorStatusCode(err), er{
+			filters.WriteProblem(w, r, filters.GetErrorStatusCode(err), er{
 				err: errors.Wrap(err, "failed to get filter output"),
 				msg: "failed to get filter output",
 			})
@@ -67,7 +65,7 @@ func (a *Assert) FilterOutputType(next http.Handler) http.Handler {
 
 		if filterOutput.Type == flexible || filterOutput.Type == multivariate {
 			if err := a.doProxyRequest(w, r); err != nil {
-				a.respond.Error(ctx, w, filters.GetErrorStatusCode(err), er{
+				filters.WriteProblem(w, r, filters.GetErrorStatusCode(err), er{
 					err: errors.Wrap(err, "failed to do proxy request"),
 					msg: "unable to fulfil request",
 				})
@@ -98,7 +96,7 @@ func (a *Assert) DatasetType(next http.Handler) http.Handler {
 		}
 
 		if err := json.NewDecoder(rdr).Decode(&req); err != nil {
-			a.respond.Error(ctx, w, http.StatusBadRequest, er{
+			filters.WriteProblem(w, r, http.StatusBadRequest, er{
 				err: errors.Wrap(err, "failed to decode json"),
 				msg: fmt.Sprintf("badly formed request: %s", err),
 			})
@@ -108,7 +106,7 @@ func (a *Assert) DatasetType(next http.Handler) http.Handler {
 		// TODO: Probably better to create a new GetDatasetType function in dataset api-client
 		d, err := a.DatasetAPI.Get(ctx, "", a.svcAuthToken, "", req.Dataset.ID)
 		if err != nil {
-			a.respond.Error(ctx, w, filters.GetErrorStatusCode(err), er{
+			filters.WriteProblem(w, r, filters.GetErrorStatusCode(err), er{
 				err: errors.Wrap(err, "failed to get dataset"),
 				msg: "failed to get dataset",
 			})
@@ -119,14 +117,14 @@ func (a *Assert) DatasetType(next http.Handler) http.Handler {
 
 		if d.Type == cantabularFlexibleTable || d.Type == cantabularMultivariateTable {
 			if err := a.doProxyRequest(w, r); err != nil {
-				a.respond.Error(ctx, w, filters.GetErrorStatusCode(err), er{
+				filters.WriteProblem(w, r, filters.GetErrorStatusCode(err), er{
 					err: errors.Wrap(err, "failed to do proxy request"),
 					msg: "unable to fulfil request",
 				})
 			}
 			return
 		} else if d.Type == cantabularTable {
-			a.respond.Error(ctx, w, http.StatusBadRequest, errors.New("invalid dataset type"))
+			filters.WriteProblem(w, r, http.StatusBadRequest, errors.New("invalid dataset type"))
 			return
 		}
 
@@ -149,7 +147,7 @@ func (a *Assert) FilterType(next http.Handler) http.Handler {
 		// TODO: Better to add GetFilterType query to mongo?
 		f, err := a.store.GetFilter(ctx, filterID, anyEtagSelector)
 		if err != nil {
-			a.respond.Error(ctx, w, filters.GetErrorStatusCode(err), er{
+			filters.WriteProblem(w, r, filters.GetErrorStatusCode(err), er{
 				err: errors.Wrap(err, "failed to get filter"),
 				msg: "failed to get filter",
 			})
@@ -158,7 +156,7 @@ func (a *Assert) FilterType(next http.Handler) http.Handler {
 
 		if f.Type == flexible || f.Type == multivariate {
 			if err := a.doProxyRequest(w, r); err != nil {
-				a.respond.Error(ctx, w, filters.GetErrorStatusCode(err), er{
+				filters.WriteProblem(w, r, filters.GetErrorStatusCode(err), er{
 					err: errors.Wrap(err, "failed to do proxy request"),
 					msg: "unable to fulfil request",
 				})
@@ -15,7 +15,6 @@ import (
 	"github.com/ONSdigital/dp-filter-api/models"
 
 	"github.com/ONSdigital/dp-api-clients-go/v2/dataset"
-	"github.com/ONSdigital/dp-net/v2/responder"
 
 	"github.com/gorilla/mux"
 	. "github.com/smartystreets/goconvey/convey"
@@ -57,7 +56,6 @@ func TestAssertFilterType(t *testing.T) {
 
 			Convey("When an incoming request passes through the assert.FilterType middleware", func() {
 				assert := middleware.NewAssert(
-					responder.New(),
 					&mock.DatasetAPI{},
 					filterFlexAPIMock,
 					datastoreMock,
@@ -91,7 +89,6 @@ func TestAssertFilterType(t *testing.T) {
 
 			Convey("When an incoming request passes through a disabled assert.FilterType middleware", func() {
 				assert := middleware.NewAssert(
-					responder.New(),
 					&mock.DatasetAPI{},
 					filterFlexAPIMock,
 					datastoreMock,
@@ -138,7 +135,6 @@ func TestAssertFilterType(t *testing.T) {
 
 			Convey("When an incoming request passes through the assert.FilterType middleware", func() {
 				assert := middleware.NewAssert(
-					responder.New(),
 					&mock.DatasetAPI{},
 					filterFlexAPIMock,
 					datastoreMock,
@@ -172,7 +168,6 @@ func TestAssertFilterType(t *testing.T) {
 
 			Convey("When an incoming request passes through a disabled assert.FilterType middleware", func() {
 				assert := middleware.NewAssert(
-					responder.New(),
 					&mock.DatasetAPI{},
 					filterFlexAPIMock,
 					datastoreMock,
@@ -219,7 +214,6 @@ func TestAssertFilterType(t *testing.T) {
 
 			Convey("When an incoming request pases through the asert.FilterOutputType middleware", func() {
 				assert := middleware.NewAssert(
-					responder.New(),
 					&mock.DatasetAPI{},
 					filterFlexAPIMock,
 					datastoreMock,
@@ -269,7 +263,6 @@ func TestAssertFilterType(t *testing.T) {
 
 			Convey("When an incoming request pases through the asert.FilterOutputType middleware", func() {
 				assert := middleware.NewAssert(
-					responder.New(),
 					&mock.DatasetAPI{},
 					filterFlexAPIMock,
 					datastoreMock,
@@ -318,7 +311,6 @@ func TestAssertFilterType(t *testing.T) {
 
 			Convey("When an incoming request passes through the assert.FilterType middleware", func() {
 				assert := middleware.NewAssert(
-					responder.New(),
 					&mock.DatasetAPI{},
 					filterFlexAPIMock,
 					datastoreMock,
@@ -380,7 +372,6 @@ func TestAssertDatasetType(t *testing.T) {
 
 			Convey("When an incoming request passes through the assert.FilterType middleware", func() {
 				assert := middleware.NewAssert(
-					responder.New(),
 					datasetAPIMock,
 					filterFlexAPIMock,
 					&mock.DataStore{},
@@ -413,7 +404,6 @@ func TestAssertDatasetType(t *testing.T) {
 
 			Convey("When an incoming request passes through a disabled assert.FilterType middleware", func() {
 				assert := middleware.NewAssert(
-					responder.New(),
 					datasetAPIMock,
 					filterFlexAPIMock,
 					&mock.DataStore{},
@@ -456,7 +446,6 @@ func TestAssertDatasetType(t *testing.T) {
 
 			Convey("When an incoming request passes through the assert.FilterType middleware", func() {
 				assert := middleware.NewAssert(
-					responder.New(),
 					datasetAPIMock,
 					filterFlexAPIMock,
 					&mock.DataStore{},
@@ -499,7 +488,6 @@ func TestAssertDatasetType(t *testing.T) {
 
 			Convey("When an incoming request passes through the assert.FilterType middleware", func() {
 				assert := middleware.NewAssert(
-					responder.New(),
 					datasetAPIMock,
 					filterFlexAPIMock,
 					&mock.DataStore{},
@@ -520,11 +508,12 @@ func TestAssertDatasetType(t *testing.T) {
 				f := assert.DatasetType(next)
 				f.ServeHTTP(w, r)
 
-				Convey("The response should be an error with status code 400", func() {
-					expectedBody := `{"errors":["invalid dataset type"]}`
+				Convey("The response should be a problem with status code 400", func() {
+					expectedBody := `{"type":"urn:dp-filter-api:problem:bad_request","title":"Bad Request","status":400,"detail":"invalid dataset type","instance":"/test","code":"bad_request"}`
 					So(len(datasetAPIMock.GetCalls()), ShouldEqual, 1)
 					So(len(filterFlexAPIMock.ForwardRequestCalls()), ShouldEqual, 0)
 					So(w.Code, ShouldEqual, http.StatusBadRequest)
+					So(w.Header().Get("Content-Type"), ShouldEqual, "application/problem+json")
 					So(w.Body.String(), ShouldResemble, expectedBody)
 				})
 			})
@@ -541,7 +530,6 @@ func TestAssertDatasetType(t *testing.T) {
 
 			Convey("When an incoming request passes through the assert.FilterType middleware", func() {
 				assert := middleware.NewAssert(
-					responder.New(),
 					datasetAPIMock,
 					filterFlexAPIMock,
 					&mock.DataStore{},
@@ -19,6 +19,15 @@ func (e er) Error() string {
 	return e.err.Error()
 }
 
+// Message returns the message of the error to respond with, which is rendered
+// as the detail of the problem instead of the wrapped error
+func (e er) Message() string {
+	if e.msg == "" {
+		return e.Error()
+	}
+	return e.msg
+}
+
 // Unwrap implements the standard library Go unwrapper interface
 func (e er) Unwrap() error {
 	return e.err
@@ -21,8 +21,3 @@ type datastore interface {
 	GetFilter(ctx context.Context, filterID, eTagSelector string) (*models.Filter, error)
 	GetFilterOutput(ctx context.Context, filterID string) (*models.Filter, error)
 }
-
-type responder interface {
-	JSON(context.Context, http.ResponseWriter, int, interface{})
-	Error(context.Context, http.ResponseWriter, int, error)
-}
@@ -0,0 +1,208 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/ONSdigital/dp-filter-api/filters"
+	"github.com/ONSdigital/log.go/v2/log"
+
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/invopop/yaml"
+	"github.com/pkg/errors"
+)
+
+// SwaggerValidator validates requests, and optionally responses, against the swagger specification of the API.
+// Requests to routes that are not described by the specification are not validated.
+type SwaggerValidator struct {
+	router            routers.Router
+	options           *openapi3filter.Options
+	validateResponses bool
+}
+
+// NewSwaggerValidator loads the swagger specification at the provided path to validate requests against it.
+// Responses are also validated if validateResponses is true, which is intended for tests, as responses have to be buffered.
+func NewSwaggerValidator(ctx context.Context, specPath string, validateResponses bool) (*SwaggerValidator, error) {
+	b, err := os.ReadFile(specPath)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read swagger specification")
+	}
+
+	var spec openapi2.T
+	if err := yaml.Unmarshal(b, &spec); err != nil {
+		return nil, errors.Wrap(err, "failed to parse swagger specification")
+	}
+
+	// operations that do not declare the media types they produce produce those of the specification
+	for _, pathItem := range spec.Paths {
+		for _, operation := range pathItem.Operations() {
+			if len(operation.Produces) == 0 {
+				operation.Produces = spec.Produces
+			}
+		}
+	}
+
+	doc, err := openapi2conv.ToV3(&spec)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to convert swagger specification")
+	}
+
+	// the base path of the specification is removed by the API router before requests reach this service
+	doc.Servers = nil
+	if err := doc.Validate(ctx); err != nil {
+		return nil, errors.Wrap(err, "invalid swagger specification")
+	}
+
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create swagger specification router")
+	}
+
+	options := &openapi3filter.Options{
+		// authentication is checked by the identity middleware and the handlers
+		AuthenticationFunc:    openapi3filter.NoopAuthenticationFunc,
+		IncludeResponseStatus: true,
+	}
+	options.WithCustomSchemaErrorFunc(schemaErrorMessage)
+
+	return &SwaggerValidator{
+		router:            router,
+		options:           options,
+		validateResponses: validateResponses,
+	}, nil
+}
+
+// setDefaultContentType sets the Content-Type of a request with a body that does not provide one to the media type consumed by its operation,
+// preferring JSON, as the API reads request bodies as JSON regardless, and some clients do not set the header
+func setDefaultContentType(r *http.Request, route *routers.Route) {
+	if r.Header.Get("Content-Type") != "" || r.Body == nil || r.Body == http.NoBody {
+		return
+	}
+	if route.Operation == nil || route.Operation.RequestBody == nil || route.Operation.RequestBody.Value == nil {
+		return
+	}
+
+	content := route.Operation.RequestBody.Value.Content
+	if content.Get("application/json") != nil {
+		r.Header.Set("Content-Type", "application/json")
+		return
+	}
+	if mediaTypes := slices.Sorted(maps.Keys(content)); len(mediaTypes) > 0 {
+		r.Header.Set("Content-Type", mediaTypes[0])
+	}
+}
+
+// Validate is a middleware that rejects requests that do not match the swagger specification with 400 bad request.
+// If responses are validated, a response that does not match the specification is replaced with 500 internal server error.
+func (v *SwaggerValidator) Validate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		route, pathParams, err := v.router.FindRoute(r)
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		setDefaultContentType(r, route)
+
+		requestInput := &openapi3filter.RequestValidationInput{
+			Request:    r,
+			PathParams: pathParams,
+			Route:      route,
+			Options:    v.options,
+		}
+		if err := openapi3filter.ValidateRequest(ctx, requestInput); err != nil {
+			logData := log.Data{"method": r.Method, "path": r.URL.Path}
+			log.Error(ctx, "request does not match the swagger specification", err, logData)
+			filters.WriteProblem(w, r, http.StatusBadRequest, er{
+				err:    errors.Wrap(filters.ErrSchemaViolation, err.Error()),
+				msg:    err.Error(),
+				status: http.StatusBadRequest,
+				data:   logData,
+			})
+			return
+		}
+
+		if !v.validateResponses {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rec := &responseRecorder{header: make(http.Header), status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		responseInput := &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: requestInput,
+			Status:                 rec.status,
+			Header:                 rec.header,
+			Body:                   io.NopCloser(bytes.NewReader(rec.body.Bytes())),
+			Options:                v.options,
+		}
+		if err := openapi3filter.ValidateResponse(ctx, responseInput); err != nil {
+			logData := log.Data{"method": r.Method, "path": r.URL.Path, "status": rec.status, "body": rec.body.String()}
+			log.Error(ctx, "response does not match the swagger specification", err, logData)
+			filters.WriteProblem(w, r, http.StatusInternalServerError, er{
+				err:    errors.Wrap(err, "response does not match the swagger specification"),
+				msg:    fmt.Sprintf("%d response does not match the swagger specification: %s", rec.status, err.Error()),
+				status: http.StatusInternalServerError,
+				data:   logData,
+			})
+			return
+		}
+
+		for key, values := range rec.header {
+			w.Header()[key] = values
+		}
+		w.WriteHeader(rec.status)
+		if _, err := w.Write(rec.body.Bytes()); err != nil {
+			log.Error(ctx, "failed to write bytes for http response", err)
+		}
+	})
+}
+
+// schemaErrorMessage names the value that violates a schema by its JSON pointer, without dumping the schema and the value.
+// The error of a value that violates a subschema, such as one of an allOf, is kept as its cause.
+func schemaErrorMessage(err *openapi3.SchemaError) string {
+	message := err.Reason
+	if err.Origin != nil {
+		message += ": " + err.Origin.Error()
+	}
+
+	pointer := err.JSONPointer()
+	if len(pointer) == 0 || err.Origin != nil {
+		return message
+	}
+	return fmt.Sprintf("/%s: %s", strings.Join(pointer, "/"), message)
+}
+
+// responseRecorder buffers a response, so that it can be validated before it is written
+type responseRecorder struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (rec *responseRecorder) Header() http.Header {
+	return rec.header
+}
+
+func (rec *responseRecorder) WriteHeader(status int) {
+	rec.status = status
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	return rec.body.Write(b)
+}
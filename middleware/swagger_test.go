@@ -0,0 +1,182 @@
+package middleware_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ONSdigital/dp-filter-api/filters"
+	"github.com/ONSdigital/dp-filter-api/middleware"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const swaggerSpecPath = "../swagger.yaml"
+
+func TestNewSwaggerValidator(t *testing.T) {
+	Convey("When a swagger validator is created from the swagger specification of the API", t, func() {
+		validator, err := middleware.NewSwaggerValidator(context.Background(), swaggerSpecPath, false)
+
+		Convey("Then the specification is loaded without error", func() {
+			So(err, ShouldBeNil)
+			So(validator, ShouldNotBeNil)
+		})
+	})
+
+	Convey("When a swagger validator is created from a specification that does not exist", t, func() {
+		validator, err := middleware.NewSwaggerValidator(context.Background(), "missing.yaml", false)
+
+		Convey("Then an error is returned", func() {
+			So(err, ShouldNotBeNil)
+			So(validator, ShouldBeNil)
+		})
+	})
+}
+
+func TestSwaggerValidatorValidate(t *testing.T) {
+	Convey("Given a handler that responds with a valid list of dimensions", t, func() {
+		handlerCalled := false
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handlerCalled = true
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"items":[],"count":0,"offset":0,"limit":20,"total_count":0}`))
+		})
+
+		Convey("And a swagger validator that only validates requests", func() {
+			validator, err := middleware.NewSwaggerValidator(context.Background(), swaggerSpecPath, false)
+			So(err, ShouldBeNil)
+
+			Convey("When a request that matches the specification is made", func() {
+				w := httptest.NewRecorder()
+				r, err := http.NewRequest("GET", "http://localhost:22100/filters/12345678/dimensions?limit=10", http.NoBody)
+				So(err, ShouldBeNil)
+				validator.Validate(next).ServeHTTP(w, r)
+
+				Convey("Then the request is handled", func() {
+					So(handlerCalled, ShouldBeTrue)
+					So(w.Code, ShouldEqual, http.StatusOK)
+				})
+			})
+
+			Convey("When a request with a query parameter that does not match the specification is made", func() {
+				w := httptest.NewRecorder()
+				r, err := http.NewRequest("GET", "http://localhost:22100/filters/12345678/dimensions?limit=ten", http.NoBody)
+				So(err, ShouldBeNil)
+				validator.Validate(next).ServeHTTP(w, r)
+
+				Convey("Then 400 bad request is returned, naming the schema violation, without handling the request", func() {
+					So(handlerCalled, ShouldBeFalse)
+					So(w.Code, ShouldEqual, http.StatusBadRequest)
+					So(w.Header().Get("Content-Type"), ShouldEqual, filters.ProblemContentType)
+
+					var problem filters.Problem
+					So(json.Unmarshal(w.Body.Bytes(), &problem), ShouldBeNil)
+					So(problem.Code, ShouldEqual, "schema_violation")
+					So(problem.Detail, ShouldStartWith, `parameter "limit" in query has an error`)
+				})
+			})
+
+			Convey("When a request with a body that does not match the specification is made", func() {
+				w := httptest.NewRecorder()
+				r, err := http.NewRequest("POST", "http://localhost:22100/filters", strings.NewReader(`{"dataset":{"id":123}}`))
+				So(err, ShouldBeNil)
+				r.Header.Set("Content-Type", "application/json")
+				validator.Validate(next).ServeHTTP(w, r)
+
+				Convey("Then 400 bad request is returned, naming the value that violates the schema", func() {
+					So(handlerCalled, ShouldBeFalse)
+					So(w.Code, ShouldEqual, http.StatusBadRequest)
+
+					var problem filters.Problem
+					So(json.Unmarshal(w.Body.Bytes(), &problem), ShouldBeNil)
+					So(problem.Detail, ShouldContainSubstring, "/dataset/id: value must be a string")
+				})
+			})
+
+			Convey("When a request with a body that matches the specification is made without a Content-Type header", func() {
+				w := httptest.NewRecorder()
+				r, err := http.NewRequest("POST", "http://localhost:22100/filters", strings.NewReader(`{"dataset":{"id":"123","edition":"2017","version":1}}`))
+				So(err, ShouldBeNil)
+				validator.Validate(next).ServeHTTP(w, r)
+
+				Convey("Then the body is validated as the media type consumed by the operation, and the request is handled", func() {
+					So(w.Code, ShouldEqual, http.StatusOK)
+					So(handlerCalled, ShouldBeTrue)
+				})
+			})
+
+			Convey("When a request to a route that is not described by the specification is made", func() {
+				w := httptest.NewRecorder()
+				r, err := http.NewRequest("GET", "http://localhost:22100/v2/filters/12345678?limit=ten", http.NoBody)
+				So(err, ShouldBeNil)
+				validator.Validate(next).ServeHTTP(w, r)
+
+				Convey("Then the request is handled without being validated", func() {
+					So(handlerCalled, ShouldBeTrue)
+					So(w.Code, ShouldEqual, http.StatusOK)
+				})
+			})
+		})
+
+		Convey("And a swagger validator that also validates responses", func() {
+			validator, err := middleware.NewSwaggerValidator(context.Background(), swaggerSpecPath, true)
+			So(err, ShouldBeNil)
+
+			Convey("When a request that matches the specification is made", func() {
+				w := httptest.NewRecorder()
+				r, err := http.NewRequest("GET", "http://localhost:22100/filters/12345678/dimensions", http.NoBody)
+				So(err, ShouldBeNil)
+				validator.Validate(next).ServeHTTP(w, r)
+
+				Convey("Then the response of the handler is written unchanged", func() {
+					So(w.Code, ShouldEqual, http.StatusOK)
+					So(w.Header().Get("Content-Type"), ShouldEqual, "application/json")
+					So(w.Body.String(), ShouldEqual, `{"items":[],"count":0,"offset":0,"limit":20,"total_count":0}`)
+				})
+			})
+		})
+	})
+
+	Convey("Given a handler that responds with a list of dimensions that does not match the specification", t, func() {
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"items":"age"}`))
+		})
+
+		r, err := http.NewRequest("GET", "http://localhost:22100/filters/12345678/dimensions", http.NoBody)
+		So(err, ShouldBeNil)
+
+		Convey("When the response is validated", func() {
+			validator, err := middleware.NewSwaggerValidator(context.Background(), swaggerSpecPath, true)
+			So(err, ShouldBeNil)
+			w := httptest.NewRecorder()
+			validator.Validate(next).ServeHTTP(w, r)
+
+			Convey("Then 500 internal server error is returned, naming the schema violation", func() {
+				So(w.Code, ShouldEqual, http.StatusInternalServerError)
+
+				var problem filters.Problem
+				So(json.Unmarshal(w.Body.Bytes(), &problem), ShouldBeNil)
+				So(problem.Detail, ShouldStartWith, "200 response does not match the swagger specification")
+				So(problem.Detail, ShouldContainSubstring, "/items: value must be an array")
+			})
+		})
+
+		Convey("When only the request is validated", func() {
+			validator, err := middleware.NewSwaggerValidator(context.Background(), swaggerSpecPath, false)
+			So(err, ShouldBeNil)
+			w := httptest.NewRecorder()
+			validator.Validate(next).ServeHTTP(w, r)
+
+			Convey("Then the response of the handler is written unchanged", func() {
+				So(w.Code, ShouldEqual, http.StatusOK)
+				So(w.Body.String(), ShouldEqual, `{"items":"age"}`)
+			})
+		})
+	})
+}
@@ -29,6 +29,7 @@ func NewDatasetAPI() *DatasetAPI {
 		Cfg: DatasetAPIConfig{},
 	}
 	ds.Mock = &apimock.DatasetAPIMock{
+		GetEditionFunc:             ds.GetEdition,
 		GetVersionFunc:             ds.GetVersion,
 		GetVersionDimensionsFunc:   ds.GetVersionDimensions,
 		GetOptionsBatchProcessFunc: ds.GetOptionsBatchProcess,
@@ -58,6 +59,26 @@ func (ds *DatasetAPI) Get(ctx context.Context, userToken, svcToken, collectionID
 	return dataset.DatasetDetails{}, nil
 }
 
+// GetEdition represents the mocked version of getting an edition document from dataset API
+func (ds *DatasetAPI) GetEdition(ctx context.Context, userAuthToken, serviceAuthToken, collectionID, datasetID, edition string) (m dataset.Edition, err error) {
+	if ds.Cfg.InternalServerError {
+		return m, errorInternalServer
+	}
+
+	return dataset.Edition{
+		Edition: edition,
+		Links: dataset.Links{
+			Dataset: dataset.Link{
+				ID: datasetID,
+			},
+			LatestVersion: dataset.Link{
+				ID: "1",
+			},
+		},
+		State: "published",
+	}, nil
+}
+
 // GetVersion represents the mocked version of getting an version document from dataset API
 func (ds *DatasetAPI) GetVersion(ctx context.Context, userAuthToken, serviceAuthToken, downloadServiceAuthToken, collectionID, datasetID, edition, version string) (m dataset.Version, err error) {
 	if ds.Cfg.InternalServerError {
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
+	"time"
 
 	apimock "github.com/ONSdigital/dp-filter-api/api/mock"
 	"github.com/ONSdigital/dp-filter-api/filters"
@@ -35,15 +37,19 @@ type DataStoreConfig struct {
 	BadRequest             bool
 	ConflictRequest        bool
 	AgeDimension           bool
+	SnapshotNotFound       bool
 }
 
 // DataStore holds the list of possible error flats along with the mocked datastore.
 // This struct can be used directly as a mock, as it implements the required methods,
 // or you can use the internal 'moq' Mock if you want ot validate calls, parameters etc.
 type DataStore struct {
-	Cfg             DataStoreConfig
-	Mock            *apimock.DataStoreMock
-	eTagUpdateCount int
+	Cfg                DataStoreConfig
+	Mock               *apimock.DataStoreMock
+	eTagUpdateCount    int
+	instancePublished  bool
+	idempotencyMutex   sync.Mutex
+	idempotencyRecords map[string]*models.IdempotencyRecord
 }
 
 // NewDataStore creates a new datastore mock with an empty config
@@ -61,12 +67,20 @@ func NewDataStore() *DataStore {
 		GetFilterFunc:                    ds.GetFilter,
 		GetFilterDimensionFunc:           ds.GetFilterDimension,
 		GetFilterOutputFunc:              ds.GetFilterOutput,
+		UpdateFilterDimensionFunc:        ds.UpdateFilterDimension,
 		RemoveFilterDimensionFunc:        ds.RemoveFilterDimension,
 		RemoveFilterDimensionOptionFunc:  ds.RemoveFilterDimensionOption,
 		RemoveFilterDimensionOptionsFunc: ds.RemoveFilterDimensionOptions,
 		UpdateFilterFunc:                 ds.UpdateFilter,
+		ReplaceFilterFunc:                ds.ReplaceFilter,
+		GetFilterSnapshotFunc:            ds.GetFilterSnapshot,
+		PublishFilterBlueprintsFunc:      ds.PublishFilterBlueprints,
 		UpdateFilterOutputFunc:           ds.UpdateFilterOutput,
 		AddEventToFilterOutputFunc:       ds.AddEventToFilterOutput,
+		PublishFilterOutputsFunc:         ds.PublishFilterOutputs,
+		ReserveIdempotencyRecordFunc:     ds.ReserveIdempotencyRecord,
+		SaveIdempotencyRecordFunc:        ds.SaveIdempotencyRecord,
+		DeleteIdempotencyRecordFunc:      ds.DeleteIdempotencyRecord,
 		RunTransactionFunc:               ds.RunTransaction,
 	}
 	return ds
@@ -163,6 +177,12 @@ func (ds *DataStore) AgeDimension() *DataStore {
 	return ds
 }
 
+// SnapshotNotFound sets SnapshotNotFound flag to true
+func (ds *DataStore) SnapshotNotFound() *DataStore {
+	ds.Cfg.SnapshotNotFound = true
+	return ds
+}
+
 // AddFilter represents the mocked version of creating a filter blueprint to the datastore
 func (ds *DataStore) AddFilter(ctx context.Context, filterJob *models.Filter) (*models.Filter, error) {
 	if ds.Cfg.InternalError {
@@ -172,7 +192,7 @@ func (ds *DataStore) AddFilter(ctx context.Context, filterJob *models.Filter) (*
 }
 
 // AddFilterDimension represents the mocked version of creating a filter dimension to the datastore
-func (ds *DataStore) AddFilterDimension(ctx context.Context, filterID, name string, options []string, dimensions []models.Dimension, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (newETag string, err error) {
+func (ds *DataStore) AddFilterDimension(ctx context.Context, filterID, name, mode string, options []string, ranges []models.OptionRange, dimensions []models.Dimension, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (newETag string, err error) {
 	if ds.Cfg.InternalError {
 		return "", errorInternalServer
 	}
@@ -192,6 +212,27 @@ func (ds *DataStore) AddFilterDimension(ctx context.Context, filterID, name stri
 	return ds.newETag(), nil
 }
 
+// UpdateFilterDimension represents the mocked version of replacing a filter dimension in the datastore
+func (ds *DataStore) UpdateFilterDimension(ctx context.Context, filterID string, dimension models.Dimension, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (newETag string, err error) {
+	if ds.Cfg.InternalError {
+		return "", errorInternalServer
+	}
+
+	if ds.Cfg.NotFound {
+		return "", filters.ErrDimensionNotFound
+	}
+
+	if ds.Cfg.ConflictRequest {
+		return "", filters.ErrFilterBlueprintConflict
+	}
+
+	if err := ds.validateETag(eTagSelector); err != nil {
+		return "", err
+	}
+
+	return ds.newETag(), nil
+}
+
 // AddFilterDimensionOption represents the mocked version of creating a filter dimension option to the datastore
 func (ds *DataStore) AddFilterDimensionOption(ctx context.Context, filterID, name, option string, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (newETag string, err error) {
 	if ds.Cfg.InternalError {
@@ -266,10 +307,14 @@ func (ds *DataStore) GetFilter(ctx context.Context, filterID, eTagSelector strin
 	}
 
 	if ds.Cfg.Unpublished {
+		var published *bool
+		if ds.instancePublished {
+			published = &models.Published
+		}
 		if ds.Cfg.DimensionNotFound {
-			return &models.Filter{Dataset: &models.Dataset{ID: "123", Edition: "2017", Version: 1}, InstanceID: "12345678", Dimensions: []models.Dimension{{URL: "http://localhost:22100/filters/12345678/dimensions/time", Name: "time", Options: []string{"2014", "2015"}}}, ETag: ds.currentETag()}, nil
+			return &models.Filter{Dataset: &models.Dataset{ID: "123", Edition: "2017", Version: 1}, InstanceID: "12345678", Published: published, Dimensions: []models.Dimension{{URL: "http://localhost:22100/filters/12345678/dimensions/time", Name: "time", Options: []string{"2014", "2015"}}}, ETag: ds.currentETag()}, nil
 		}
-		return &models.Filter{Dataset: &models.Dataset{ID: "123", Edition: "2017", Version: 1}, InstanceID: "12345678", Dimensions: []models.Dimension{{Name: "age", Options: []string{"33"}}, {URL: "http://localhost:22100/filters/12345678/dimensions/time", Name: "time", Options: []string{"2014", "2015"}}, {URL: "http://localhost:22100/filters/12345678/dimensions/1_age", Name: "1_age", Options: []string{"2014", "2015"}}}, ETag: ds.currentETag()}, nil
+		return &models.Filter{Dataset: &models.Dataset{ID: "123", Edition: "2017", Version: 1}, InstanceID: "12345678", Published: published, Dimensions: []models.Dimension{{Name: "age", Options: []string{"33"}}, {URL: "http://localhost:22100/filters/12345678/dimensions/time", Name: "time", Options: []string{"2014", "2015"}}, {URL: "http://localhost:22100/filters/12345678/dimensions/1_age", Name: "1_age", Options: []string{"2014", "2015"}}}, ETag: ds.currentETag()}, nil
 	}
 
 	if ds.Cfg.DimensionNotFound {
@@ -425,6 +470,40 @@ func (ds *DataStore) UpdateFilter(ctx context.Context, updatedFilter *models.Fil
 	return ds.newETag(), nil
 }
 
+// ReplaceFilter represents the mocked version of replacing the content of a filter blueprint in the datastore
+func (ds *DataStore) ReplaceFilter(ctx context.Context, updatedFilter *models.Filter, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (newETag string, err error) {
+	if ds.Cfg.InternalError {
+		return "", errorInternalServer
+	}
+
+	if ds.Cfg.NotFound {
+		return "", filters.ErrFilterBlueprintNotFound
+	}
+
+	if ds.Cfg.ConflictRequest {
+		return "", filters.ErrFilterBlueprintConflict
+	}
+
+	if err := ds.validateETag(eTagSelector); err != nil {
+		return "", err
+	}
+
+	return ds.newETag(), nil
+}
+
+// GetFilterSnapshot represents the mocked version of getting a previous state of a filter blueprint from the datastore
+func (ds *DataStore) GetFilterSnapshot(ctx context.Context, filterID, eTag string) (*models.FilterSnapshot, error) {
+	if ds.Cfg.InternalError {
+		return nil, errorInternalServer
+	}
+
+	if ds.Cfg.SnapshotNotFound {
+		return nil, filters.ErrFilterSnapshotNotFound
+	}
+
+	return &models.FilterSnapshot{FilterID: filterID, ETag: eTag, Dataset: &models.Dataset{ID: "123", Edition: "2017", Version: 1}, InstanceID: "12345678", Dimensions: []models.Dimension{{URL: "http://localhost:22100/filters/12345678/dimensions/time", Name: "time", Options: []string{"2014"}}}}, nil
+}
+
 // UpdateFilterOutput represents the mocked version of updating a filter output from the datastore
 func (ds *DataStore) UpdateFilterOutput(ctx context.Context, filterJob *models.Filter, timestamp primitive.Timestamp) error {
 	if ds.Cfg.InternalError {
@@ -455,6 +534,79 @@ func (ds *DataStore) AddEventToFilterOutput(ctx context.Context, filterOutputID
 	return nil
 }
 
+// PublishFilterBlueprints represents the mocked version of publishing the filter blueprints of an instance in the datastore
+func (ds *DataStore) PublishFilterBlueprints(ctx context.Context, instanceID string) (int, error) {
+	if ds.Cfg.InternalError {
+		return 0, errorInternalServer
+	}
+
+	ds.instancePublished = true
+	return 1, nil
+}
+
+// PublishFilterOutputs represents the mocked version of publishing the filter outputs of an instance in the datastore
+func (ds *DataStore) PublishFilterOutputs(ctx context.Context, instanceID string) ([]string, error) {
+	if ds.Cfg.InternalError {
+		return nil, errorInternalServer
+	}
+
+	return []string{"12345678"}, nil
+}
+
+// ReserveIdempotencyRecord represents the mocked version of reserving the record of a request made with an idempotency key in the datastore,
+// which returns the existing record instead if it has not expired, nor been abandoned while in progress
+func (ds *DataStore) ReserveIdempotencyRecord(ctx context.Context, record *models.IdempotencyRecord, expiredBefore, leaseExpiredBefore time.Time) (*models.IdempotencyRecord, error) {
+	if ds.Cfg.InternalError {
+		return nil, errorInternalServer
+	}
+
+	ds.idempotencyMutex.Lock()
+	defer ds.idempotencyMutex.Unlock()
+
+	if existing, ok := ds.idempotencyRecords[record.Scope+" "+record.Key]; ok && !existing.CreatedAt.Before(expiredBefore) {
+		if !existing.InProgress || !existing.CreatedAt.Before(leaseExpiredBefore) {
+			return existing, nil
+		}
+	}
+	if ds.idempotencyRecords == nil {
+		ds.idempotencyRecords = make(map[string]*models.IdempotencyRecord)
+	}
+	ds.idempotencyRecords[record.Scope+" "+record.Key] = record
+	return nil, nil
+}
+
+// SaveIdempotencyRecord represents the mocked version of storing the response of a request made with an idempotency key in the datastore.
+// Records are kept in memory, so that retries of the request can be replayed
+func (ds *DataStore) SaveIdempotencyRecord(ctx context.Context, record *models.IdempotencyRecord) error {
+	if ds.Cfg.InternalError {
+		return errorInternalServer
+	}
+
+	ds.idempotencyMutex.Lock()
+	defer ds.idempotencyMutex.Unlock()
+
+	if existing, ok := ds.idempotencyRecords[record.Scope+" "+record.Key]; !ok || !existing.CreatedAt.Equal(record.CreatedAt) {
+		return filters.ErrIdempotencyRecordNotFound
+	}
+	ds.idempotencyRecords[record.Scope+" "+record.Key] = record
+	return nil
+}
+
+// DeleteIdempotencyRecord represents the mocked version of releasing the record of a request made with an idempotency key in the datastore
+func (ds *DataStore) DeleteIdempotencyRecord(ctx context.Context, record *models.IdempotencyRecord) error {
+	if ds.Cfg.InternalError {
+		return errorInternalServer
+	}
+
+	ds.idempotencyMutex.Lock()
+	defer ds.idempotencyMutex.Unlock()
+
+	if existing, ok := ds.idempotencyRecords[record.Scope+" "+record.Key]; ok && existing.CreatedAt.Equal(record.CreatedAt) {
+		delete(ds.idempotencyRecords, record.Scope+" "+record.Key)
+	}
+	return nil
+}
+
 func (ds *DataStore) RunTransaction(ctx context.Context, retry bool, fn mongodriver.TransactionFunc) (interface{}, error) {
 	if ds.Cfg.InternalError {
 		return nil, errorInternalServer
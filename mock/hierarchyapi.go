@@ -0,0 +1,60 @@
+package mock
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/ONSdigital/dp-api-clients-go/v2/hierarchy"
+	apimock "github.com/ONSdigital/dp-filter-api/api/mock"
+)
+
+// HierarchyAPI is a stub hierarchy source, holding the codes of the children of each node of a dimension hierarchy.
+// This struct can be used directly as a mock, as it implements the required methods,
+// or you can use the internal 'moq' Mock if you want ot validate calls, parameters etc.
+type HierarchyAPI struct {
+	Children map[string][]string
+	Mock     *apimock.HierarchyAPIMock
+}
+
+// NewHierarchyAPI creates a new hierarchy API mock for the provided tree of codes
+func NewHierarchyAPI(children map[string][]string) *HierarchyAPI {
+	h := &HierarchyAPI{
+		Children: children,
+	}
+	h.Mock = &apimock.HierarchyAPIMock{
+		GetChildFunc: h.GetChild,
+	}
+	return h
+}
+
+// GetChild represents the mocked version of getting a node, along with its children, from the hierarchy API.
+// Codes that are not part of the tree are not found.
+func (h *HierarchyAPI) GetChild(ctx context.Context, instanceID, name, code string) (m hierarchy.Model, err error) {
+	if !h.contains(code) {
+		return m, hierarchy.NewErrInvalidHierarchyAPIResponse(http.StatusOK, http.StatusNotFound, fmt.Sprintf("/hierarchies/%s/%s/%s", instanceID, name, code))
+	}
+
+	m.Links.Code.ID = code
+	m.NumberofChildren = len(h.Children[code])
+	for _, childCode := range h.Children[code] {
+		child := hierarchy.Child{NumberofChildren: len(h.Children[childCode])}
+		child.Links.Code.ID = childCode
+		m.Children = append(m.Children, child)
+	}
+	return m, nil
+}
+
+func (h *HierarchyAPI) contains(code string) bool {
+	if _, ok := h.Children[code]; ok {
+		return true
+	}
+	for _, children := range h.Children {
+		for _, child := range children {
+			if child == code {
+				return true
+			}
+		}
+	}
+	return false
+}
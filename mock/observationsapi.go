@@ -0,0 +1,67 @@
+package mock
+
+import (
+	"context"
+	"net/url"
+
+	apimock "github.com/ONSdigital/dp-filter-api/api/mock"
+	"github.com/ONSdigital/dp-filter-api/observations"
+)
+
+// ObservationCell is the value of the observation for one combination of dimension options
+type ObservationCell struct {
+	Options map[string]string
+	Value   string
+}
+
+// ObservationsAPI is a stub observations source, holding the observation of each combination of dimension options.
+// This struct can be used directly as a mock, as it implements the required methods,
+// or you can use the internal 'moq' Mock if you want ot validate calls, parameters etc.
+type ObservationsAPI struct {
+	Cells []ObservationCell
+	Mock  *apimock.ObservationsAPIMock
+}
+
+// NewObservationsAPI creates a new observations API mock for the provided cells
+func NewObservationsAPI(cells []ObservationCell) *ObservationsAPI {
+	o := &ObservationsAPI{
+		Cells: cells,
+	}
+	o.Mock = &apimock.ObservationsAPIMock{
+		GetObservationsFunc: o.GetObservations,
+	}
+	return o
+}
+
+// GetObservations represents the mocked version of querying observations from the dataset API.
+// A cell matches the query if each queried dimension is either the '*' wildcard or the option of the cell.
+func (o *ObservationsAPI) GetObservations(ctx context.Context, userAuthToken, serviceAuthToken, collectionID, datasetID, edition, version string, query url.Values) (m observations.Observations, err error) {
+	m.Observations = []observations.Observation{}
+	for _, cell := range o.Cells {
+		if !cell.matches(query) {
+			continue
+		}
+
+		observation := observations.Observation{Observation: cell.Value}
+		for name := range query {
+			if query.Get(name) == "*" {
+				if observation.Dimensions == nil {
+					observation.Dimensions = map[string]*observations.Option{}
+				}
+				observation.Dimensions[name] = &observations.Option{ID: cell.Options[name]}
+			}
+		}
+		m.Observations = append(m.Observations, observation)
+	}
+	m.TotalObservations = len(m.Observations)
+	return m, nil
+}
+
+func (c ObservationCell) matches(query url.Values) bool {
+	for name := range query {
+		if option := query.Get(name); option != "*" && option != c.Options[name] {
+			return false
+		}
+	}
+	return true
+}
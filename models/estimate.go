@@ -0,0 +1,73 @@
+package models
+
+import (
+	"fmt"
+	"math"
+)
+
+// Approximate sizes used to estimate the downloads of a filter output
+const (
+	// bytes of the observation value, and of the code and label of each dimension option, in a CSV row
+	estimatedCSVObservationBytes = 12
+	estimatedCSVDimensionBytes   = 40
+
+	// an XLSX file is compressed, being roughly this percentage of the size of the equivalent CSV file
+	estimatedXLSXSizePercentage = 60
+)
+
+// DimensionEstimate reports the number of options that a filter output would contain for a dimension
+type DimensionEstimate struct {
+	Name        string `json:"name"`
+	OptionCount int    `json:"option_count"`
+	AllOptions  bool   `json:"all_options"`
+}
+
+// FilterEstimate reports the approximate number of rows and size of the downloads that a filter output would produce
+type FilterEstimate struct {
+	Dimensions   []DimensionEstimate `json:"dimensions"`
+	Rows         int64               `json:"rows"`
+	CSVSize      int64               `json:"csv_size"`
+	XLSXSize     int64               `json:"xlsx_size"`
+	XLSXRowLimit int                 `json:"xlsx_row_limit"`
+	XLSXSkipped  bool                `json:"xlsx_skipped"`
+	Warnings     []string            `json:"warnings,omitempty"`
+}
+
+// NewFilterEstimate estimates the rows of a filter output, as the product of the number of options of each dimension,
+// and the approximate size of its CSV and XLSX downloads. The XLSX download is expected to be skipped
+// if the rows exceed the provided limit.
+func NewFilterEstimate(dimensions []DimensionEstimate, xlsxRowLimit int) *FilterEstimate {
+	estimate := &FilterEstimate{
+		Dimensions:   dimensions,
+		XLSXRowLimit: xlsxRowLimit,
+	}
+
+	estimate.Rows = 1
+	for _, d := range dimensions {
+		if d.OptionCount == 0 {
+			estimate.Rows = 0
+			break
+		}
+		// the product is capped, instead of overflowing, for filters that are far too large to be exported anyway
+		if estimate.Rows > math.MaxInt64/int64(d.OptionCount) {
+			estimate.Rows = math.MaxInt64
+			continue
+		}
+		estimate.Rows *= int64(d.OptionCount)
+	}
+
+	rowBytes := int64(estimatedCSVObservationBytes + estimatedCSVDimensionBytes*len(dimensions))
+	estimate.CSVSize = math.MaxInt64
+	if estimate.Rows < math.MaxInt64/rowBytes {
+		estimate.CSVSize = estimate.Rows * rowBytes
+	}
+	estimate.XLSXSize = estimate.CSVSize/100*estimatedXLSXSizePercentage + estimate.CSVSize%100*estimatedXLSXSizePercentage/100
+
+	if estimate.Rows > int64(xlsxRowLimit) {
+		estimate.XLSXSkipped = true
+		estimate.Warnings = append(estimate.Warnings,
+			fmt.Sprintf("the estimated %d rows exceed the XLSX limit of %d rows, so the XLSX download will be skipped", estimate.Rows, xlsxRowLimit))
+	}
+
+	return estimate
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"math"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestNewFilterEstimate(t *testing.T) {
+	Convey("When the option counts of the dimensions are provided, the rows are their product", t, func() {
+		estimate := NewFilterEstimate([]DimensionEstimate{
+			{Name: "age", OptionCount: 10},
+			{Name: "geography", OptionCount: 300, AllOptions: true},
+		}, 1048575)
+		So(estimate.Rows, ShouldEqual, 3000)
+		So(estimate.CSVSize, ShouldEqual, 3000*(12+40*2))
+		So(estimate.XLSXSize, ShouldEqual, 3000*(12+40*2)*60/100)
+		So(estimate.XLSXRowLimit, ShouldEqual, 1048575)
+		So(estimate.XLSXSkipped, ShouldBeFalse)
+		So(estimate.Warnings, ShouldBeEmpty)
+	})
+
+	Convey("When a dimension has no options, no rows are estimated", t, func() {
+		estimate := NewFilterEstimate([]DimensionEstimate{{Name: "age", OptionCount: 0}, {Name: "sex", OptionCount: 2}}, 1048575)
+		So(estimate.Rows, ShouldEqual, 0)
+		So(estimate.CSVSize, ShouldEqual, 0)
+	})
+
+	Convey("When the rows exceed the XLSX limit, the XLSX download is expected to be skipped", t, func() {
+		estimate := NewFilterEstimate([]DimensionEstimate{{Name: "geography", OptionCount: 2000}, {Name: "age", OptionCount: 1000}}, 1048575)
+		So(estimate.Rows, ShouldEqual, 2000000)
+		So(estimate.XLSXSkipped, ShouldBeTrue)
+		So(estimate.Warnings, ShouldResemble, []string{"the estimated 2000000 rows exceed the XLSX limit of 1048575 rows, so the XLSX download will be skipped"})
+	})
+
+	Convey("When the product of the option counts overflows, the estimate is capped", t, func() {
+		estimate := NewFilterEstimate([]DimensionEstimate{
+			{Name: "a", OptionCount: math.MaxInt32},
+			{Name: "b", OptionCount: math.MaxInt32},
+			{Name: "c", OptionCount: math.MaxInt32},
+		}, 1048575)
+		So(estimate.Rows, ShouldEqual, int64(math.MaxInt64))
+		So(estimate.CSVSize, ShouldEqual, int64(math.MaxInt64))
+		So(estimate.XLSXSkipped, ShouldBeTrue)
+	})
+}
@@ -15,6 +15,7 @@ const (
 	EventFilterOutputXLSXGenStart = "FilterOutputXLSXGenStart"
 	EventFilterOutputXLSXGenEnd   = "FilterOutputXLSXGenEnd"
 	EventFilterOutputCompleted    = "FilterOutputCompleted"
+	EventFilterOutputPublished    = "FilterOutputPublished"
 )
 
 // Event captures the time certain stages of filter response were completed
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// IdempotencyRecord represents a request made with an Idempotency-Key header, which is in progress until its response is recorded,
+// so that retries of the request with the same key are responded without repeating its side effects
+type IdempotencyRecord struct {
+	Key          string      `bson:"key"`
+	Scope        string      `bson:"scope"`
+	RequestHash  string      `bson:"request_hash"`
+	InProgress   bool        `bson:"in_progress"`
+	StatusCode   int         `bson:"status_code,omitempty"`
+	Header       http.Header `bson:"header,omitempty"`
+	Body         []byte      `bson:"body,omitempty"`
+	ResponseHash string      `bson:"response_hash,omitempty"`
+	CreatedAt    time.Time   `bson:"created_at"`
+}
+
+// NewIdempotencyRecord creates the record of a request made with an idempotency key, which is in progress until its response is set.
+// Its creation time identifies the reservation of the key, so it is truncated to the precision it is stored with.
+func NewIdempotencyRecord(key, scope, requestHash string) *IdempotencyRecord {
+	return &IdempotencyRecord{
+		Key:         key,
+		Scope:       scope,
+		RequestHash: requestHash,
+		InProgress:  true,
+		CreatedAt:   time.Now().UTC().Truncate(time.Millisecond),
+	}
+}
+
+// SetResponse records the response to the request, hashing its body, so that the request is no longer in progress
+func (r *IdempotencyRecord) SetResponse(statusCode int, header http.Header, body []byte) {
+	r.InProgress = false
+	r.StatusCode = statusCode
+	r.Header = header
+	r.Body = body
+	r.ResponseHash = HashIdempotentRequest(body)
+}
+
+// HashIdempotentRequest generates a SHA-256 hash of the provided parts of a request or response,
+// which is used to detect an idempotency key being reused for a different request
+func HashIdempotentRequest(parts ...[]byte) string {
+	h := sha256.New()
+	for _, part := range parts {
+		// the length prefix prevents different parts from producing the same hash once concatenated
+		fmt.Fprintf(h, "%d:", len(part))
+		h.Write(part)
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"net/http"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestIdempotencyRecord(t *testing.T) {
+	Convey("Given the record of a request made with an idempotency key", t, func() {
+		record := NewIdempotencyRecord("key", "POST /filters", HashIdempotentRequest([]byte("{}")))
+
+		Convey("Then it is in progress until its response is set", func() {
+			So(record.InProgress, ShouldBeTrue)
+			So(record.CreatedAt, ShouldNotBeZeroValue)
+		})
+
+		Convey("When its response is set", func() {
+			record.SetResponse(http.StatusCreated, http.Header{"Etag": {"etag"}}, []byte(`{"filter_id":"123"}`))
+
+			Convey("Then it is no longer in progress, and the hash of the response body is stored with it", func() {
+				So(record.InProgress, ShouldBeFalse)
+				So(record.StatusCode, ShouldEqual, http.StatusCreated)
+				So(record.ResponseHash, ShouldEqual, HashIdempotentRequest([]byte(`{"filter_id":"123"}`)))
+			})
+		})
+	})
+
+	Convey("The hash of a request depends on how its parts are split", t, func() {
+		So(HashIdempotentRequest([]byte("a"), []byte("bc")), ShouldNotEqual, HashIdempotentRequest([]byte("ab"), []byte("c")))
+		So(HashIdempotentRequest([]byte("a"), []byte("bc")), ShouldEqual, HashIdempotentRequest([]byte("a"), []byte("bc")))
+	})
+}
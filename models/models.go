@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/url"
+	"slices"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -22,6 +23,12 @@ const (
 	CompletedState = "completed"
 )
 
+// A list of dimension modes, which define how the options of a dimension are interpreted
+const (
+	DimensionModeInclude = "include"
+	DimensionModeExclude = "exclude"
+)
+
 var (
 	Unpublished = false
 	Published   = true
@@ -57,6 +64,9 @@ type Filter struct {
 	Published  *bool       `bson:"published,omitempty"  json:"published,omitempty"`
 	Links      LinkMap     `bson:"links"                json:"links,omitempty"`
 	Type       string      `bson:"type,omitempty"       json:"type,omitempty"`
+	Language   string      `bson:"language,omitempty"   json:"language,omitempty"`
+
+	NewerVersionAvailable *bool `bson:"-" json:"newer_version_available,omitempty"`
 }
 
 // Hash generates a SHA-1 hash of the filter struct. SHA-1 is not cryptographically safe,
@@ -83,6 +93,39 @@ func (filter *Filter) Hash(extraBytes []byte) (string, error) {
 	return fmt.Sprintf("%x", h.Sum(nil)), nil
 }
 
+// FilterSnapshot represents the state of a filter blueprint at the time its ETag was generated,
+// so that the blueprint can later be restored to that state
+type FilterSnapshot struct {
+	FilterID    string      `bson:"filter_id"            json:"filter_id"`
+	ETag        string      `bson:"e_tag"                json:"e_tag"`
+	LastUpdated time.Time   `bson:"last_updated"         json:"-"`
+	Dataset     *Dataset    `bson:"dataset"              json:"dataset"`
+	InstanceID  string      `bson:"instance_id"          json:"instance_id"`
+	Dimensions  []Dimension `bson:"dimensions,omitempty" json:"dimensions,omitempty"`
+}
+
+// NewFilterSnapshot creates a snapshot of the provided filter blueprint
+func NewFilterSnapshot(filter *Filter) *FilterSnapshot {
+	return &FilterSnapshot{
+		FilterID:    filter.FilterID,
+		ETag:        filter.ETag,
+		LastUpdated: time.Now().UTC(),
+		Dataset:     filter.Dataset,
+		InstanceID:  filter.InstanceID,
+		Dimensions:  filter.Dimensions,
+	}
+}
+
+// RestoreFilter represents the body of a request to restore a filter blueprint to a previous ETag
+type RestoreFilter struct {
+	ETag string `json:"e_tag"`
+}
+
+// CopyFilter represents the optional body of a request to copy a filter blueprint
+type CopyFilter struct {
+	Dataset *Dataset `json:"dataset,omitempty"`
+}
+
 // LinkMap contains a named *LinkObject for each link to other resources
 type LinkMap struct {
 	Dimensions      *LinkObject `bson:"dimensions"                 json:"dimensions,omitempty"`
@@ -98,12 +141,16 @@ type LinkObject struct {
 	HRef string `bson:"href"         json:"href,omitempty"`
 }
 
-// Dimension represents an object containing a list of dimension values and the dimension name
+// Dimension represents an object containing a list of dimension values and the dimension name.
+// Ranges list the values between two values, which are resolved against the current dataset version when needed.
+// In exclude mode, the options and ranges are the values left out of the dimension, and every other value is selected.
 type Dimension struct {
-	URL        string   `bson:"dimension_url,omitempty" json:"dimension_url,omitempty"`
-	Name       string   `bson:"name"                    json:"name"`
-	Options    []string `bson:"options,omitempty"       json:"options"`
-	IsAreaType *bool    `bson:"is_area_type,omitempty"  json:"is_area_type,omitempty"`
+	URL        string        `bson:"dimension_url,omitempty" json:"dimension_url,omitempty"`
+	Name       string        `bson:"name"                    json:"name"`
+	Mode       string        `bson:"mode,omitempty"          json:"mode,omitempty"`
+	Options    []string      `bson:"options,omitempty"       json:"options"`
+	Ranges     []OptionRange `bson:"ranges,omitempty"        json:"ranges,omitempty"`
+	IsAreaType *bool         `bson:"is_area_type,omitempty"  json:"is_area_type,omitempty"`
 }
 
 type UpdateDimensionResponse struct {
@@ -119,6 +166,21 @@ type DimensionItemLinks struct {
 	Self    LinkObject `json:"self"`
 }
 
+// IsExclude returns true if the options of this dimension are the values to be left out of the selection
+func (d *Dimension) IsExclude() bool {
+	return d.Mode == DimensionModeExclude
+}
+
+// ValidateMode checks that the dimension mode, if provided, is supported
+func (d *Dimension) ValidateMode() error {
+	switch d.Mode {
+	case "", DimensionModeInclude, DimensionModeExclude:
+		return nil
+	default:
+		return fmt.Errorf("invalid dimension mode provided: %q. Supported values: %s, %s", d.Mode, DimensionModeInclude, DimensionModeExclude)
+	}
+}
+
 // EncodedOptions returns the list of options for this dimension after escaping the values for URL query paramters
 func (d *Dimension) EncodedOptions() []string {
 	encodedIDs := make([]string, len(d.Options))
@@ -130,8 +192,14 @@ func (d *Dimension) EncodedOptions() []string {
 
 // PublicDimension represents information about a single dimension as served by /dimensions and /dimensions/<id>
 type PublicDimension struct {
-	Name  string                  `bson:"name"                    json:"name"`
-	Links *PublicDimensionLinkMap `bson:"links"                   json:"links"`
+	Name   string                  `bson:"name"                    json:"name"`
+	Label  string                  `bson:"label,omitempty"         json:"label,omitempty"`
+	Mode   string                  `bson:"mode,omitempty"          json:"mode,omitempty"`
+	Ranges []OptionRange           `bson:"ranges,omitempty"        json:"ranges,omitempty"`
+	Links  *PublicDimensionLinkMap `bson:"links"                   json:"links"`
+
+	// Options are only set when the dimension is embedded in a filter blueprint with its options
+	Options *PublicDimensionOptions `bson:"-" json:"options,omitempty"`
 }
 
 type PublicDimensions struct {
@@ -140,6 +208,7 @@ type PublicDimensions struct {
 	Offset     int                `json:"offset"`
 	Limit      int                `json:"limit"`
 	TotalCount int                `json:"total_count"`
+	Links      *PageLinks         `json:"links,omitempty"`
 }
 
 // PublicDimensionLinkMap is the links map for the PublicDimension structure
@@ -153,6 +222,7 @@ type PublicDimensionLinkMap struct {
 type PublicDimensionOption struct {
 	Links  *PublicDimensionOptionLinkMap `bson:"links"               json:"links"`
 	Option string                        `bson:"option"              json:"option"`
+	Label  string                        `bson:"label,omitempty"     json:"label,omitempty"`
 }
 
 // PublicDimensionOptions represents information about a set of dimension options
@@ -162,6 +232,13 @@ type PublicDimensionOptions struct {
 	Offset     int                      `json:"offset"`
 	Limit      int                      `json:"limit"`
 	TotalCount int                      `json:"total_count"`
+	Links      *PageLinks               `json:"links,omitempty"`
+}
+
+// PageLinks holds the links to the next and previous pages of a list, which are only set if there are such pages
+type PageLinks struct {
+	Next *LinkObject `json:"next,omitempty"`
+	Prev *LinkObject `json:"prev,omitempty"`
 }
 
 // PublicDimensionOptionLinkMap is the links map for the PublicDimensionOption structure
@@ -191,6 +268,8 @@ var (
 	ErrorReadingBody = errors.New("failed to read message body")
 	ErrorParsingBody = errors.New("failed to parse json body")
 	ErrorNoData      = errors.New("bad request - missing data in body")
+
+	ErrorMissingDimensionName = errors.New("bad request - missing dimension name")
 )
 
 // DuplicateDimensionError is returned if a request contains a duplicate dimension
@@ -227,26 +306,35 @@ func (filter *NewFilter) ValidateNewFilter() error {
 		return fmt.Errorf("missing mandatory fields: %v", missingFields)
 	}
 
+	for i := range filter.Dimensions {
+		if err := filter.Dimensions[i].ValidateMode(); err != nil {
+			return err
+		}
+		if err := ValidateOptionRanges(filter.Dimensions[i].Ranges); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
-// ValidateFilterDimensions checks the selected filter dimension
-// are valid for a version of a dataset
+// ValidateFilterDimensions checks the selected filter dimension are dimensions of the dataset version,
+// returning ValidationErrors with every dimension that is not
 func ValidateFilterDimensions(filterDimensions []Dimension, dimensions *dataset.VersionDimensions) error {
-	dimensionNames := make(map[string]int)
+	dimensionNames := make([]string, len(dimensions.Items))
 	for i := range dimensions.Items {
-		dimensionNames[dimensions.Items[i].Name] = 1
+		dimensionNames[i] = dimensions.Items[i].Name
 	}
 
-	var incorrectDimensions []string
+	var validationErrors ValidationErrors
 	for _, filterDimension := range filterDimensions {
-		if _, ok := dimensionNames[filterDimension.Name]; !ok {
-			incorrectDimensions = append(incorrectDimensions, filterDimension.Name)
+		if !slices.Contains(dimensionNames, filterDimension.Name) {
+			validationErrors.Errors = append(validationErrors.Errors, NewDimensionNotFoundError(filterDimension.Name, dimensionNames))
 		}
 	}
 
-	if incorrectDimensions != nil {
-		return fmt.Errorf("incorrect dimensions chosen: %v", incorrectDimensions)
+	if len(validationErrors.Errors) > 0 {
+		return validationErrors
 	}
 
 	return nil
@@ -377,9 +465,63 @@ func CreateFilter(reader io.Reader) (*Filter, error) {
 	return &filter, nil
 }
 
-// CreateDimensionOptions manages the creation of options for a dimension from a reader
-func CreateDimensionOptions(reader io.Reader) ([]string, error) {
-	var dimension Dimension
+// CreateRestoreFilter manages the creation of a filter blueprint restore request from a reader
+func CreateRestoreFilter(reader io.Reader) (*RestoreFilter, error) {
+	bytes, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, ErrorReadingBody
+	}
+
+	var restore RestoreFilter
+	err = json.Unmarshal(bytes, &restore)
+	if err != nil {
+		return nil, ErrorParsingBody
+	}
+
+	if restore.ETag == "" {
+		return nil, errors.New("missing mandatory fields: [e_tag]")
+	}
+
+	return &restore, nil
+}
+
+// CreateCopyFilter manages the creation of a filter blueprint copy request from a reader.
+// An empty body is valid, and results in a copy with no overrides.
+func CreateCopyFilter(reader io.Reader) (*CopyFilter, error) {
+	bytes, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, ErrorReadingBody
+	}
+
+	var copyFilter CopyFilter
+	if len(bytes) == 0 {
+		return &copyFilter, nil
+	}
+
+	err = json.Unmarshal(bytes, &copyFilter)
+	if err != nil {
+		return nil, ErrorParsingBody
+	}
+
+	if copyFilter.Dataset != nil && copyFilter.Dataset.Version < 0 {
+		return nil, errors.New("invalid dataset version")
+	}
+
+	return &copyFilter, nil
+}
+
+// DimensionOptions represents the body of a request to add a dimension to a filter blueprint
+type DimensionOptions struct {
+	Mode      string           `json:"mode,omitempty"`
+	Options   []string         `json:"options"`
+	Selectors []OptionSelector `json:"selectors,omitempty"`
+	Ranges    []OptionRange    `json:"ranges,omitempty"`
+}
+
+// CreateDimensionOptions manages the creation of options for a dimension from a reader,
+// along with the dimension mode, any option selectors to be expanded against the dimension hierarchy and any option ranges
+func CreateDimensionOptions(reader io.Reader) (*DimensionOptions, error) {
+	var request DimensionOptions
 
 	bytes, err := io.ReadAll(reader)
 	if err != nil {
@@ -387,15 +529,27 @@ func CreateDimensionOptions(reader io.Reader) ([]string, error) {
 	}
 
 	if len(bytes) == 0 {
-		return dimension.Options, nil
+		return &request, nil
 	}
 
-	err = json.Unmarshal(bytes, &dimension)
+	err = json.Unmarshal(bytes, &request)
 	if err != nil {
 		return nil, ErrorParsingBody
 	}
 
-	return dimension.Options, nil
+	if err := (&Dimension{Mode: request.Mode}).ValidateMode(); err != nil {
+		return nil, err
+	}
+
+	if err := ValidateOptionSelectors(request.Selectors); err != nil {
+		return nil, err
+	}
+
+	if err := ValidateOptionRanges(request.Ranges); err != nil {
+		return nil, err
+	}
+
+	return &request, nil
 }
 
 // CreatePatches manages the creation of an array of patch structs from the provided reader, and validates them
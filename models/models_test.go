@@ -65,6 +65,24 @@ func TestCreateFilterBlueprintWithInvalidJson(t *testing.T) {
 		So(err, ShouldNotBeNil)
 		So(err, ShouldResemble, fmt.Errorf("missing mandatory fields: %v", missingFields))
 	})
+
+	Convey("When a filter blueprint message has a dimension with an unsupported mode, an error is returned", t, func() {
+		filter, err := CreateNewFilter(strings.NewReader(`{"dataset":{"version":1, "edition":"1", "id":"1"}, "dimensions":[{"name":"age","mode":"invert","options":["27"]}]}`))
+		So(err, ShouldBeNil)
+
+		err = filter.ValidateNewFilter()
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldStartWith, `invalid dimension mode provided: "invert"`)
+	})
+}
+
+func TestCreateFilterBlueprintWithExcludeModeDimension(t *testing.T) {
+	Convey("When a filter blueprint message has a dimension in exclude mode, no error is returned", t, func() {
+		filter, err := CreateNewFilter(strings.NewReader(`{"dataset":{"version":1, "edition":"1", "id":"1"}, "dimensions":[{"name":"age","mode":"exclude","options":["27"]}]}`))
+		So(err, ShouldBeNil)
+		So(filter.ValidateNewFilter(), ShouldBeNil)
+		So(filter.Dimensions[0].IsExclude(), ShouldBeTrue)
+	})
 }
 
 func TestCreateBlueprintWithInvalidJson(t *testing.T) {
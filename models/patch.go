@@ -0,0 +1,137 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	dprequest "github.com/ONSdigital/dp-net/request"
+)
+
+// Targets of the paths supported by a JSON Patch of a filter blueprint
+const (
+	PatchTargetDimension        = "dimension"
+	PatchTargetDimensionOptions = "dimension_options"
+	PatchTargetDatasetVersion   = "dataset_version"
+)
+
+const (
+	patchPathDimensions     = "/dimensions/"
+	patchPathOptionsSuffix  = "/options/-"
+	patchPathDatasetVersion = "/dataset/version"
+)
+
+// ErrPatchTestFailed is returned when the value of a test operation does not match the filter blueprint
+var ErrPatchTestFailed = errors.New("test operation failed, the filter blueprint does not hold the tested value")
+
+// PatchPath is a parsed path of a JSON Patch operation on a filter blueprint
+type PatchPath struct {
+	Target    string
+	Dimension string
+}
+
+// supportedPatchOps lists the operations supported for each path target
+var supportedPatchOps = map[string][]dprequest.PatchOp{
+	PatchTargetDimension:        {dprequest.OpAdd, dprequest.OpRemove, dprequest.OpReplace, dprequest.OpMove, dprequest.OpTest},
+	PatchTargetDimensionOptions: {dprequest.OpAdd, dprequest.OpRemove},
+	PatchTargetDatasetVersion:   {dprequest.OpReplace, dprequest.OpTest},
+}
+
+// ParsePatchPath parses the path of a JSON Patch operation on a filter blueprint,
+// which can be '/dimensions/{name}', '/dimensions/{name}/options/-' or '/dataset/version'
+func ParsePatchPath(path string) (PatchPath, error) {
+	if path == patchPathDatasetVersion {
+		return PatchPath{Target: PatchTargetDatasetVersion}, nil
+	}
+
+	if name, ok := strings.CutPrefix(path, patchPathDimensions); ok {
+		target := PatchTargetDimension
+		if trimmed, isOptions := strings.CutSuffix(name, patchPathOptionsSuffix); isOptions {
+			target = PatchTargetDimensionOptions
+			name = trimmed
+		}
+		if name != "" && !strings.Contains(name, "/") {
+			return PatchPath{Target: target, Dimension: unescapePointerToken(name)}, nil
+		}
+	}
+
+	return PatchPath{}, fmt.Errorf("provided path '%s' not supported. Supported paths: '/dimensions/{name}', '/dimensions/{name}/options/-', '/dataset/version'", path)
+}
+
+// unescapePointerToken decodes the escaped characters of a JSON Pointer reference token, as defined by RFC 6901
+func unescapePointerToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+}
+
+// CreateFilterPatches manages the creation of a JSON Patch document for a filter blueprint from the provided reader,
+// validating that each operation is supported for its path
+func CreateFilterPatches(reader io.Reader) ([]dprequest.Patch, error) {
+	patches := []dprequest.Patch{}
+
+	bytes, err := io.ReadAll(reader)
+	if err != nil {
+		return []dprequest.Patch{}, ErrorReadingBody
+	}
+
+	err = json.Unmarshal(bytes, &patches)
+	if err != nil {
+		return []dprequest.Patch{}, ErrorParsingBody
+	}
+
+	for _, patch := range patches {
+		if err := ValidateFilterPatch(patch); err != nil {
+			return []dprequest.Patch{}, err
+		}
+	}
+	return patches, nil
+}
+
+// ValidateFilterPatch checks that the operation of a patch is supported for its path.
+// A move operation is only supported between dimensions, renaming the dimension in the 'from' path.
+func ValidateFilterPatch(patch dprequest.Patch) error {
+	if err := patch.Validate(dprequest.OpAdd, dprequest.OpRemove, dprequest.OpReplace, dprequest.OpMove, dprequest.OpTest); err != nil {
+		return err
+	}
+
+	path, err := ParsePatchPath(patch.Path)
+	if err != nil {
+		return err
+	}
+
+	if !slices.ContainsFunc(supportedPatchOps[path.Target], func(op dprequest.PatchOp) bool { return op.String() == patch.Op }) {
+		return dprequest.ErrUnsupportedOp(patch.Op, supportedPatchOps[path.Target])
+	}
+
+	if patch.Op == dprequest.OpMove.String() {
+		from, err := ParsePatchPath(patch.From)
+		if err != nil {
+			return err
+		}
+		if from.Target != PatchTargetDimension {
+			return fmt.Errorf("provided from '%s' not supported. Supported from: '/dimensions/{name}'", patch.From)
+		}
+	}
+	return nil
+}
+
+// SameSelection returns true if both dimensions select the same options, regardless of the order of their options and ranges
+func (d *Dimension) SameSelection(other *Dimension) bool {
+	if d.IsExclude() != other.IsExclude() {
+		return false
+	}
+	if len(d.Ranges) != len(other.Ranges) {
+		return false
+	}
+	for _, r := range d.Ranges {
+		if !containsRange(other.Ranges, r) {
+			return false
+		}
+	}
+
+	options := slices.Compact(slices.Sorted(slices.Values(d.Options)))
+	otherOptions := slices.Compact(slices.Sorted(slices.Values(other.Options)))
+	return slices.Equal(options, otherOptions)
+}
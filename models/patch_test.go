@@ -0,0 +1,79 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	dprequest "github.com/ONSdigital/dp-net/request"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestParsePatchPath(t *testing.T) {
+	Convey("When the paths of the supported targets are parsed, the target and dimension are returned", t, func() {
+		path, err := ParsePatchPath("/dimensions/age")
+		So(err, ShouldBeNil)
+		So(path, ShouldResemble, PatchPath{Target: PatchTargetDimension, Dimension: "age"})
+
+		path, err = ParsePatchPath("/dimensions/age/options/-")
+		So(err, ShouldBeNil)
+		So(path, ShouldResemble, PatchPath{Target: PatchTargetDimensionOptions, Dimension: "age"})
+
+		path, err = ParsePatchPath("/dataset/version")
+		So(err, ShouldBeNil)
+		So(path, ShouldResemble, PatchPath{Target: PatchTargetDatasetVersion})
+	})
+
+	Convey("When a dimension name has escaped characters, they are decoded", t, func() {
+		path, err := ParsePatchPath("/dimensions/age~1sex~0")
+		So(err, ShouldBeNil)
+		So(path.Dimension, ShouldEqual, "age/sex~")
+	})
+
+	Convey("When a path is not supported, an error is returned", t, func() {
+		for _, p := range []string{"/dimensions/", "/dimensions/age/options", "/dimensions/age/options/27", "/dataset", "/state"} {
+			_, err := ParsePatchPath(p)
+			So(err, ShouldNotBeNil)
+		}
+	})
+}
+
+func TestCreateFilterPatches(t *testing.T) {
+	Convey("When a patch document has supported operations for its paths, the patches are returned", t, func() {
+		patches, err := CreateFilterPatches(strings.NewReader(`[
+			{"op": "test", "path": "/dataset/version", "value": 1},
+			{"op": "move", "from": "/dimensions/age", "path": "/dimensions/age_group"},
+			{"op": "remove", "path": "/dimensions/age_group/options/-", "value": ["27"]}
+		]`))
+		So(err, ShouldBeNil)
+		So(patches, ShouldHaveLength, 3)
+	})
+
+	Convey("When a patch document has an operation that is not supported for its path, an error is returned", t, func() {
+		_, err := CreateFilterPatches(strings.NewReader(`[{"op": "add", "path": "/dataset/version", "value": 2}]`))
+		So(err, ShouldResemble, dprequest.ErrUnsupportedOp("add", []dprequest.PatchOp{dprequest.OpReplace, dprequest.OpTest}))
+	})
+
+	Convey("When a patch document moves options instead of a dimension, an error is returned", t, func() {
+		_, err := CreateFilterPatches(strings.NewReader(`[{"op": "move", "from": "/dimensions/age/options/-", "path": "/dimensions/age_group"}]`))
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("When a patch document cannot be parsed, an error is returned", t, func() {
+		_, err := CreateFilterPatches(strings.NewReader(`{"op": "add"}`))
+		So(err, ShouldEqual, ErrorParsingBody)
+	})
+}
+
+func TestDimensionSameSelection(t *testing.T) {
+	Convey("Dimensions with the same options and ranges in a different order have the same selection", t, func() {
+		d := &Dimension{Options: []string{"27", "33"}, Ranges: []OptionRange{{From: "1", To: "5"}}}
+		other := &Dimension{Mode: DimensionModeInclude, Options: []string{"33", "27", "27"}, Ranges: []OptionRange{{From: "1", To: "5"}}}
+		So(d.SameSelection(other), ShouldBeTrue)
+	})
+
+	Convey("Dimensions with different modes do not have the same selection", t, func() {
+		d := &Dimension{Options: []string{"27"}}
+		other := &Dimension{Mode: DimensionModeExclude, Options: []string{"27"}}
+		So(d.SameSelection(other), ShouldBeFalse)
+	})
+}
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// OptionPatternMatch reports the dimension options matched by an option pattern
+type OptionPatternMatch struct {
+	Pattern string   `json:"pattern"`
+	Options []string `json:"options"`
+	Count   int      `json:"count"`
+}
+
+// OptionPatternMatches reports the dimension options matched by a list of option patterns
+type OptionPatternMatches struct {
+	Items []OptionPatternMatch `json:"items"`
+	Count int                  `json:"count"`
+}
+
+// OptionPatternPrefix marks an option as a pattern instead of an option code, such as 'pattern:E06*',
+// so that option codes containing wildcard characters are never mistaken for patterns
+const OptionPatternPrefix = "pattern:"
+
+// IsOptionPattern returns true if the provided option is a pattern, starting with the option pattern prefix,
+// instead of an option code
+func IsOptionPattern(option string) bool {
+	return strings.HasPrefix(option, OptionPatternPrefix)
+}
+
+// ValidateOptionPattern checks that the provided option pattern is well formed.
+// Patterns follow the syntax of path.Match after their prefix, where '*' matches any sequence of characters and '?' matches a single character.
+func ValidateOptionPattern(pattern string) error {
+	glob := strings.TrimPrefix(pattern, OptionPatternPrefix)
+	if _, err := path.Match(glob, ""); err != nil || glob == "" {
+		return fmt.Errorf("invalid option pattern provided: %q", pattern)
+	}
+	return nil
+}
+
+// MatchOptionPattern returns the options that match the provided pattern, keeping their order
+func MatchOptionPattern(pattern string, options []string) []string {
+	glob := strings.TrimPrefix(pattern, OptionPatternPrefix)
+	matched := []string{}
+	for _, option := range options {
+		if ok, err := path.Match(glob, option); err == nil && ok {
+			matched = append(matched, option)
+		}
+	}
+	return matched
+}
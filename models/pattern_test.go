@@ -0,0 +1,37 @@
+package models
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestOptionPatterns(t *testing.T) {
+	Convey("Options starting with the pattern prefix are identified as patterns", t, func() {
+		So(IsOptionPattern("pattern:E06*"), ShouldBeTrue)
+		So(IsOptionPattern("pattern:K0400000?"), ShouldBeTrue)
+		So(IsOptionPattern("K04000001"), ShouldBeFalse)
+	})
+
+	Convey("Option codes containing wildcard characters are not identified as patterns", t, func() {
+		So(IsOptionPattern("E06*"), ShouldBeFalse)
+		So(IsOptionPattern("what?"), ShouldBeFalse)
+	})
+
+	Convey("When a pattern is malformed, it fails validation", t, func() {
+		err := ValidateOptionPattern("pattern:E06[*")
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldEqual, `invalid option pattern provided: "pattern:E06[*"`)
+	})
+
+	Convey("When a pattern is empty, it fails validation", t, func() {
+		So(ValidateOptionPattern("pattern:"), ShouldNotBeNil)
+	})
+
+	Convey("When a pattern is matched against a list of options, the matching options are returned in order", t, func() {
+		options := []string{"E06000002", "E07000001", "E06000001", "K04000001"}
+		So(MatchOptionPattern("pattern:E06*", options), ShouldResemble, []string{"E06000002", "E06000001"})
+		So(MatchOptionPattern("pattern:K0400000?", options), ShouldResemble, []string{"K04000001"})
+		So(MatchOptionPattern("pattern:W*", options), ShouldBeEmpty)
+	})
+}
@@ -0,0 +1,32 @@
+package models
+
+// FilterPreview is a small table of the observations that a filter output would contain.
+// The first column holds the observation values, followed by a column with the option code of each dimension.
+// A preview is partial when only the first of the selected options is used for some of the dimensions, which are listed as fixed.
+type FilterPreview struct {
+	Headers         []string   `json:"headers"`
+	Rows            [][]string `json:"rows"`
+	Count           int        `json:"count"`
+	Partial         bool       `json:"partial"`
+	FixedDimensions []string   `json:"fixed_dimensions,omitempty"`
+}
+
+// NewFilterPreview creates an empty preview for the provided dimensions
+func NewFilterPreview(dimensionNames []string) *FilterPreview {
+	return &FilterPreview{
+		Headers: append([]string{"observation"}, dimensionNames...),
+		Rows:    [][]string{},
+	}
+}
+
+// AddRow adds the row of an observation to the preview
+func (p *FilterPreview) AddRow(observation string, options []string) {
+	p.Rows = append(p.Rows, append([]string{observation}, options...))
+	p.Count = len(p.Rows)
+}
+
+// AddFixedDimension records that only the first selected option of a dimension is used in the preview
+func (p *FilterPreview) AddFixedDimension(name string) {
+	p.FixedDimensions = append(p.FixedDimensions, name)
+	p.Partial = true
+}
@@ -0,0 +1,178 @@
+package models
+
+import (
+	"fmt"
+)
+
+// OptionRange selects the dimension options between two option codes, both included,
+// following the order of the dimension options provided by the Dataset API
+type OptionRange struct {
+	From string `bson:"from" json:"from"`
+	To   string `bson:"to"   json:"to"`
+}
+
+// Validate checks that the option range provides both boundaries
+func (r OptionRange) Validate() error {
+	var missingFields []string
+	if r.From == "" {
+		missingFields = append(missingFields, "from")
+	}
+	if r.To == "" {
+		missingFields = append(missingFields, "to")
+	}
+	if missingFields != nil {
+		return fmt.Errorf("missing mandatory fields: %v", missingFields)
+	}
+	return nil
+}
+
+// Resolve returns the positions of the range boundaries in the provided ordered list of options
+func (r OptionRange) Resolve(orderedOptions []string) (from, to int, err error) {
+	from, to = -1, -1
+	for i, option := range orderedOptions {
+		if option == r.From {
+			from = i
+		}
+		if option == r.To {
+			to = i
+		}
+	}
+
+	if from < 0 {
+		return from, to, fmt.Errorf("range boundary %s not found in the dimension options", r.From)
+	}
+	if to < 0 {
+		return from, to, fmt.Errorf("range boundary %s not found in the dimension options", r.To)
+	}
+	if from > to {
+		return from, to, fmt.Errorf("invalid range from %s to %s: %s is after %s in the dimension options", r.From, r.To, r.From, r.To)
+	}
+	return from, to, nil
+}
+
+// Options returns the options selected by the range, from the provided ordered list of options
+func (r OptionRange) Options(orderedOptions []string) ([]string, error) {
+	from, to, err := r.Resolve(orderedOptions)
+	if err != nil {
+		return nil, err
+	}
+	return orderedOptions[from : to+1], nil
+}
+
+// ValidateOptionRanges validates all the provided option ranges
+func ValidateOptionRanges(ranges []OptionRange) error {
+	for _, r := range ranges {
+		if err := r.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HasRanges returns true if the dimension contains any option range
+func (d *Dimension) HasRanges() bool {
+	return len(d.Ranges) > 0
+}
+
+// ExpandRanges returns the options of the dimension, followed by the options listed by its ranges,
+// given the ordered list of all the options of the dataset dimension
+func (d *Dimension) ExpandRanges(orderedOptions []string) ([]string, error) {
+	options := append([]string{}, d.Options...)
+	for _, r := range d.Ranges {
+		rangeOptions, err := r.Options(orderedOptions)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, rangeOptions...)
+	}
+	return options, nil
+}
+
+// SelectedOptions returns the options selected by the dimension, according to its mode, options and ranges,
+// given the ordered list of all the options of the dataset dimension.
+// Options and ranges that are not available in the dataset dimension are ignored.
+func (d *Dimension) SelectedOptions(orderedOptions []string) []string {
+	listed := make(map[string]struct{}, len(d.Options))
+	for _, option := range d.Options {
+		listed[option] = struct{}{}
+	}
+	for _, r := range d.Ranges {
+		rangeOptions, err := r.Options(orderedOptions)
+		if err != nil {
+			continue
+		}
+		for _, option := range rangeOptions {
+			listed[option] = struct{}{}
+		}
+	}
+
+	// in include mode the listed options are selected, in exclude mode the rest of options are selected
+	selected := []string{}
+	for _, option := range orderedOptions {
+		if _, isListed := listed[option]; isListed != d.IsExclude() {
+			selected = append(selected, option)
+		}
+	}
+	return selected
+}
+
+// AddRanges adds the provided ranges to the dimension, skipping the ones that it already contains
+func (d *Dimension) AddRanges(ranges []OptionRange) {
+	for _, r := range ranges {
+		if !containsRange(d.Ranges, r) {
+			d.Ranges = append(d.Ranges, r)
+		}
+	}
+}
+
+// RemoveRanges removes the options listed by the provided ranges from the dimension, given the ordered list of all the options
+// of the dataset dimension. Ranges of the dimension that overlap a removed range are cut down to the options outside of it.
+func (d *Dimension) RemoveRanges(ranges []OptionRange, orderedOptions []string) error {
+	positions := make(map[string]int, len(orderedOptions))
+	for i, option := range orderedOptions {
+		positions[option] = i
+	}
+
+	for _, removed := range ranges {
+		from, to, err := removed.Resolve(orderedOptions)
+		if err != nil {
+			return err
+		}
+
+		options := []string{}
+		for _, option := range d.Options {
+			if pos, ok := positions[option]; ok && pos >= from && pos <= to {
+				continue
+			}
+			options = append(options, option)
+		}
+		d.Options = options
+
+		// ranges that cannot be resolved do not list any option, so they are kept as they are
+		dimensionRanges := []OptionRange{}
+		for _, r := range d.Ranges {
+			rangeFrom, rangeTo, err := r.Resolve(orderedOptions)
+			if err != nil || rangeTo < from || rangeFrom > to {
+				dimensionRanges = append(dimensionRanges, r)
+				continue
+			}
+			if rangeFrom < from {
+				dimensionRanges = append(dimensionRanges, OptionRange{From: r.From, To: orderedOptions[from-1]})
+			}
+			if rangeTo > to {
+				dimensionRanges = append(dimensionRanges, OptionRange{From: orderedOptions[to+1], To: r.To})
+			}
+		}
+		d.Ranges = dimensionRanges
+	}
+	return nil
+}
+
+func containsRange(ranges []OptionRange, r OptionRange) bool {
+	for _, existing := range ranges {
+		if existing == r {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var orderedYears = []string{"2008", "2009", "2010", "2011", "2012", "2013"}
+
+func TestOptionRangeResolve(t *testing.T) {
+	Convey("When both boundaries are options in order, their positions are returned", t, func() {
+		from, to, err := OptionRange{From: "2009", To: "2011"}.Resolve(orderedYears)
+		So(err, ShouldBeNil)
+		So(from, ShouldEqual, 1)
+		So(to, ShouldEqual, 3)
+	})
+
+	Convey("When a boundary is not an option, an error is returned", t, func() {
+		_, _, err := OptionRange{From: "2009", To: "2020"}.Resolve(orderedYears)
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldEqual, "range boundary 2020 not found in the dimension options")
+	})
+
+	Convey("When the range starts after it ends, an error is returned", t, func() {
+		_, _, err := OptionRange{From: "2011", To: "2009"}.Resolve(orderedYears)
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldEqual, "invalid range from 2011 to 2009: 2011 is after 2009 in the dimension options")
+	})
+
+	Convey("When a range does not provide both boundaries, it fails validation", t, func() {
+		err := ValidateOptionRanges([]OptionRange{{From: "2009"}})
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldEqual, "missing mandatory fields: [to]")
+	})
+}
+
+func TestDimensionSelectedOptions(t *testing.T) {
+	Convey("Given a dimension with options and a range", t, func() {
+		dimension := Dimension{Name: "time", Options: []string{"2013"}, Ranges: []OptionRange{{From: "2009", To: "2010"}}}
+
+		Convey("Then in include mode the listed options are selected, following the dataset order", func() {
+			So(dimension.SelectedOptions(orderedYears), ShouldResemble, []string{"2009", "2010", "2013"})
+		})
+
+		Convey("Then in exclude mode the rest of options are selected", func() {
+			dimension.Mode = DimensionModeExclude
+			So(dimension.SelectedOptions(orderedYears), ShouldResemble, []string{"2008", "2011", "2012"})
+		})
+
+		Convey("Then ranges that cannot be resolved are ignored", func() {
+			dimension.Ranges = append(dimension.Ranges, OptionRange{From: "2000", To: "2001"})
+			So(dimension.SelectedOptions(orderedYears), ShouldResemble, []string{"2009", "2010", "2013"})
+		})
+
+		Convey("Then expanding the ranges appends the options they list", func() {
+			options, err := dimension.ExpandRanges(orderedYears)
+			So(err, ShouldBeNil)
+			So(options, ShouldResemble, []string{"2013", "2009", "2010"})
+		})
+	})
+}
+
+func TestDimensionAddAndRemoveRanges(t *testing.T) {
+	Convey("Given a dimension with a range", t, func() {
+		dimension := Dimension{Name: "time", Options: []string{"2008", "2011"}, Ranges: []OptionRange{{From: "2009", To: "2013"}}}
+
+		Convey("When ranges are added, only the new ones are stored", func() {
+			dimension.AddRanges([]OptionRange{{From: "2009", To: "2013"}, {From: "2008", To: "2009"}})
+			So(dimension.Ranges, ShouldResemble, []OptionRange{{From: "2009", To: "2013"}, {From: "2008", To: "2009"}})
+		})
+
+		Convey("When a range inside the stored range is removed, the stored range is split and the options inside it are removed", func() {
+			err := dimension.RemoveRanges([]OptionRange{{From: "2011", To: "2012"}}, orderedYears)
+			So(err, ShouldBeNil)
+			So(dimension.Options, ShouldResemble, []string{"2008"})
+			So(dimension.Ranges, ShouldResemble, []OptionRange{{From: "2009", To: "2010"}, {From: "2013", To: "2013"}})
+		})
+
+		Convey("When a range covering the stored range is removed, the stored range is removed", func() {
+			err := dimension.RemoveRanges([]OptionRange{{From: "2009", To: "2013"}}, orderedYears)
+			So(err, ShouldBeNil)
+			So(dimension.Options, ShouldResemble, []string{"2008"})
+			So(dimension.Ranges, ShouldBeEmpty)
+		})
+
+		Convey("When an invalid range is removed, an error is returned", func() {
+			err := dimension.RemoveRanges([]OptionRange{{From: "2013", To: "2009"}}, orderedYears)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,56 @@
+package models
+
+// A list of outcomes for a dimension when a filter blueprint is rebased onto another dataset version
+const (
+	RebaseDimensionKept    = "kept"
+	RebaseDimensionRenamed = "renamed"
+	RebaseDimensionDropped = "dropped"
+)
+
+// RebaseReport describes the changes applied to the dimensions of a filter blueprint
+// when it is moved from one dataset version to another
+type RebaseReport struct {
+	FromVersion int               `json:"from_version"`
+	ToVersion   int               `json:"to_version"`
+	Lossy       bool              `json:"lossy"`
+	Dimensions  []RebaseDimension `json:"dimensions"`
+}
+
+// RebaseDimension describes the outcome of rebasing a single filter dimension
+type RebaseDimension struct {
+	Name           string          `json:"name"`
+	NewName        string          `json:"new_name,omitempty"`
+	Status         string          `json:"status"`
+	DroppedOptions []string        `json:"dropped_options,omitempty"`
+	RenamedOptions []RenamedOption `json:"renamed_options,omitempty"`
+	DroppedRanges  []OptionRange   `json:"dropped_ranges,omitempty"`
+	RenamedRanges  []RenamedRange  `json:"renamed_ranges,omitempty"`
+}
+
+// RenamedOption represents a dimension option whose code changed between dataset versions, while keeping its label
+type RenamedOption struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// RenamedRange represents an option range whose boundaries changed between dataset versions, while keeping their labels
+type RenamedRange struct {
+	From OptionRange `json:"from"`
+	To   OptionRange `json:"to"`
+}
+
+// RebaseResponse represents the response body of a successful rebase
+type RebaseResponse struct {
+	Filter *Filter       `json:"filter"`
+	Report *RebaseReport `json:"report"`
+}
+
+// IsLossy returns true if any dimension, option or option range was dropped by the rebase
+func (r *RebaseReport) IsLossy() bool {
+	for _, d := range r.Dimensions {
+		if d.Status == RebaseDimensionDropped || len(d.DroppedOptions) > 0 || len(d.DroppedRanges) > 0 {
+			return true
+		}
+	}
+	return false
+}
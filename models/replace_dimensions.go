@@ -0,0 +1,86 @@
+package models
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// ReplaceDimension represents a dimension in the body of a request replacing all the dimensions of a filter blueprint
+type ReplaceDimension struct {
+	Name string `json:"name"`
+	DimensionOptions
+}
+
+// ReplaceDimensions represents the body of a request replacing all the dimensions of a filter blueprint
+type ReplaceDimensions struct {
+	Items []ReplaceDimension `json:"items"`
+}
+
+// CreateReplaceDimensions manages the creation of the dimensions replacing the ones of a filter blueprint from a reader
+func CreateReplaceDimensions(reader io.Reader) (*ReplaceDimensions, error) {
+	bytes, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, ErrorReadingBody
+	}
+
+	var request ReplaceDimensions
+	err = json.Unmarshal(bytes, &request)
+	if err != nil {
+		return nil, ErrorParsingBody
+	}
+
+	if request.Items == nil {
+		return nil, ErrorNoData
+	}
+
+	return &request, nil
+}
+
+// Validate checks the name, mode, option selectors and option ranges of every dimension,
+// returning ValidationErrors with all the dimensions that are not valid
+func (r *ReplaceDimensions) Validate() error {
+	var validationErrors ValidationErrors
+	names := make(map[string]bool)
+
+	for _, dimension := range r.Items {
+		err := dimension.validate(names)
+		if err != nil {
+			validationErrors.Errors = append(validationErrors.Errors, NewInvalidSelectionError(dimension.Name, err))
+		}
+		names[dimension.Name] = true
+	}
+
+	if len(validationErrors.Errors) > 0 {
+		return validationErrors
+	}
+	return nil
+}
+
+func (d *ReplaceDimension) validate(names map[string]bool) error {
+	if d.Name == "" {
+		return ErrorMissingDimensionName
+	}
+
+	if names[d.Name] {
+		return DuplicateDimensionError{d.Name}
+	}
+
+	if err := (&Dimension{Mode: d.Mode}).ValidateMode(); err != nil {
+		return err
+	}
+
+	if err := ValidateOptionSelectors(d.Selectors); err != nil {
+		return err
+	}
+
+	return ValidateOptionRanges(d.Ranges)
+}
+
+// TotalValues returns the overall number of options, option selectors and option ranges provided for the dimensions
+func (r *ReplaceDimensions) TotalValues() int {
+	total := 0
+	for _, dimension := range r.Items {
+		total += len(dimension.Options) + len(dimension.Selectors) + len(dimension.Ranges)
+	}
+	return total
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCreateReplaceDimensions(t *testing.T) {
+	Convey("When the body has a list of dimensions, they are returned", t, func() {
+		replaceDimensions, err := CreateReplaceDimensions(strings.NewReader(`{"items": [{"name": "age", "mode": "exclude", "options": ["27"]}]}`))
+		So(err, ShouldBeNil)
+		So(replaceDimensions.Items, ShouldHaveLength, 1)
+		So(replaceDimensions.Items[0].Name, ShouldEqual, "age")
+		So(replaceDimensions.Items[0].Mode, ShouldEqual, DimensionModeExclude)
+		So(replaceDimensions.Items[0].Options, ShouldResemble, []string{"27"})
+	})
+
+	Convey("When the body is not valid json, an error is returned", t, func() {
+		_, err := CreateReplaceDimensions(strings.NewReader(`{"items": `))
+		So(err, ShouldEqual, ErrorParsingBody)
+	})
+
+	Convey("When the body has no list of dimensions, an error is returned", t, func() {
+		_, err := CreateReplaceDimensions(strings.NewReader(`{}`))
+		So(err, ShouldEqual, ErrorNoData)
+	})
+}
+
+func TestReplaceDimensionsValidate(t *testing.T) {
+	Convey("Given a list of valid dimensions, no errors are returned", t, func() {
+		replaceDimensions := &ReplaceDimensions{Items: []ReplaceDimension{
+			{Name: "age", DimensionOptions: DimensionOptions{Options: []string{"27"}}},
+			{Name: "sex", DimensionOptions: DimensionOptions{Mode: DimensionModeExclude}},
+		}}
+		So(replaceDimensions.Validate(), ShouldBeNil)
+		So(replaceDimensions.TotalValues(), ShouldEqual, 1)
+	})
+
+	Convey("Given a list with several invalid dimensions, the error of each invalid dimension is returned", t, func() {
+		replaceDimensions := &ReplaceDimensions{Items: []ReplaceDimension{
+			{Name: "age", DimensionOptions: DimensionOptions{Mode: "all"}},
+			{Name: "sex"},
+			{Name: ""},
+			{Name: "sex"},
+		}}
+
+		err := replaceDimensions.Validate()
+		So(err, ShouldHaveSameTypeAs, ValidationErrors{})
+
+		errs := err.(ValidationErrors).Errors
+		So(errs, ShouldHaveLength, 3)
+		So(errs[0].Dimension, ShouldEqual, "age")
+		So(errs[0].Reason, ShouldEqual, ValidationReasonInvalidSelection)
+		So(errs[1], ShouldResemble, NewInvalidSelectionError("", ErrorMissingDimensionName))
+		So(errs[2], ShouldResemble, NewInvalidSelectionError("sex", DuplicateDimensionError{"sex"}))
+	})
+}
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+// A list of hierarchy relations that can be included by an option selector
+const (
+	IncludeChildren    = "children"
+	IncludeDescendants = "descendants"
+	IncludeLeaves      = "leaves"
+)
+
+// OptionSelector selects a dimension option by its code, along with the related options in the dimension hierarchy:
+// - children selects the code and its direct children
+// - descendants selects the code and every option below it
+// - leaves selects only the options below the code that have no children of their own
+type OptionSelector struct {
+	Code    string `json:"code"`
+	Include string `json:"include"`
+}
+
+// Validate checks that the option selector provides a code and a supported hierarchy relation
+func (s OptionSelector) Validate() error {
+	if s.Code == "" {
+		return errors.New("missing mandatory fields: [code]")
+	}
+
+	switch s.Include {
+	case IncludeChildren, IncludeDescendants, IncludeLeaves:
+		return nil
+	default:
+		return fmt.Errorf("invalid include value provided for option selector %s: %q. Supported values: %s, %s, %s",
+			s.Code, s.Include, IncludeChildren, IncludeDescendants, IncludeLeaves)
+	}
+}
+
+// ValidateOptionSelectors validates all the provided option selectors
+func ValidateOptionSelectors(selectors []OptionSelector) error {
+	for _, selector := range selectors {
+		if err := selector.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
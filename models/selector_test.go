@@ -0,0 +1,79 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestValidateOptionSelectors(t *testing.T) {
+	Convey("When option selectors provide a code and a supported include value, no error is returned", t, func() {
+		selectors := []OptionSelector{
+			{Code: "E92000001", Include: IncludeChildren},
+			{Code: "E92000001", Include: IncludeDescendants},
+			{Code: "E92000001", Include: IncludeLeaves},
+		}
+		So(ValidateOptionSelectors(selectors), ShouldBeNil)
+	})
+
+	Convey("When an option selector does not provide a code, an error is returned", t, func() {
+		err := ValidateOptionSelectors([]OptionSelector{{Include: IncludeChildren}})
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldEqual, "missing mandatory fields: [code]")
+	})
+
+	Convey("When an option selector provides an unsupported include value, an error is returned", t, func() {
+		err := ValidateOptionSelectors([]OptionSelector{{Code: "E92000001", Include: "siblings"}})
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldStartWith, `invalid include value provided for option selector E92000001: "siblings"`)
+	})
+}
+
+func TestCreateDimensionOptions(t *testing.T) {
+	Convey("When the body contains options and option selectors, both are returned", t, func() {
+		reader := strings.NewReader(`{"options":["K02000001"],"selectors":[{"code":"E92000001","include":"leaves"}]}`)
+		dimensionOptions, err := CreateDimensionOptions(reader)
+		So(err, ShouldBeNil)
+		So(dimensionOptions.Options, ShouldResemble, []string{"K02000001"})
+		So(dimensionOptions.Selectors, ShouldResemble, []OptionSelector{{Code: "E92000001", Include: IncludeLeaves}})
+	})
+
+	Convey("When the body is empty, no options or option selectors are returned", t, func() {
+		dimensionOptions, err := CreateDimensionOptions(strings.NewReader(""))
+		So(err, ShouldBeNil)
+		So(dimensionOptions.Options, ShouldBeEmpty)
+		So(dimensionOptions.Selectors, ShouldBeEmpty)
+	})
+
+	Convey("When the body contains an invalid option selector, an error is returned", t, func() {
+		reader := strings.NewReader(`{"selectors":[{"code":"E92000001"}]}`)
+		_, err := CreateDimensionOptions(reader)
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("When the body contains a dimension mode, it is returned", t, func() {
+		reader := strings.NewReader(`{"mode":"exclude","options":["K02000001"]}`)
+		dimensionOptions, err := CreateDimensionOptions(reader)
+		So(err, ShouldBeNil)
+		So(dimensionOptions.Mode, ShouldEqual, DimensionModeExclude)
+		So(dimensionOptions.Options, ShouldResemble, []string{"K02000001"})
+	})
+
+	Convey("When the body contains an unsupported dimension mode, an error is returned", t, func() {
+		reader := strings.NewReader(`{"mode":"invert","options":["K02000001"]}`)
+		_, err := CreateDimensionOptions(reader)
+		So(err, ShouldNotBeNil)
+		So(err.Error(), ShouldStartWith, `invalid dimension mode provided: "invert"`)
+	})
+
+	Convey("When the body is not valid json, a parsing error is returned", t, func() {
+		_, err := CreateDimensionOptions(strings.NewReader("{"))
+		So(err, ShouldEqual, ErrorParsingBody)
+	})
+
+	Convey("When the body cannot be read, a reading error is returned", t, func() {
+		_, err := CreateDimensionOptions(reader{})
+		So(err, ShouldEqual, ErrorReadingBody)
+	})
+}
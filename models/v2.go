@@ -0,0 +1,133 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// FilterBlueprintV2 represents a filter blueprint in version 2 of the API.
+// Unlike version 1, it is distinct from the representation of a filter output, and omits storage details such as the dataset instance.
+type FilterBlueprintV2 struct {
+	ID                    string                 `json:"id"`
+	Dataset               *Dataset               `json:"dataset"`
+	Dimensions            []DimensionV2          `json:"dimensions"`
+	Published             bool                   `json:"published"`
+	Language              string                 `json:"language,omitempty"`
+	NewerVersionAvailable *bool                  `json:"newer_version_available,omitempty"`
+	Links                 FilterBlueprintLinksV2 `json:"links"`
+}
+
+// FilterBlueprintLinksV2 holds the links of a filter blueprint in version 2 of the API
+type FilterBlueprintLinksV2 struct {
+	Self         *LinkObject `json:"self"`
+	Version      *LinkObject `json:"version,omitempty"`
+	FilterOutput *LinkObject `json:"filter_output,omitempty"`
+}
+
+// FilterOutputV2 represents a filter output in version 2 of the API
+type FilterOutputV2 struct {
+	ID         string              `json:"id"`
+	Dataset    *Dataset            `json:"dataset"`
+	Dimensions []DimensionV2       `json:"dimensions"`
+	State      string              `json:"state"`
+	Published  bool                `json:"published"`
+	Downloads  *Downloads          `json:"downloads,omitempty"`
+	Events     []*Event            `json:"events,omitempty"`
+	Links      FilterOutputLinksV2 `json:"links"`
+}
+
+// FilterOutputLinksV2 holds the links of a filter output in version 2 of the API
+type FilterOutputLinksV2 struct {
+	Self            *LinkObject `json:"self"`
+	FilterBlueprint *LinkObject `json:"filter_blueprint,omitempty"`
+	Version         *LinkObject `json:"version,omitempty"`
+}
+
+// DimensionV2 represents a dimension of a filter blueprint or output in version 2 of the API, with the options selected in it
+type DimensionV2 struct {
+	Name    string        `json:"name"`
+	Mode    string        `json:"mode,omitempty"`
+	Options []string      `json:"options,omitempty"`
+	Ranges  []OptionRange `json:"ranges,omitempty"`
+}
+
+// NewFilterBlueprintV2 creates the version 2 representation of a filter blueprint, whose links are relative to the provided host.
+// Version 2 of the API does not serve the dimensions of a filter blueprint as sub-resources, so they are returned with their options instead of links.
+func NewFilterBlueprintV2(filter *Filter, host string) *FilterBlueprintV2 {
+	blueprint := &FilterBlueprintV2{
+		ID:                    filter.FilterID,
+		Dataset:               filter.Dataset,
+		Dimensions:            make([]DimensionV2, 0, len(filter.Dimensions)),
+		Published:             filter.Published != nil && *filter.Published == Published,
+		Language:              filter.Language,
+		NewerVersionAvailable: filter.NewerVersionAvailable,
+		Links: FilterBlueprintLinksV2{
+			Self:    &LinkObject{HRef: fmt.Sprintf("%s/v2/filters/%s", host, filter.FilterID), ID: filter.FilterID},
+			Version: versionLinkV2(filter),
+		},
+	}
+
+	if filter.Links.FilterOutput != nil && filter.Links.FilterOutput.ID != "" {
+		blueprint.Links.FilterOutput = &LinkObject{
+			HRef: fmt.Sprintf("%s/v2/filter-outputs/%s", host, filter.Links.FilterOutput.ID),
+			ID:   filter.Links.FilterOutput.ID,
+		}
+	}
+
+	for _, dimension := range filter.Dimensions {
+		blueprint.Dimensions = append(blueprint.Dimensions, DimensionV2{
+			Name:    dimension.Name,
+			Mode:    dimension.Mode,
+			Options: dimension.Options,
+			Ranges:  dimension.Ranges,
+		})
+	}
+	return blueprint
+}
+
+// NewFilterOutputV2 creates the version 2 representation of a filter output, whose links are relative to the provided host
+func NewFilterOutputV2(output *Filter, host string) *FilterOutputV2 {
+	filterOutput := &FilterOutputV2{
+		ID:         output.FilterID,
+		Dataset:    output.Dataset,
+		Dimensions: make([]DimensionV2, 0, len(output.Dimensions)),
+		State:      output.State,
+		Published:  output.Published != nil && *output.Published == Published,
+		Downloads:  output.Downloads,
+		Events:     output.Events,
+		Links: FilterOutputLinksV2{
+			Self:    &LinkObject{HRef: fmt.Sprintf("%s/v2/filter-outputs/%s", host, output.FilterID), ID: output.FilterID},
+			Version: versionLinkV2(output),
+		},
+	}
+
+	if output.Links.FilterBlueprint != nil && output.Links.FilterBlueprint.ID != "" {
+		filterOutput.Links.FilterBlueprint = &LinkObject{
+			HRef: fmt.Sprintf("%s/v2/filters/%s", host, output.Links.FilterBlueprint.ID),
+			ID:   output.Links.FilterBlueprint.ID,
+		}
+	}
+
+	for _, dimension := range output.Dimensions {
+		filterOutput.Dimensions = append(filterOutput.Dimensions, DimensionV2{
+			Name:    dimension.Name,
+			Mode:    dimension.Mode,
+			Options: dimension.Options,
+			Ranges:  dimension.Ranges,
+		})
+	}
+	return filterOutput
+}
+
+// versionLinkV2 returns the link to the dataset version of a filter blueprint or output, identified by its version number
+func versionLinkV2(filter *Filter) *LinkObject {
+	if filter.Links.Version == nil || filter.Links.Version.HRef == "" {
+		return nil
+	}
+
+	link := &LinkObject{HRef: filter.Links.Version.HRef, ID: filter.Links.Version.ID}
+	if link.ID == "" && filter.Dataset != nil {
+		link.ID = strconv.Itoa(filter.Dataset.Version)
+	}
+	return link
+}
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestNewFilterBlueprintV2(t *testing.T) {
+	Convey("Given a published filter blueprint with a filter output and a dimension", t, func() {
+		filter := &Filter{
+			FilterID:   "blueprint1",
+			InstanceID: "instance1",
+			Dataset:    &Dataset{ID: "cpih01", Edition: "time-series", Version: 3},
+			Published:  &Published,
+			Dimensions: []Dimension{{Name: "time", Options: []string{"2014", "2015"}}},
+			Links: LinkMap{
+				Version:      &LinkObject{HRef: "http://localhost:22000/datasets/cpih01/editions/time-series/versions/3"},
+				FilterOutput: &LinkObject{ID: "output1"},
+			},
+		}
+
+		Convey("When its version 2 representation is created", func() {
+			blueprint := NewFilterBlueprintV2(filter, "http://localhost:22100")
+
+			Convey("Then it links to itself and its filter output with version 2 URLs", func() {
+				So(blueprint.ID, ShouldEqual, "blueprint1")
+				So(blueprint.Published, ShouldBeTrue)
+				So(blueprint.Links.Self, ShouldResemble, &LinkObject{HRef: "http://localhost:22100/v2/filters/blueprint1", ID: "blueprint1"})
+				So(blueprint.Links.FilterOutput, ShouldResemble, &LinkObject{HRef: "http://localhost:22100/v2/filter-outputs/output1", ID: "output1"})
+			})
+
+			Convey("Then its version link is identified by the version number", func() {
+				So(blueprint.Links.Version, ShouldResemble, &LinkObject{HRef: "http://localhost:22000/datasets/cpih01/editions/time-series/versions/3", ID: "3"})
+			})
+
+			Convey("Then its dimensions list their options instead of linking to version 1 of the API", func() {
+				So(blueprint.Dimensions, ShouldResemble, []DimensionV2{{Name: "time", Options: []string{"2014", "2015"}}})
+			})
+		})
+	})
+
+	Convey("Given an unpublished filter blueprint that has not been submitted", t, func() {
+		filter := &Filter{FilterID: "blueprint1"}
+
+		Convey("When its version 2 representation is created", func() {
+			blueprint := NewFilterBlueprintV2(filter, "http://localhost:22100")
+
+			Convey("Then it is unpublished, with no filter output or version link", func() {
+				So(blueprint.Published, ShouldBeFalse)
+				So(blueprint.Dimensions, ShouldBeEmpty)
+				So(blueprint.Links.FilterOutput, ShouldBeNil)
+				So(blueprint.Links.Version, ShouldBeNil)
+			})
+		})
+	})
+}
+
+func TestNewFilterOutputV2(t *testing.T) {
+	Convey("Given a completed filter output created from a filter blueprint", t, func() {
+		output := &Filter{
+			FilterID:   "output1",
+			InstanceID: "instance1",
+			Dataset:    &Dataset{ID: "cpih01", Edition: "time-series", Version: 3},
+			State:      "completed",
+			Dimensions: []Dimension{{Name: "time", Options: []string{"2014", "2015"}}},
+			Downloads:  &Downloads{CSV: &DownloadItem{HRef: "http://localhost:23600/downloads/filter-outputs/output1.csv", Size: "12"}},
+			Links: LinkMap{
+				FilterBlueprint: &LinkObject{ID: "blueprint1"},
+				Version:         &LinkObject{HRef: "http://localhost:22000/datasets/cpih01/editions/time-series/versions/3", ID: "3"},
+			},
+		}
+
+		Convey("When its version 2 representation is created", func() {
+			filterOutput := NewFilterOutputV2(output, "http://localhost:22100")
+
+			Convey("Then it links to itself and its filter blueprint with version 2 URLs", func() {
+				So(filterOutput.ID, ShouldEqual, "output1")
+				So(filterOutput.State, ShouldEqual, "completed")
+				So(filterOutput.Published, ShouldBeFalse)
+				So(filterOutput.Links.Self, ShouldResemble, &LinkObject{HRef: "http://localhost:22100/v2/filter-outputs/output1", ID: "output1"})
+				So(filterOutput.Links.FilterBlueprint, ShouldResemble, &LinkObject{HRef: "http://localhost:22100/v2/filters/blueprint1", ID: "blueprint1"})
+				So(filterOutput.Links.Version.ID, ShouldEqual, "3")
+			})
+
+			Convey("Then its dimensions list their options, and its downloads are returned", func() {
+				So(filterOutput.Dimensions, ShouldResemble, []DimensionV2{{Name: "time", Options: []string{"2014", "2015"}}})
+				So(filterOutput.Downloads, ShouldEqual, output.Downloads)
+			})
+		})
+	})
+}
@@ -0,0 +1,176 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Reasons a dimension of a filter fails validation
+const (
+	ValidationReasonDimensionNotFound = "dimension_not_found"
+	ValidationReasonOptionsNotFound   = "options_not_found"
+	ValidationReasonInvalidSelection  = "invalid_selection"
+)
+
+const (
+	// maxSuggestions is the maximum number of alternatives suggested for an invalid dimension or option
+	maxSuggestions = 5
+	// maxSuggestedValues is the maximum number of invalid values that alternatives are suggested for
+	maxSuggestedValues = 10
+	// maxSuggestionCandidates is the maximum number of candidates that are compared with the invalid values
+	maxSuggestionCandidates = 5000
+	// maxSuggestionLength is the maximum length of the values and candidates that are compared
+	maxSuggestionLength = 64
+)
+
+// ValidationError describes why a dimension of a filter, or some of its options, failed validation against the dataset version.
+// Options holds the invalid option codes, and Suggestions the dimension names or option codes of the dataset version that are close to the invalid ones.
+type ValidationError struct {
+	Dimension   string   `json:"dimension"`
+	Reason      string   `json:"reason"`
+	Message     string   `json:"message"`
+	Options     []string `json:"options,omitempty"`
+	Suggestions []string `json:"suggestions,omitempty"`
+}
+
+// ValidationErrors holds the validation errors of every invalid dimension of a filter
+type ValidationErrors struct {
+	Errors []ValidationError `json:"errors"`
+}
+
+// Error summarises the invalid dimensions and options, listing the invalid dimension names and option codes together
+func (e ValidationErrors) Error() string {
+	var dimensions, options, messages []string
+	for _, validationError := range e.Errors {
+		switch validationError.Reason {
+		case ValidationReasonDimensionNotFound:
+			dimensions = append(dimensions, validationError.Dimension)
+		case ValidationReasonOptionsNotFound:
+			options = append(options, validationError.Options...)
+		default:
+			messages = append(messages, validationError.Message)
+		}
+	}
+
+	summary := []string{}
+	if len(dimensions) > 0 {
+		summary = append(summary, fmt.Sprintf("incorrect dimensions chosen: %v", dimensions))
+	}
+	if len(options) > 0 {
+		summary = append(summary, fmt.Sprintf("incorrect dimension options chosen: %v", options))
+	}
+	return strings.Join(append(summary, messages...), "; ")
+}
+
+// ProblemCode returns the machine-readable code of the problem the validation errors are responded with
+func (e ValidationErrors) ProblemCode() string {
+	return "invalid_dimensions"
+}
+
+// ErrorList returns the validation errors of every invalid dimension, rendered in the errors member of the problem
+func (e ValidationErrors) ErrorList() interface{} {
+	return e.Errors
+}
+
+// NewDimensionNotFoundError returns the validation error of a dimension that is not in the dataset version,
+// suggesting the dataset dimensions with a similar name
+func NewDimensionNotFoundError(dimension string, datasetDimensions []string) ValidationError {
+	return ValidationError{
+		Dimension:   dimension,
+		Reason:      ValidationReasonDimensionNotFound,
+		Message:     fmt.Sprintf("dimension %s is not a dimension of the dataset version", dimension),
+		Suggestions: SuggestAlternatives([]string{dimension}, datasetDimensions),
+	}
+}
+
+// NewOptionsNotFoundError returns the validation error of the options that are not options of the dimension in the dataset version,
+// suggesting the dimension options with a similar code
+func NewOptionsNotFoundError(dimension string, options, dimensionOptions []string) ValidationError {
+	return ValidationError{
+		Dimension:   dimension,
+		Reason:      ValidationReasonOptionsNotFound,
+		Message:     fmt.Sprintf("options %v are not options of dimension %s in the dataset version", options, dimension),
+		Options:     options,
+		Suggestions: SuggestAlternatives(options, dimensionOptions),
+	}
+}
+
+// NewInvalidSelectionError returns the validation error of a dimension whose selection is not valid for any other reason
+func NewInvalidSelectionError(dimension string, err error) ValidationError {
+	return ValidationError{
+		Dimension: dimension,
+		Reason:    ValidationReasonInvalidSelection,
+		Message:   err.Error(),
+	}
+}
+
+// SuggestAlternatives returns the candidates that are close to any of the provided values, in the order of the candidates.
+// A candidate is close to a value if they only differ in case, the shorter one of at least 3 characters is contained in the other,
+// or they are a few edits apart, allowing one edit for every 3 characters of the value, up to 2 edits.
+// To bound the cost, only the first values and candidates are compared, and longer ones are ignored.
+func SuggestAlternatives(values, candidates []string) []string {
+	values = values[:min(len(values), maxSuggestedValues)]
+	candidates = candidates[:min(len(candidates), maxSuggestionCandidates)]
+
+	var suggestions []string
+	for _, candidate := range candidates {
+		if len(suggestions) == maxSuggestions {
+			break
+		}
+		if len(candidate) > maxSuggestionLength || slices.Contains(values, candidate) {
+			continue
+		}
+		if slices.ContainsFunc(values, func(value string) bool { return isClose(value, candidate) }) {
+			suggestions = append(suggestions, candidate)
+		}
+	}
+	return suggestions
+}
+
+func isClose(value, candidate string) bool {
+	if value == "" || candidate == "" || len(value) > maxSuggestionLength {
+		return false
+	}
+	value, candidate = strings.ToLower(value), strings.ToLower(candidate)
+	if min(len(value), len(candidate)) >= 3 && (strings.Contains(candidate, value) || strings.Contains(value, candidate)) {
+		return true
+	}
+	maxEdits := min(max(1, len(value)/3), 2)
+	// the edit distance is at least the difference in length, so it is not computed when that is already too large
+	if abs(utf8.RuneCountInString(value)-utf8.RuneCountInString(candidate)) > maxEdits {
+		return false
+	}
+	return editDistance(value, candidate) <= maxEdits
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// editDistance returns the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
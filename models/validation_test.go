@@ -0,0 +1,87 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ONSdigital/dp-api-clients-go/v2/dataset"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestValidateFilterDimensions(t *testing.T) {
+	datasetDimensions := &dataset.VersionDimensions{Items: []dataset.VersionDimension{{Name: "age"}, {Name: "sex"}, {Name: "geography"}}}
+
+	Convey("When every filter dimension is a dimension of the dataset version, no error is returned", t, func() {
+		So(ValidateFilterDimensions([]Dimension{{Name: "age"}, {Name: "geography"}}, datasetDimensions), ShouldBeNil)
+	})
+
+	Convey("When several filter dimensions are not dimensions of the dataset version, a validation error is returned for each of them", t, func() {
+		err := ValidateFilterDimensions([]Dimension{{Name: "Age"}, {Name: "sex"}, {Name: "weight"}}, datasetDimensions)
+		So(err, ShouldResemble, ValidationErrors{Errors: []ValidationError{
+			{
+				Dimension:   "Age",
+				Reason:      ValidationReasonDimensionNotFound,
+				Message:     "dimension Age is not a dimension of the dataset version",
+				Suggestions: []string{"age"},
+			},
+			{
+				Dimension: "weight",
+				Reason:    ValidationReasonDimensionNotFound,
+				Message:   "dimension weight is not a dimension of the dataset version",
+			},
+		}})
+		So(err.Error(), ShouldEqual, "incorrect dimensions chosen: [Age weight]")
+	})
+}
+
+func TestValidationErrors(t *testing.T) {
+	Convey("Given validation errors for every reason", t, func() {
+		err := ValidationErrors{Errors: []ValidationError{
+			NewOptionsNotFoundError("age", []string{"29", "wrong"}, []string{"27", "33"}),
+			NewDimensionNotFoundError("weight", []string{"age"}),
+			NewInvalidSelectionError("time", ErrorNoData),
+		}}
+
+		Convey("Then the error summarises the invalid dimensions, the invalid options and any other validation error", func() {
+			So(err.Error(), ShouldEqual, "incorrect dimensions chosen: [weight]; incorrect dimension options chosen: [29 wrong]; "+ErrorNoData.Error())
+		})
+	})
+}
+
+func TestSuggestAlternatives(t *testing.T) {
+	Convey("Candidates that only differ in case or are a few edits apart are suggested", t, func() {
+		So(SuggestAlternatives([]string{"Geography"}, []string{"geography", "age"}), ShouldResemble, []string{"geography"})
+		So(SuggestAlternatives([]string{"K0200001"}, []string{"K02000001", "E92000001"}), ShouldResemble, []string{"K02000001"})
+		So(SuggestAlternatives([]string{"29"}, []string{"27", "33", "290"}), ShouldResemble, []string{"27", "290"})
+	})
+
+	Convey("Candidates containing a value of at least 3 characters are suggested", t, func() {
+		So(SuggestAlternatives([]string{"sex"}, []string{"sex_at_birth", "age"}), ShouldResemble, []string{"sex_at_birth"})
+		So(SuggestAlternatives([]string{"1"}, []string{"10", "111", "K1"}), ShouldResemble, []string{"10", "K1"})
+	})
+
+	Convey("Candidates equal to a value are not suggested, and the suggestions are limited", t, func() {
+		So(SuggestAlternatives([]string{"27"}, []string{"27"}), ShouldBeEmpty)
+		So(SuggestAlternatives([]string{"a"}, []string{"b", "c", "d", "e", "f", "g"}), ShouldHaveLength, maxSuggestions)
+	})
+
+	Convey("Only the first values and candidates are compared, and longer ones are ignored", t, func() {
+		values := make([]string, maxSuggestedValues+1)
+		for i := range values {
+			values[i] = "value"
+		}
+		values[maxSuggestedValues] = "27"
+		So(SuggestAlternatives(values, []string{"28"}), ShouldBeEmpty)
+
+		candidates := make([]string, maxSuggestionCandidates+1)
+		for i := range candidates {
+			candidates[i] = "candidate"
+		}
+		candidates[maxSuggestionCandidates] = "28"
+		So(SuggestAlternatives([]string{"27"}, candidates), ShouldBeEmpty)
+
+		long := strings.Repeat("a", maxSuggestionLength+1)
+		So(SuggestAlternatives([]string{long}, []string{long + "b"}), ShouldBeEmpty)
+		So(SuggestAlternatives([]string{"aaa"}, []string{long}), ShouldBeEmpty)
+	})
+}
@@ -1,7 +1,9 @@
 package mongo
 
 import (
+	"crypto/sha1"
 	"fmt"
+	"time"
 
 	"github.com/ONSdigital/dp-filter-api/models"
 	"go.mongodb.org/mongo-driver/bson"
@@ -26,6 +28,14 @@ func newETagForAddDimensions(currentFilter *models.Filter, filterID string, dims
 	return currentFilter.Hash(b)
 }
 
+func newETagForUpdateDimension(currentFilter *models.Filter, filterID string, dimension models.Dimension) (eTag string, err error) {
+	b, err := bson.Marshal(dimension)
+	if err != nil {
+		return "", err
+	}
+	return currentFilter.Hash(b)
+}
+
 func newETagForRemoveDimension(currentFilter *models.Filter, filterID, dimensionNameToRemove string) (eTag string, err error) {
 	b := []byte(fmt.Sprintf("RemoveDimension %s", dimensionNameToRemove))
 	return currentFilter.Hash(b)
@@ -40,3 +50,11 @@ func newETagForRemoveDimensionOptions(currentFilter *models.Filter, filterID, di
 	b := []byte(fmt.Sprintf("RemoveDimensionOptions %v", options))
 	return currentFilter.Hash(b)
 }
+
+// newETagForPublish generates the eTag of the filter blueprints of an instance once they are published in bulk.
+// It is the same for all of them, which is fine as eTags only need to differ between the states of each filter blueprint.
+func newETagForPublish(instanceID string, publishedAt time.Time) string {
+	h := sha1.New()
+	fmt.Fprintf(h, "Publish %s %d", instanceID, publishedAt.UnixNano())
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
@@ -2,6 +2,7 @@ package mongo
 
 import (
 	"testing"
+	"time"
 
 	"github.com/ONSdigital/dp-filter-api/models"
 	. "github.com/smartystreets/goconvey/convey"
@@ -99,6 +100,15 @@ func TestGetNewETagForDimensionOperations(t *testing.T) {
 				So(err, ShouldBeNil)
 				So(eTag3, ShouldNotEqual, eTag1)
 			})
+
+			Convey("Adding the same dimension in exclude mode results in a different ETag", func() {
+				excludeDims := []models.Dimension{
+					{URL: "url1", Name: "dim1", Mode: models.DimensionModeExclude},
+				}
+				eTag4, err := newETagForAddDimensions(currentFilter, testFilterID, excludeDims)
+				So(err, ShouldBeNil)
+				So(eTag4, ShouldNotEqual, eTag1)
+			})
 		})
 
 		Convey("getNewETagForRemoveDimension returns an eTag that is different from the original filter ETag", func() {
@@ -145,6 +155,14 @@ func TestGetNewETagForDimensionOptionsOperations(t *testing.T) {
 				So(eTag3, ShouldNotEqual, eTag1)
 			})
 
+			Convey("Applying the same update to a filter whose dimension is in exclude mode results in a different ETag", func() {
+				filter2 := testFilter()
+				filter2.Dimensions[0].Mode = models.DimensionModeExclude
+				eTag2, err := newETagForAddDimensionOptions(filter2, testFilterID, testDimensionName, []string{"op4", "op5"})
+				So(err, ShouldBeNil)
+				So(eTag2, ShouldNotEqual, eTag1)
+			})
+
 			Convey("Removing the same dimensions from the same filter results in a different ETag", func() {
 				eTag4, err := newETagForRemoveDimensionOptions(currentFilter, testFilterID, testDimensionName, []string{"op4", "op5"})
 				So(err, ShouldBeNil)
@@ -179,3 +197,53 @@ func TestGetNewETagForDimensionOptionsOperations(t *testing.T) {
 		})
 	})
 }
+
+func TestGetNewETagForUpdateDimension(t *testing.T) {
+	Convey("Given a filter with a dimension that we want to replace", t, func() {
+		currentFilter := testFilter()
+		dimension := models.Dimension{Name: testDimensionName, Options: []string{"op1"}, Ranges: []models.OptionRange{{From: "op2", To: "op3"}}}
+
+		Convey("newETagForUpdateDimension returns an eTag that is different from the original filter ETag", func() {
+			eTag1, err := newETagForUpdateDimension(currentFilter, testFilterID, dimension)
+			So(err, ShouldBeNil)
+			So(eTag1, ShouldNotEqual, currentFilter.ETag)
+
+			Convey("Applying the same update to a different filter results in a different ETag", func() {
+				filter2 := testFilter()
+				filter2.FilterID = "otherFilter"
+				eTag2, err := newETagForUpdateDimension(filter2, testFilterID, dimension)
+				So(err, ShouldBeNil)
+				So(eTag2, ShouldNotEqual, eTag1)
+			})
+
+			Convey("Applying an update with different ranges to the same filter results in a different ETag", func() {
+				dimension.Ranges = []models.OptionRange{{From: "op2", To: "op2"}}
+				eTag3, err := newETagForUpdateDimension(currentFilter, testFilterID, dimension)
+				So(err, ShouldBeNil)
+				So(eTag3, ShouldNotEqual, eTag1)
+			})
+		})
+	})
+}
+
+func TestGetNewETagForPublish(t *testing.T) {
+	Convey("Given a filter whose instance is published", t, func() {
+		currentFilter := testFilter()
+		publishedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+		Convey("newETagForPublish returns an eTag that is different from the original filter ETag", func() {
+			eTag1 := newETagForPublish(testInstanceID, publishedAt)
+			So(eTag1, ShouldNotEqual, currentFilter.ETag)
+
+			Convey("Publishing the same instance again later results in a different ETag", func() {
+				eTag2 := newETagForPublish(testInstanceID, publishedAt.Add(time.Second))
+				So(eTag2, ShouldNotEqual, eTag1)
+			})
+
+			Convey("Publishing a different instance at the same time results in a different ETag", func() {
+				eTag3 := newETagForPublish("anotherInstanceID", publishedAt)
+				So(eTag3, ShouldNotEqual, eTag1)
+			})
+		})
+	})
+}
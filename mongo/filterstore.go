@@ -15,6 +15,7 @@ import (
 	mongodriver "github.com/ONSdigital/dp-mongodb/v3/mongodb"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 // FilterStore containing all filter jobs stored in mongodb
@@ -28,11 +29,11 @@ type FilterStore struct {
 }
 
 // CreateFilterStore which can store, update and fetch filter jobs
-func CreateFilterStore(cfg config.MongoConfig, host string) (*FilterStore, error) {
+func CreateFilterStore(ctx context.Context, cfg *config.Config) (*FilterStore, error) {
 	var (
 		filterStore = &FilterStore{
 			MongoDriverConfig: cfg.MongoDriverConfig,
-			URI:               host,
+			URI:               cfg.Host,
 		}
 		err error
 	)
@@ -42,9 +43,17 @@ func CreateFilterStore(cfg config.MongoConfig, host string) (*FilterStore, error
 		return nil, err
 	}
 
+	indexCtx, cancel := context.WithTimeout(ctx, cfg.QueryTimeout)
+	defer cancel()
+	if err = filterStore.createIndexes(indexCtx, cfg.FilterHistoryTTL, cfg.IdempotencyKeyTTL); err != nil {
+		return nil, fmt.Errorf("failed to create indexes: %w", err)
+	}
+
 	databaseCollectionBuilder := map[mongohealth.Database][]mongohealth.Collection{mongohealth.Database(cfg.Database): {
 		mongohealth.Collection(filterStore.ActualCollectionName(config.FiltersCollection)),
-		mongohealth.Collection(filterStore.ActualCollectionName(config.OutputsCollection))}}
+		mongohealth.Collection(filterStore.ActualCollectionName(config.OutputsCollection)),
+		mongohealth.Collection(filterStore.ActualCollectionName(config.FilterHistoryCollection)),
+		mongohealth.Collection(filterStore.ActualCollectionName(config.IdempotencyCollection))}}
 	filterStore.healthCheckClient = mongohealth.NewClientWithCollections(filterStore.Connection, databaseCollectionBuilder)
 
 	return filterStore, nil
@@ -110,6 +119,11 @@ func (s *FilterStore) UpdateFilter(ctx context.Context, updatedFilter *models.Fi
 		return "", err
 	}
 
+	// keep a snapshot of the current state, so that the filter can be restored to it later
+	if err := s.saveSnapshot(ctx, currentFilter); err != nil {
+		return "", err
+	}
+
 	// create selector query
 	selector := selector(updatedFilter.FilterID, "", timestamp, eTagSelector)
 
@@ -137,6 +151,102 @@ func (s *FilterStore) UpdateFilter(ctx context.Context, updatedFilter *models.Fi
 	return newETag, nil
 }
 
+// ReplaceFilter replaces the dataset, instance, dimensions and version link of a filter blueprint.
+func (s *FilterStore) ReplaceFilter(ctx context.Context, updatedFilter *models.Filter, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error) {
+	// calculate the new eTag hash for the filter that would result from replacing it
+	newETag, err := newETagForUpdate(currentFilter, updatedFilter)
+	if err != nil {
+		return "", err
+	}
+
+	// keep a snapshot of the current state, so that the filter can be restored to it later
+	if err := s.saveSnapshot(ctx, currentFilter); err != nil {
+		return "", err
+	}
+
+	dimensions := updatedFilter.Dimensions
+	if dimensions == nil {
+		dimensions = []models.Dimension{}
+	}
+
+	// create update query from updatedFilter and newly generated eTag
+	update, err := mongodriver.WithUpdates(bson.M{
+		"$set": bson.M{
+			"dataset":       updatedFilter.Dataset,
+			"instance_id":   updatedFilter.InstanceID,
+			"dimensions":    dimensions,
+			"published":     updatedFilter.Published,
+			"links.version": updatedFilter.Links.Version,
+			"e_tag":         newETag,
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// execute the update against MongoDB to atomically check and update the filter
+	if _, err := s.Connection.Collection(s.ActualCollectionName(config.FiltersCollection)).Must().Update(ctx, selector(updatedFilter.FilterID, "", timestamp, eTagSelector), update); err != nil {
+		if errors.Is(err, mongodriver.ErrNoDocumentFound) {
+			return "", filters.ErrFilterBlueprintConflict
+		}
+		return "", err
+	}
+
+	return newETag, nil
+}
+
+// PublishFilterBlueprints marks every unpublished filter blueprint for the provided instance as published in a single update,
+// returning the number of filter blueprints that have been updated. The eTag of each of them changes, so that they are not
+// served from caches as unpublished, and their unique timestamp too, so that any concurrent update conflicts instead of
+// reverting the published flag.
+func (s *FilterStore) PublishFilterBlueprints(ctx context.Context, instanceID string) (int, error) {
+	update, err := mongodriver.WithUpdates(bson.M{
+		"$set": bson.M{
+			"published": models.Published,
+			"e_tag":     newETagForPublish(instanceID, time.Now().UTC()),
+		},
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	query := bson.M{"instance_id": instanceID, "published": bson.M{"$ne": true}}
+	result, err := s.Connection.Collection(s.ActualCollectionName(config.FiltersCollection)).UpdateMany(ctx, query, update)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// GetFilterSnapshot returns the state of a filter blueprint at the time the provided eTag was generated
+func (s *FilterStore) GetFilterSnapshot(ctx context.Context, filterID, eTag string) (*models.FilterSnapshot, error) {
+	var result models.FilterSnapshot
+
+	query := bson.M{"filter_id": filterID, "e_tag": eTag}
+	if err := s.Connection.Collection(s.ActualCollectionName(config.FilterHistoryCollection)).FindOne(ctx, query, &result); err != nil {
+		if errors.Is(err, mongodriver.ErrNoDocumentFound) {
+			return nil, filters.ErrFilterSnapshotNotFound
+		}
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// saveSnapshot stores the provided filter blueprint state in the history collection, keyed by its eTag
+func (s *FilterStore) saveSnapshot(ctx context.Context, currentFilter *models.Filter) error {
+	if currentFilter == nil || currentFilter.ETag == "" {
+		return nil
+	}
+
+	snapshot := models.NewFilterSnapshot(currentFilter)
+	_, err := s.Connection.Collection(s.ActualCollectionName(config.FilterHistoryCollection)).Upsert(ctx,
+		bson.M{"filter_id": snapshot.FilterID, "e_tag": snapshot.ETag},
+		bson.M{"$set": snapshot})
+	return err
+}
+
 // GetFilterDimension return a single dimension, along with the filter eTag hash
 func (s *FilterStore) GetFilterDimension(ctx context.Context, filterID string, name, eTagSelector string) (*models.Dimension, error) {
 	var result models.Filter
@@ -156,9 +266,9 @@ func (s *FilterStore) GetFilterDimension(ctx context.Context, filterID string, n
 }
 
 // AddFilterDimension to a filter
-func (s *FilterStore) AddFilterDimension(ctx context.Context, filterID, name string, options []string, dimensions []models.Dimension, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error) {
+func (s *FilterStore) AddFilterDimension(ctx context.Context, filterID, name, mode string, options []string, ranges []models.OptionRange, dimensions []models.Dimension, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error) {
 	url := fmt.Sprintf("%s/filters/%s/dimensions/%s", s.URI, filterID, name)
-	d := models.Dimension{Name: name, Options: options, URL: url}
+	d := models.Dimension{Name: name, Mode: mode, Options: options, Ranges: ranges, URL: url}
 
 	list := dimensions
 	var found bool
@@ -183,6 +293,11 @@ func (s *FilterStore) AddFilterDimension(ctx context.Context, filterID, name str
 		return "", err
 	}
 
+	// keep a snapshot of the current state, so that the filter can be restored to it later
+	if err := s.saveSnapshot(ctx, currentFilter); err != nil {
+		return "", err
+	}
+
 	// define update query
 	update, err := mongodriver.WithUpdates(bson.M{
 		"$set": bson.M{"dimensions": list, "e_tag": newETag},
@@ -202,6 +317,41 @@ func (s *FilterStore) AddFilterDimension(ctx context.Context, filterID, name str
 	return newETag, nil
 }
 
+// UpdateFilterDimension replaces an existing dimension of a filter with the provided one
+func (s *FilterStore) UpdateFilterDimension(ctx context.Context, filterID string, dimension models.Dimension, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error) {
+	// define selector query
+	selector := selector(filterID, dimension.Name, timestamp, eTagSelector)
+
+	// calculate the new eTag hash for the filter that would result from replacing the dimension
+	newETag, err := newETagForUpdateDimension(currentFilter, filterID, dimension)
+	if err != nil {
+		return "", err
+	}
+
+	// keep a snapshot of the current state, so that the filter can be restored to it later
+	if err := s.saveSnapshot(ctx, currentFilter); err != nil {
+		return "", err
+	}
+
+	// define update query
+	update, err := mongodriver.WithUpdates(bson.M{
+		"$set": bson.M{"dimensions.$": dimension, "e_tag": newETag},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// execute update
+	if _, err := s.Connection.Collection(s.ActualCollectionName(config.FiltersCollection)).Must().Update(ctx, selector, update); err != nil {
+		if errors.Is(err, mongodriver.ErrNoDocumentFound) {
+			return "", filters.ErrFilterBlueprintConflict
+		}
+		return "", err
+	}
+
+	return newETag, nil
+}
+
 // RemoveFilterDimension from a filter
 func (s *FilterStore) RemoveFilterDimension(ctx context.Context, filterID, name string, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error) {
 	// define selector query
@@ -213,6 +363,11 @@ func (s *FilterStore) RemoveFilterDimension(ctx context.Context, filterID, name
 		return "", err
 	}
 
+	// keep a snapshot of the current state, so that the filter can be restored to it later
+	if err := s.saveSnapshot(ctx, currentFilter); err != nil {
+		return "", err
+	}
+
 	// define update query
 	update, err := mongodriver.WithUpdates(bson.M{
 		"$pull": bson.M{"dimensions": bson.M{"name": name}},
@@ -255,6 +410,11 @@ func (s *FilterStore) AddFilterDimensionOptions(ctx context.Context, filterID, n
 		return "", err
 	}
 
+	// keep a snapshot of the current state, so that the filter can be restored to it later
+	if err := s.saveSnapshot(ctx, currentFilter); err != nil {
+		return "", err
+	}
+
 	// define update query
 	update, err := mongodriver.WithUpdates(bson.M{
 		"$addToSet": bson.M{"dimensions.$.options": bson.M{"$each": options}},
@@ -286,6 +446,11 @@ func (s *FilterStore) RemoveFilterDimensionOption(ctx context.Context, filterID
 		return "", err
 	}
 
+	// keep a snapshot of the current state, so that the filter can be restored to it later
+	if err := s.saveSnapshot(ctx, currentFilter); err != nil {
+		return "", err
+	}
+
 	// define update query
 	update, err := mongodriver.WithUpdates(bson.M{
 		"$pull": bson.M{"dimensions.$.options": option},
@@ -324,6 +489,11 @@ func (s *FilterStore) RemoveFilterDimensionOptions(ctx context.Context, filterID
 		return "", err
 	}
 
+	// keep a snapshot of the current state, so that the filter can be restored to it later
+	if err := s.saveSnapshot(ctx, currentFilter); err != nil {
+		return "", err
+	}
+
 	// define update query
 	update, err := mongodriver.WithUpdates(bson.M{
 		"$pull": bson.M{"dimensions.$.options": bson.M{"$in": options}},
@@ -426,6 +596,111 @@ func (s *FilterStore) AddEventToFilterOutput(ctx context.Context, filterOutputID
 	return nil
 }
 
+// ReserveIdempotencyRecord stores the record of a request made with an idempotency key before the request is processed,
+// so that concurrent requests with the same key are not processed too, replacing any record created before expiredBefore,
+// and any record of a request still in progress that was created before leaseExpiredBefore, as that request has been abandoned.
+// If a record with the same key has not expired, it is returned instead, and nothing is stored.
+func (s *FilterStore) ReserveIdempotencyRecord(ctx context.Context, record *models.IdempotencyRecord, expiredBefore, leaseExpiredBefore time.Time) (*models.IdempotencyRecord, error) {
+	// a record that has not expired is not matched, so the unique index on scope and key makes the upsert fail
+	query := bson.M{
+		"scope": record.Scope,
+		"key":   record.Key,
+		"$or": bson.A{
+			bson.M{"created_at": bson.M{"$lt": expiredBefore}},
+			bson.M{"in_progress": true, "created_at": bson.M{"$lt": leaseExpiredBefore}},
+		},
+	}
+	_, err := s.Connection.Collection(s.ActualCollectionName(config.IdempotencyCollection)).Upsert(ctx, query, bson.M{"$set": record})
+	if err == nil {
+		return nil, nil
+	}
+	if !mongo.IsDuplicateKeyError(err) {
+		return nil, err
+	}
+
+	existing, err := s.getIdempotencyRecord(ctx, record.Scope, record.Key)
+	if errors.Is(err, filters.ErrIdempotencyRecordNotFound) {
+		// the record has just been released by a failed request, so the key can be used again once it has completed
+		return nil, filters.ErrIdempotencyKeyInUse
+	}
+	return existing, err
+}
+
+// getIdempotencyRecord returns the record of a request made with the provided idempotency key
+func (s *FilterStore) getIdempotencyRecord(ctx context.Context, scope, key string) (*models.IdempotencyRecord, error) {
+	var result models.IdempotencyRecord
+
+	query := bson.M{"scope": scope, "key": key}
+	if err := s.Connection.Collection(s.ActualCollectionName(config.IdempotencyCollection)).FindOne(ctx, query, &result); err != nil {
+		if errors.Is(err, mongodriver.ErrNoDocumentFound) {
+			return nil, filters.ErrIdempotencyRecordNotFound
+		}
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// SaveIdempotencyRecord stores the response of a request made with an idempotency key in its reserved record.
+// Nothing is stored if the reservation has been taken over by a retry, once its lease expired.
+func (s *FilterStore) SaveIdempotencyRecord(ctx context.Context, record *models.IdempotencyRecord) error {
+	result, err := s.Connection.Collection(s.ActualCollectionName(config.IdempotencyCollection)).UpdateOne(ctx,
+		idempotencyReservation(record),
+		bson.M{"$set": record})
+	if err != nil {
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return filters.ErrIdempotencyRecordNotFound
+	}
+	return nil
+}
+
+// DeleteIdempotencyRecord releases the record reserved for a request made with an idempotency key, so that it can be retried.
+// A reservation that has been taken over by a retry, once its lease expired, is left untouched.
+func (s *FilterStore) DeleteIdempotencyRecord(ctx context.Context, record *models.IdempotencyRecord) error {
+	_, err := s.Connection.Collection(s.ActualCollectionName(config.IdempotencyCollection)).DeleteOne(ctx, idempotencyReservation(record))
+	return err
+}
+
+// idempotencyReservation selects the record of a request made with an idempotency key, as long as it is still reserved for that request
+func idempotencyReservation(record *models.IdempotencyRecord) bson.M {
+	return bson.M{"scope": record.Scope, "key": record.Key, "created_at": record.CreatedAt}
+}
+
+// PublishFilterOutputs marks every unpublished filter output for the provided instance as published,
+// adding a published event to each of them and returning their IDs
+func (s *FilterStore) PublishFilterOutputs(ctx context.Context, instanceID string) ([]string, error) {
+	collection := s.Connection.Collection(s.ActualCollectionName(config.OutputsCollection))
+
+	var unpublished []*models.Filter
+	query := bson.M{"instance_id": instanceID, "published": bson.M{"$ne": true}}
+	if _, err := collection.Find(ctx, query, &unpublished, mongodriver.Projection(bson.M{"filter_id": 1})); err != nil {
+		return nil, err
+	}
+
+	if len(unpublished) == 0 {
+		return []string{}, nil
+	}
+
+	ids := make([]string, 0, len(unpublished))
+	for _, output := range unpublished {
+		ids = append(ids, output.FilterID)
+	}
+
+	now := time.Now().UTC()
+	event := &models.Event{Type: models.EventFilterOutputPublished, Time: now}
+	update := bson.M{
+		"$set":  bson.M{"published": models.Published, "last_updated": now},
+		"$push": bson.M{"events": event},
+	}
+	if _, err := collection.UpdateMany(ctx, bson.M{"filter_id": bson.M{"$in": ids}, "published": bson.M{"$ne": true}}, update); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
 func createUpdateFilterOutput(filter *models.Filter) bson.M {
 	var downloads models.Downloads
 	state := models.CreatedState
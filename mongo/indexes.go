@@ -0,0 +1,91 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ONSdigital/dp-filter-api/config"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	// mongo error codes returned when an index already exists with different options, or does not exist
+	indexOptionsConflictCode = 85
+	indexNotFoundCode        = 27
+
+	ttlIndexName = "ttl"
+)
+
+// createIndexes creates the collections that are only written to on demand, along with their indexes,
+// so that they exist for the health check of a fresh deployment and their documents expire after the configured time
+func (s *FilterStore) createIndexes(ctx context.Context, filterHistoryTTL, idempotencyKeyTTL time.Duration) error {
+	// restored filter blueprints are looked up by their filter blueprint ID and eTag
+	if err := s.createIndex(ctx, config.FilterHistoryCollection, bson.M{
+		"name":   "filter_id_e_tag",
+		"key":    bson.D{{Key: "filter_id", Value: 1}, {Key: "e_tag", Value: 1}},
+		"unique": true,
+	}); err != nil {
+		return err
+	}
+
+	if err := s.ensureTTLIndex(ctx, config.FilterHistoryCollection, "last_updated", filterHistoryTTL); err != nil {
+		return err
+	}
+
+	// an idempotency key is reserved by a single request for each scope, so that concurrent retries are not processed
+	if err := s.createIndex(ctx, config.IdempotencyCollection, bson.M{
+		"name":   "scope_key",
+		"key":    bson.D{{Key: "scope", Value: 1}, {Key: "key", Value: 1}},
+		"unique": true,
+	}); err != nil {
+		return err
+	}
+
+	return s.ensureTTLIndex(ctx, config.IdempotencyCollection, "created_at", idempotencyKeyTTL)
+}
+
+// createIndex creates the provided index on a collection, creating the collection if it does not exist yet
+func (s *FilterStore) createIndex(ctx context.Context, collection string, index bson.M) error {
+	return s.Connection.RunCommand(ctx, bson.D{
+		{Key: "createIndexes", Value: s.ActualCollectionName(collection)},
+		{Key: "indexes", Value: []bson.M{index}},
+	})
+}
+
+// ensureTTLIndex expires the documents of a collection once the provided time has passed since the value of field.
+// An existing TTL index is updated if the time has changed, and removed if the time is zero, so that documents are kept.
+func (s *FilterStore) ensureTTLIndex(ctx context.Context, collection, field string, ttl time.Duration) error {
+	if ttl <= 0 {
+		err := s.Connection.RunCommand(ctx, bson.D{
+			{Key: "dropIndexes", Value: s.ActualCollectionName(collection)},
+			{Key: "index", Value: ttlIndexName},
+		})
+		if hasErrorCode(err, indexNotFoundCode) {
+			return nil
+		}
+		return err
+	}
+
+	expireAfterSeconds := int64(ttl.Seconds())
+	err := s.createIndex(ctx, collection, bson.M{
+		"name":               ttlIndexName,
+		"key":                bson.D{{Key: field, Value: 1}},
+		"expireAfterSeconds": expireAfterSeconds,
+	})
+	if !hasErrorCode(err, indexOptionsConflictCode) {
+		return err
+	}
+
+	return s.Connection.RunCommand(ctx, bson.D{
+		{Key: "collMod", Value: s.ActualCollectionName(collection)},
+		{Key: "index", Value: bson.M{"name": ttlIndexName, "expireAfterSeconds": expireAfterSeconds}},
+	})
+}
+
+// hasErrorCode returns true if the provided error is a mongo command error with the provided code
+func hasErrorCode(err error, code int) bool {
+	var commandErr mongo.CommandError
+	return errors.As(err, &commandErr) && commandErr.HasErrorCode(code)
+}
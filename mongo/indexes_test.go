@@ -0,0 +1,30 @@
+package mongo
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+func TestHasErrorCode(t *testing.T) {
+	Convey("Given a mongo command error with an index options conflict code", t, func() {
+		err := fmt.Errorf("failed to create index: %w", mongo.CommandError{Code: indexOptionsConflictCode, Message: "Index with name: ttl already exists with different options"})
+
+		Convey("Then it is identified by its code, even when wrapped", func() {
+			So(hasErrorCode(err, indexOptionsConflictCode), ShouldBeTrue)
+			So(hasErrorCode(err, indexNotFoundCode), ShouldBeFalse)
+		})
+	})
+
+	Convey("Given an error that is not a mongo command error", t, func() {
+		err := errors.New("connection refused")
+
+		Convey("Then it has no error code", func() {
+			So(hasErrorCode(err, indexOptionsConflictCode), ShouldBeFalse)
+			So(hasErrorCode(nil, indexOptionsConflictCode), ShouldBeFalse)
+		})
+	})
+}
@@ -0,0 +1,115 @@
+package observations
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/ONSdigital/dp-api-clients-go/v2/headers"
+	healthcheck "github.com/ONSdigital/dp-api-clients-go/v2/health"
+	"github.com/ONSdigital/log.go/v2/log"
+)
+
+// ErrInvalidObservationsResponse is returned when the dataset API does not respond with the observations
+type ErrInvalidObservationsResponse struct {
+	actualCode int
+	uri        string
+}
+
+// Error returns the stringified version of the error
+func (e ErrInvalidObservationsResponse) Error() string {
+	return fmt.Sprintf("invalid response from dataset api observations - should be: %d, got: %d, path: %s",
+		http.StatusOK,
+		e.actualCode,
+		e.uri,
+	)
+}
+
+// Code returns the status code received from the dataset API
+func (e ErrInvalidObservationsResponse) Code() int {
+	return e.actualCode
+}
+
+// Option represents the option of a dimension that an observation belongs to
+type Option struct {
+	HRef  string `json:"href"`
+	ID    string `json:"id"`
+	Label string `json:"label,omitempty"`
+}
+
+// Observation represents a single observation of a dataset version, along with the option of each wildcard dimension
+type Observation struct {
+	Dimensions  map[string]*Option `json:"dimensions,omitempty"`
+	Metadata    map[string]string  `json:"metadata,omitempty"`
+	Observation string             `json:"observation"`
+}
+
+// Observations represents the observations of a dataset version that match the requested dimension options
+type Observations struct {
+	Dimensions        map[string]Option `json:"dimensions"`
+	Limit             int               `json:"limit"`
+	Observations      []Observation     `json:"observations"`
+	Offset            int               `json:"offset"`
+	TotalObservations int               `json:"total_observations"`
+}
+
+// Client queries the observations endpoint of the dataset API
+type Client struct {
+	hcCli *healthcheck.Client
+}
+
+// NewWithHealthClient creates a new instance of Client, reusing the URL and Clienter from the provided dataset API health check client
+func NewWithHealthClient(hcCli *healthcheck.Client) *Client {
+	return &Client{
+		hcCli: hcCli,
+	}
+}
+
+// GetObservations returns the observations of a dataset version for the provided query, which holds an option code,
+// or a '*' wildcard, for each dimension of the version
+func (c *Client) GetObservations(ctx context.Context, userAuthToken, serviceAuthToken, collectionID, datasetID, edition, version string, query url.Values) (m Observations, err error) {
+	uri := fmt.Sprintf("%s/datasets/%s/editions/%s/versions/%s/observations?%s", c.hcCli.URL, datasetID, edition, version, query.Encode())
+
+	req, err := http.NewRequest(http.MethodGet, uri, http.NoBody)
+	if err != nil {
+		return m, err
+	}
+	if err = headers.SetAuthToken(req, userAuthToken); err != nil && err != headers.ErrValueEmpty {
+		return m, err
+	}
+	if err = headers.SetServiceAuthToken(req, serviceAuthToken); err != nil && err != headers.ErrValueEmpty {
+		return m, err
+	}
+	if err = headers.SetCollectionID(req, collectionID); err != nil && err != headers.ErrValueEmpty {
+		return m, err
+	}
+
+	resp, err := c.hcCli.Client.Do(ctx, req)
+	if err != nil {
+		return m, err
+	}
+	defer closeResponseBody(ctx, resp)
+
+	if resp.StatusCode != http.StatusOK {
+		return m, &ErrInvalidObservationsResponse{actualCode: resp.StatusCode, uri: uri}
+	}
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return m, err
+	}
+	err = json.Unmarshal(b, &m)
+	return m, err
+}
+
+// closeResponseBody closes the response body and logs an error if unsuccessful
+func closeResponseBody(ctx context.Context, resp *http.Response) {
+	if resp.Body != nil {
+		if err := resp.Body.Close(); err != nil {
+			log.Error(ctx, "error closing http response body", err)
+		}
+	}
+}
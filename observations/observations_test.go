@@ -0,0 +1,62 @@
+package observations
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	healthcheck "github.com/ONSdigital/dp-api-clients-go/v2/health"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGetObservations(t *testing.T) {
+	Convey("Given a dataset API that returns the observations of a dataset version", t, func() {
+		var received *http.Request
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received = r
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"observations":[{"dimensions":{"sex":{"id":"female","label":"Female"}},"observation":"10"}],"total_observations":1}`))
+		}))
+		defer server.Close()
+		client := NewWithHealthClient(healthcheck.NewClient("dataset-api", server.URL))
+
+		Convey("When the observations are requested", func() {
+			m, err := client.GetObservations(context.Background(), "", "service-token", "", "123", "2017", "1", url.Values{"age": {"33"}, "sex": {"*"}})
+
+			Convey("Then the observations endpoint of the dataset version is queried", func() {
+				So(err, ShouldBeNil)
+				So(received.URL.Path, ShouldEqual, "/datasets/123/editions/2017/versions/1/observations")
+				So(received.URL.Query().Get("age"), ShouldEqual, "33")
+				So(received.URL.Query().Get("sex"), ShouldEqual, "*")
+				So(received.Header.Get("Authorization"), ShouldEqual, "Bearer service-token")
+			})
+
+			Convey("Then the observations are returned", func() {
+				So(m.TotalObservations, ShouldEqual, 1)
+				So(m.Observations, ShouldResemble, []Observation{
+					{Dimensions: map[string]*Option{"sex": {ID: "female", Label: "Female"}}, Observation: "10"},
+				})
+			})
+		})
+	})
+
+	Convey("Given a dataset API that does not find the observations", t, func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+		client := NewWithHealthClient(healthcheck.NewClient("dataset-api", server.URL))
+
+		Convey("When the observations are requested", func() {
+			_, err := client.GetObservations(context.Background(), "", "", "", "123", "2017", "1", url.Values{"age": {"33"}})
+
+			Convey("Then an error with the status code of the response is returned", func() {
+				apiErr, ok := err.(*ErrInvalidObservationsResponse)
+				So(ok, ShouldBeTrue)
+				So(apiErr.Code(), ShouldEqual, http.StatusNotFound)
+			})
+		})
+	})
+}
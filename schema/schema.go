@@ -19,3 +19,38 @@ var filterOutputSubmitted = `{
 var FilterSubmittedSchema *avro.Schema = &avro.Schema{
 	Definition: filterOutputSubmitted,
 }
+
+var instancePublished = `{
+  "type": "record",
+  "name": "instance-published",
+  "fields": [
+    {"name": "instance_id", "type": "string", "default": ""},
+    {"name": "dataset_id", "type": "string", "default": ""},
+    {"name": "edition", "type": "string", "default": ""},
+    {"name": "version", "type": "string", "default": ""}
+  ]
+}`
+
+// InstancePublishedSchema is the Avro schema for each
+// dataset instance published
+var InstancePublishedSchema *avro.Schema = &avro.Schema{
+	Definition: instancePublished,
+}
+
+var filterOutputPublished = `{
+  "type": "record",
+  "name": "filter-output-published",
+  "fields": [
+    {"name": "filter_output_id", "type": "string", "default": ""},
+    {"name": "instance_id", "type": "string", "default": ""},
+    {"name": "dataset_id", "type": "string", "default": ""},
+    {"name": "edition", "type": "string", "default": ""},
+    {"name": "version", "type": "string", "default": ""}
+  ]
+}`
+
+// FilterOutputPublishedSchema is the Avro schema for each
+// filter output published along with its dataset instance
+var FilterOutputPublishedSchema *avro.Schema = &avro.Schema{
+	Definition: filterOutputPublished,
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"net/http"
+	"time"
 
 	"github.com/ONSdigital/dp-filter-api/models"
 	"github.com/ONSdigital/dp-healthcheck/healthcheck"
@@ -34,8 +35,11 @@ type MongoDB interface {
 	AddFilter(ctx context.Context, filter *models.Filter) (*models.Filter, error)
 	GetFilter(ctx context.Context, filterID, eTagSelector string) (*models.Filter, error)
 	UpdateFilter(ctx context.Context, updatedFilter *models.Filter, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (newETag string, err error)
+	ReplaceFilter(ctx context.Context, updatedFilter *models.Filter, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (newETag string, err error)
+	GetFilterSnapshot(ctx context.Context, filterID, eTag string) (*models.FilterSnapshot, error)
 	GetFilterDimension(ctx context.Context, filterID string, name, eTagSelector string) (dimension *models.Dimension, err error)
-	AddFilterDimension(ctx context.Context, filterID, name string, options []string, dimensions []models.Dimension, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (newETag string, err error)
+	AddFilterDimension(ctx context.Context, filterID, name, mode string, options []string, ranges []models.OptionRange, dimensions []models.Dimension, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (newETag string, err error)
+	UpdateFilterDimension(ctx context.Context, filterID string, dimension models.Dimension, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (newETag string, err error)
 	RemoveFilterDimension(ctx context.Context, filterID, name string, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (newETag string, err error)
 	AddFilterDimensionOption(ctx context.Context, filterID, name, option string, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (newETag string, err error)
 	AddFilterDimensionOptions(ctx context.Context, filterID, name string, options []string, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (newETag string, err error)
@@ -45,6 +49,11 @@ type MongoDB interface {
 	GetFilterOutput(ctx context.Context, filterOutputID string) (*models.Filter, error)
 	UpdateFilterOutput(ctx context.Context, filter *models.Filter, timestamp primitive.Timestamp) error
 	AddEventToFilterOutput(ctx context.Context, filterOutputID string, event *models.Event) error
+	ReserveIdempotencyRecord(ctx context.Context, record *models.IdempotencyRecord, expiredBefore, leaseExpiredBefore time.Time) (*models.IdempotencyRecord, error)
+	SaveIdempotencyRecord(ctx context.Context, record *models.IdempotencyRecord) error
+	DeleteIdempotencyRecord(ctx context.Context, record *models.IdempotencyRecord) error
+	PublishFilterBlueprints(ctx context.Context, instanceID string) (int, error)
+	PublishFilterOutputs(ctx context.Context, instanceID string) ([]string, error)
 	Checker(ctx context.Context, state *healthcheck.CheckState) error
 	Close(ctx context.Context) error
 	RunTransaction(ctx context.Context, retry bool, fn mongodriver.TransactionFunc) (interface{}, error)
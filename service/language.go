@@ -0,0 +1,30 @@
+package service
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ONSdigital/dp-api-clients-go/v2/headers"
+	dprequest "github.com/ONSdigital/dp-net/request"
+	dphttp "github.com/ONSdigital/dp-net/v2/http"
+)
+
+// languageClienter forwards the language requested by the caller, if any, to the Accept-Language header of outgoing requests,
+// so that the Dataset API returns its metadata in that language
+type languageClienter struct {
+	dphttp.Clienter
+}
+
+func newLanguageClienter(clienter dphttp.Clienter) *languageClienter {
+	return &languageClienter{Clienter: clienter}
+}
+
+// Do sets the Accept-Language header from the context before executing the request
+func (c *languageClienter) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
+	if language, ok := ctx.Value(dprequest.LocaleContextKey).(string); ok && req.Header.Get("Accept-Language") == "" {
+		if err := headers.SetAcceptedLang(req, language); err != nil {
+			return nil, err
+		}
+	}
+	return c.Clienter.Do(ctx, req)
+}
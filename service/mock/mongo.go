@@ -11,6 +11,7 @@ import (
 	mongodriver "github.com/ONSdigital/dp-mongodb/v3/mongodb"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"sync"
+	"time"
 )
 
 // Ensure, that MongoDBMock does implement service.MongoDB.
@@ -29,7 +30,7 @@ var _ service.MongoDB = &MongoDBMock{}
 //			AddFilterFunc: func(ctx context.Context, filter *models.Filter) (*models.Filter, error) {
 //				panic("mock out the AddFilter method")
 //			},
-//			AddFilterDimensionFunc: func(ctx context.Context, filterID string, name string, options []string, dimensions []models.Dimension, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error) {
+//			AddFilterDimensionFunc: func(ctx context.Context, filterID string, name string, mode string, options []string, ranges []models.OptionRange, dimensions []models.Dimension, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error) {
 //				panic("mock out the AddFilterDimension method")
 //			},
 //			AddFilterDimensionOptionFunc: func(ctx context.Context, filterID string, name string, option string, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error) {
@@ -47,6 +48,9 @@ var _ service.MongoDB = &MongoDBMock{}
 //			CreateFilterOutputFunc: func(ctx context.Context, filter *models.Filter) error {
 //				panic("mock out the CreateFilterOutput method")
 //			},
+//			DeleteIdempotencyRecordFunc: func(ctx context.Context, record *models.IdempotencyRecord) error {
+//				panic("mock out the DeleteIdempotencyRecord method")
+//			},
 //			GetFilterFunc: func(ctx context.Context, filterID string, eTagSelector string) (*models.Filter, error) {
 //				panic("mock out the GetFilter method")
 //			},
@@ -56,6 +60,15 @@ var _ service.MongoDB = &MongoDBMock{}
 //			GetFilterOutputFunc: func(ctx context.Context, filterOutputID string) (*models.Filter, error) {
 //				panic("mock out the GetFilterOutput method")
 //			},
+//			GetFilterSnapshotFunc: func(ctx context.Context, filterID string, eTag string) (*models.FilterSnapshot, error) {
+//				panic("mock out the GetFilterSnapshot method")
+//			},
+//			PublishFilterBlueprintsFunc: func(ctx context.Context, instanceID string) (int, error) {
+//				panic("mock out the PublishFilterBlueprints method")
+//			},
+//			PublishFilterOutputsFunc: func(ctx context.Context, instanceID string) ([]string, error) {
+//				panic("mock out the PublishFilterOutputs method")
+//			},
 //			RemoveFilterDimensionFunc: func(ctx context.Context, filterID string, name string, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error) {
 //				panic("mock out the RemoveFilterDimension method")
 //			},
@@ -65,12 +78,24 @@ var _ service.MongoDB = &MongoDBMock{}
 //			RemoveFilterDimensionOptionsFunc: func(ctx context.Context, filterID string, name string, options []string, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error) {
 //				panic("mock out the RemoveFilterDimensionOptions method")
 //			},
+//			ReplaceFilterFunc: func(ctx context.Context, updatedFilter *models.Filter, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error) {
+//				panic("mock out the ReplaceFilter method")
+//			},
+//			ReserveIdempotencyRecordFunc: func(ctx context.Context, record *models.IdempotencyRecord, expiredBefore time.Time, leaseExpiredBefore time.Time) (*models.IdempotencyRecord, error) {
+//				panic("mock out the ReserveIdempotencyRecord method")
+//			},
 //			RunTransactionFunc: func(ctx context.Context, retry bool, fn mongodriver.TransactionFunc) (interface{}, error) {
 //				panic("mock out the RunTransaction method")
 //			},
+//			SaveIdempotencyRecordFunc: func(ctx context.Context, record *models.IdempotencyRecord) error {
+//				panic("mock out the SaveIdempotencyRecord method")
+//			},
 //			UpdateFilterFunc: func(ctx context.Context, updatedFilter *models.Filter, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error) {
 //				panic("mock out the UpdateFilter method")
 //			},
+//			UpdateFilterDimensionFunc: func(ctx context.Context, filterID string, dimension models.Dimension, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error) {
+//				panic("mock out the UpdateFilterDimension method")
+//			},
 //			UpdateFilterOutputFunc: func(ctx context.Context, filter *models.Filter, timestamp primitive.Timestamp) error {
 //				panic("mock out the UpdateFilterOutput method")
 //			},
@@ -88,7 +113,7 @@ type MongoDBMock struct {
 	AddFilterFunc func(ctx context.Context, filter *models.Filter) (*models.Filter, error)
 
 	// AddFilterDimensionFunc mocks the AddFilterDimension method.
-	AddFilterDimensionFunc func(ctx context.Context, filterID string, name string, options []string, dimensions []models.Dimension, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error)
+	AddFilterDimensionFunc func(ctx context.Context, filterID string, name string, mode string, options []string, ranges []models.OptionRange, dimensions []models.Dimension, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error)
 
 	// AddFilterDimensionOptionFunc mocks the AddFilterDimensionOption method.
 	AddFilterDimensionOptionFunc func(ctx context.Context, filterID string, name string, option string, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error)
@@ -105,6 +130,9 @@ type MongoDBMock struct {
 	// CreateFilterOutputFunc mocks the CreateFilterOutput method.
 	CreateFilterOutputFunc func(ctx context.Context, filter *models.Filter) error
 
+	// DeleteIdempotencyRecordFunc mocks the DeleteIdempotencyRecord method.
+	DeleteIdempotencyRecordFunc func(ctx context.Context, record *models.IdempotencyRecord) error
+
 	// GetFilterFunc mocks the GetFilter method.
 	GetFilterFunc func(ctx context.Context, filterID string, eTagSelector string) (*models.Filter, error)
 
@@ -114,6 +142,15 @@ type MongoDBMock struct {
 	// GetFilterOutputFunc mocks the GetFilterOutput method.
 	GetFilterOutputFunc func(ctx context.Context, filterOutputID string) (*models.Filter, error)
 
+	// GetFilterSnapshotFunc mocks the GetFilterSnapshot method.
+	GetFilterSnapshotFunc func(ctx context.Context, filterID string, eTag string) (*models.FilterSnapshot, error)
+
+	// PublishFilterBlueprintsFunc mocks the PublishFilterBlueprints method.
+	PublishFilterBlueprintsFunc func(ctx context.Context, instanceID string) (int, error)
+
+	// PublishFilterOutputsFunc mocks the PublishFilterOutputs method.
+	PublishFilterOutputsFunc func(ctx context.Context, instanceID string) ([]string, error)
+
 	// RemoveFilterDimensionFunc mocks the RemoveFilterDimension method.
 	RemoveFilterDimensionFunc func(ctx context.Context, filterID string, name string, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error)
 
@@ -123,12 +160,24 @@ type MongoDBMock struct {
 	// RemoveFilterDimensionOptionsFunc mocks the RemoveFilterDimensionOptions method.
 	RemoveFilterDimensionOptionsFunc func(ctx context.Context, filterID string, name string, options []string, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error)
 
+	// ReplaceFilterFunc mocks the ReplaceFilter method.
+	ReplaceFilterFunc func(ctx context.Context, updatedFilter *models.Filter, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error)
+
+	// ReserveIdempotencyRecordFunc mocks the ReserveIdempotencyRecord method.
+	ReserveIdempotencyRecordFunc func(ctx context.Context, record *models.IdempotencyRecord, expiredBefore time.Time, leaseExpiredBefore time.Time) (*models.IdempotencyRecord, error)
+
 	// RunTransactionFunc mocks the RunTransaction method.
 	RunTransactionFunc func(ctx context.Context, retry bool, fn mongodriver.TransactionFunc) (interface{}, error)
 
+	// SaveIdempotencyRecordFunc mocks the SaveIdempotencyRecord method.
+	SaveIdempotencyRecordFunc func(ctx context.Context, record *models.IdempotencyRecord) error
+
 	// UpdateFilterFunc mocks the UpdateFilter method.
 	UpdateFilterFunc func(ctx context.Context, updatedFilter *models.Filter, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error)
 
+	// UpdateFilterDimensionFunc mocks the UpdateFilterDimension method.
+	UpdateFilterDimensionFunc func(ctx context.Context, filterID string, dimension models.Dimension, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error)
+
 	// UpdateFilterOutputFunc mocks the UpdateFilterOutput method.
 	UpdateFilterOutputFunc func(ctx context.Context, filter *models.Filter, timestamp primitive.Timestamp) error
 
@@ -158,8 +207,12 @@ type MongoDBMock struct {
 			FilterID string
 			// Name is the name argument value.
 			Name string
+			// Mode is the mode argument value.
+			Mode string
 			// Options is the options argument value.
 			Options []string
+			// Ranges is the ranges argument value.
+			Ranges []models.OptionRange
 			// Dimensions is the dimensions argument value.
 			Dimensions []models.Dimension
 			// Timestamp is the timestamp argument value.
@@ -222,6 +275,13 @@ type MongoDBMock struct {
 			// Filter is the filter argument value.
 			Filter *models.Filter
 		}
+		// DeleteIdempotencyRecord holds details about calls to the DeleteIdempotencyRecord method.
+		DeleteIdempotencyRecord []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Record is the record argument value.
+			Record *models.IdempotencyRecord
+		}
 		// GetFilter holds details about calls to the GetFilter method.
 		GetFilter []struct {
 			// Ctx is the ctx argument value.
@@ -249,6 +309,29 @@ type MongoDBMock struct {
 			// FilterOutputID is the filterOutputID argument value.
 			FilterOutputID string
 		}
+		// GetFilterSnapshot holds details about calls to the GetFilterSnapshot method.
+		GetFilterSnapshot []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// FilterID is the filterID argument value.
+			FilterID string
+			// ETag is the eTag argument value.
+			ETag string
+		}
+		// PublishFilterBlueprints holds details about calls to the PublishFilterBlueprints method.
+		PublishFilterBlueprints []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// InstanceID is the instanceID argument value.
+			InstanceID string
+		}
+		// PublishFilterOutputs holds details about calls to the PublishFilterOutputs method.
+		PublishFilterOutputs []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// InstanceID is the instanceID argument value.
+			InstanceID string
+		}
 		// RemoveFilterDimension holds details about calls to the RemoveFilterDimension method.
 		RemoveFilterDimension []struct {
 			// Ctx is the ctx argument value.
@@ -298,6 +381,30 @@ type MongoDBMock struct {
 			// CurrentFilter is the currentFilter argument value.
 			CurrentFilter *models.Filter
 		}
+		// ReplaceFilter holds details about calls to the ReplaceFilter method.
+		ReplaceFilter []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// UpdatedFilter is the updatedFilter argument value.
+			UpdatedFilter *models.Filter
+			// Timestamp is the timestamp argument value.
+			Timestamp primitive.Timestamp
+			// ETagSelector is the eTagSelector argument value.
+			ETagSelector string
+			// CurrentFilter is the currentFilter argument value.
+			CurrentFilter *models.Filter
+		}
+		// ReserveIdempotencyRecord holds details about calls to the ReserveIdempotencyRecord method.
+		ReserveIdempotencyRecord []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Record is the record argument value.
+			Record *models.IdempotencyRecord
+			// ExpiredBefore is the expiredBefore argument value.
+			ExpiredBefore time.Time
+			// LeaseExpiredBefore is the leaseExpiredBefore argument value.
+			LeaseExpiredBefore time.Time
+		}
 		// RunTransaction holds details about calls to the RunTransaction method.
 		RunTransaction []struct {
 			// Ctx is the ctx argument value.
@@ -307,6 +414,13 @@ type MongoDBMock struct {
 			// Fn is the fn argument value.
 			Fn mongodriver.TransactionFunc
 		}
+		// SaveIdempotencyRecord holds details about calls to the SaveIdempotencyRecord method.
+		SaveIdempotencyRecord []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// Record is the record argument value.
+			Record *models.IdempotencyRecord
+		}
 		// UpdateFilter holds details about calls to the UpdateFilter method.
 		UpdateFilter []struct {
 			// Ctx is the ctx argument value.
@@ -320,6 +434,21 @@ type MongoDBMock struct {
 			// CurrentFilter is the currentFilter argument value.
 			CurrentFilter *models.Filter
 		}
+		// UpdateFilterDimension holds details about calls to the UpdateFilterDimension method.
+		UpdateFilterDimension []struct {
+			// Ctx is the ctx argument value.
+			Ctx context.Context
+			// FilterID is the filterID argument value.
+			FilterID string
+			// Dimension is the dimension argument value.
+			Dimension models.Dimension
+			// Timestamp is the timestamp argument value.
+			Timestamp primitive.Timestamp
+			// ETagSelector is the eTagSelector argument value.
+			ETagSelector string
+			// CurrentFilter is the currentFilter argument value.
+			CurrentFilter *models.Filter
+		}
 		// UpdateFilterOutput holds details about calls to the UpdateFilterOutput method.
 		UpdateFilterOutput []struct {
 			// Ctx is the ctx argument value.
@@ -338,14 +467,22 @@ type MongoDBMock struct {
 	lockChecker                      sync.RWMutex
 	lockClose                        sync.RWMutex
 	lockCreateFilterOutput           sync.RWMutex
+	lockDeleteIdempotencyRecord      sync.RWMutex
 	lockGetFilter                    sync.RWMutex
 	lockGetFilterDimension           sync.RWMutex
 	lockGetFilterOutput              sync.RWMutex
+	lockGetFilterSnapshot            sync.RWMutex
+	lockPublishFilterBlueprints      sync.RWMutex
+	lockPublishFilterOutputs         sync.RWMutex
 	lockRemoveFilterDimension        sync.RWMutex
 	lockRemoveFilterDimensionOption  sync.RWMutex
 	lockRemoveFilterDimensionOptions sync.RWMutex
+	lockReplaceFilter                sync.RWMutex
+	lockReserveIdempotencyRecord     sync.RWMutex
 	lockRunTransaction               sync.RWMutex
+	lockSaveIdempotencyRecord        sync.RWMutex
 	lockUpdateFilter                 sync.RWMutex
+	lockUpdateFilterDimension        sync.RWMutex
 	lockUpdateFilterOutput           sync.RWMutex
 }
 
@@ -426,7 +563,7 @@ func (mock *MongoDBMock) AddFilterCalls() []struct {
 }
 
 // AddFilterDimension calls AddFilterDimensionFunc.
-func (mock *MongoDBMock) AddFilterDimension(ctx context.Context, filterID string, name string, options []string, dimensions []models.Dimension, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error) {
+func (mock *MongoDBMock) AddFilterDimension(ctx context.Context, filterID string, name string, mode string, options []string, ranges []models.OptionRange, dimensions []models.Dimension, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error) {
 	if mock.AddFilterDimensionFunc == nil {
 		panic("MongoDBMock.AddFilterDimensionFunc: method is nil but MongoDB.AddFilterDimension was just called")
 	}
@@ -434,7 +571,9 @@ func (mock *MongoDBMock) AddFilterDimension(ctx context.Context, filterID string
 		Ctx           context.Context
 		FilterID      string
 		Name          string
+		Mode          string
 		Options       []string
+		Ranges        []models.OptionRange
 		Dimensions    []models.Dimension
 		Timestamp     primitive.Timestamp
 		ETagSelector  string
@@ -443,7 +582,9 @@ func (mock *MongoDBMock) AddFilterDimension(ctx context.Context, filterID string
 		Ctx:           ctx,
 		FilterID:      filterID,
 		Name:          name,
+		Mode:          mode,
 		Options:       options,
+		Ranges:        ranges,
 		Dimensions:    dimensions,
 		Timestamp:     timestamp,
 		ETagSelector:  eTagSelector,
@@ -452,7 +593,7 @@ func (mock *MongoDBMock) AddFilterDimension(ctx context.Context, filterID string
 	mock.lockAddFilterDimension.Lock()
 	mock.calls.AddFilterDimension = append(mock.calls.AddFilterDimension, callInfo)
 	mock.lockAddFilterDimension.Unlock()
-	return mock.AddFilterDimensionFunc(ctx, filterID, name, options, dimensions, timestamp, eTagSelector, currentFilter)
+	return mock.AddFilterDimensionFunc(ctx, filterID, name, mode, options, ranges, dimensions, timestamp, eTagSelector, currentFilter)
 }
 
 // AddFilterDimensionCalls gets all the calls that were made to AddFilterDimension.
@@ -463,7 +604,9 @@ func (mock *MongoDBMock) AddFilterDimensionCalls() []struct {
 	Ctx           context.Context
 	FilterID      string
 	Name          string
+	Mode          string
 	Options       []string
+	Ranges        []models.OptionRange
 	Dimensions    []models.Dimension
 	Timestamp     primitive.Timestamp
 	ETagSelector  string
@@ -473,7 +616,9 @@ func (mock *MongoDBMock) AddFilterDimensionCalls() []struct {
 		Ctx           context.Context
 		FilterID      string
 		Name          string
+		Mode          string
 		Options       []string
+		Ranges        []models.OptionRange
 		Dimensions    []models.Dimension
 		Timestamp     primitive.Timestamp
 		ETagSelector  string
@@ -701,6 +846,42 @@ func (mock *MongoDBMock) CreateFilterOutputCalls() []struct {
 	return calls
 }
 
+// DeleteIdempotencyRecord calls DeleteIdempotencyRecordFunc.
+func (mock *MongoDBMock) DeleteIdempotencyRecord(ctx context.Context, record *models.IdempotencyRecord) error {
+	if mock.DeleteIdempotencyRecordFunc == nil {
+		panic("MongoDBMock.DeleteIdempotencyRecordFunc: method is nil but MongoDB.DeleteIdempotencyRecord was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Record *models.IdempotencyRecord
+	}{
+		Ctx:    ctx,
+		Record: record,
+	}
+	mock.lockDeleteIdempotencyRecord.Lock()
+	mock.calls.DeleteIdempotencyRecord = append(mock.calls.DeleteIdempotencyRecord, callInfo)
+	mock.lockDeleteIdempotencyRecord.Unlock()
+	return mock.DeleteIdempotencyRecordFunc(ctx, record)
+}
+
+// DeleteIdempotencyRecordCalls gets all the calls that were made to DeleteIdempotencyRecord.
+// Check the length with:
+//
+//	len(mockedMongoDB.DeleteIdempotencyRecordCalls())
+func (mock *MongoDBMock) DeleteIdempotencyRecordCalls() []struct {
+	Ctx    context.Context
+	Record *models.IdempotencyRecord
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Record *models.IdempotencyRecord
+	}
+	mock.lockDeleteIdempotencyRecord.RLock()
+	calls = mock.calls.DeleteIdempotencyRecord
+	mock.lockDeleteIdempotencyRecord.RUnlock()
+	return calls
+}
+
 // GetFilter calls GetFilterFunc.
 func (mock *MongoDBMock) GetFilter(ctx context.Context, filterID string, eTagSelector string) (*models.Filter, error) {
 	if mock.GetFilterFunc == nil {
@@ -821,6 +1002,118 @@ func (mock *MongoDBMock) GetFilterOutputCalls() []struct {
 	return calls
 }
 
+// GetFilterSnapshot calls GetFilterSnapshotFunc.
+func (mock *MongoDBMock) GetFilterSnapshot(ctx context.Context, filterID string, eTag string) (*models.FilterSnapshot, error) {
+	if mock.GetFilterSnapshotFunc == nil {
+		panic("MongoDBMock.GetFilterSnapshotFunc: method is nil but MongoDB.GetFilterSnapshot was just called")
+	}
+	callInfo := struct {
+		Ctx      context.Context
+		FilterID string
+		ETag     string
+	}{
+		Ctx:      ctx,
+		FilterID: filterID,
+		ETag:     eTag,
+	}
+	mock.lockGetFilterSnapshot.Lock()
+	mock.calls.GetFilterSnapshot = append(mock.calls.GetFilterSnapshot, callInfo)
+	mock.lockGetFilterSnapshot.Unlock()
+	return mock.GetFilterSnapshotFunc(ctx, filterID, eTag)
+}
+
+// GetFilterSnapshotCalls gets all the calls that were made to GetFilterSnapshot.
+// Check the length with:
+//
+//	len(mockedMongoDB.GetFilterSnapshotCalls())
+func (mock *MongoDBMock) GetFilterSnapshotCalls() []struct {
+	Ctx      context.Context
+	FilterID string
+	ETag     string
+} {
+	var calls []struct {
+		Ctx      context.Context
+		FilterID string
+		ETag     string
+	}
+	mock.lockGetFilterSnapshot.RLock()
+	calls = mock.calls.GetFilterSnapshot
+	mock.lockGetFilterSnapshot.RUnlock()
+	return calls
+}
+
+// PublishFilterBlueprints calls PublishFilterBlueprintsFunc.
+func (mock *MongoDBMock) PublishFilterBlueprints(ctx context.Context, instanceID string) (int, error) {
+	if mock.PublishFilterBlueprintsFunc == nil {
+		panic("MongoDBMock.PublishFilterBlueprintsFunc: method is nil but MongoDB.PublishFilterBlueprints was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		InstanceID string
+	}{
+		Ctx:        ctx,
+		InstanceID: instanceID,
+	}
+	mock.lockPublishFilterBlueprints.Lock()
+	mock.calls.PublishFilterBlueprints = append(mock.calls.PublishFilterBlueprints, callInfo)
+	mock.lockPublishFilterBlueprints.Unlock()
+	return mock.PublishFilterBlueprintsFunc(ctx, instanceID)
+}
+
+// PublishFilterBlueprintsCalls gets all the calls that were made to PublishFilterBlueprints.
+// Check the length with:
+//
+//	len(mockedMongoDB.PublishFilterBlueprintsCalls())
+func (mock *MongoDBMock) PublishFilterBlueprintsCalls() []struct {
+	Ctx        context.Context
+	InstanceID string
+} {
+	var calls []struct {
+		Ctx        context.Context
+		InstanceID string
+	}
+	mock.lockPublishFilterBlueprints.RLock()
+	calls = mock.calls.PublishFilterBlueprints
+	mock.lockPublishFilterBlueprints.RUnlock()
+	return calls
+}
+
+// PublishFilterOutputs calls PublishFilterOutputsFunc.
+func (mock *MongoDBMock) PublishFilterOutputs(ctx context.Context, instanceID string) ([]string, error) {
+	if mock.PublishFilterOutputsFunc == nil {
+		panic("MongoDBMock.PublishFilterOutputsFunc: method is nil but MongoDB.PublishFilterOutputs was just called")
+	}
+	callInfo := struct {
+		Ctx        context.Context
+		InstanceID string
+	}{
+		Ctx:        ctx,
+		InstanceID: instanceID,
+	}
+	mock.lockPublishFilterOutputs.Lock()
+	mock.calls.PublishFilterOutputs = append(mock.calls.PublishFilterOutputs, callInfo)
+	mock.lockPublishFilterOutputs.Unlock()
+	return mock.PublishFilterOutputsFunc(ctx, instanceID)
+}
+
+// PublishFilterOutputsCalls gets all the calls that were made to PublishFilterOutputs.
+// Check the length with:
+//
+//	len(mockedMongoDB.PublishFilterOutputsCalls())
+func (mock *MongoDBMock) PublishFilterOutputsCalls() []struct {
+	Ctx        context.Context
+	InstanceID string
+} {
+	var calls []struct {
+		Ctx        context.Context
+		InstanceID string
+	}
+	mock.lockPublishFilterOutputs.RLock()
+	calls = mock.calls.PublishFilterOutputs
+	mock.lockPublishFilterOutputs.RUnlock()
+	return calls
+}
+
 // RemoveFilterDimension calls RemoveFilterDimensionFunc.
 func (mock *MongoDBMock) RemoveFilterDimension(ctx context.Context, filterID string, name string, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error) {
 	if mock.RemoveFilterDimensionFunc == nil {
@@ -985,6 +1278,98 @@ func (mock *MongoDBMock) RemoveFilterDimensionOptionsCalls() []struct {
 	return calls
 }
 
+// ReplaceFilter calls ReplaceFilterFunc.
+func (mock *MongoDBMock) ReplaceFilter(ctx context.Context, updatedFilter *models.Filter, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error) {
+	if mock.ReplaceFilterFunc == nil {
+		panic("MongoDBMock.ReplaceFilterFunc: method is nil but MongoDB.ReplaceFilter was just called")
+	}
+	callInfo := struct {
+		Ctx           context.Context
+		UpdatedFilter *models.Filter
+		Timestamp     primitive.Timestamp
+		ETagSelector  string
+		CurrentFilter *models.Filter
+	}{
+		Ctx:           ctx,
+		UpdatedFilter: updatedFilter,
+		Timestamp:     timestamp,
+		ETagSelector:  eTagSelector,
+		CurrentFilter: currentFilter,
+	}
+	mock.lockReplaceFilter.Lock()
+	mock.calls.ReplaceFilter = append(mock.calls.ReplaceFilter, callInfo)
+	mock.lockReplaceFilter.Unlock()
+	return mock.ReplaceFilterFunc(ctx, updatedFilter, timestamp, eTagSelector, currentFilter)
+}
+
+// ReplaceFilterCalls gets all the calls that were made to ReplaceFilter.
+// Check the length with:
+//
+//	len(mockedMongoDB.ReplaceFilterCalls())
+func (mock *MongoDBMock) ReplaceFilterCalls() []struct {
+	Ctx           context.Context
+	UpdatedFilter *models.Filter
+	Timestamp     primitive.Timestamp
+	ETagSelector  string
+	CurrentFilter *models.Filter
+} {
+	var calls []struct {
+		Ctx           context.Context
+		UpdatedFilter *models.Filter
+		Timestamp     primitive.Timestamp
+		ETagSelector  string
+		CurrentFilter *models.Filter
+	}
+	mock.lockReplaceFilter.RLock()
+	calls = mock.calls.ReplaceFilter
+	mock.lockReplaceFilter.RUnlock()
+	return calls
+}
+
+// ReserveIdempotencyRecord calls ReserveIdempotencyRecordFunc.
+func (mock *MongoDBMock) ReserveIdempotencyRecord(ctx context.Context, record *models.IdempotencyRecord, expiredBefore time.Time, leaseExpiredBefore time.Time) (*models.IdempotencyRecord, error) {
+	if mock.ReserveIdempotencyRecordFunc == nil {
+		panic("MongoDBMock.ReserveIdempotencyRecordFunc: method is nil but MongoDB.ReserveIdempotencyRecord was just called")
+	}
+	callInfo := struct {
+		Ctx                context.Context
+		Record             *models.IdempotencyRecord
+		ExpiredBefore      time.Time
+		LeaseExpiredBefore time.Time
+	}{
+		Ctx:                ctx,
+		Record:             record,
+		ExpiredBefore:      expiredBefore,
+		LeaseExpiredBefore: leaseExpiredBefore,
+	}
+	mock.lockReserveIdempotencyRecord.Lock()
+	mock.calls.ReserveIdempotencyRecord = append(mock.calls.ReserveIdempotencyRecord, callInfo)
+	mock.lockReserveIdempotencyRecord.Unlock()
+	return mock.ReserveIdempotencyRecordFunc(ctx, record, expiredBefore, leaseExpiredBefore)
+}
+
+// ReserveIdempotencyRecordCalls gets all the calls that were made to ReserveIdempotencyRecord.
+// Check the length with:
+//
+//	len(mockedMongoDB.ReserveIdempotencyRecordCalls())
+func (mock *MongoDBMock) ReserveIdempotencyRecordCalls() []struct {
+	Ctx                context.Context
+	Record             *models.IdempotencyRecord
+	ExpiredBefore      time.Time
+	LeaseExpiredBefore time.Time
+} {
+	var calls []struct {
+		Ctx                context.Context
+		Record             *models.IdempotencyRecord
+		ExpiredBefore      time.Time
+		LeaseExpiredBefore time.Time
+	}
+	mock.lockReserveIdempotencyRecord.RLock()
+	calls = mock.calls.ReserveIdempotencyRecord
+	mock.lockReserveIdempotencyRecord.RUnlock()
+	return calls
+}
+
 // RunTransaction calls RunTransactionFunc.
 func (mock *MongoDBMock) RunTransaction(ctx context.Context, retry bool, fn mongodriver.TransactionFunc) (interface{}, error) {
 	if mock.RunTransactionFunc == nil {
@@ -1025,6 +1410,42 @@ func (mock *MongoDBMock) RunTransactionCalls() []struct {
 	return calls
 }
 
+// SaveIdempotencyRecord calls SaveIdempotencyRecordFunc.
+func (mock *MongoDBMock) SaveIdempotencyRecord(ctx context.Context, record *models.IdempotencyRecord) error {
+	if mock.SaveIdempotencyRecordFunc == nil {
+		panic("MongoDBMock.SaveIdempotencyRecordFunc: method is nil but MongoDB.SaveIdempotencyRecord was just called")
+	}
+	callInfo := struct {
+		Ctx    context.Context
+		Record *models.IdempotencyRecord
+	}{
+		Ctx:    ctx,
+		Record: record,
+	}
+	mock.lockSaveIdempotencyRecord.Lock()
+	mock.calls.SaveIdempotencyRecord = append(mock.calls.SaveIdempotencyRecord, callInfo)
+	mock.lockSaveIdempotencyRecord.Unlock()
+	return mock.SaveIdempotencyRecordFunc(ctx, record)
+}
+
+// SaveIdempotencyRecordCalls gets all the calls that were made to SaveIdempotencyRecord.
+// Check the length with:
+//
+//	len(mockedMongoDB.SaveIdempotencyRecordCalls())
+func (mock *MongoDBMock) SaveIdempotencyRecordCalls() []struct {
+	Ctx    context.Context
+	Record *models.IdempotencyRecord
+} {
+	var calls []struct {
+		Ctx    context.Context
+		Record *models.IdempotencyRecord
+	}
+	mock.lockSaveIdempotencyRecord.RLock()
+	calls = mock.calls.SaveIdempotencyRecord
+	mock.lockSaveIdempotencyRecord.RUnlock()
+	return calls
+}
+
 // UpdateFilter calls UpdateFilterFunc.
 func (mock *MongoDBMock) UpdateFilter(ctx context.Context, updatedFilter *models.Filter, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error) {
 	if mock.UpdateFilterFunc == nil {
@@ -1073,6 +1494,58 @@ func (mock *MongoDBMock) UpdateFilterCalls() []struct {
 	return calls
 }
 
+// UpdateFilterDimension calls UpdateFilterDimensionFunc.
+func (mock *MongoDBMock) UpdateFilterDimension(ctx context.Context, filterID string, dimension models.Dimension, timestamp primitive.Timestamp, eTagSelector string, currentFilter *models.Filter) (string, error) {
+	if mock.UpdateFilterDimensionFunc == nil {
+		panic("MongoDBMock.UpdateFilterDimensionFunc: method is nil but MongoDB.UpdateFilterDimension was just called")
+	}
+	callInfo := struct {
+		Ctx           context.Context
+		FilterID      string
+		Dimension     models.Dimension
+		Timestamp     primitive.Timestamp
+		ETagSelector  string
+		CurrentFilter *models.Filter
+	}{
+		Ctx:           ctx,
+		FilterID:      filterID,
+		Dimension:     dimension,
+		Timestamp:     timestamp,
+		ETagSelector:  eTagSelector,
+		CurrentFilter: currentFilter,
+	}
+	mock.lockUpdateFilterDimension.Lock()
+	mock.calls.UpdateFilterDimension = append(mock.calls.UpdateFilterDimension, callInfo)
+	mock.lockUpdateFilterDimension.Unlock()
+	return mock.UpdateFilterDimensionFunc(ctx, filterID, dimension, timestamp, eTagSelector, currentFilter)
+}
+
+// UpdateFilterDimensionCalls gets all the calls that were made to UpdateFilterDimension.
+// Check the length with:
+//
+//	len(mockedMongoDB.UpdateFilterDimensionCalls())
+func (mock *MongoDBMock) UpdateFilterDimensionCalls() []struct {
+	Ctx           context.Context
+	FilterID      string
+	Dimension     models.Dimension
+	Timestamp     primitive.Timestamp
+	ETagSelector  string
+	CurrentFilter *models.Filter
+} {
+	var calls []struct {
+		Ctx           context.Context
+		FilterID      string
+		Dimension     models.Dimension
+		Timestamp     primitive.Timestamp
+		ETagSelector  string
+		CurrentFilter *models.Filter
+	}
+	mock.lockUpdateFilterDimension.RLock()
+	calls = mock.calls.UpdateFilterDimension
+	mock.lockUpdateFilterDimension.RUnlock()
+	return calls
+}
+
 // UpdateFilterOutput calls UpdateFilterOutputFunc.
 func (mock *MongoDBMock) UpdateFilterOutput(ctx context.Context, filter *models.Filter, timestamp primitive.Timestamp) error {
 	if mock.UpdateFilterOutputFunc == nil {
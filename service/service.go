@@ -11,14 +11,20 @@ import (
 	"github.com/ONSdigital/dp-api-clients-go/identity"
 	"github.com/ONSdigital/dp-api-clients-go/v2/dataset"
 	"github.com/ONSdigital/dp-api-clients-go/v2/filterflex"
+	"github.com/ONSdigital/dp-api-clients-go/v2/health"
+	"github.com/ONSdigital/dp-api-clients-go/v2/hierarchy"
 	"github.com/ONSdigital/dp-filter-api/api"
 	"github.com/ONSdigital/dp-filter-api/config"
 	"github.com/ONSdigital/dp-filter-api/filterOutputQueue"
+	"github.com/ONSdigital/dp-filter-api/instancePublished"
+	"github.com/ONSdigital/dp-filter-api/middleware"
 	"github.com/ONSdigital/dp-filter-api/mongo"
+	"github.com/ONSdigital/dp-filter-api/observations"
 	"github.com/ONSdigital/dp-healthcheck/healthcheck"
 	kafka "github.com/ONSdigital/dp-kafka/v2"
 	dphandlers "github.com/ONSdigital/dp-net/handlers"
 	dphttp "github.com/ONSdigital/dp-net/http"
+	dphttpv2 "github.com/ONSdigital/dp-net/v2/http"
 	"github.com/ONSdigital/log.go/v2/log"
 	"github.com/gorilla/mux"
 	"github.com/justinas/alice"
@@ -32,17 +38,21 @@ type Service struct {
 	Cfg                           *config.Config
 	FilterStore                   MongoDB
 	FilterOutputSubmittedProducer kafka.IProducer
+	InstancePublishedConsumer     kafka.IConsumerGroup
+	FilterOutputPublishedProducer kafka.IProducer
 	IdentityClient                *identity.Client
 	datasetAPI                    *dataset.Client
 	filterFlexAPI                 *filterflex.Client
+	hierarchyAPI                  *hierarchy.Client
+	observationsAPI               *observations.Client
 	HealthCheck                   HealthChecker
 	Server                        HTTPServer
 	api                           *api.FilterAPI
 }
 
 // GetFilterStore returns an initialised connection to filter store (mongo database)
-var GetFilterStore = func(cfg *config.Config) (datastore MongoDB, err error) {
-	return mongo.CreateFilterStore(cfg.MongoConfig, cfg.Host)
+var GetFilterStore = func(ctx context.Context, cfg *config.Config) (datastore MongoDB, err error) {
+	return mongo.CreateFilterStore(ctx, cfg)
 }
 
 // GetProducer returns a kafka producer
@@ -63,6 +73,23 @@ var GetProducer = func(ctx context.Context, cfg *config.Config, kafkaBrokers []s
 	return kafka.NewProducer(ctx, kafkaBrokers, topic, producerChannels, pConfig)
 }
 
+// GetConsumer returns a kafka consumer group
+var GetConsumer = func(ctx context.Context, cfg *config.Config, kafkaBrokers []string, topic, group string) (kafkaConsumer kafka.IConsumerGroup, err error) {
+	cgConfig := &kafka.ConsumerGroupConfig{
+		KafkaVersion: &cfg.KafkaVersion,
+	}
+	if cfg.KafkaSecProtocol == "TLS" {
+		cgConfig.SecurityConfig = kafka.GetSecurityConfig(
+			cfg.KafkaSecCACerts,
+			cfg.KafkaSecClientCert,
+			cfg.KafkaSecClientKey,
+			cfg.KafkaSecSkipVerify,
+		)
+	}
+	cgChannels := kafka.CreateConsumerGroupChannels(1)
+	return kafka.NewConsumerGroup(ctx, kafkaBrokers, topic, group, cgChannels, cgConfig)
+}
+
 // GetHealthCheck returns a healthcheck
 var GetHealthCheck = func(version healthcheck.VersionInfo, criticalTimeout, interval time.Duration) HealthChecker {
 	hc := healthcheck.New(version, criticalTimeout, interval)
@@ -86,7 +113,7 @@ func (svc *Service) Init(ctx context.Context, cfg *config.Config, buildTime, git
 	svc.Cfg = cfg
 
 	// Get data store.
-	svc.FilterStore, err = GetFilterStore(svc.Cfg)
+	svc.FilterStore, err = GetFilterStore(ctx, svc.Cfg)
 	if err != nil {
 		log.Error(ctx, "could not connect to mongodb", err)
 		// We don't return 'err' here because we don't want to stop this service
@@ -101,17 +128,37 @@ func (svc *Service) Init(ctx context.Context, cfg *config.Config, buildTime, git
 		return err
 	}
 
+	// Get kafka consumer, only if filters are published from instance published events
+	if svc.Cfg.EnablePublishEventConsumer {
+		svc.InstancePublishedConsumer, err = GetConsumer(ctx, cfg, svc.Cfg.Brokers, svc.Cfg.InstancePublishedTopic, svc.Cfg.InstancePublishedGroup)
+		if err != nil {
+			log.Error(ctx, "error creating kafka instance published consumer", err)
+			return err
+		}
+
+		svc.FilterOutputPublishedProducer, err = GetProducer(ctx, cfg, svc.Cfg.Brokers, svc.Cfg.FilterOutputPublishedTopic)
+		if err != nil {
+			log.Error(ctx, "error creating kafka filter output published producer", err)
+			return err
+		}
+	}
+
 	// Create Identity Client
 	if svc.Cfg.EnablePrivateEndpoints {
 		svc.IdentityClient = identity.New(svc.Cfg.ZebedeeURL)
 	}
 
 	// Create Dataset API client.
-	svc.datasetAPI = dataset.NewAPIClient(svc.Cfg.DatasetAPIURL)
+	datasetAPIClient := health.NewClientWithClienter("dataset-api", svc.Cfg.DatasetAPIURL, newLanguageClienter(dphttpv2.NewClient()))
+	svc.datasetAPI = dataset.NewWithHealthClient(datasetAPIClient)
+	svc.observationsAPI = observations.NewWithHealthClient(datasetAPIClient)
 	svc.filterFlexAPI = filterflex.New(filterflex.Config{
 		HostURL: svc.Cfg.FilterFlexAPIURL,
 	})
 
+	// Create Hierarchy API client.
+	svc.hierarchyAPI = hierarchy.New(svc.Cfg.HierarchyAPIURL)
+
 	// Get HealthCheck and register checkers
 	versionInfo, err := healthcheck.NewVersionInfo(buildTime, gitCommit, version)
 	if err != nil {
@@ -127,6 +174,14 @@ func (svc *Service) Init(ctx context.Context, cfg *config.Config, buildTime, git
 	r := mux.NewRouter()
 	r.Use(otelmux.Middleware(cfg.OTServiceName))
 	m := svc.createMiddleware(ctx, cfg)
+	if svc.Cfg.EnableSwaggerValidation {
+		log.Info(ctx, "swagger validation is enabled. requests that do not match the swagger specification will be rejected", log.Data{"spec": svc.Cfg.SwaggerSpecPath})
+		validator, err := middleware.NewSwaggerValidator(ctx, svc.Cfg.SwaggerSpecPath, false)
+		if err != nil {
+			return errors.Wrap(err, "unable to create swagger validator")
+		}
+		m = m.Append(validator.Validate)
+	}
 	svc.Server = GetHTTPServer(svc.Cfg.BindAddr, m.Then(r))
 
 	host, err := url.Parse(svc.Cfg.Host)
@@ -159,6 +214,8 @@ func (svc *Service) Init(ctx context.Context, cfg *config.Config, buildTime, git
 		DatasetAPIURL,
 		DownloadServiceURL,
 		svc.Cfg.EnableURLRewriting,
+		api.WithHierarchyAPI(svc.hierarchyAPI),
+		api.WithObservationsAPI(svc.observationsAPI),
 	)
 	return nil
 }
@@ -168,6 +225,13 @@ func (svc *Service) Start(ctx context.Context, svcErrors chan error) {
 	// Start kafka logging
 	svc.FilterOutputSubmittedProducer.Channels().LogErrors(ctx, "error received from kafka producer, topic: "+svc.Cfg.FilterOutputSubmittedTopic)
 
+	// Start consuming instance published events
+	if svc.InstancePublishedConsumer != nil {
+		svc.InstancePublishedConsumer.Channels().LogErrors(ctx, "error received from kafka consumer, topic: "+svc.Cfg.InstancePublishedTopic)
+		svc.FilterOutputPublishedProducer.Channels().LogErrors(ctx, "error received from kafka producer, topic: "+svc.Cfg.FilterOutputPublishedTopic)
+		instancePublished.Consume(ctx, svc.InstancePublishedConsumer, instancePublished.NewHandler(svc.FilterStore, svc.FilterOutputPublishedProducer.Channels().Output))
+	}
+
 	// Start healthcheck
 	svc.HealthCheck.Start(ctx)
 
@@ -239,6 +303,15 @@ func (svc *Service) Close(ctx context.Context) error {
 			}
 		}
 
+		// Close Kafka Consumer (if it exists), before the data store that it writes to
+		if svc.InstancePublishedConsumer != nil {
+			log.Info(ctx, "closing instance published consumer")
+			if err := svc.InstancePublishedConsumer.Close(ctx); err != nil {
+				log.Error(ctx, "unable to close instance published consumer", err)
+				hasShutdownError = true
+			}
+		}
+
 		// Close MongoDB (if it exists)
 		if svc.FilterStore != nil {
 			log.Info(ctx, "closing mongoDB filter data store")
@@ -257,6 +330,15 @@ func (svc *Service) Close(ctx context.Context) error {
 				hasShutdownError = true
 			}
 		}
+
+		// Close Kafka Producer of filter output published events (if it exists), after the consumer that produces them
+		if svc.FilterOutputPublishedProducer != nil {
+			log.Info(ctx, "closing filter output published producer")
+			if err := svc.FilterOutputPublishedProducer.Close(ctx); err != nil {
+				log.Error(ctx, "unable to close filter output published producer", err)
+				hasShutdownError = true
+			}
+		}
 	}()
 
 	// wait for shutdown success (via cancel) or failure (timeout)
@@ -279,6 +361,23 @@ func (svc *Service) Close(ctx context.Context) error {
 	return nil
 }
 
+// nonCriticalDependency is a dependency that only some features rely on, whose failing checks are reported as a warning instead of critical
+type nonCriticalDependency struct {
+	checker healthcheck.Checker
+}
+
+// Checker runs the check of the dependency, downgrading a critical state to a warning
+func (d nonCriticalDependency) Checker(ctx context.Context, state *healthcheck.CheckState) error {
+	err := d.checker(ctx, state)
+	if state.Status() == healthcheck.StatusCritical {
+		if updateErr := state.Update(healthcheck.StatusWarning, state.Message(), state.StatusCode()); updateErr != nil {
+			log.Error(ctx, "failed to update healthcheck state", updateErr)
+			return updateErr
+		}
+	}
+	return err
+}
+
 // registerCheckers adds the checkers for the service clients to the health check object.
 func (svc *Service) registerCheckers(ctx context.Context) (err error) {
 	hasErrors := false
@@ -312,9 +411,17 @@ func (svc *Service) registerCheckers(ctx context.Context) (err error) {
 	}
 
 	registerChecker("Dataset API", svc.datasetAPI)
+	// the hierarchy API is only required by option selectors, so its failures do not make the whole service critical
+	registerChecker("Hierarchy API", nonCriticalDependency{checker: svc.hierarchyAPI.Checker})
 	registerChecker("Kafka Producer", svc.FilterOutputSubmittedProducer)
 	registerChecker("Mongo DB", svc.FilterStore)
 
+	// the consumer and its producer are only created if filters are published from instance published events
+	if svc.Cfg.EnablePublishEventConsumer {
+		registerChecker("Kafka Consumer", svc.InstancePublishedConsumer)
+		registerChecker("Kafka Filter Output Published Producer", svc.FilterOutputPublishedProducer)
+	}
+
 	// zebedee is used only for identity checking if private endpoints are enabled
 	if svc.Cfg.EnablePrivateEndpoints {
 		registerChecker("Zebedee", svc.IdentityClient)
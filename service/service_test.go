@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"sync"
 	"testing"
 	"time"
@@ -42,10 +43,11 @@ func TestInit(t *testing.T) {
 	Convey("Having a set of mocked dependencies", t, func() {
 		cfg, err := config.Get()
 		cfg.EnablePrivateEndpoints = true
+		cfg.EnablePublishEventConsumer = false
 		So(err, ShouldBeNil)
 
 		mongoDBMock := &serviceMock.MongoDBMock{}
-		service.GetFilterStore = func(cfg *config.Config) (datastore service.MongoDB, err error) {
+		service.GetFilterStore = func(ctx context.Context, cfg *config.Config) (datastore service.MongoDB, err error) {
 			return mongoDBMock, nil
 		}
 
@@ -58,6 +60,15 @@ func TestInit(t *testing.T) {
 			return kafkaProducerMock, nil
 		}
 
+		kafkaConsumerMock := &kafkatest.IConsumerGroupMock{
+			ChannelsFunc: func() *kafka.ConsumerGroupChannels {
+				return &kafka.ConsumerGroupChannels{}
+			},
+		}
+		service.GetConsumer = func(ctx context.Context, cfg *config.Config, kafkaBrokers []string, topic, group string) (kafkaConsumer kafka.IConsumerGroup, err error) {
+			return kafkaConsumerMock, nil
+		}
+
 		hcMock := &serviceMock.HealthCheckerMock{
 			AddCheckFunc: func(name string, checker healthcheck.Checker) error { return nil },
 		}
@@ -73,7 +84,7 @@ func TestInit(t *testing.T) {
 		svc := &service.Service{}
 
 		Convey("Given that initialising MongoDB datastore returns an error", func() {
-			service.GetFilterStore = func(cfg *config.Config) (datastore service.MongoDB, err error) {
+			service.GetFilterStore = func(ctx context.Context, cfg *config.Config) (datastore service.MongoDB, err error) {
 				return nil, errMongo
 			}
 
@@ -87,11 +98,12 @@ func TestInit(t *testing.T) {
 				So(svc.Server, ShouldResemble, serverMock)
 
 				Convey("And all checks try to register", func() {
-					So(len(hcMock.AddCheckCalls()), ShouldEqual, 4)
+					So(len(hcMock.AddCheckCalls()), ShouldEqual, 5)
 					So(hcMock.AddCheckCalls()[0].Name, ShouldResemble, "Dataset API")
-					So(hcMock.AddCheckCalls()[1].Name, ShouldResemble, "Kafka Producer")
-					So(hcMock.AddCheckCalls()[2].Name, ShouldResemble, "Mongo DB")
-					So(hcMock.AddCheckCalls()[3].Name, ShouldResemble, "Zebedee")
+					So(hcMock.AddCheckCalls()[1].Name, ShouldResemble, "Hierarchy API")
+					So(hcMock.AddCheckCalls()[2].Name, ShouldResemble, "Kafka Producer")
+					So(hcMock.AddCheckCalls()[3].Name, ShouldResemble, "Mongo DB")
+					So(hcMock.AddCheckCalls()[4].Name, ShouldResemble, "Zebedee")
 				})
 			})
 		})
@@ -112,6 +124,41 @@ func TestInit(t *testing.T) {
 			})
 		})
 
+		Convey("Given that the publish event consumer is enabled and initialising the kafka filter output published Producer returns an error", func() {
+			cfg.EnablePublishEventConsumer = true
+			service.GetProducer = func(ctx context.Context, cfg *config.Config, kafkaBrokers []string, topic string) (kafkaProducer kafka.IProducer, err error) {
+				if topic == cfg.FilterOutputPublishedTopic {
+					return nil, errKafka
+				}
+				return kafkaProducerMock, nil
+			}
+
+			Convey("Then service Init fails with the same error and no further initialisations are attempted", func() {
+				err := svc.Init(ctx, cfg, testBuildTime, testGitCommit, testVersion)
+				So(err, ShouldResemble, errKafka)
+				So(svc.InstancePublishedConsumer, ShouldResemble, kafkaConsumerMock)
+				So(svc.FilterOutputPublishedProducer, ShouldBeNil)
+				So(svc.HealthCheck, ShouldBeNil)
+				So(svc.Server, ShouldBeNil)
+			})
+		})
+
+		Convey("Given that the publish event consumer is enabled and initialising the kafka Consumer returns an error", func() {
+			cfg.EnablePublishEventConsumer = true
+			service.GetConsumer = func(ctx context.Context, cfg *config.Config, kafkaBrokers []string, topic, group string) (kafkaConsumer kafka.IConsumerGroup, err error) {
+				return nil, errKafka
+			}
+
+			Convey("Then service Init fails with the same error and no further initialisations are attempted", func() {
+				err := svc.Init(ctx, cfg, testBuildTime, testGitCommit, testVersion)
+				So(err, ShouldResemble, errKafka)
+				So(svc.FilterOutputSubmittedProducer, ShouldResemble, kafkaProducerMock)
+				So(svc.InstancePublishedConsumer, ShouldBeNil)
+				So(svc.HealthCheck, ShouldBeNil)
+				So(svc.Server, ShouldBeNil)
+			})
+		})
+
 		Convey("Given that healthcheck versionInfo cannot be created due to a wrong build time", func() {
 			wrongBuildTime := "wrongFormat"
 
@@ -141,11 +188,12 @@ func TestInit(t *testing.T) {
 				So(svc.Server, ShouldBeNil)
 
 				Convey("But all checks try to register", func() {
-					So(len(hcMock.AddCheckCalls()), ShouldEqual, 4)
+					So(len(hcMock.AddCheckCalls()), ShouldEqual, 5)
 					So(hcMock.AddCheckCalls()[0].Name, ShouldResemble, "Dataset API")
-					So(hcMock.AddCheckCalls()[1].Name, ShouldResemble, "Kafka Producer")
-					So(hcMock.AddCheckCalls()[2].Name, ShouldResemble, "Mongo DB")
-					So(hcMock.AddCheckCalls()[3].Name, ShouldResemble, "Zebedee")
+					So(hcMock.AddCheckCalls()[1].Name, ShouldResemble, "Hierarchy API")
+					So(hcMock.AddCheckCalls()[2].Name, ShouldResemble, "Kafka Producer")
+					So(hcMock.AddCheckCalls()[3].Name, ShouldResemble, "Mongo DB")
+					So(hcMock.AddCheckCalls()[4].Name, ShouldResemble, "Zebedee")
 				})
 			})
 		})
@@ -161,15 +209,35 @@ func TestInit(t *testing.T) {
 				So(svc.Server, ShouldResemble, serverMock)
 
 				Convey("And all checks are registered", func() {
-					So(len(hcMock.AddCheckCalls()), ShouldEqual, 4)
+					So(len(hcMock.AddCheckCalls()), ShouldEqual, 5)
 					So(hcMock.AddCheckCalls()[0].Name, ShouldResemble, "Dataset API")
-					So(hcMock.AddCheckCalls()[1].Name, ShouldResemble, "Kafka Producer")
-					So(hcMock.AddCheckCalls()[2].Name, ShouldResemble, "Mongo DB")
-					So(hcMock.AddCheckCalls()[3].Name, ShouldResemble, "Zebedee")
+					So(hcMock.AddCheckCalls()[1].Name, ShouldResemble, "Hierarchy API")
+					So(hcMock.AddCheckCalls()[2].Name, ShouldResemble, "Kafka Producer")
+					So(hcMock.AddCheckCalls()[3].Name, ShouldResemble, "Mongo DB")
+					So(hcMock.AddCheckCalls()[4].Name, ShouldResemble, "Zebedee")
 				})
 			})
 		})
 
+		Convey("Given that all dependencies are successfully initialised and the hierarchy API is failing", func() {
+			hierarchyAPIServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			}))
+			defer hierarchyAPIServer.Close()
+			cfg.HierarchyAPIURL = hierarchyAPIServer.URL
+
+			Convey("Then service Init succeeds, and the hierarchy API check only reports a warning, as only option selectors rely on it", func() {
+				err := svc.Init(ctx, cfg, testBuildTime, testGitCommit, testVersion)
+				So(err, ShouldBeNil)
+				So(hcMock.AddCheckCalls()[1].Name, ShouldResemble, "Hierarchy API")
+
+				state := healthcheck.NewCheckState("Hierarchy API")
+				hcMock.AddCheckCalls()[1].Checker(ctx, state)
+				So(state.Status(), ShouldEqual, healthcheck.StatusWarning)
+				So(state.StatusCode(), ShouldEqual, http.StatusInternalServerError)
+			})
+		})
+
 		Convey("Given that all dependencies are successfully initialised and private endpoints are disabled", func() {
 			cfg.EnablePrivateEndpoints = false
 
@@ -184,10 +252,33 @@ func TestInit(t *testing.T) {
 				So(svc.IdentityClient, ShouldBeNil)
 
 				Convey("And all checks are registered, except Zebedee", func() {
-					So(len(hcMock.AddCheckCalls()), ShouldEqual, 3)
+					So(len(hcMock.AddCheckCalls()), ShouldEqual, 4)
 					So(hcMock.AddCheckCalls()[0].Name, ShouldResemble, "Dataset API")
-					So(hcMock.AddCheckCalls()[1].Name, ShouldResemble, "Kafka Producer")
-					So(hcMock.AddCheckCalls()[2].Name, ShouldResemble, "Mongo DB")
+					So(hcMock.AddCheckCalls()[1].Name, ShouldResemble, "Hierarchy API")
+					So(hcMock.AddCheckCalls()[2].Name, ShouldResemble, "Kafka Producer")
+					So(hcMock.AddCheckCalls()[3].Name, ShouldResemble, "Mongo DB")
+				})
+			})
+		})
+
+		Convey("Given that all dependencies are successfully initialised and the publish event consumer is enabled", func() {
+			cfg.EnablePublishEventConsumer = true
+
+			Convey("Then service Init succeeds, and the kafka consumer and filter output published producer are initialised", func() {
+				err := svc.Init(ctx, cfg, testBuildTime, testGitCommit, testVersion)
+				So(err, ShouldBeNil)
+				So(svc.InstancePublishedConsumer, ShouldResemble, kafkaConsumerMock)
+				So(svc.FilterOutputPublishedProducer, ShouldResemble, kafkaProducerMock)
+
+				Convey("And all checks are registered, including the kafka consumer and filter output published producer", func() {
+					So(len(hcMock.AddCheckCalls()), ShouldEqual, 7)
+					So(hcMock.AddCheckCalls()[0].Name, ShouldResemble, "Dataset API")
+					So(hcMock.AddCheckCalls()[1].Name, ShouldResemble, "Hierarchy API")
+					So(hcMock.AddCheckCalls()[2].Name, ShouldResemble, "Kafka Producer")
+					So(hcMock.AddCheckCalls()[3].Name, ShouldResemble, "Mongo DB")
+					So(hcMock.AddCheckCalls()[4].Name, ShouldResemble, "Kafka Consumer")
+					So(hcMock.AddCheckCalls()[5].Name, ShouldResemble, "Kafka Filter Output Published Producer")
+					So(hcMock.AddCheckCalls()[6].Name, ShouldResemble, "Zebedee")
 				})
 			})
 		})
@@ -300,12 +391,24 @@ func TestClose(t *testing.T) {
 			CloseFunc: funcClose,
 		}
 
+		// Kafka consumer will fail if healthcheck or http server are not stopped
+		kafkaConsumerMock := &kafkatest.IConsumerGroupMock{
+			CloseFunc: func(ctx context.Context, optFuncs ...kafka.OptFunc) error { return funcClose(ctx) },
+		}
+
+		// Kafka filter output published producer will fail if healthcheck or http server are not stopped
+		kafkaPublishedProducerMock := &kafkatest.IProducerMock{
+			CloseFunc: funcClose,
+		}
+
 		svc := &service.Service{
 			Cfg:                           cfg,
 			HealthCheck:                   hcMock,
 			Server:                        serverMock,
 			FilterStore:                   mongoMock,
 			FilterOutputSubmittedProducer: kafkaProducerMock,
+			InstancePublishedConsumer:     kafkaConsumerMock,
+			FilterOutputPublishedProducer: kafkaPublishedProducerMock,
 		}
 
 		Convey("Given that all dependencies succeed to close", func() {
@@ -314,8 +417,10 @@ func TestClose(t *testing.T) {
 				So(err, ShouldBeNil)
 				So(len(hcMock.StopCalls()), ShouldEqual, 1)
 				So(len(serverMock.ShutdownCalls()), ShouldEqual, 1)
+				So(len(kafkaConsumerMock.CloseCalls()), ShouldEqual, 1)
 				So(len(mongoMock.CloseCalls()), ShouldEqual, 1)
 				So(len(kafkaProducerMock.CloseCalls()), ShouldEqual, 1)
+				So(len(kafkaPublishedProducerMock.CloseCalls()), ShouldEqual, 1)
 			})
 		})
 
@@ -329,6 +434,9 @@ func TestClose(t *testing.T) {
 			kafkaProducerMock.CloseFunc = func(ctx context.Context) error {
 				return errKafka
 			}
+			kafkaConsumerMock.CloseFunc = func(ctx context.Context, optFuncs ...kafka.OptFunc) error {
+				return errKafka
+			}
 
 			Convey("Then closing the service fails with the expected error and further dependencies are attempted to close", func() {
 				err = svc.Close(context.Background())
@@ -336,6 +444,7 @@ func TestClose(t *testing.T) {
 				So(err.Error(), ShouldResemble, "failed to shutdown gracefully")
 				So(len(hcMock.StopCalls()), ShouldEqual, 1)
 				So(len(serverMock.ShutdownCalls()), ShouldEqual, 1)
+				So(len(kafkaConsumerMock.CloseCalls()), ShouldEqual, 1)
 				So(len(mongoMock.CloseCalls()), ShouldEqual, 1)
 				So(len(kafkaProducerMock.CloseCalls()), ShouldEqual, 1)
 			})
@@ -353,6 +462,7 @@ func TestClose(t *testing.T) {
 				So(err, ShouldResemble, context.DeadlineExceeded)
 				So(len(hcMock.StopCalls()), ShouldEqual, 1)
 				So(len(serverMock.ShutdownCalls()), ShouldEqual, 1)
+				So(len(kafkaConsumerMock.CloseCalls()), ShouldEqual, 0)
 				So(len(mongoMock.CloseCalls()), ShouldEqual, 0)
 				So(len(kafkaProducerMock.CloseCalls()), ShouldEqual, 0)
 			})